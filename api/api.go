@@ -0,0 +1,10 @@
+// Package api holds the API definitions for the serve command:
+// the OpenAPI spec of the REST API and the protobuf definitions of the gRPC API
+package api
+
+import _ "embed"
+
+// OpenAPISpec is the OpenAPI 3 document describing the REST API
+//
+//go:embed openapi.yaml
+var OpenAPISpec []byte
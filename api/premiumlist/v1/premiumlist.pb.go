@@ -0,0 +1,1363 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: premiumlist/v1/premiumlist.proto
+
+package premiumlistv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Label struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Length        int32                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Label) Reset() {
+	*x = Label{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Label) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Label) ProtoMessage() {}
+
+func (x *Label) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Label.ProtoReflect.Descriptor instead.
+func (*Label) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Label) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Label) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Label) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Label) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type Tag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	LabelCount    int64                  `protobuf:"varint,3,opt,name=label_count,json=labelCount,proto3" json:"label_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Tag) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Tag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tag) GetLabelCount() int64 {
+	if x != nil {
+		return x.LabelCount
+	}
+	return 0
+}
+
+type Tier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tier          int32                  `protobuf:"varint,1,opt,name=tier,proto3" json:"tier,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	PriceReg      *float64               `protobuf:"fixed64,4,opt,name=price_reg,json=priceReg,proto3,oneof" json:"price_reg,omitempty"`
+	PriceRen      *float64               `protobuf:"fixed64,5,opt,name=price_ren,json=priceRen,proto3,oneof" json:"price_ren,omitempty"`
+	PriceRes      *float64               `protobuf:"fixed64,6,opt,name=price_res,json=priceRes,proto3,oneof" json:"price_res,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tier) Reset() {
+	*x = Tier{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tier) ProtoMessage() {}
+
+func (x *Tier) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tier.ProtoReflect.Descriptor instead.
+func (*Tier) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Tier) GetTier() int32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *Tier) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Tier) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Tier) GetPriceReg() float64 {
+	if x != nil && x.PriceReg != nil {
+		return *x.PriceReg
+	}
+	return 0
+}
+
+func (x *Tier) GetPriceRen() float64 {
+	if x != nil && x.PriceRen != nil {
+		return *x.PriceRen
+	}
+	return 0
+}
+
+func (x *Tier) GetPriceRes() float64 {
+	if x != nil && x.PriceRes != nil {
+		return *x.PriceRes
+	}
+	return 0
+}
+
+type ListLabelsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Labels must carry all of these tags
+	Tags      []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	MinLength int32    `protobuf:"varint,2,opt,name=min_length,json=minLength,proto3" json:"min_length,omitempty"`
+	MaxLength int32    `protobuf:"varint,3,opt,name=max_length,json=maxLength,proto3" json:"max_length,omitempty"`
+	Prefix    string   `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Contains  string   `protobuf:"bytes,5,opt,name=contains,proto3" json:"contains,omitempty"`
+	// Defaults to 100 when 0
+	Limit         int32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32 `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLabelsRequest) Reset() {
+	*x = ListLabelsRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLabelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLabelsRequest) ProtoMessage() {}
+
+func (x *ListLabelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLabelsRequest.ProtoReflect.Descriptor instead.
+func (*ListLabelsRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListLabelsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListLabelsRequest) GetMinLength() int32 {
+	if x != nil {
+		return x.MinLength
+	}
+	return 0
+}
+
+func (x *ListLabelsRequest) GetMaxLength() int32 {
+	if x != nil {
+		return x.MaxLength
+	}
+	return 0
+}
+
+func (x *ListLabelsRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListLabelsRequest) GetContains() string {
+	if x != nil {
+		return x.Contains
+	}
+	return ""
+}
+
+func (x *ListLabelsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListLabelsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListLabelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        []*Label               `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLabelsResponse) Reset() {
+	*x = ListLabelsResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLabelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLabelsResponse) ProtoMessage() {}
+
+func (x *ListLabelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLabelsResponse.ProtoReflect.Descriptor instead.
+func (*ListLabelsResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListLabelsResponse) GetLabels() []*Label {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListLabelsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetLabelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLabelRequest) Reset() {
+	*x = GetLabelRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLabelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLabelRequest) ProtoMessage() {}
+
+func (x *GetLabelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLabelRequest.ProtoReflect.Descriptor instead.
+func (*GetLabelRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetLabelRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type CreateLabelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLabelRequest) Reset() {
+	*x = CreateLabelRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLabelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLabelRequest) ProtoMessage() {}
+
+func (x *CreateLabelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLabelRequest.ProtoReflect.Descriptor instead.
+func (*CreateLabelRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateLabelRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *CreateLabelRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type DeleteLabelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLabelRequest) Reset() {
+	*x = DeleteLabelRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLabelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLabelRequest) ProtoMessage() {}
+
+func (x *DeleteLabelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLabelRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLabelRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteLabelRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type DeleteLabelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLabelResponse) Reset() {
+	*x = DeleteLabelResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLabelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLabelResponse) ProtoMessage() {}
+
+func (x *DeleteLabelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLabelResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLabelResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{8}
+}
+
+type AddLabelTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddLabelTagsRequest) Reset() {
+	*x = AddLabelTagsRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddLabelTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddLabelTagsRequest) ProtoMessage() {}
+
+func (x *AddLabelTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddLabelTagsRequest.ProtoReflect.Descriptor instead.
+func (*AddLabelTagsRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AddLabelTagsRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *AddLabelTagsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type RemoveLabelTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLabelTagRequest) Reset() {
+	*x = RemoveLabelTagRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLabelTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLabelTagRequest) ProtoMessage() {}
+
+func (x *RemoveLabelTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveLabelTagRequest.ProtoReflect.Descriptor instead.
+func (*RemoveLabelTagRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RemoveLabelTagRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *RemoveLabelTagRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type RemoveLabelTagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLabelTagResponse) Reset() {
+	*x = RemoveLabelTagResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLabelTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLabelTagResponse) ProtoMessage() {}
+
+func (x *RemoveLabelTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveLabelTagResponse.ProtoReflect.Descriptor instead.
+func (*RemoveLabelTagResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{11}
+}
+
+type ListTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsRequest) Reset() {
+	*x = ListTagsRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsRequest) ProtoMessage() {}
+
+func (x *ListTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsRequest.ProtoReflect.Descriptor instead.
+func (*ListTagsRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{12}
+}
+
+type ListTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*Tag                 `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsResponse) Reset() {
+	*x = ListTagsResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsResponse) ProtoMessage() {}
+
+func (x *ListTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsResponse.ProtoReflect.Descriptor instead.
+func (*ListTagsResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTagsResponse) GetTags() []*Tag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type CreateTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTagRequest) Reset() {
+	*x = CreateTagRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTagRequest) ProtoMessage() {}
+
+func (x *CreateTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTagRequest.ProtoReflect.Descriptor instead.
+func (*CreateTagRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateTagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RenameTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	NewName       string                 `protobuf:"bytes,2,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameTagRequest) Reset() {
+	*x = RenameTagRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameTagRequest) ProtoMessage() {}
+
+func (x *RenameTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameTagRequest.ProtoReflect.Descriptor instead.
+func (*RenameTagRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RenameTagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RenameTagRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+type DeleteTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTagRequest) Reset() {
+	*x = DeleteTagRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTagRequest) ProtoMessage() {}
+
+func (x *DeleteTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTagRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTagRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteTagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteTagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTagResponse) Reset() {
+	*x = DeleteTagResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTagResponse) ProtoMessage() {}
+
+func (x *DeleteTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTagResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTagResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{17}
+}
+
+type ValidateTiersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tiers         []*Tier                `protobuf:"bytes,1,rep,name=tiers,proto3" json:"tiers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateTiersRequest) Reset() {
+	*x = ValidateTiersRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateTiersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTiersRequest) ProtoMessage() {}
+
+func (x *ValidateTiersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTiersRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTiersRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ValidateTiersRequest) GetTiers() []*Tier {
+	if x != nil {
+		return x.Tiers
+	}
+	return nil
+}
+
+type ValidateTiersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	Warnings      []string               `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateTiersResponse) Reset() {
+	*x = ValidateTiersResponse{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateTiersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTiersResponse) ProtoMessage() {}
+
+func (x *ValidateTiersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTiersResponse.ProtoReflect.Descriptor instead.
+func (*ValidateTiersResponse) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ValidateTiersResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateTiersResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ValidateTiersResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type GenerateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tiers []*Tier                `protobuf:"bytes,1,rep,name=tiers,proto3" json:"tiers,omitempty"`
+	// default or cnic-new
+	Format string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	// Required for cnic-new
+	Tld           string   `protobuf:"bytes,3,opt,name=tld,proto3" json:"tld,omitempty"`
+	ExcludeTags   []string `protobuf:"bytes,4,rep,name=exclude_tags,json=excludeTags,proto3" json:"exclude_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateRequest) Reset() {
+	*x = GenerateRequest{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRequest) ProtoMessage() {}
+
+func (x *GenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GenerateRequest) GetTiers() []*Tier {
+	if x != nil {
+		return x.Tiers
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetTld() string {
+	if x != nil {
+		return x.Tld
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetExcludeTags() []string {
+	if x != nil {
+		return x.ExcludeTags
+	}
+	return nil
+}
+
+type GenerateChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateChunk) Reset() {
+	*x = GenerateChunk{}
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateChunk) ProtoMessage() {}
+
+func (x *GenerateChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_premiumlist_v1_premiumlist_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateChunk.ProtoReflect.Descriptor instead.
+func (*GenerateChunk) Descriptor() ([]byte, []int) {
+	return file_premiumlist_v1_premiumlist_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GenerateChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_premiumlist_v1_premiumlist_proto protoreflect.FileDescriptor
+
+const file_premiumlist_v1_premiumlist_proto_rawDesc = "" +
+	"\n" +
+	" premiumlist/v1/premiumlist.proto\x12\x0epremiumlist.v1\"Y\n" +
+	"\x05Label\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x05R\x06length\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\"J\n" +
+	"\x03Tag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vlabel_count\x18\x03 \x01(\x03R\n" +
+	"labelCount\"\xda\x01\n" +
+	"\x04Tier\x12\x12\n" +
+	"\x04tier\x18\x01 \x01(\x05R\x04tier\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12 \n" +
+	"\tprice_reg\x18\x04 \x01(\x01H\x00R\bpriceReg\x88\x01\x01\x12 \n" +
+	"\tprice_ren\x18\x05 \x01(\x01H\x01R\bpriceRen\x88\x01\x01\x12 \n" +
+	"\tprice_res\x18\x06 \x01(\x01H\x02R\bpriceRes\x88\x01\x01B\f\n" +
+	"\n" +
+	"_price_regB\f\n" +
+	"\n" +
+	"_price_renB\f\n" +
+	"\n" +
+	"_price_res\"\xc7\x01\n" +
+	"\x11ListLabelsRequest\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"min_length\x18\x02 \x01(\x05R\tminLength\x12\x1d\n" +
+	"\n" +
+	"max_length\x18\x03 \x01(\x05R\tmaxLength\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12\x1a\n" +
+	"\bcontains\x18\x05 \x01(\tR\bcontains\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\"Y\n" +
+	"\x12ListLabelsResponse\x12-\n" +
+	"\x06labels\x18\x01 \x03(\v2\x15.premiumlist.v1.LabelR\x06labels\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"'\n" +
+	"\x0fGetLabelRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\">\n" +
+	"\x12CreateLabelRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"*\n" +
+	"\x12DeleteLabelRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\"\x15\n" +
+	"\x13DeleteLabelResponse\"?\n" +
+	"\x13AddLabelTagsRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"?\n" +
+	"\x15RemoveLabelTagRequest\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"\x18\n" +
+	"\x16RemoveLabelTagResponse\"\x11\n" +
+	"\x0fListTagsRequest\";\n" +
+	"\x10ListTagsResponse\x12'\n" +
+	"\x04tags\x18\x01 \x03(\v2\x13.premiumlist.v1.TagR\x04tags\"&\n" +
+	"\x10CreateTagRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"A\n" +
+	"\x10RenameTagRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bnew_name\x18\x02 \x01(\tR\anewName\"&\n" +
+	"\x10DeleteTagRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x13\n" +
+	"\x11DeleteTagResponse\"B\n" +
+	"\x14ValidateTiersRequest\x12*\n" +
+	"\x05tiers\x18\x01 \x03(\v2\x14.premiumlist.v1.TierR\x05tiers\"a\n" +
+	"\x15ValidateTiersResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\x12\x1a\n" +
+	"\bwarnings\x18\x03 \x03(\tR\bwarnings\"\x8a\x01\n" +
+	"\x0fGenerateRequest\x12*\n" +
+	"\x05tiers\x18\x01 \x03(\v2\x14.premiumlist.v1.TierR\x05tiers\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x10\n" +
+	"\x03tld\x18\x03 \x01(\tR\x03tld\x12!\n" +
+	"\fexclude_tags\x18\x04 \x03(\tR\vexcludeTags\"#\n" +
+	"\rGenerateChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data2\xd1\a\n" +
+	"\x12PremiumListService\x12S\n" +
+	"\n" +
+	"ListLabels\x12!.premiumlist.v1.ListLabelsRequest\x1a\".premiumlist.v1.ListLabelsResponse\x12B\n" +
+	"\bGetLabel\x12\x1f.premiumlist.v1.GetLabelRequest\x1a\x15.premiumlist.v1.Label\x12H\n" +
+	"\vCreateLabel\x12\".premiumlist.v1.CreateLabelRequest\x1a\x15.premiumlist.v1.Label\x12V\n" +
+	"\vDeleteLabel\x12\".premiumlist.v1.DeleteLabelRequest\x1a#.premiumlist.v1.DeleteLabelResponse\x12J\n" +
+	"\fAddLabelTags\x12#.premiumlist.v1.AddLabelTagsRequest\x1a\x15.premiumlist.v1.Label\x12_\n" +
+	"\x0eRemoveLabelTag\x12%.premiumlist.v1.RemoveLabelTagRequest\x1a&.premiumlist.v1.RemoveLabelTagResponse\x12M\n" +
+	"\bListTags\x12\x1f.premiumlist.v1.ListTagsRequest\x1a .premiumlist.v1.ListTagsResponse\x12B\n" +
+	"\tCreateTag\x12 .premiumlist.v1.CreateTagRequest\x1a\x13.premiumlist.v1.Tag\x12B\n" +
+	"\tRenameTag\x12 .premiumlist.v1.RenameTagRequest\x1a\x13.premiumlist.v1.Tag\x12P\n" +
+	"\tDeleteTag\x12 .premiumlist.v1.DeleteTagRequest\x1a!.premiumlist.v1.DeleteTagResponse\x12\\\n" +
+	"\rValidateTiers\x12$.premiumlist.v1.ValidateTiersRequest\x1a%.premiumlist.v1.ValidateTiersResponse\x12L\n" +
+	"\bGenerate\x12\x1f.premiumlist.v1.GenerateRequest\x1a\x1d.premiumlist.v1.GenerateChunk0\x01B5Z3premium-list-maker/api/premiumlist/v1;premiumlistv1b\x06proto3"
+
+var (
+	file_premiumlist_v1_premiumlist_proto_rawDescOnce sync.Once
+	file_premiumlist_v1_premiumlist_proto_rawDescData []byte
+)
+
+func file_premiumlist_v1_premiumlist_proto_rawDescGZIP() []byte {
+	file_premiumlist_v1_premiumlist_proto_rawDescOnce.Do(func() {
+		file_premiumlist_v1_premiumlist_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_premiumlist_v1_premiumlist_proto_rawDesc), len(file_premiumlist_v1_premiumlist_proto_rawDesc)))
+	})
+	return file_premiumlist_v1_premiumlist_proto_rawDescData
+}
+
+var file_premiumlist_v1_premiumlist_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_premiumlist_v1_premiumlist_proto_goTypes = []any{
+	(*Label)(nil),                  // 0: premiumlist.v1.Label
+	(*Tag)(nil),                    // 1: premiumlist.v1.Tag
+	(*Tier)(nil),                   // 2: premiumlist.v1.Tier
+	(*ListLabelsRequest)(nil),      // 3: premiumlist.v1.ListLabelsRequest
+	(*ListLabelsResponse)(nil),     // 4: premiumlist.v1.ListLabelsResponse
+	(*GetLabelRequest)(nil),        // 5: premiumlist.v1.GetLabelRequest
+	(*CreateLabelRequest)(nil),     // 6: premiumlist.v1.CreateLabelRequest
+	(*DeleteLabelRequest)(nil),     // 7: premiumlist.v1.DeleteLabelRequest
+	(*DeleteLabelResponse)(nil),    // 8: premiumlist.v1.DeleteLabelResponse
+	(*AddLabelTagsRequest)(nil),    // 9: premiumlist.v1.AddLabelTagsRequest
+	(*RemoveLabelTagRequest)(nil),  // 10: premiumlist.v1.RemoveLabelTagRequest
+	(*RemoveLabelTagResponse)(nil), // 11: premiumlist.v1.RemoveLabelTagResponse
+	(*ListTagsRequest)(nil),        // 12: premiumlist.v1.ListTagsRequest
+	(*ListTagsResponse)(nil),       // 13: premiumlist.v1.ListTagsResponse
+	(*CreateTagRequest)(nil),       // 14: premiumlist.v1.CreateTagRequest
+	(*RenameTagRequest)(nil),       // 15: premiumlist.v1.RenameTagRequest
+	(*DeleteTagRequest)(nil),       // 16: premiumlist.v1.DeleteTagRequest
+	(*DeleteTagResponse)(nil),      // 17: premiumlist.v1.DeleteTagResponse
+	(*ValidateTiersRequest)(nil),   // 18: premiumlist.v1.ValidateTiersRequest
+	(*ValidateTiersResponse)(nil),  // 19: premiumlist.v1.ValidateTiersResponse
+	(*GenerateRequest)(nil),        // 20: premiumlist.v1.GenerateRequest
+	(*GenerateChunk)(nil),          // 21: premiumlist.v1.GenerateChunk
+}
+var file_premiumlist_v1_premiumlist_proto_depIdxs = []int32{
+	0,  // 0: premiumlist.v1.ListLabelsResponse.labels:type_name -> premiumlist.v1.Label
+	1,  // 1: premiumlist.v1.ListTagsResponse.tags:type_name -> premiumlist.v1.Tag
+	2,  // 2: premiumlist.v1.ValidateTiersRequest.tiers:type_name -> premiumlist.v1.Tier
+	2,  // 3: premiumlist.v1.GenerateRequest.tiers:type_name -> premiumlist.v1.Tier
+	3,  // 4: premiumlist.v1.PremiumListService.ListLabels:input_type -> premiumlist.v1.ListLabelsRequest
+	5,  // 5: premiumlist.v1.PremiumListService.GetLabel:input_type -> premiumlist.v1.GetLabelRequest
+	6,  // 6: premiumlist.v1.PremiumListService.CreateLabel:input_type -> premiumlist.v1.CreateLabelRequest
+	7,  // 7: premiumlist.v1.PremiumListService.DeleteLabel:input_type -> premiumlist.v1.DeleteLabelRequest
+	9,  // 8: premiumlist.v1.PremiumListService.AddLabelTags:input_type -> premiumlist.v1.AddLabelTagsRequest
+	10, // 9: premiumlist.v1.PremiumListService.RemoveLabelTag:input_type -> premiumlist.v1.RemoveLabelTagRequest
+	12, // 10: premiumlist.v1.PremiumListService.ListTags:input_type -> premiumlist.v1.ListTagsRequest
+	14, // 11: premiumlist.v1.PremiumListService.CreateTag:input_type -> premiumlist.v1.CreateTagRequest
+	15, // 12: premiumlist.v1.PremiumListService.RenameTag:input_type -> premiumlist.v1.RenameTagRequest
+	16, // 13: premiumlist.v1.PremiumListService.DeleteTag:input_type -> premiumlist.v1.DeleteTagRequest
+	18, // 14: premiumlist.v1.PremiumListService.ValidateTiers:input_type -> premiumlist.v1.ValidateTiersRequest
+	20, // 15: premiumlist.v1.PremiumListService.Generate:input_type -> premiumlist.v1.GenerateRequest
+	4,  // 16: premiumlist.v1.PremiumListService.ListLabels:output_type -> premiumlist.v1.ListLabelsResponse
+	0,  // 17: premiumlist.v1.PremiumListService.GetLabel:output_type -> premiumlist.v1.Label
+	0,  // 18: premiumlist.v1.PremiumListService.CreateLabel:output_type -> premiumlist.v1.Label
+	8,  // 19: premiumlist.v1.PremiumListService.DeleteLabel:output_type -> premiumlist.v1.DeleteLabelResponse
+	0,  // 20: premiumlist.v1.PremiumListService.AddLabelTags:output_type -> premiumlist.v1.Label
+	11, // 21: premiumlist.v1.PremiumListService.RemoveLabelTag:output_type -> premiumlist.v1.RemoveLabelTagResponse
+	13, // 22: premiumlist.v1.PremiumListService.ListTags:output_type -> premiumlist.v1.ListTagsResponse
+	1,  // 23: premiumlist.v1.PremiumListService.CreateTag:output_type -> premiumlist.v1.Tag
+	1,  // 24: premiumlist.v1.PremiumListService.RenameTag:output_type -> premiumlist.v1.Tag
+	17, // 25: premiumlist.v1.PremiumListService.DeleteTag:output_type -> premiumlist.v1.DeleteTagResponse
+	19, // 26: premiumlist.v1.PremiumListService.ValidateTiers:output_type -> premiumlist.v1.ValidateTiersResponse
+	21, // 27: premiumlist.v1.PremiumListService.Generate:output_type -> premiumlist.v1.GenerateChunk
+	16, // [16:28] is the sub-list for method output_type
+	4,  // [4:16] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_premiumlist_v1_premiumlist_proto_init() }
+func file_premiumlist_v1_premiumlist_proto_init() {
+	if File_premiumlist_v1_premiumlist_proto != nil {
+		return
+	}
+	file_premiumlist_v1_premiumlist_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_premiumlist_v1_premiumlist_proto_rawDesc), len(file_premiumlist_v1_premiumlist_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_premiumlist_v1_premiumlist_proto_goTypes,
+		DependencyIndexes: file_premiumlist_v1_premiumlist_proto_depIdxs,
+		MessageInfos:      file_premiumlist_v1_premiumlist_proto_msgTypes,
+	}.Build()
+	File_premiumlist_v1_premiumlist_proto = out.File
+	file_premiumlist_v1_premiumlist_proto_goTypes = nil
+	file_premiumlist_v1_premiumlist_proto_depIdxs = nil
+}
@@ -0,0 +1,557 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: premiumlist/v1/premiumlist.proto
+
+package premiumlistv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PremiumListService_ListLabels_FullMethodName     = "/premiumlist.v1.PremiumListService/ListLabels"
+	PremiumListService_GetLabel_FullMethodName       = "/premiumlist.v1.PremiumListService/GetLabel"
+	PremiumListService_CreateLabel_FullMethodName    = "/premiumlist.v1.PremiumListService/CreateLabel"
+	PremiumListService_DeleteLabel_FullMethodName    = "/premiumlist.v1.PremiumListService/DeleteLabel"
+	PremiumListService_AddLabelTags_FullMethodName   = "/premiumlist.v1.PremiumListService/AddLabelTags"
+	PremiumListService_RemoveLabelTag_FullMethodName = "/premiumlist.v1.PremiumListService/RemoveLabelTag"
+	PremiumListService_ListTags_FullMethodName       = "/premiumlist.v1.PremiumListService/ListTags"
+	PremiumListService_CreateTag_FullMethodName      = "/premiumlist.v1.PremiumListService/CreateTag"
+	PremiumListService_RenameTag_FullMethodName      = "/premiumlist.v1.PremiumListService/RenameTag"
+	PremiumListService_DeleteTag_FullMethodName      = "/premiumlist.v1.PremiumListService/DeleteTag"
+	PremiumListService_ValidateTiers_FullMethodName  = "/premiumlist.v1.PremiumListService/ValidateTiers"
+	PremiumListService_Generate_FullMethodName       = "/premiumlist.v1.PremiumListService/Generate"
+)
+
+// PremiumListServiceClient is the client API for PremiumListService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PremiumListService exposes the label database and premium list generation.
+// It mirrors the REST API served under /api.
+type PremiumListServiceClient interface {
+	// Labels
+	ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error)
+	GetLabel(ctx context.Context, in *GetLabelRequest, opts ...grpc.CallOption) (*Label, error)
+	CreateLabel(ctx context.Context, in *CreateLabelRequest, opts ...grpc.CallOption) (*Label, error)
+	DeleteLabel(ctx context.Context, in *DeleteLabelRequest, opts ...grpc.CallOption) (*DeleteLabelResponse, error)
+	AddLabelTags(ctx context.Context, in *AddLabelTagsRequest, opts ...grpc.CallOption) (*Label, error)
+	RemoveLabelTag(ctx context.Context, in *RemoveLabelTagRequest, opts ...grpc.CallOption) (*RemoveLabelTagResponse, error)
+	// Tags
+	ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error)
+	CreateTag(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Tag, error)
+	RenameTag(ctx context.Context, in *RenameTagRequest, opts ...grpc.CallOption) (*Tag, error)
+	DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*DeleteTagResponse, error)
+	// Tiers and generation
+	ValidateTiers(ctx context.Context, in *ValidateTiersRequest, opts ...grpc.CallOption) (*ValidateTiersResponse, error)
+	// Generate streams the generated CSV in chunks
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateChunk], error)
+}
+
+type premiumListServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPremiumListServiceClient(cc grpc.ClientConnInterface) PremiumListServiceClient {
+	return &premiumListServiceClient{cc}
+}
+
+func (c *premiumListServiceClient) ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLabelsResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_ListLabels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) GetLabel(ctx context.Context, in *GetLabelRequest, opts ...grpc.CallOption) (*Label, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Label)
+	err := c.cc.Invoke(ctx, PremiumListService_GetLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) CreateLabel(ctx context.Context, in *CreateLabelRequest, opts ...grpc.CallOption) (*Label, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Label)
+	err := c.cc.Invoke(ctx, PremiumListService_CreateLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) DeleteLabel(ctx context.Context, in *DeleteLabelRequest, opts ...grpc.CallOption) (*DeleteLabelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLabelResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_DeleteLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) AddLabelTags(ctx context.Context, in *AddLabelTagsRequest, opts ...grpc.CallOption) (*Label, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Label)
+	err := c.cc.Invoke(ctx, PremiumListService_AddLabelTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) RemoveLabelTag(ctx context.Context, in *RemoveLabelTagRequest, opts ...grpc.CallOption) (*RemoveLabelTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveLabelTagResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_RemoveLabelTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTagsResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_ListTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) CreateTag(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Tag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tag)
+	err := c.cc.Invoke(ctx, PremiumListService_CreateTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) RenameTag(ctx context.Context, in *RenameTagRequest, opts ...grpc.CallOption) (*Tag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tag)
+	err := c.cc.Invoke(ctx, PremiumListService_RenameTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*DeleteTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTagResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_DeleteTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) ValidateTiers(ctx context.Context, in *ValidateTiersRequest, opts ...grpc.CallOption) (*ValidateTiersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateTiersResponse)
+	err := c.cc.Invoke(ctx, PremiumListService_ValidateTiers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *premiumListServiceClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PremiumListService_ServiceDesc.Streams[0], PremiumListService_Generate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GenerateRequest, GenerateChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PremiumListService_GenerateClient = grpc.ServerStreamingClient[GenerateChunk]
+
+// PremiumListServiceServer is the server API for PremiumListService service.
+// All implementations must embed UnimplementedPremiumListServiceServer
+// for forward compatibility.
+//
+// PremiumListService exposes the label database and premium list generation.
+// It mirrors the REST API served under /api.
+type PremiumListServiceServer interface {
+	// Labels
+	ListLabels(context.Context, *ListLabelsRequest) (*ListLabelsResponse, error)
+	GetLabel(context.Context, *GetLabelRequest) (*Label, error)
+	CreateLabel(context.Context, *CreateLabelRequest) (*Label, error)
+	DeleteLabel(context.Context, *DeleteLabelRequest) (*DeleteLabelResponse, error)
+	AddLabelTags(context.Context, *AddLabelTagsRequest) (*Label, error)
+	RemoveLabelTag(context.Context, *RemoveLabelTagRequest) (*RemoveLabelTagResponse, error)
+	// Tags
+	ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error)
+	CreateTag(context.Context, *CreateTagRequest) (*Tag, error)
+	RenameTag(context.Context, *RenameTagRequest) (*Tag, error)
+	DeleteTag(context.Context, *DeleteTagRequest) (*DeleteTagResponse, error)
+	// Tiers and generation
+	ValidateTiers(context.Context, *ValidateTiersRequest) (*ValidateTiersResponse, error)
+	// Generate streams the generated CSV in chunks
+	Generate(*GenerateRequest, grpc.ServerStreamingServer[GenerateChunk]) error
+	mustEmbedUnimplementedPremiumListServiceServer()
+}
+
+// UnimplementedPremiumListServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPremiumListServiceServer struct{}
+
+func (UnimplementedPremiumListServiceServer) ListLabels(context.Context, *ListLabelsRequest) (*ListLabelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLabels not implemented")
+}
+func (UnimplementedPremiumListServiceServer) GetLabel(context.Context, *GetLabelRequest) (*Label, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLabel not implemented")
+}
+func (UnimplementedPremiumListServiceServer) CreateLabel(context.Context, *CreateLabelRequest) (*Label, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateLabel not implemented")
+}
+func (UnimplementedPremiumListServiceServer) DeleteLabel(context.Context, *DeleteLabelRequest) (*DeleteLabelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteLabel not implemented")
+}
+func (UnimplementedPremiumListServiceServer) AddLabelTags(context.Context, *AddLabelTagsRequest) (*Label, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddLabelTags not implemented")
+}
+func (UnimplementedPremiumListServiceServer) RemoveLabelTag(context.Context, *RemoveLabelTagRequest) (*RemoveLabelTagResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveLabelTag not implemented")
+}
+func (UnimplementedPremiumListServiceServer) ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTags not implemented")
+}
+func (UnimplementedPremiumListServiceServer) CreateTag(context.Context, *CreateTagRequest) (*Tag, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTag not implemented")
+}
+func (UnimplementedPremiumListServiceServer) RenameTag(context.Context, *RenameTagRequest) (*Tag, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenameTag not implemented")
+}
+func (UnimplementedPremiumListServiceServer) DeleteTag(context.Context, *DeleteTagRequest) (*DeleteTagResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTag not implemented")
+}
+func (UnimplementedPremiumListServiceServer) ValidateTiers(context.Context, *ValidateTiersRequest) (*ValidateTiersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ValidateTiers not implemented")
+}
+func (UnimplementedPremiumListServiceServer) Generate(*GenerateRequest, grpc.ServerStreamingServer[GenerateChunk]) error {
+	return status.Error(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedPremiumListServiceServer) mustEmbedUnimplementedPremiumListServiceServer() {}
+func (UnimplementedPremiumListServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafePremiumListServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PremiumListServiceServer will
+// result in compilation errors.
+type UnsafePremiumListServiceServer interface {
+	mustEmbedUnimplementedPremiumListServiceServer()
+}
+
+func RegisterPremiumListServiceServer(s grpc.ServiceRegistrar, srv PremiumListServiceServer) {
+	// If the following call panics, it indicates UnimplementedPremiumListServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PremiumListService_ServiceDesc, srv)
+}
+
+func _PremiumListService_ListLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).ListLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_ListLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).ListLabels(ctx, req.(*ListLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_GetLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).GetLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_GetLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).GetLabel(ctx, req.(*GetLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_CreateLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).CreateLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_CreateLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).CreateLabel(ctx, req.(*CreateLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_DeleteLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).DeleteLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_DeleteLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).DeleteLabel(ctx, req.(*DeleteLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_AddLabelTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddLabelTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).AddLabelTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_AddLabelTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).AddLabelTags(ctx, req.(*AddLabelTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_RemoveLabelTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveLabelTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).RemoveLabelTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_RemoveLabelTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).RemoveLabelTag(ctx, req.(*RemoveLabelTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_ListTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).ListTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_ListTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).ListTags(ctx, req.(*ListTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_CreateTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).CreateTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_CreateTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).CreateTag(ctx, req.(*CreateTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_RenameTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).RenameTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_RenameTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).RenameTag(ctx, req.(*RenameTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_DeleteTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).DeleteTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_DeleteTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).DeleteTag(ctx, req.(*DeleteTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_ValidateTiers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTiersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PremiumListServiceServer).ValidateTiers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PremiumListService_ValidateTiers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PremiumListServiceServer).ValidateTiers(ctx, req.(*ValidateTiersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PremiumListService_Generate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PremiumListServiceServer).Generate(m, &grpc.GenericServerStream[GenerateRequest, GenerateChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PremiumListService_GenerateServer = grpc.ServerStreamingServer[GenerateChunk]
+
+// PremiumListService_ServiceDesc is the grpc.ServiceDesc for PremiumListService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PremiumListService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "premiumlist.v1.PremiumListService",
+	HandlerType: (*PremiumListServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLabels",
+			Handler:    _PremiumListService_ListLabels_Handler,
+		},
+		{
+			MethodName: "GetLabel",
+			Handler:    _PremiumListService_GetLabel_Handler,
+		},
+		{
+			MethodName: "CreateLabel",
+			Handler:    _PremiumListService_CreateLabel_Handler,
+		},
+		{
+			MethodName: "DeleteLabel",
+			Handler:    _PremiumListService_DeleteLabel_Handler,
+		},
+		{
+			MethodName: "AddLabelTags",
+			Handler:    _PremiumListService_AddLabelTags_Handler,
+		},
+		{
+			MethodName: "RemoveLabelTag",
+			Handler:    _PremiumListService_RemoveLabelTag_Handler,
+		},
+		{
+			MethodName: "ListTags",
+			Handler:    _PremiumListService_ListTags_Handler,
+		},
+		{
+			MethodName: "CreateTag",
+			Handler:    _PremiumListService_CreateTag_Handler,
+		},
+		{
+			MethodName: "RenameTag",
+			Handler:    _PremiumListService_RenameTag_Handler,
+		},
+		{
+			MethodName: "DeleteTag",
+			Handler:    _PremiumListService_DeleteTag_Handler,
+		},
+		{
+			MethodName: "ValidateTiers",
+			Handler:    _PremiumListService_ValidateTiers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Generate",
+			Handler:       _PremiumListService_Generate_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "premiumlist/v1/premiumlist.proto",
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/archive"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveTLD    string
+	archiveDate   string
+	archiveID     string
+	archiveLabels []string
+	archiveOutput string
+
+	// generateArchive is the --archive directory of generate
+	generateArchive string
+)
+
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Keep generated lists in a compressed archive and query past lists by date",
+		Long: `Store generated lists in an archive directory as zstd-compressed JSONL files with an index,
+instead of keeping loose CSVs. generate --archive <dir> archives every new list; archive add imports existing ones.`,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <archive-dir> <list.csv>...",
+		Short: "Archive existing generated CSV lists",
+		Long:  "Archive generated CSV lists, dated by --date or else by their modification time. The originals are left in place.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  runArchiveAdd,
+	}
+	addCmd.Flags().StringVar(&archiveTLD, "tld", "", "TLD of the lists")
+	addCmd.Flags().StringVar(&archiveDate, "date", "", "Generation date of the lists, YYYY-MM-DD or RFC 3339 (default: file modification time)")
+	cmd.AddCommand(addCmd)
+
+	listCmd := &cobra.Command{
+		Use:   "list <archive-dir>",
+		Short: "List the archived lists",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runArchiveList,
+	}
+	listCmd.Flags().StringVar(&archiveTLD, "tld", "", "Only list lists of this TLD")
+	cmd.AddCommand(listCmd)
+
+	showCmd := &cobra.Command{
+		Use:   "show <archive-dir>",
+		Short: "Write an archived list as CSV",
+		Long: `Write the list that was current at --date (the last one archived on or before it), or the list with --id, as CSV.
+--label limits the output to some labels, e.g. to look up the price a name had at a given date.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runArchiveShow,
+	}
+	showCmd.Flags().StringVar(&archiveTLD, "tld", "", "Only consider lists of this TLD")
+	showCmd.Flags().StringVar(&archiveDate, "date", "", "Date, YYYY-MM-DD (end of the day, UTC) or RFC 3339 (default: now)")
+	showCmd.Flags().StringVar(&archiveID, "id", "", "ID of the list, as shown by archive list")
+	showCmd.Flags().StringSliceVar(&archiveLabels, "label", nil, "Only write rows of these labels")
+	showCmd.Flags().StringVarP(&archiveOutput, "output", "o", "", "Write to this file instead of stdout")
+	addQuotingFlags(showCmd)
+	cmd.AddCommand(showCmd)
+
+	return cmd
+}
+
+// parseArchiveDate parses a date flag; a day without time means the end of that day in UTC
+func parseArchiveDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
+
+func runArchiveAdd(cmd *cobra.Command, args []string) error {
+	a, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+
+	var archivedAt time.Time
+	if archiveDate != "" {
+		if archivedAt, err = parseArchiveDate(archiveDate); err != nil {
+			return err
+		}
+	}
+	for _, path := range args[1:] {
+		createdAt := archivedAt
+		if createdAt.IsZero() {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			createdAt = info.ModTime()
+		}
+		entry, err := a.AddCSV(path, strings.ToLower(archiveTLD), createdAt)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", path, err)
+		}
+		printArchived(entry)
+	}
+	return nil
+}
+
+// printArchived prints a line about a newly archived list
+func printArchived(entry *archive.Entry) {
+	fmt.Printf("Archived %s as %s (%d rows, %d bytes)\n", entry.Name, entry.ID, entry.Rows, entry.SizeBytes)
+}
+
+func runArchiveList(cmd *cobra.Command, args []string) error {
+	a, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+	entries, err := a.Entries()
+	if err != nil {
+		return err
+	}
+
+	tld := strings.ToLower(archiveTLD)
+	fmt.Printf("%-40s %-20s %-8s %10s %12s\n", "ID", "CREATED", "TLD", "ROWS", "BYTES")
+	for _, e := range entries {
+		if tld != "" && e.TLD != tld {
+			continue
+		}
+		fmt.Printf("%-40s %-20s %-8s %10d %12d\n", e.ID, e.CreatedAt.Format(time.RFC3339), e.TLD, e.Rows, e.SizeBytes)
+	}
+	return nil
+}
+
+func runArchiveShow(cmd *cobra.Command, args []string) error {
+	quoting, err := quotingOption()
+	if err != nil {
+		return err
+	}
+	a, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+
+	var entry *archive.Entry
+	if archiveID != "" {
+		entry, err = a.Find(archiveID)
+	} else {
+		asOf := time.Now()
+		if archiveDate != "" {
+			if asOf, err = parseArchiveDate(archiveDate); err != nil {
+				return err
+			}
+		}
+		entry, err = a.At(asOf, strings.ToLower(archiveTLD))
+	}
+	if err != nil {
+		return err
+	}
+
+	var keep func(map[string]string) bool
+	if len(archiveLabels) > 0 {
+		labels := make(map[string]bool, len(archiveLabels))
+		for _, l := range archiveLabels {
+			labels[strings.ToLower(l)] = true
+		}
+		// The label is in the first column of every format
+		column := entry.Columns[0]
+		keep = func(row map[string]string) bool { return labels[strings.ToLower(row[column])] }
+	}
+
+	var w io.Writer = os.Stdout
+	if archiveOutput != "" {
+		f, err := os.Create(archiveOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	fmt.Fprintf(os.Stderr, "List %s of %s\n", entry.ID, entry.CreatedAt.Format(time.RFC3339))
+	return a.WriteCSV(entry, w, quoting, keep)
+}
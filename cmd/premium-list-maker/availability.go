@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"premium-list-maker/internal/availability"
+)
+
+// availabilityOptions holds the live availability check settings for deduplicate
+type availabilityOptions struct {
+	Method      string // dns or epp
+	TLD         string
+	Concurrency int
+	Rate        float64
+	Resolver    string
+	EPP         availability.EPPConfig
+}
+
+// newAvailabilityChecker creates the checker for the configured method
+// The returned close function must be called when done
+func newAvailabilityChecker(opts availabilityOptions) (availability.Checker, func(), error) {
+	switch opts.Method {
+	case "dns":
+		return availability.NewDNSChecker(opts.Resolver, opts.Concurrency, opts.Rate), func() {}, nil
+	case "epp":
+		if opts.EPP.Server == "" || opts.EPP.Username == "" {
+			return nil, nil, fmt.Errorf("--epp-server and --epp-user are required for EPP checks")
+		}
+		opts.EPP.Rate = opts.Rate
+		checker, err := availability.NewEPPChecker(opts.EPP)
+		if err != nil {
+			return nil, nil, err
+		}
+		return checker, func() { checker.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown check method: %s (expected dns or epp)", opts.Method)
+	}
+}
+
+// checkPremiumAvailability checks every premium label not already matched under the TLD
+// and records registered labels in existingDomains with the given source index
+// Returns the number of labels whose status could not be determined
+func checkPremiumAvailability(premiumPath string, existingDomains map[string]int, sourceIdx int, opts availabilityOptions) (int, error) {
+	tld := strings.Trim(strings.ToLower(opts.TLD), ".")
+	if tld == "" {
+		return 0, fmt.Errorf("--tld is required for availability checks")
+	}
+
+	// Collect labels that still need a check
+	premiumFile, err := os.Open(premiumPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open premium list: %w", err)
+	}
+	defer premiumFile.Close()
+
+	var names []string
+	seen := make(map[string]bool)
+	err = forEachCSVDomain(premiumFile, func(label string) error {
+		if _, matched := existingDomains[label]; !matched && !seen[label] {
+			seen[label] = true
+			names = append(names, label+"."+tld)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error reading premium list: %w", err)
+	}
+
+	checker, closeChecker, err := newAvailabilityChecker(opts)
+	if err != nil {
+		return 0, err
+	}
+	defer closeChecker()
+
+	fmt.Printf("Checking availability of %d label(s) via %s...\n", len(names), opts.Method)
+
+	var registered, unknown, checked int
+	err = checker.CheckNames(context.Background(), names, func(result availability.Result) {
+		checked++
+		if result.Err != nil {
+			unknown++
+			if unknown <= 5 {
+				fmt.Printf("  Warning: could not check %s: %v\n", result.Name, result.Err)
+			}
+		} else if result.Registered {
+			label := strings.TrimSuffix(strings.ToLower(result.Name), "."+tld)
+			existingDomains[label] = sourceIdx
+			registered++
+		}
+		if checked%1000 == 0 {
+			fmt.Printf("  [Heartbeat] Checked %d/%d names\n", checked, len(names))
+		}
+	})
+	if err != nil {
+		return unknown, err
+	}
+
+	fmt.Printf("Found %d registered label(s) (%d could not be checked).\n", registered, unknown)
+	return unknown, nil
+}
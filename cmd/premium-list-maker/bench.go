@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchRows           int
+	benchBatchSize      int
+	benchCommitInterval int
+	benchWorkers        int
+	benchAutoTag        bool
+	benchDir            string
+	benchSummaryJSON    string
+)
+
+// benchSummary is the machine-readable result written with --summary-json
+type benchSummary struct {
+	Rows           int     `json:"rows"`
+	Imported       int     `json:"imported"`
+	BatchSize      int     `json:"batch_size"`
+	CommitInterval int     `json:"commit_interval"`
+	Workers        int     `json:"workers"`
+	AutoTag        bool    `json:"auto_tag"`
+	CacheSizeKB    int     `json:"cache_size_kb"`
+	MmapSizeMB     int     `json:"mmap_size_mb"`
+	Synchronous    string  `json:"synchronous"`
+	DurationMS     int64   `json:"duration_ms"`
+	RowsPerSecond  float64 `json:"rows_per_second"`
+	MaxMemoryMB    uint64  `json:"max_memory_mb"`
+	DatabaseBytes  int64   `json:"database_bytes"`
+}
+
+func newBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure performance under the current settings",
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Measure import throughput with synthetic labels",
+		Long: `Import --rows synthetic labels into a new, temporary database and report rows per second and peak memory.
+The global tuning flags (--tuning, --cache-size, ...) and the batch flags apply, so settings can be compared per machine,
+and --summary-json gives a result to track between releases. The database given with --db is not touched.`,
+		Args: cobra.NoArgs,
+		RunE: runBenchImport,
+	}
+	importCmd.Flags().IntVar(&benchRows, "rows", 1000000, "Number of synthetic labels to import")
+	importCmd.Flags().IntVar(&benchBatchSize, "batch-size", importer.DefaultBatchSize, "Rows parsed and inserted per batch")
+	importCmd.Flags().IntVar(&benchCommitInterval, "commit-interval", importer.DefaultCommitInterval, "Labels per transaction")
+	importCmd.Flags().IntVar(&benchWorkers, "workers", runtime.NumCPU(), "Goroutines validating and tagging batches")
+	importCmd.Flags().BoolVar(&benchAutoTag, "auto-tag", true, "Add length and content tags, as import does")
+	importCmd.Flags().StringVar(&benchDir, "dir", "", "Directory for the temporary database, to measure a given disk (default: the system temp directory)")
+	importCmd.Flags().StringVar(&benchSummaryJSON, "summary-json", "", "Write a machine-readable JSON result to this path")
+	cmd.AddCommand(importCmd)
+
+	return cmd
+}
+
+func runBenchImport(cmd *cobra.Command, args []string) error {
+	if benchRows <= 0 {
+		return fmt.Errorf("--rows must be positive")
+	}
+
+	dir, err := os.MkdirTemp(benchDir, "premium-list-bench-")
+	if err != nil {
+		return fmt.Errorf("failed to create benchmark directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The benchmark always measures a SQLite file, whatever --db-driver is
+	database, err := openDriverDatabase(db.DriverSQLite, filepath.Join(dir, "bench.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	// Generate the CSV while it is imported, so the measurement includes parsing but not disk reads
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeBenchLabels(w, benchRows))
+	}()
+
+	opts := []importer.ImportOption{
+		importer.WithBatchSize(benchBatchSize),
+		importer.WithCommitInterval(benchCommitInterval),
+		importer.WithWorkers(benchWorkers),
+	}
+	if benchAutoTag {
+		opts = append(opts, importer.WithAutoTag())
+	}
+
+	fmt.Printf("Importing %d synthetic labels (batch size %d, commit interval %d, %d workers, cache %d KB, mmap %d MB, synchronous %s)...\n",
+		benchRows, benchBatchSize, benchCommitInterval, benchWorkers, dbTuning.CacheSizeKB, dbTuning.MmapSizeMB, dbTuning.Synchronous)
+	start := time.Now()
+	stats, err := importer.ImportCSVReader(database, r, opts...)
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("benchmark import failed: %w", err)
+	}
+	duration := time.Since(start)
+
+	size, err := database.Size()
+	if err != nil {
+		return err
+	}
+
+	summary := benchSummary{
+		Rows:           benchRows,
+		Imported:       stats.Imported,
+		BatchSize:      benchBatchSize,
+		CommitInterval: benchCommitInterval,
+		Workers:        benchWorkers,
+		AutoTag:        benchAutoTag,
+		CacheSizeKB:    dbTuning.CacheSizeKB,
+		MmapSizeMB:     dbTuning.MmapSizeMB,
+		Synchronous:    dbTuning.Synchronous,
+		DurationMS:     duration.Milliseconds(),
+		RowsPerSecond:  float64(stats.Imported) / duration.Seconds(),
+		MaxMemoryMB:    stats.MaxMemoryMB,
+		DatabaseBytes:  size,
+	}
+
+	fmt.Printf("  Imported:     %d labels\n", summary.Imported)
+	fmt.Printf("  Duration:     %v\n", duration.Round(time.Millisecond))
+	fmt.Printf("  Throughput:   %.0f rows/sec\n", summary.RowsPerSecond)
+	fmt.Printf("  Peak memory:  %d MB\n", summary.MaxMemoryMB)
+	fmt.Printf("  Database:     %.1f MB\n", float64(size)/1024/1024)
+
+	if benchSummaryJSON != "" {
+		if err := writeJSONFile(benchSummaryJSON, summary); err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeBenchLabels writes n distinct, valid labels of varying length, one per line
+func writeBenchLabels(w io.Writer, n int) error {
+	buf := make([]byte, 0, 64*1024)
+	for i := 0; i < n; i++ {
+		// A letter prefix keeps the labels valid; base 36 varies their length and characters
+		buf = append(buf, "b"...)
+		buf = strconv.AppendInt(buf, int64(i), 36)
+		buf = append(buf, '\n')
+		if len(buf) > 60*1024 {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
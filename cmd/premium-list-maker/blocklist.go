@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/export"
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	blocklistTLD          string
+	blocklistFormat       string
+	blocklistReservedTags []string
+	blocklistExcludeTags  []string
+	blocklistTTL          int
+	blocklistRPZOrigin    string
+	blocklistNameserver   string
+	blocklistUploads      []string
+)
+
+func newExportBlocklistCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-blocklist <tiers.json> <output>",
+		Short: "Write the premium and reserved names as a zone include or RPZ blocklist",
+		Long: `Write the premium names matched by the tiers and the reserved names (labels carrying --reserved-tag)
+for provisioning systems that block registration of listed names until pricing goes live.
+
+Formats:
+  zone  records relative to the TLD origin, for $INCLUDE in a zone: <label> <ttl> IN TXT "<reason>"
+  rpz   a response policy zone answering NXDOMAIN for every <label>.<tld>`,
+		Args: cobra.ExactArgs(2),
+		RunE: runExportBlocklist,
+	}
+
+	cmd.Flags().StringVar(&blocklistTLD, "tld", "", "TLD of the listed names")
+	cmd.Flags().StringVar(&blocklistFormat, "format", export.BlocklistZone, "Blocklist format (zone, rpz)")
+	cmd.Flags().StringArrayVar(&blocklistReservedTags, "reserved-tag", nil, "Tag marking reserved labels; repeatable, the tag is recorded as the reason")
+	cmd.Flags().StringSliceVar(&blocklistExcludeTags, "exclude-tags", nil, "Tags to exclude from the premium names (e.g. registered)")
+	cmd.Flags().IntVar(&blocklistTTL, "ttl", 3600, "TTL of the records")
+	cmd.Flags().StringVar(&blocklistRPZOrigin, "rpz-origin", "", "Origin of the RPZ zone (default: rpz.<tld>)")
+	cmd.Flags().StringVar(&blocklistNameserver, "rpz-nameserver", "localhost.", "Nameserver in the SOA and NS records of the RPZ zone")
+	cmd.Flags().StringArrayVar(&blocklistUploads, "upload", nil, "Upload the blocklist after it is written (sftp://, ftps:// or s3:// URI, repeatable)")
+	addUploadFlags(cmd)
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runExportBlocklist(cmd *cobra.Command, args []string) error {
+	tiersPath, outputPath := args[0], args[1]
+
+	tld := strings.Trim(strings.ToLower(blocklistTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	rpzOrigin := blocklistRPZOrigin
+	if rpzOrigin == "" {
+		rpzOrigin = "rpz." + tld
+	}
+
+	tiers, err := generator.LoadTiers(tiersPath)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	entries, excluded, err := generator.MatchLabels(database, tiers, blocklistExcludeTags)
+	if err != nil {
+		return err
+	}
+	reserved, err := loadReservedNames(database, blocklistReservedTags)
+	if err != nil {
+		return err
+	}
+
+	list := export.Blocklist{
+		TLD:         tld,
+		Format:      blocklistFormat,
+		GeneratedAt: time.Now(),
+		TTL:         blocklistTTL,
+		RPZOrigin:   rpzOrigin,
+		Nameserver:  blocklistNameserver,
+	}
+	listed, err := export.WriteBlocklist(outputPath, list, entries, reserved)
+	if err != nil {
+		return err
+	}
+
+	if excluded > 0 {
+		fmt.Printf("Excluded %d label(s) by tags\n", excluded)
+	}
+	fmt.Printf("Blocklist of %d name(s) (%d premium, %d reserved, format: %s)\n", listed, len(entries), len(reserved), blocklistFormat)
+	fmt.Printf("Blocklist saved to: %s\n", outputPath)
+	return uploadFile(outputPath, blocklistUploads)
+}
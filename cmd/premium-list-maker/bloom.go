@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+)
+
+// bloomFilter is a fixed-size probabilistic set used to pre-screen existing domains
+// It can report false positives but never false negatives
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64 // number of bits
+	hashes int    // number of hash functions
+}
+
+// newBloomFilter sizes a bloom filter for n items at the given false positive rate
+func newBloomFilter(n uint64, fpRate float64) *bloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	size := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := int(math.Round(float64(size) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// hashPair returns two independent hashes for double hashing
+func (b *bloomFilter) hashPair(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	h1 := h.Sum64()
+	h2 := h1>>33 | h1<<31
+	h2 ^= 0x9e3779b97f4a7c15
+	return h1, h2 | 1
+}
+
+// Add adds a string to the filter
+func (b *bloomFilter) Add(s string) {
+	h1, h2 := b.hashPair(s)
+	for i := 0; i < b.hashes; i++ {
+		pos := (h1 + uint64(i)*h2) % b.size
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// Test reports whether the string may be in the filter
+func (b *bloomFilter) Test(s string) bool {
+	h1, h2 := b.hashPair(s)
+	for i := 0; i < b.hashes; i++ {
+		pos := (h1 + uint64(i)*h2) % b.size
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SizeMB returns the memory used by the filter's bit array
+func (b *bloomFilter) SizeMB() uint64 {
+	return uint64(len(b.bits)) * 8 / 1024 / 1024
+}
+
+// loadExistingDomainsBloom finds the premium labels present in the existing domains lists
+// without holding the full existing lists in memory:
+//  1. stream the existing lists into a bloom filter
+//  2. collect premium labels that the filter reports as possibly present
+//  3. stream the existing lists again to confirm exact matches for those candidates
+//
+// Only confirmed matches are returned (label -> index of first source), so the result is exact
+func loadExistingDomainsBloom(sources []string, format, premiumPath string, fpRate float64) (map[string]int, error) {
+	// Estimate item count from file sizes, assuming at least 8 bytes per line
+	var estimate uint64
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		estimate += uint64(info.Size()) / 8
+	}
+
+	filter := newBloomFilter(estimate, fpRate)
+	fmt.Printf("Building bloom filter (%d MB for up to %d domains)...\n", filter.SizeMB(), estimate)
+
+	for _, source := range sources {
+		err := forEachExistingDomain(source, format, func(label string) error {
+			filter.Add(label)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	// Collect candidates from the premium list
+	premiumFile, err := os.Open(premiumPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open premium list: %w", err)
+	}
+	defer premiumFile.Close()
+
+	candidates := make(map[string]bool)
+	err = forEachCSVDomain(premiumFile, func(label string) error {
+		if filter.Test(label) {
+			candidates[label] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading premium list: %w", err)
+	}
+	fmt.Printf("Verifying %d candidate(s) against existing domains...\n", len(candidates))
+
+	// Exact verification pass
+	confirmed := make(map[string]int)
+	if len(candidates) == 0 {
+		return confirmed, nil
+	}
+	for i, source := range sources {
+		err := forEachExistingDomain(source, format, func(label string) error {
+			if _, exists := confirmed[label]; !exists && candidates[label] {
+				confirmed[label] = i
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	return confirmed, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadExistingDomainsBloom_FalsePositives(t *testing.T) {
+	dir := t.TempDir()
+	sources, premium := writeDomainLists(t, dir)
+	want := premiumMatches(t, sources, premium)
+
+	// A filter this loose reports most absent labels, which only the verify pass can drop
+	// It is sized like the one of loadExistingDomainsBloom, by the bytes of the lists
+	const fpRate = 0.99
+	var estimate uint64
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		estimate += uint64(info.Size()) / 8
+	}
+	filter := newBloomFilter(estimate, fpRate)
+	for _, source := range sources {
+		if err := forEachExistingDomain(source, "csv", func(label string) error {
+			filter.Add(label)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.Open(premium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	falsePositives := 0
+	if err := forEachCSVDomain(file, func(label string) error {
+		if _, found := want[label]; !found && filter.Test(label) {
+			falsePositives++
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if falsePositives == 0 {
+		t.Fatal("the filter reported no false positives")
+	}
+
+	got, err := loadExistingDomainsBloom(sources, "csv", premium, fpRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("bloom strategy found %d labels, the map strategy %d", len(got), len(want))
+	}
+	for label, source := range want {
+		if s, found := got[label]; !found || s != source {
+			t.Errorf("%s in source %d (found %t), want %d", label, s, found, source)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"premium-list-maker/internal/notify"
+)
+
+// configPath is the optional JSON config file with settings for unattended runs
+var configPath string
+
+// appConfig holds the settings loaded from the config file
+var appConfig fileConfig
+
+// fileConfig is the layout of the config file
+type fileConfig struct {
+	Email  *notify.EmailConfig `json:"email,omitempty"`  // Email a summary after imports and generations
+	Chat   []notify.ChatConfig `json:"chat,omitempty"`   // Post summaries to Slack or Teams channels
+	SQLite *sqliteConfig       `json:"sqlite,omitempty"` // SQLite tuning, overridden by the tuning flags
+	Naming *namingConfig       `json:"naming,omitempty"` // Names of auto-named files, overridden by the naming flags
+}
+
+// loadConfig reads the config file, if one is configured
+func loadConfig() error {
+	if configPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, &appConfig); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	return nil
+}
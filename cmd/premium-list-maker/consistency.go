@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	consistencyOutputPath       string
+	consistencyDetailedExitCode bool
+)
+
+func newConsistencyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-consistency <list1.csv> <list2.csv> [list3.csv...]",
+		Short: "Check generated premium lists for missing labels and price differences",
+		Long:  "Compare several generated premium lists (e.g. one per TLD) and report labels that are present in one list but missing in another, or priced differently.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  runConsistency,
+	}
+
+	cmd.Flags().StringVar(&consistencyOutputPath, "output", "", "Write the issues to a CSV file (label, issue, details)")
+	cmd.Flags().BoolVar(&consistencyDetailedExitCode, "detailed-exit-code", false, "Exit with code 2 when inconsistencies were found")
+
+	return cmd
+}
+
+func runConsistency(cmd *cobra.Command, args []string) error {
+	lists := make(map[string]map[string]*generator.PremiumListEntry)
+	for _, path := range args {
+		name := filepath.Base(path)
+		if _, exists := lists[name]; exists {
+			// Disambiguate same-named files in different folders
+			name = path
+		}
+
+		entries, err := generator.LoadPremiumList(path)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		lists[name] = entries
+		fmt.Printf("Loaded %s (%d labels)\n", path, len(entries))
+	}
+
+	issues := generator.CheckConsistency(lists)
+
+	missing, mismatched := 0, 0
+	for _, issue := range issues {
+		if issue.Kind == "missing" {
+			missing++
+		} else {
+			mismatched++
+		}
+	}
+
+	fmt.Printf("\nConsistency check complete!\n")
+	fmt.Printf("  - Missing in some lists: %d\n", missing)
+	fmt.Printf("  - Price mismatches:      %d\n", mismatched)
+
+	if consistencyOutputPath != "" {
+		if err := writeConsistencyReport(issues, consistencyOutputPath); err != nil {
+			return err
+		}
+		fmt.Printf("Report saved to %s\n", consistencyOutputPath)
+	} else {
+		limit := 20
+		for i, issue := range issues {
+			if i == limit {
+				fmt.Printf("  ... and %d more (use --output for the full report)\n", len(issues)-limit)
+				break
+			}
+			fmt.Printf("  %s [%s] %s\n", issue.Label, issue.Kind, issue.Details)
+		}
+	}
+
+	if consistencyDetailedExitCode && len(issues) > 0 {
+		return newExitError(exitFindings, cmd)
+	}
+
+	return nil
+}
+
+// writeConsistencyReport writes the issues to a CSV file
+func writeConsistencyReport(issues []generator.ConsistencyIssue, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"label", "issue", "details"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	for _, issue := range issues {
+		if err := writer.Write([]string{issue.Label, issue.Kind, issue.Details}); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	return nil
+}
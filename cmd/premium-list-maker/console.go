@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"premium-list-maker/internal/progress"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	noColor bool
+	noEmoji bool
+
+	// colorEnabled is set by setupConsole when stdout is a terminal and color isn't turned off
+	colorEnabled bool
+	// progressBars is set by setupConsole when stdout is a terminal that isn't also logged to a file
+	progressBars bool
+)
+
+// ANSI escape sequences of the console colors
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// ansiEscape matches the color sequences, which the log file leaves out
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// addConsoleFlags adds the global console output flags
+func addConsoleFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Don't color the output (also set by $NO_COLOR; color is off anyway when the output is not a terminal)")
+	cmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Leave the emoji out of reports, for terminals that can't render them")
+}
+
+// setupConsole decides whether to color the output
+// It must run before startLog, which replaces stdout with a pipe
+func setupConsole() {
+	colorEnabled = !noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(os.Stdout)
+	progressBars = logFilePath == "" && isTerminal(os.Stdout)
+}
+
+// newProgress returns the progress display of an operation of total rows (0 if unknown), and a function to call
+// once the operation returns: a bar redrawn in place on a terminal, heartbeat lines every 100,000 rows otherwise
+// (e.g. when piped or logged, where a redrawn line would be a mess)
+func newProgress(total int) (progress.Func, func()) {
+	if !progressBars {
+		return progress.Printer(os.Stdout, 100000), func() {}
+	}
+	bar := progress.NewBar(os.Stdout, total)
+	return bar.Report, bar.End
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in an ANSI color if the output is colored
+func colorize(color, s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func green(s string) string  { return colorize(ansiGreen, s) }
+func yellow(s string) string { return colorize(ansiYellow, s) }
+func red(s string) string    { return colorize(ansiRed, s) }
+func bold(s string) string   { return colorize(ansiBold, s) }
+
+// heading formats a report heading, led by emoji unless --no-emoji is set
+func heading(emoji, text string) string {
+	if noEmoji {
+		return bold(text)
+	}
+	return bold(emoji + " " + text)
+}
+
+// warnCount formats a count of skipped or doubtful items: green when zero, yellow otherwise
+func warnCount(n int) string {
+	if n == 0 {
+		return green("0")
+	}
+	return yellow(fmt.Sprint(n))
+}
+
+// errorCount formats a count of errors or failures: green when zero, red otherwise
+func errorCount(n int) string {
+	if n == 0 {
+		return green("0")
+	}
+	return red(fmt.Sprint(n))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -9,52 +10,216 @@ import (
 	"strings"
 	"time"
 
+	"premium-list-maker/internal/atomicfile"
+	"premium-list-maker/internal/csvout"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/progress"
+
 	"github.com/spf13/cobra"
 )
 
 var (
-	premiumListPath     string
-	existingDomainsPath string
+	premiumListPath       string
+	existingDomainsPaths  []string
+	existingDomainsFormat string
+	zoneOrigin            string
+	dedupeStrategy        string
+	bloomFPRate           float64
+	diskRunSize           int
+	dedupeTempDir         string
+	sanitizedOutputPath   string
+	catchListOutputPath   string
+	noCatchList           bool
+	checkAction           string
+	checkOpts             availabilityOptions
+	summaryJSONPath       string
+	detailedExitCode      bool
+	tagDBName             string
+)
+
+// Exit codes for deduplicate with --detailed-exit-code
+const (
+	dedupeExitClean     = exitClean    // Nothing matched
+	dedupeExitRemovals  = exitFindings // Labels were removed or flagged, re-review required
+	dedupeExitUnchecked = exitWarnings // Nothing matched, but the availability of some labels could not be checked
 )
 
+// dedupeSummary is the machine-readable summary written with --summary-json
+type dedupeSummary struct {
+	Status          string                `json:"status"` // "clean", "removals" or "unchecked"
+	ExitCode        int                   `json:"exit_code"`
+	PremiumList     string                `json:"premium_list"`
+	Action          string                `json:"action"`
+	Processed       int                   `json:"processed"`
+	Removed         int                   `json:"removed"`
+	Kept            int                   `json:"kept"`
+	Unchecked       int                   `json:"unchecked"`
+	SanitizedOutput string                `json:"sanitized_output,omitempty"`
+	TaggedInDB      string                `json:"tagged_in_db,omitempty"`
+	CatchListOutput string                `json:"catch_list_output,omitempty"`
+	Sources         []dedupeSourceSummary `json:"sources"`
+	StartedAt       time.Time             `json:"started_at"`
+	DurationMS      int64                 `json:"duration_ms"`
+}
+
+// dedupeSourceSummary reports matches attributed to a single existing-domains source
+type dedupeSourceSummary struct {
+	Source  string `json:"source"`
+	Matches int    `json:"matches"`
+}
+
 func newDeduplicateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deduplicate",
 		Short: "Deduplicate premium list against existing domains",
-		Long:  "Filter domains from the premium list that are present in the existing domains list.",
+		Long:  "Filter domains from the premium list that are present in the existing domains list, or that are already delegated according to a live DNS/EPP check.",
 		RunE:  runDeduplicate,
 	}
 
 	cmd.Flags().StringVar(&premiumListPath, "premium-list", "", "Path to the premium list file (CSV)")
-	cmd.Flags().StringVar(&existingDomainsPath, "existing-domains-list", "", "Path to the existing domains list (CSV)")
+	cmd.Flags().StringArrayVar(&existingDomainsPaths, "existing-domains-list", nil, "Path to an existing domains list (CSV or DNS zone file), folder, or glob; can be repeated")
+	cmd.Flags().StringVar(&existingDomainsFormat, "existing-domains-format", "auto", "Format of the existing domains list (auto, csv, zone); auto treats *.zone files as zone files")
+	cmd.Flags().StringVar(&zoneOrigin, "zone-origin", "", "Zone origin for zone files (defaults to the file's $ORIGIN or SOA owner)")
+	cmd.Flags().StringVar(&dedupeStrategy, "strategy", "map", "Lookup strategy (map, bloom, disk); bloom keeps memory low for huge existing-domain lists, disk sorts them into a temp file for the smallest machines")
+	cmd.Flags().Float64Var(&bloomFPRate, "bloom-fp-rate", 0.01, "False positive rate for the bloom strategy (only affects memory and verification work, not results)")
+	cmd.Flags().IntVar(&diskRunSize, "disk-run-size", 2000000, "Domains sorted in memory at a time by the disk strategy")
+	cmd.Flags().StringVar(&dedupeTempDir, "temp-dir", "", "Directory for the disk strategy's temp files, which need about twice the size of the existing lists (default: the system temp directory)")
+	cmd.Flags().StringVar(&sanitizedOutputPath, "output", "", "Path for the sanitized premium list (default: sanitized-<timestamp>-<premium-list> next to the input, or named by --name-template)")
+	cmd.Flags().StringVar(&catchListOutputPath, "catch-list-output", "", "Path for the catch list (default: catch-list-<timestamp>.csv next to the input, or named by --name-template)")
+	cmd.Flags().BoolVar(&noCatchList, "no-catch-list", false, "Don't write a catch list of removed labels")
+	cmd.Flags().StringVar(&checkOpts.Method, "check", "", "Check availability live instead of (or in addition to) existing domains lists (dns, epp)")
+	cmd.Flags().StringVar(&checkOpts.TLD, "tld", "", "TLD to append to labels for availability checks")
+	cmd.Flags().StringVar(&checkAction, "check-action", "remove", "What to do with registered labels (remove, flag); flag keeps all rows and appends a 'registered' column")
+	cmd.Flags().IntVar(&checkOpts.Concurrency, "concurrency", 10, "Number of parallel DNS lookups")
+	cmd.Flags().Float64Var(&checkOpts.Rate, "rate", 50, "Maximum DNS lookups or EPP commands per second (0 = unlimited)")
+	cmd.Flags().StringVar(&checkOpts.Resolver, "resolver", "", "DNS resolver address (host:port), defaults to the system resolver")
+	cmd.Flags().StringVar(&checkOpts.EPP.Server, "epp-server", "", "EPP server address (host:port)")
+	cmd.Flags().StringVar(&checkOpts.EPP.Username, "epp-user", "", "EPP client ID")
+	cmd.Flags().StringVar(&checkOpts.EPP.Password, "epp-password", os.Getenv("PREMIUM_LIST_EPP_PASSWORD"), "EPP password (defaults to $PREMIUM_LIST_EPP_PASSWORD)")
+	cmd.Flags().StringVar(&checkOpts.EPP.CertFile, "epp-cert", "", "EPP client certificate file")
+	cmd.Flags().StringVar(&checkOpts.EPP.KeyFile, "epp-key", "", "EPP client key file")
+	cmd.Flags().StringVar(&tagDBName, "tag-db", "", "Instead of writing filtered CSVs, add this tag (e.g. registered) to matching labels in the database")
+	cmd.Flags().StringVar(&summaryJSONPath, "summary-json", "", "Write a machine-readable JSON summary to this path")
+	cmd.Flags().BoolVar(&detailedExitCode, "detailed-exit-code", false, "Exit with code 2 when labels were removed or flagged, 3 when nothing matched but labels could not be checked (0 = nothing matched, 1 = error)")
+	addQuotingFlags(cmd)
+	cmd.MarkFlagsMutuallyExclusive("catch-list-output", "no-catch-list")
 	cmd.MarkFlagRequired("premium-list")
-	cmd.MarkFlagRequired("existing-domains-list")
 
 	return cmd
 }
 
 func runDeduplicate(cmd *cobra.Command, args []string) error {
-	// 1. Load existing domains
-	fmt.Println("Loading existing domains...")
-	existingDomains, err := loadExistingDomains(existingDomainsPath)
+	startTime := time.Now()
+
+	if len(existingDomainsPaths) == 0 && checkOpts.Method == "" {
+		return fmt.Errorf("either --existing-domains-list or --check is required")
+	}
+	if checkAction != "remove" && checkAction != "flag" {
+		return fmt.Errorf("unknown check action: %s (expected remove or flag)", checkAction)
+	}
+	quoting, err := quotingOption()
 	if err != nil {
-		return fmt.Errorf("failed to load existing domains: %w", err)
+		return err
+	}
+
+	// 1. Load existing domains
+	var sources []string
+	if len(existingDomainsPaths) > 0 {
+		sources, err = expandExistingDomainsSources(existingDomainsPaths)
+		if err != nil {
+			return err
+		}
 	}
-	fmt.Printf("Loaded %d existing domains.\n", len(existingDomains))
+
+	fmt.Printf("Loading existing domains from %d source(s)...\n", len(sources))
+	var existingDomains map[string]int // label -> index of first source containing it
+	switch dedupeStrategy {
+	case "map":
+		existingDomains, err = loadExistingDomainsList(sources, existingDomainsFormat)
+		if err != nil {
+			return fmt.Errorf("failed to load existing domains: %w", err)
+		}
+		fmt.Printf("Loaded %d existing domains.\n", len(existingDomains))
+	case "bloom":
+		if bloomFPRate <= 0 || bloomFPRate >= 1 {
+			return fmt.Errorf("--bloom-fp-rate must be between 0 and 1")
+		}
+		existingDomains, err = loadExistingDomainsBloom(sources, existingDomainsFormat, premiumListPath, bloomFPRate)
+		if err != nil {
+			return fmt.Errorf("failed to load existing domains: %w", err)
+		}
+		fmt.Printf("Confirmed %d premium label(s) in existing domains.\n", len(existingDomains))
+	case "disk":
+		if diskRunSize <= 0 {
+			return fmt.Errorf("--disk-run-size must be positive")
+		}
+		existingDomains, err = loadExistingDomainsDisk(sources, existingDomainsFormat, premiumListPath, dedupeTempDir, diskRunSize)
+		if err != nil {
+			return fmt.Errorf("failed to load existing domains: %w", err)
+		}
+		fmt.Printf("Found %d premium label(s) in existing domains.\n", len(existingDomains))
+	default:
+		return fmt.Errorf("unknown strategy: %s (expected map, bloom or disk)", dedupeStrategy)
+	}
+
+	// Live availability check acts as an additional source
+	unchecked := 0
+	if checkOpts.Method != "" {
+		sources = append(sources, fmt.Sprintf("%s check (.%s)", checkOpts.Method, strings.Trim(checkOpts.TLD, ".")))
+		unchecked, err = checkPremiumAvailability(premiumListPath, existingDomains, len(sources)-1, checkOpts)
+		if err != nil {
+			return fmt.Errorf("availability check failed: %w", err)
+		}
+	}
+	flagOnly := checkAction == "flag"
 
 	// 2. Process premium list
 	fmt.Println("Processing premium list...")
 
 	// Create output filenames
-	timestamp := time.Now().Format("20060102-150405")
+	now := time.Now()
 	premiumDir := filepath.Dir(premiumListPath)
-	premiumBase := filepath.Base(premiumListPath)
+	premiumExt := filepath.Ext(premiumListPath)
+	vars := map[string]string{
+		"input": strings.TrimSuffix(filepath.Base(premiumListPath), premiumExt),
+		"tld":   strings.Trim(checkOpts.TLD, "."),
+	}
 
-	sanitizedFilename := fmt.Sprintf("sanitized-%s-%s", timestamp, premiumBase)
-	sanitizedPath := filepath.Join(premiumDir, sanitizedFilename)
+	sanitizedPath := sanitizedOutputPath
+	if sanitizedPath == "" {
+		vars["name"] = "sanitized"
+		name, err := autoName("{name}-{date}-{input}", now, premiumExt, vars)
+		if err != nil {
+			return err
+		}
+		sanitizedPath = filepath.Join(premiumDir, name)
+	}
+
+	catchListPath := catchListOutputPath
+	if catchListPath == "" {
+		vars["name"] = "catch-list"
+		name, err := autoName("{name}-{date}", now, ".csv", vars)
+		if err != nil {
+			return err
+		}
+		catchListPath = filepath.Join(premiumDir, name)
+	}
 
-	catchListFilename := fmt.Sprintf("catch-list-%s.csv", timestamp)
-	catchListPath := filepath.Join(premiumDir, catchListFilename)
+	// In tag-db mode, matches go to the database instead of output files
+	writeFiles := tagDBName == ""
+	if !writeFiles {
+		noCatchList = true
+	}
+
+	// Ensure output directories exist
+	for _, path := range []string{sanitizedPath, catchListPath} {
+		if !writeFiles || (noCatchList && path == catchListPath) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
 
 	// Open input file
 	inputFile, err := os.Open(premiumListPath)
@@ -63,28 +228,37 @@ func runDeduplicate(cmd *cobra.Command, args []string) error {
 	}
 	defer inputFile.Close()
 
-	// Open output files
-	sanitizedFile, err := os.Create(sanitizedPath)
-	if err != nil {
-		return fmt.Errorf("failed to create sanitized file: %w", err)
+	// Open output files; they only replace their paths once complete
+	var outputs []*atomicfile.File
+	var sanitizedOutput io.Writer = io.Discard
+	if writeFiles {
+		sanitizedFile, err := atomicfile.Create(sanitizedPath)
+		if err != nil {
+			return fmt.Errorf("failed to create sanitized file: %w", err)
+		}
+		defer sanitizedFile.Abort()
+		sanitizedOutput = sanitizedFile
+		outputs = append(outputs, sanitizedFile)
 	}
-	defer sanitizedFile.Close()
 
-	catchFile, err := os.Create(catchListPath)
-	if err != nil {
-		return fmt.Errorf("failed to create catch list file: %w", err)
+	// Removed labels are discarded if no catch list is wanted
+	var catchOutput io.Writer = io.Discard
+	if !noCatchList {
+		catchFile, err := atomicfile.Create(catchListPath)
+		if err != nil {
+			return fmt.Errorf("failed to create catch list file: %w", err)
+		}
+		defer catchFile.Abort()
+		catchOutput = catchFile
+		outputs = append(outputs, catchFile)
 	}
-	defer catchFile.Close()
 
 	// Set up CSV reader/writers
 	reader := csv.NewReader(inputFile)
 	reader.FieldsPerRecord = -1 // Allow variable fields
 
-	sanitizedWriter := csv.NewWriter(sanitizedFile)
-	defer sanitizedWriter.Flush()
-
-	catchWriter := csv.NewWriter(catchFile)
-	defer catchWriter.Flush()
+	sanitizedWriter := csvout.NewWriter(sanitizedOutput, quoting)
+	catchWriter := csvout.NewWriter(catchOutput, quoting)
 
 	// Write header for catch list
 	if err := catchWriter.Write([]string{"label", "w"}); err != nil {
@@ -98,6 +272,9 @@ func runDeduplicate(cmd *cobra.Command, args []string) error {
 		keptCount      int
 		headerSkipped  bool
 	)
+	removedBySource := make([]int, len(sources))
+	reportProgress := progress.Printer(os.Stdout, 100000)
+	var matchedLabels []string
 
 	// Helper to check if row is header
 	isHeader := func(row []string) bool {
@@ -119,9 +296,13 @@ func runDeduplicate(cmd *cobra.Command, args []string) error {
 		}
 
 		processedCount++
+		reportProgress(progress.Update{Phase: progress.PhaseDeduplicating, Rows: processedCount})
 
 		// Handle header: always write to sanitized, skip check
 		if !headerSkipped && isHeader(record) {
+			if flagOnly {
+				record = append(record, "registered")
+			}
 			if err := sanitizedWriter.Write(record); err != nil {
 				return fmt.Errorf("failed to write header to sanitized list: %w", err)
 			}
@@ -133,11 +314,32 @@ func runDeduplicate(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		// Compare A-labels so U-labels match xn-- forms in the existing lists
 		label := strings.TrimSpace(record[0])
-		normalizedLabel := strings.ToLower(label)
+		normalizedLabel := importer.NormalizeLabel(label)
 
-		if existingDomains[normalizedLabel] {
+		sourceIdx, found := existingDomains[normalizedLabel]
+		if found {
+			matchedLabels = append(matchedLabels, normalizedLabel)
+		}
+		if flagOnly {
+			// Keep every row, flagging registered labels
+			flag := "no"
+			if found {
+				flag = "yes"
+				removedBySource[sourceIdx]++
+				if err := catchWriter.Write([]string{label, "w"}); err != nil {
+					return fmt.Errorf("failed to write to catch list: %w", err)
+				}
+				removedCount++
+			}
+			if err := sanitizedWriter.Write(append(record, flag)); err != nil {
+				return fmt.Errorf("failed to write to sanitized list: %w", err)
+			}
+			keptCount++
+		} else if found {
 			// Found in existing list - add to catch list
+			removedBySource[sourceIdx]++
 			if err := catchWriter.Write([]string{label, "w"}); err != nil {
 				return fmt.Errorf("failed to write to catch list: %w", err)
 			}
@@ -151,25 +353,207 @@ func runDeduplicate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	sanitizedWriter.Flush()
+	if err := sanitizedWriter.Error(); err != nil {
+		return fmt.Errorf("failed to write sanitized list: %w", err)
+	}
+	catchWriter.Flush()
+	if err := catchWriter.Error(); err != nil {
+		return fmt.Errorf("failed to write catch list: %w", err)
+	}
+	for _, output := range outputs {
+		if err := output.Commit(); err != nil {
+			return err
+		}
+	}
+
+	// Persist matches as tags so future generations can exclude them
+	tagged := 0
+	if !writeFiles {
+		database, err := openDatabase(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		tagged, err = database.TagLabels(matchedLabels, tagDBName)
+		if err != nil {
+			return fmt.Errorf("failed to tag labels: %w", err)
+		}
+	}
+
 	fmt.Printf("Processing complete!\n")
 	fmt.Printf("  - Processed: %d\n", processedCount)
-	fmt.Printf("  - Removed:   %d (saved to %s)\n", removedCount, catchListFilename)
-	fmt.Printf("  - Kept:      %d (saved to %s)\n", keptCount, sanitizedFilename)
+	removedVerb := "Removed:  "
+	if flagOnly || !writeFiles {
+		removedVerb = "Matched:  "
+	}
+	switch {
+	case !writeFiles:
+		fmt.Printf("  - %s %d (tagged '%s' in %s, %d newly)\n", removedVerb, removedCount, tagDBName, databaseName(), tagged)
+	case noCatchList:
+		fmt.Printf("  - %s %d\n", removedVerb, removedCount)
+	default:
+		fmt.Printf("  - %s %d (saved to %s)\n", removedVerb, removedCount, catchListPath)
+	}
+	if writeFiles {
+		fmt.Printf("  - Kept:      %d (saved to %s)\n", keptCount, sanitizedPath)
+	}
+	if len(sources) > 1 {
+		fmt.Printf("Matches per source (first matching source wins):\n")
+		for i, source := range sources {
+			fmt.Printf("  - %s: %d\n", source, removedBySource[i])
+		}
+	}
+
+	summary := dedupeSummary{
+		Status:      "clean",
+		ExitCode:    dedupeExitClean,
+		PremiumList: premiumListPath,
+		Action:      checkAction,
+		Processed:   processedCount,
+		Removed:     removedCount,
+		Kept:        keptCount,
+		Unchecked:   unchecked,
+		Sources:     make([]dedupeSourceSummary, len(sources)),
+		StartedAt:   startTime,
+		DurationMS:  time.Since(startTime).Milliseconds(),
+	}
+	if writeFiles {
+		summary.SanitizedOutput = sanitizedPath
+	} else {
+		summary.TaggedInDB = tagDBName
+	}
+	if !noCatchList {
+		summary.CatchListOutput = catchListPath
+	}
+	for i, source := range sources {
+		summary.Sources[i] = dedupeSourceSummary{Source: source, Matches: removedBySource[i]}
+	}
+	if removedCount > 0 {
+		summary.Status = "removals"
+		summary.ExitCode = dedupeExitRemovals
+	} else if unchecked > 0 {
+		summary.Status = "unchecked"
+		summary.ExitCode = dedupeExitUnchecked
+	}
+
+	if summaryJSONPath != "" {
+		if err := writeJSONFile(summaryJSONPath, summary); err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+		fmt.Printf("Summary saved to %s\n", summaryJSONPath)
+	}
+
+	if detailedExitCode && summary.ExitCode != dedupeExitClean {
+		return newExitError(summary.ExitCode, cmd)
+	}
 
 	return nil
 }
 
-func loadExistingDomains(path string) (map[string]bool, error) {
+// writeJSONFile writes v as indented JSON to path
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'))
+}
+
+// resolveExistingDomainsFormat resolves the "auto" format from the file extension
+func resolveExistingDomainsFormat(path, format string) string {
+	if format != "auto" {
+		return format
+	}
+	if strings.EqualFold(filepath.Ext(path), ".zone") {
+		return "zone"
+	}
+	return "csv"
+}
+
+// expandExistingDomainsSources expands folders and glob patterns into a list of files
+// Folders contribute all regular files directly inside them
+func expandExistingDomainsSources(paths []string) ([]string, error) {
+	var sources []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			if !info.IsDir() {
+				sources = append(sources, path)
+				continue
+			}
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read folder %s: %w", path, err)
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					sources = append(sources, filepath.Join(path, entry.Name()))
+				}
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("existing domains list not found: %s", path)
+		}
+		sources = append(sources, matches...)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no existing domains lists found")
+	}
+	return sources, nil
+}
+
+// loadExistingDomainsList loads existing domains from CSV or zone files depending on format
+// Returns a map of label -> index of the first source containing it
+func loadExistingDomainsList(sources []string, format string) (map[string]int, error) {
+	domains := make(map[string]int)
+	for i, source := range sources {
+		err := forEachExistingDomain(source, format, func(label string) error {
+			if _, exists := domains[label]; !exists {
+				domains[label] = i
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	return domains, nil
+}
+
+// forEachExistingDomain streams the normalized (lowercase A-label) labels of an existing domains list to fn
+func forEachExistingDomain(path, format string, fn func(label string) error) error {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.FieldsPerRecord = -1
+	switch resolveExistingDomainsFormat(path, format) {
+	case "csv":
+		return forEachCSVDomain(file, fn)
+	case "zone":
+		// Extract the unique second-level labels under the zone origin
+		return importer.ParseZoneSLDs(file, zoneOrigin, func(label string) error {
+			return fn(importer.NormalizeLabel(label))
+		})
+	default:
+		return fmt.Errorf("unknown existing domains format: %s", format)
+	}
+}
 
-	domains := make(map[string]bool)
+// forEachCSVDomain streams the first column of a CSV domains list to fn
+func forEachCSVDomain(r io.Reader, fn func(label string) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
 
 	for {
 		record, err := reader.Read()
@@ -177,21 +561,21 @@ func loadExistingDomains(path string) (map[string]bool, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if len(record) > 0 {
-			label := strings.TrimSpace(record[0])
-			// Skip empty lines or likely headers if midway (though simplified reading assumes header might be processed or just ignored as a non-match)
-			// For existing domains list, we probably want to skip header if it exists.
-			// Let's assume typical lowercase check for "label"
-			if strings.ToLower(label) == "label" || strings.ToLower(label) == "domain" {
+			label := importer.NormalizeLabel(record[0])
+			// Skip empty lines and likely headers
+			if label == "" || label == "label" || label == "domain" {
 				continue
 			}
 
-			domains[strings.ToLower(label)] = true
+			if err := fn(label); err != nil {
+				return err
+			}
 		}
 	}
 
-	return domains, nil
+	return nil
 }
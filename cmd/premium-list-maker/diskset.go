@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// diskSetIndexEvery is the number of records between entries of a disk set's in-memory index
+const diskSetIndexEvery = 1024
+
+// diskSet is a sorted file of "label\tsource" lines with a sparse in-memory index
+// Lookups binary-search the index and scan one block of the file, so memory stays small
+// however many domains the set holds
+type diskSet struct {
+	file    *os.File
+	keys    []string // Label of every diskSetIndexEvery-th record
+	offsets []int64  // Offset of those records
+	size    int64
+	count   int
+}
+
+// diskRecord is a label with the index of the first source containing it
+type diskRecord struct {
+	label  string
+	source int
+}
+
+// buildDiskSet sorts the labels of the existing domains lists into a disk set in tmpDir
+// Labels are sorted in runs of runSize in memory, then the runs are merged
+func buildDiskSet(sources []string, format, tmpDir string, runSize int) (*diskSet, error) {
+	dir, err := os.MkdirTemp(tmpDir, "premium-list-dedupe-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 1. Write sorted runs
+	var runs []string
+	run := make([]diskRecord, 0, runSize)
+	flush := func() error {
+		if len(run) == 0 {
+			return nil
+		}
+		path := filepath.Join(dir, fmt.Sprintf("run-%d", len(runs)))
+		if err := writeDiskRun(path, run); err != nil {
+			return err
+		}
+		runs = append(runs, path)
+		run = run[:0]
+		return nil
+	}
+	for i, source := range sources {
+		err := forEachExistingDomain(source, format, func(label string) error {
+			run = append(run, diskRecord{label: label, source: i})
+			if len(run) >= runSize {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sorted existing domains into %d run(s), merging...\n", len(runs))
+
+	// 2. Merge the runs into the set file, which outlives the temp directory
+	file, err := os.CreateTemp(tmpDir, "premium-list-existing-*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create existing domains set: %w", err)
+	}
+	// The file is removed right away and stays readable through the open handle
+	os.Remove(file.Name())
+
+	set := &diskSet{file: file}
+	if err := set.merge(runs); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return set, nil
+}
+
+// writeDiskRun sorts a run by label and source and writes it, keeping the first source of every label
+func writeDiskRun(path string, run []diskRecord) error {
+	sort.Slice(run, func(i, j int) bool {
+		if run[i].label != run[j].label {
+			return run[i].label < run[j].label
+		}
+		return run[i].source < run[j].source
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create sort run: %w", err)
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+	for i, r := range run {
+		if i > 0 && run[i-1].label == r.label {
+			continue
+		}
+		w.WriteString(r.label)
+		w.WriteByte('\t')
+		w.WriteString(strconv.Itoa(r.source))
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write sort run: %w", err)
+	}
+	return f.Close()
+}
+
+// merge merges sorted runs into the set file and builds the index
+func (s *diskSet) merge(runs []string) error {
+	h := &runHeap{}
+	for _, path := range runs {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open sort run: %w", err)
+		}
+		defer f.Close()
+		r := &runReader{scanner: bufio.NewScanner(f)}
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			heap.Push(h, r)
+		}
+	}
+
+	w := bufio.NewWriterSize(s.file, 1<<20)
+	var last string
+	for h.Len() > 0 {
+		r := (*h)[0]
+		rec := r.current
+		// Equal labels come out in source order, so the first one has the first source
+		if s.count == 0 || rec.label != last {
+			if s.count%diskSetIndexEvery == 0 {
+				s.keys = append(s.keys, rec.label)
+				s.offsets = append(s.offsets, s.size)
+			}
+			line := rec.label + "\t" + strconv.Itoa(rec.source) + "\n"
+			if _, err := w.WriteString(line); err != nil {
+				return fmt.Errorf("failed to write existing domains set: %w", err)
+			}
+			s.size += int64(len(line))
+			s.count++
+			last = rec.label
+		}
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write existing domains set: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the first source containing label
+func (s *diskSet) Lookup(label string) (int, bool, error) {
+	// Find the last block starting at or before label
+	block := sort.SearchStrings(s.keys, label)
+	if block == len(s.keys) || s.keys[block] != label {
+		block--
+	}
+	if block < 0 {
+		return 0, false, nil
+	}
+	end := s.size
+	if block+1 < len(s.offsets) {
+		end = s.offsets[block+1]
+	}
+
+	scanner := bufio.NewScanner(io.NewSectionReader(s.file, s.offsets[block], end-s.offsets[block]))
+	for scanner.Scan() {
+		key, source, _ := strings.Cut(scanner.Text(), "\t")
+		if key == label {
+			n, err := strconv.Atoi(source)
+			return n, err == nil, err
+		}
+		if key > label {
+			break
+		}
+	}
+	return 0, false, scanner.Err()
+}
+
+// Close closes and thereby deletes the set file
+func (s *diskSet) Close() error {
+	return s.file.Close()
+}
+
+// runReader reads the records of a sorted run
+type runReader struct {
+	scanner *bufio.Scanner
+	current diskRecord
+}
+
+// next reads the next record, returning false at the end of the run
+func (r *runReader) next() (bool, error) {
+	if !r.scanner.Scan() {
+		return false, r.scanner.Err()
+	}
+	label, source, _ := strings.Cut(r.scanner.Text(), "\t")
+	n, err := strconv.Atoi(source)
+	if err != nil {
+		return false, fmt.Errorf("corrupt sort run: %w", err)
+	}
+	r.current = diskRecord{label: label, source: n}
+	return true, nil
+}
+
+// runHeap orders run readers by their current record
+type runHeap []*runReader
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if h[i].current.label != h[j].current.label {
+		return h[i].current.label < h[j].current.label
+	}
+	return h[i].current.source < h[j].current.source
+}
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)   { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
+
+// loadExistingDomainsDisk finds the premium labels present in the existing domains lists
+// by sorting the lists into a disk set and looking up every premium label in it
+// Only the matches are returned (label -> index of first source), like the other strategies
+func loadExistingDomainsDisk(sources []string, format, premiumPath, tmpDir string, runSize int) (map[string]int, error) {
+	set, err := buildDiskSet(sources, format, tmpDir, runSize)
+	if err != nil {
+		return nil, err
+	}
+	defer set.Close()
+	fmt.Printf("Built on-disk set of %d existing domains (%d MB), looking up premium labels...\n", set.count, set.size/1024/1024)
+
+	premiumFile, err := os.Open(premiumPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open premium list: %w", err)
+	}
+	defer premiumFile.Close()
+
+	matches := make(map[string]int)
+	err = forEachCSVDomain(premiumFile, func(label string) error {
+		source, found, err := set.Lookup(label)
+		if found {
+			matches[label] = source
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading premium list: %w", err)
+	}
+	return matches, nil
+}
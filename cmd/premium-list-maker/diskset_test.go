@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeDomainLists writes three overlapping existing domains lists and a premium list of labels in and out of them
+// It returns the lists and the premium list
+func writeDomainLists(t *testing.T, dir string) ([]string, string) {
+	t.Helper()
+	var sources []string
+	for i, span := range [][2]int{{0, 3000}, {2000, 4500}, {1000, 2500}} {
+		var b strings.Builder
+		b.WriteString("domain\n")
+		// Written out of order, so the runs have something to sort
+		for n := span[1] - 1; n >= span[0]; n-- {
+			fmt.Fprintf(&b, "l%05d\n", n*2)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("existing-%d.csv", i))
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, path)
+	}
+
+	// Every other premium label is taken, the odd ones fall between the existing ones
+	var b strings.Builder
+	b.WriteString("label\n")
+	for n := 0; n < 9500; n += 3 {
+		fmt.Fprintf(&b, "l%05d\n", n)
+	}
+	premium := filepath.Join(dir, "premium.csv")
+	if err := os.WriteFile(premium, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return sources, premium
+}
+
+func TestDiskSet_Lookup(t *testing.T) {
+	dir := t.TempDir()
+	sources, _ := writeDomainLists(t, dir)
+	want, err := loadExistingDomainsList(sources, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Runs far smaller than the lists, so the set is merged from many
+	set, err := buildDiskSet(sources, "csv", dir, 700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+	if set.count != len(want) || len(set.keys) < 4 {
+		t.Fatalf("set of %d labels in %d blocks, want %d labels in several blocks", set.count, len(set.keys), len(want))
+	}
+
+	labels := make([]string, 0, len(want))
+	for label := range want {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	// The first and last keys of every block, and missing keys between and around the blocks
+	probes := []string{"", "a", "l", "zzz", labels[len(labels)-1] + "0"}
+	for block, key := range set.keys {
+		probes = append(probes, key)
+		at := sort.SearchStrings(labels, key)
+		if at > 0 {
+			last := labels[at-1]
+			probes = append(probes, last)
+			if between := last + "-"; between < key {
+				probes = append(probes, between)
+			} else {
+				t.Errorf("no key between blocks %d and %d", block-1, block)
+			}
+		}
+	}
+	for _, label := range append(probes, labels...) {
+		source, found, err := set.Lookup(label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSource, wantFound := want[label]
+		if found != wantFound || source != wantSource {
+			t.Errorf("Lookup(%q) = %d, %t, want %d, %t", label, source, found, wantSource, wantFound)
+		}
+	}
+}
+
+func TestLoadExistingDomainsDisk(t *testing.T) {
+	dir := t.TempDir()
+	sources, premium := writeDomainLists(t, dir)
+
+	got, err := loadExistingDomainsDisk(sources, "csv", premium, dir, 700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := premiumMatches(t, sources, premium)
+	if len(want) == 0 || len(got) != len(want) {
+		t.Errorf("disk strategy found %d labels, the map strategy %d", len(got), len(want))
+	}
+	for label, source := range want {
+		if got[label] != source {
+			t.Errorf("%s in source %d, want %d", label, got[label], source)
+		}
+	}
+}
+
+// premiumMatches returns the premium labels the map strategy finds in the existing domains lists
+func premiumMatches(t *testing.T, sources []string, premium string) map[string]int {
+	t.Helper()
+	existing, err := loadExistingDomainsList(sources, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(premium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	matches := make(map[string]int)
+	err = forEachCSVDomain(file, func(label string) error {
+		if source, found := existing[label]; found {
+			matches[label] = source
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/availability"
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dnsCheckTLD         string
+	dnsCheckTag         string
+	dnsCheckFilterTags  []string
+	dnsCheckRecord      string
+	dnsCheckResolver    string
+	dnsCheckConcurrency int
+	dnsCheckRate        float64
+	dnsCheckTimeout     time.Duration
+)
+
+func newDNSCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dns-check",
+		Short: "Tag labels that are delegated under a TLD, using NS/SOA lookups",
+		Long: `Look up every label (or every label carrying --filter-tag) under --tld in the DNS and tag delegated names.
+A name is delegated when it has NS records (--record ns), is the apex of its own zone (--record soa), or either (--record both).
+This is a fast screening step: registered names that aren't delegated (e.g. on hold) are only found by EPP or RDAP.`,
+		Args: cobra.NoArgs,
+		RunE: runDNSCheck,
+	}
+
+	cmd.Flags().StringVar(&dnsCheckTLD, "tld", "", "TLD to look the labels up under")
+	cmd.Flags().StringVar(&dnsCheckTag, "tag", "delegated", "Tag for delegated labels")
+	cmd.Flags().StringSliceVar(&dnsCheckFilterTags, "filter-tag", nil, "Only look up labels carrying all of these tags (e.g. premium candidates)")
+	cmd.Flags().StringVar(&dnsCheckRecord, "record", availability.DNSRecordNS, "Records to look up (ns, soa, both)")
+	cmd.Flags().StringVar(&dnsCheckResolver, "resolver", "", "DNS resolver address (host:port), defaults to the system resolver")
+	cmd.Flags().IntVar(&dnsCheckConcurrency, "concurrency", 20, "Number of parallel DNS lookups")
+	cmd.Flags().Float64Var(&dnsCheckRate, "rate", 0, "Maximum DNS lookups per second (0 = unlimited)")
+	cmd.Flags().DurationVar(&dnsCheckTimeout, "timeout", 5*time.Second, "Timeout per lookup")
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runDNSCheck(cmd *cobra.Command, args []string) error {
+	tld := strings.Trim(strings.ToLower(dnsCheckTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	if dnsCheckTag == "" {
+		return fmt.Errorf("--tag must not be empty")
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	labels, err := database.ListLabels(db.LabelFilter{Tags: dnsCheckFilterTags})
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		fmt.Println("No labels to look up.")
+		return nil
+	}
+
+	checker := availability.NewDNSChecker(dnsCheckResolver, dnsCheckConcurrency, dnsCheckRate)
+	checker.Record = dnsCheckRecord
+	checker.Timeout = dnsCheckTimeout
+
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Label + "." + tld
+	}
+
+	start := time.Now()
+	fmt.Printf("Looking up %d label(s) (%s records, %d parallel)...\n", len(names), checker.Record, checker.Concurrency)
+
+	var delegated []string
+	var checked, unknown int
+	err = checker.CheckNames(context.Background(), names, func(result availability.Result) {
+		checked++
+		switch {
+		case result.Err != nil:
+			unknown++
+			if unknown <= 5 {
+				fmt.Printf("  Warning: could not look up %s: %v\n", result.Name, result.Err)
+			}
+		case result.Registered:
+			delegated = append(delegated, strings.TrimSuffix(strings.ToLower(result.Name), "."+tld))
+		}
+		if checked%1000 == 0 {
+			fmt.Printf("  [Heartbeat] Looked up %d/%d names\n", checked, len(names))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	tagged, err := database.TagLabels(delegated, dnsCheckTag)
+	if err != nil {
+		return fmt.Errorf("failed to tag delegated labels: %w", err)
+	}
+
+	fmt.Printf("Found %d delegated label(s), %d newly tagged '%s' (%d could not be looked up) in %v.\n",
+		len(delegated), tagged, dnsCheckTag, unknown, time.Since(start).Round(time.Millisecond))
+	return nil
+}
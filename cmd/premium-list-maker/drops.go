@@ -0,0 +1,221 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dropsTLD           string
+	dropsDate          string
+	dropsTiers         string
+	dropsRegisteredTag string
+	dropsReport        string
+	dropsDryRun        bool
+)
+
+func newImportDropsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-drops <feed>",
+		Short: "Tag labels from a dropped domains feed as available again and report the premium ones",
+		Long: `Read a daily dropped (or expiring) domains feed, a file or http(s) URL with one domain name per line
+or in the first CSV column (optionally gzipped), and for every dropped name in --tld that is a label in the database:
+tag it 'available-again:<date>' and remove its --registered-tag. A report of the premium labels that became
+available is written and sent to the configured notifications.
+
+Premium labels are the labels matched by --tiers, or every label in the database without it.
+Running it again for the same feed and date changes nothing, so it is safe to schedule.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportDrops,
+	}
+
+	cmd.Flags().StringVar(&dropsTLD, "tld", "", "TLD of the feed; names in other TLDs are ignored")
+	cmd.Flags().StringVar(&dropsDate, "date", "", "Drop date for the available-again tag, YYYY-MM-DD (default: today, UTC)")
+	cmd.Flags().StringVar(&dropsTiers, "tiers", "", "Tiers file defining the premium labels and their prices")
+	cmd.Flags().StringVar(&dropsRegisteredTag, "registered-tag", "registered", "Tag removed from dropped labels (empty to keep it)")
+	cmd.Flags().StringVar(&dropsReport, "report", "", "Path for the available premium names report (default: available-premiums-<tld>-<date>.csv, or named by --name-template)")
+	cmd.Flags().BoolVar(&dropsDryRun, "dry-run", false, "Write the report without tagging the database")
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runImportDrops(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	tld := strings.Trim(strings.ToLower(dropsTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	date := start.UTC()
+	if dropsDate != "" {
+		parsed, err := time.Parse("2006-01-02", dropsDate)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q: expected YYYY-MM-DD", dropsDate)
+		}
+		date = parsed
+	}
+	availableTag := "available-again:" + date.Format("2006-01-02")
+
+	feed, err := openFeed(args[0])
+	if err != nil {
+		return err
+	}
+	defer feed.Close()
+
+	seen := make(map[string]bool)
+	var dropped []string
+	err = importer.ParseDroppedDomains(feed, tld, func(label string) error {
+		if !seen[label] {
+			seen[label] = true
+			dropped = append(dropped, label)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	labelTags, err := database.GetAllLabelsWithTags()
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	// The premium labels, with their prices if tiers are given
+	premium := make(map[string]*generator.PremiumListEntry)
+	if dropsTiers != "" {
+		tiers, err := generator.LoadTiers(dropsTiers)
+		if err != nil {
+			return err
+		}
+		entries, _, err := generator.MatchLabels(database, tiers, nil)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			premium[entries[i].Label] = &entries[i]
+		}
+	} else {
+		for label := range labelTags {
+			premium[label] = &generator.PremiumListEntry{Label: label}
+		}
+	}
+
+	// Only labels we know are tagged; a premium label that is already tagged for this date was reported before
+	var known, available []string
+	for _, label := range dropped {
+		tags, ok := labelTags[label]
+		if !ok {
+			continue
+		}
+		known = append(known, label)
+		if premium[label] != nil && !hasTag(tags, availableTag) {
+			available = append(available, label)
+		}
+	}
+	sort.Strings(available)
+
+	fmt.Printf("Read %d dropped name(s) in .%s, %d of them label(s) in the database, %d premium label(s) newly available\n",
+		len(dropped), tld, len(known), len(available))
+
+	reportPath := dropsReport
+	if reportPath == "" {
+		// The drop date is a calendar day, so it keeps its day in any --name-tz
+		day := date
+		if nameLocation != nil {
+			day = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, nameLocation)
+		}
+		if reportPath, err = autoName("{name}-{tld}-{date:20060102}", day, ".csv", map[string]string{"name": "available-premiums", "tld": tld}); err != nil {
+			return err
+		}
+	}
+	if err := writePremiumNamesReport(reportPath, tld, available, premium); err != nil {
+		return err
+	}
+	fmt.Printf("Available premium names report saved to: %s\n", reportPath)
+
+	if dropsDryRun {
+		fmt.Println("Dry run: database not tagged.")
+	} else if len(known) > 0 {
+		tagged, err := database.TagLabels(known, availableTag)
+		if err != nil {
+			return fmt.Errorf("failed to tag dropped labels: %w", err)
+		}
+		fmt.Printf("Tagged %d label(s) '%s'\n", tagged, availableTag)
+		if dropsRegisteredTag != "" {
+			untagged, err := database.UntagLabels(known, dropsRegisteredTag)
+			if err != nil {
+				return fmt.Errorf("failed to untag dropped labels: %w", err)
+			}
+			fmt.Printf("Removed '%s' from %d label(s)\n", dropsRegisteredTag, untagged)
+		}
+	}
+
+	notifyEvent(webhook.Event{
+		Event:      webhook.EventDropsImported,
+		Database:   databaseName(),
+		OutputPath: reportPath,
+		Stats: webhook.DropStats{
+			TLD:              tld,
+			Date:             date.Format("2006-01-02"),
+			DroppedNames:     len(dropped),
+			KnownLabels:      len(known),
+			PremiumAvailable: len(available),
+			DurationMS:       time.Since(start).Milliseconds(),
+		},
+	})
+	return nil
+}
+
+// openFeed opens a feed file or downloads it from an http(s) URL, decompressing .gz feeds
+func openFeed(path string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		client := &http.Client{Timeout: 5 * time.Minute}
+		resp, err := client.Get(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download feed: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to download feed: %s", resp.Status)
+		}
+		body = resp.Body
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open feed: %w", err)
+		}
+		body = file
+	}
+
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return body, nil
+	}
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("failed to decompress feed: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, body}, nil
+}
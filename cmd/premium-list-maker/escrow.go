@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/export"
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	escrowTLD          string
+	escrowReservedTags []string
+	escrowExcludeTags  []string
+	escrowSequence     int
+	escrowRevision     int
+	escrowDate         string
+	escrowUploads      []string
+)
+
+func newExportEscrowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-escrow <tiers.json> <output-dir>",
+		Short: "Write a data escrow deposit of the premium and reserved names",
+		Long: `Write the premium names matched by the tiers and the reserved names (labels carrying --reserved-tag)
+as an escrow-style deposit: fixed-column CSV files named <tld>_<date>_<type>_S<sequence>_R<revision>.csv
+and a JSON manifest listing every file with its row count and SHA-256 checksum.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runExportEscrow,
+	}
+
+	cmd.Flags().StringVar(&escrowTLD, "tld", "", "TLD of the deposit")
+	cmd.Flags().StringArrayVar(&escrowReservedTags, "reserved-tag", nil, "Tag marking reserved labels; repeatable, the tag is recorded as the reason")
+	cmd.Flags().StringSliceVar(&escrowExcludeTags, "exclude-tags", nil, "Tags to exclude from the premium names")
+	cmd.Flags().IntVar(&escrowSequence, "sequence", 1, "Deposit sequence number")
+	cmd.Flags().IntVar(&escrowRevision, "revision", 0, "Deposit revision, for resubmissions of the same sequence")
+	cmd.Flags().StringVar(&escrowDate, "date", "", "Watermark date of the deposit, YYYY-MM-DD (default: today, UTC)")
+	cmd.Flags().StringArrayVar(&escrowUploads, "upload", nil, "Upload the deposit files to a directory destination (e.g. s3://bucket/prefix/ or sftp://user@host/path/, repeatable)")
+	addUploadFlags(cmd)
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runExportEscrow(cmd *cobra.Command, args []string) error {
+	tiersPath, outputDir := args[0], args[1]
+
+	tld := strings.Trim(strings.ToLower(escrowTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	if escrowSequence < 1 || escrowRevision < 0 {
+		return fmt.Errorf("--sequence must be at least 1 and --revision must not be negative")
+	}
+	watermark := time.Now().UTC()
+	if escrowDate != "" {
+		parsed, err := time.Parse("2006-01-02", escrowDate)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q: expected YYYY-MM-DD", escrowDate)
+		}
+		watermark = parsed
+	}
+
+	tiers, err := generator.LoadTiers(tiersPath)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	entries, excluded, err := generator.MatchLabels(database, tiers, escrowExcludeTags)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := loadReservedNames(database, escrowReservedTags)
+	if err != nil {
+		return err
+	}
+
+	deposit := export.EscrowDeposit{TLD: tld, Watermark: watermark, Sequence: escrowSequence, Revision: escrowRevision}
+	manifest, manifestPath, err := export.WriteEscrowDeposit(outputDir, deposit, entries, reserved)
+	if err != nil {
+		return err
+	}
+
+	if excluded > 0 {
+		fmt.Printf("Excluded %d label(s) by tags\n", excluded)
+	}
+	for _, file := range manifest.Files {
+		fmt.Printf("  %s: %d row(s)\n", filepath.Join(outputDir, file.Name), file.Rows)
+	}
+	fmt.Printf("Escrow deposit manifest saved to: %s\n", manifestPath)
+
+	// Upload the manifest last, so pickup jobs that wait for it find the whole deposit
+	var files []string
+	for _, file := range manifest.Files {
+		files = append(files, filepath.Join(outputDir, file.Name))
+	}
+	return uploadFiles(append(files, manifestPath), escrowUploads)
+}
+
+// loadReservedNames returns the labels carrying any of the reserved tags, with the tag as reason
+// A label reserved by several tags is listed once, with the first tag as its reason
+func loadReservedNames(database *db.DB, tags []string) ([]export.ReservedName, error) {
+	var reserved []export.ReservedName
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		labels, err := database.ListLabels(db.LabelFilter{Tags: []string{tag}})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			if !seen[l.Label] {
+				seen[l.Label] = true
+				reserved = append(reserved, export.ReservedName{Label: l.Label, Reason: tag})
+			}
+		}
+	}
+	return reserved, nil
+}
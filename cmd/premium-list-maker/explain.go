@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainExcludeTags []string
+	explainJSON        bool
+)
+
+func newExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain <tiers.json> <label>",
+		Short: "Show how a label is listed and priced",
+		Long: `Print the decision trail of a label as generate would follow it: its tags, every tier it matches and on which tags,
+the conflict resolution (the highest tier wins), exclusions, price overrides, currency conversion and the final prices.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runExplain,
+	}
+	cmd.Flags().StringSliceVar(&explainExcludeTags, "exclude-tags", nil, "Leave out labels with any of these tags (e.g. registered), as generate does")
+	cmd.Flags().BoolVar(&explainJSON, "json", false, "Print the explanation as JSON")
+	addWhereFlag(cmd, "Leave out labels not matching this filter expression, as generate does")
+	addFXFlags(cmd)
+	addPriceFormatFlags(cmd)
+	return cmd
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	tiers, err := generator.LoadTiers(args[0])
+	if err != nil {
+		return err
+	}
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+	// Labels are stored as A-labels
+	label := importer.NormalizeLabel(args[1])
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	l, err := database.GetLabel(label)
+	if errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("label %s is not in the database", label)
+	}
+	if err != nil {
+		return err
+	}
+	override, err := database.GetPriceOverride(label)
+	if errors.Is(err, db.ErrNotFound) {
+		override = nil
+	} else if err != nil {
+		return err
+	}
+
+	opts := generator.Options{ExcludeTags: explainExcludeTags, Where: where, Currency: fxCurrency, PriceFormat: priceFormatOption()}
+	if fxCurrency != "" {
+		if opts.Rates, err = loadRates(); err != nil {
+			return err
+		}
+	}
+	explanation, err := generator.Explain(l.Label, l.Tags, override, tiers, opts)
+	if err != nil {
+		return err
+	}
+
+	if explainJSON {
+		data, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Label: %s\n", explanation.Label)
+	fmt.Printf("Tags:  %s\n", strings.Join(explanation.Tags, ", "))
+	for i, step := range explanation.Steps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"premium-list-maker/internal/atomicfile"
+	"premium-list-maker/internal/csvout"
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Export formats
+const (
+	exportCSV   = "csv"
+	exportJSONL = "jsonl"
+)
+
+var (
+	exportFormat     string
+	exportTags       []string
+	exportWithLength bool
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <output>",
+		Short: "Dump the labels and their tags to CSV or JSONL",
+		Long: `Write every label with its tags, ordered by label, to a CSV or JSONL file, e.g. to back up the database or load it into another tool.
+CSV rows are label,tags with the tags separated by ";"; JSONL lines are {"label": ..., "tags": [...]}. --with-length adds the label length.
+--tag (repeatable, all must match) and --where export only a subset. Use - as output to write to stdout.`,
+		Example: `  premium-list-maker export labels.csv
+  premium-list-maker export fashion.jsonl --tag fashion --with-length
+  premium-list-maker export - --format jsonl --where "city:* AND len <= 5"`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExport,
+	}
+	cmd.Flags().StringVar(&exportFormat, "format", "", "Output format: csv or jsonl (default: from the output extension, csv for stdout)")
+	cmd.Flags().StringSliceVar(&exportTags, "tag", nil, "Only labels carrying this tag (repeatable, all must match)")
+	cmd.Flags().BoolVar(&exportWithLength, "with-length", false, "Include the label length")
+	addWhereFlag(cmd, "Only labels matching this filter expression")
+	addQuotingFlags(cmd)
+	return cmd
+}
+
+// exportRecord is a JSONL line of the export
+type exportRecord struct {
+	Label  string   `json:"label"`
+	Length int      `json:"length,omitempty"`
+	Tags   []string `json:"tags"`
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	output := args[0]
+	format := exportFormat
+	if format == "" {
+		format = exportCSV
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".jsonl" || ext == ".ndjson" {
+			format = exportJSONL
+		}
+	}
+	if format != exportCSV && format != exportJSONL {
+		return fmt.Errorf("unknown format %q (must be csv or jsonl)", format)
+	}
+	quoting, err := quotingOption()
+	if err != nil {
+		return err
+	}
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	// Files only replace their path once complete
+	var out io.Writer = os.Stdout
+	var file *atomicfile.File
+	if output != "-" {
+		file, err = atomicfile.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Abort()
+		out = file
+	}
+	buf := bufio.NewWriter(out)
+
+	var write func(models.Label) error
+	var flush func() error
+	if format == exportCSV {
+		w := csvout.NewWriter(buf, quoting)
+		header := []string{"label", "tags"}
+		if exportWithLength {
+			header = []string{"label", "length", "tags"}
+		}
+		if err := w.Write(header); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		write = func(l models.Label) error {
+			tags := strings.Join(l.Tags, ";")
+			if exportWithLength {
+				return w.Write([]string{l.Label, strconv.Itoa(l.Length), tags})
+			}
+			return w.Write([]string{l.Label, tags})
+		}
+		flush = func() error {
+			w.Flush()
+			return w.Error()
+		}
+	} else {
+		enc := json.NewEncoder(buf)
+		write = func(l models.Label) error {
+			record := exportRecord{Label: l.Label, Tags: l.Tags}
+			if exportWithLength {
+				record.Length = l.Length
+			}
+			return enc.Encode(record)
+		}
+		flush = func() error { return nil }
+	}
+
+	count := 0
+	err = database.EachLabel(db.LabelFilter{Tags: exportTags, Where: where}, func(l models.Label) error {
+		if err := write(l); err != nil {
+			return fmt.Errorf("failed to write %s: %w", l.Label, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	if file == nil {
+		fmt.Fprintf(os.Stderr, "Exported %d label(s)\n", count)
+		return nil
+	}
+	if err := file.Commit(); err != nil {
+		return fmt.Errorf("failed to save %s: %w", output, err)
+	}
+	fmt.Printf("Exported %d label(s) to %s\n", count, output)
+	return nil
+}
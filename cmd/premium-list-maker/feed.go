@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"premium-list-maker/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	feedLayout   string
+	feedSource   string
+	feedCurrency string
+	feedTags     []string
+	feedNoPrices bool
+	feedReplace  bool
+)
+
+func newImportFeedCmd() *cobra.Command {
+	layouts := make([]string, len(importer.FeedLayouts))
+	for i, l := range importer.FeedLayouts {
+		layouts[i] = l.Name
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import-feed <feed.csv>",
+		Short: "Import a third-party premium feed as labels, tags and price overrides",
+		Long: `Import an aftermarket or registry partner premium feed in one step: every row's label (or domain name)
+is added to the database, its categories become tags alongside feed:<source>, and its prices are stored
+as price overrides, which generate uses instead of the tier prices (listing the label even if no tier matches it).
+
+Layouts: ` + strings.Join(layouts, ", ") + `. With --layout auto the layout is detected from the header row.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportFeed,
+	}
+
+	cmd.Flags().StringVar(&feedLayout, "layout", "auto", "Feed layout ("+strings.Join(layouts, ", ")+" or auto)")
+	cmd.Flags().StringVar(&feedSource, "source", "", "Name of the feed, recorded with its price overrides (default: the file name)")
+	cmd.Flags().StringVar(&feedCurrency, "currency", "", "Currency of prices that don't name one")
+	cmd.Flags().StringSliceVar(&feedTags, "tag", nil, "Extra tags for every label of the feed")
+	cmd.Flags().BoolVar(&feedNoPrices, "no-prices", false, "Only import the labels and tags, keeping the tier prices")
+	cmd.Flags().BoolVar(&feedReplace, "replace", false, "Drop the source's earlier price overrides first (for feeds that are full snapshots)")
+
+	return cmd
+}
+
+func runImportFeed(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	source := feedSource
+	if source == "" {
+		source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	stats, err := importer.ImportFeed(database, path, importer.FeedImportOptions{
+		Layout:          feedLayout,
+		Source:          source,
+		DefaultCurrency: strings.ToUpper(feedCurrency),
+		Tags:            feedTags,
+		NoPrices:        feedNoPrices,
+		Replace:         feedReplace,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported feed %s: %d row(s), %d new label(s), %d existing label(s)\n",
+		source, stats.Rows, stats.NewLabels, stats.ExistingLabels)
+	if stats.Replaced > 0 {
+		fmt.Printf("Dropped %d earlier price override(s) of %s\n", stats.Replaced, source)
+	}
+	if !feedNoPrices {
+		fmt.Printf("Stored %d price override(s)\n", stats.PriceOverrides)
+	}
+	if len(stats.Errors) > 0 {
+		fmt.Printf("Skipped %d row(s):\n", len(stats.Errors))
+		for _, e := range stats.Errors {
+			fmt.Printf("  %s\n", e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"premium-list-maker/internal/fx"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fxCurrency  string
+	fxRatesFile string
+	fxOpts      fx.Options
+)
+
+// addFXFlags adds the currency conversion flags to a command
+func addFXFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&fxCurrency, "currency", "", "Convert every price to this currency (default: keep the tier currencies)")
+	cmd.Flags().StringVar(&fxOpts.Provider, "fx-provider", fx.ProviderECB, "Exchange rate provider (ecb, openexchangerates)")
+	cmd.Flags().StringVar(&fxOpts.Date, "fx-date", "", "Use the exchange rates of this date, YYYY-MM-DD, for reproducible lists (default: latest)")
+	cmd.Flags().StringVar(&fxOpts.AppID, "fx-app-id", os.Getenv("OPENEXCHANGERATES_APP_ID"), "openexchangerates app ID (defaults to $OPENEXCHANGERATES_APP_ID)")
+	cmd.Flags().StringVar(&fxOpts.CacheDir, "fx-cache-dir", defaultFXCacheDir(), "Directory caching fetched exchange rates (empty to disable)")
+	cmd.Flags().StringVar(&fxRatesFile, "fx-rates", "", "Exchange rates JSON file to use instead of a provider (same format as the cache)")
+}
+
+// loadRates returns the exchange rates for --currency, or nil if no conversion was asked for
+func loadRates() (*fx.Rates, error) {
+	if fxCurrency == "" {
+		return nil, nil
+	}
+	if fxRatesFile != "" {
+		return fx.LoadFile(fxRatesFile)
+	}
+	return fx.Fetch(context.Background(), fxOpts)
+}
+
+// defaultFXCacheDir returns the exchange rate cache under the user cache directory, empty if there is none
+func defaultFXCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "premium-list-maker", "fx")
+}
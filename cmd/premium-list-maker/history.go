@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLimit int
+	historyJSON  bool
+)
+
+// importDetail is the JSON output of history <session-id>
+type importDetail struct {
+	*db.ImportSession
+	Errors []string `json:"errors"`
+}
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [session-id]",
+		Short: "List past import runs, or show one with its errors",
+		Long: `List the import sessions recorded in the database, newest first: when they ran, their files, counts, duration,
+errors and status. With a session ID, show that session with its files and every error it recorded.
+The session ID is the one undo-import takes.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runHistory,
+	}
+	cmd.Flags().IntVar(&historyLimit, "limit", 20, "Number of sessions to list")
+	cmd.Flags().BoolVar(&historyJSON, "json", false, "Print JSON instead of a table")
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	if len(args) == 1 {
+		return showImport(database, args[0])
+	}
+
+	sessions, err := database.ListImports(historyLimit)
+	if err != nil {
+		return err
+	}
+	if historyJSON {
+		data, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No imports recorded")
+		return nil
+	}
+
+	fmt.Printf("%-6s %-20s %-10s %6s %10s %10s %8s %7s %9s\n", "ID", "STARTED", "STATUS", "FILES", "LABELS", "NEW", "SKIPPED", "ERRORS", "DURATION")
+	for _, s := range sessions {
+		// Padded before coloring, as the color codes would count towards the width
+		fmt.Printf("%-6d %-20s %s %6d %10d %10d %8d %7d %9s\n",
+			s.ID, s.StartedAt.Local().Format("2006-01-02 15:04:05"), importStatusColor(s.Status, fmt.Sprintf("%-10s", s.Status)), len(s.Files),
+			s.LabelsProcessed, s.NewLabels, s.LabelsSkipped, s.ErrorCount, formatDuration(s.Duration()))
+	}
+	return nil
+}
+
+// showImport prints one import session with its files and errors
+func showImport(database *db.DB, arg string) error {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("invalid import session ID %q", arg)
+	}
+	session, err := database.GetImport(id)
+	if errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("import session %d not found", id)
+	}
+	if err != nil {
+		return err
+	}
+	errs, err := database.ImportErrors(id)
+	if err != nil {
+		return err
+	}
+
+	if historyJSON {
+		data, err := json.MarshalIndent(importDetail{ImportSession: session, Errors: errs}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Import session %d\n", session.ID)
+	fmt.Printf("  Status:            %s\n", importStatusColor(session.Status, session.Status))
+	fmt.Printf("  Started:           %s\n", session.StartedAt.Local().Format(time.RFC3339))
+	if session.FinishedAt != nil {
+		fmt.Printf("  Duration:          %s\n", formatDuration(session.Duration()))
+	}
+	if session.UndoneAt != nil {
+		fmt.Printf("  Undone:            %s\n", session.UndoneAt.Local().Format(time.RFC3339))
+	}
+	fmt.Printf("  Files Processed:   %d\n", session.FilesProcessed)
+	fmt.Printf("  Files Skipped:     %d\n", session.FilesSkipped)
+	fmt.Printf("  Labels Processed:  %d (New: %d, Existing: %d)\n", session.LabelsProcessed, session.NewLabels, session.ExistingLabels)
+	fmt.Printf("  Labels Skipped:    %d\n", session.LabelsSkipped)
+
+	fmt.Printf("\nFiles:\n")
+	for _, f := range session.Files {
+		fmt.Printf("  %s\n", f)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("\nErrors (%s):\n", errorCount(len(errs)))
+		for _, e := range errs {
+			fmt.Printf("  - %s\n", e)
+		}
+	}
+	return nil
+}
+
+// importStatusColor colors text by the status of an import session
+func importStatusColor(status, text string) string {
+	switch status {
+	case db.ImportCompleted:
+		return green(text)
+	case db.ImportCanceled, db.ImportUndone:
+		return yellow(text)
+	case db.ImportRunning:
+		return bold(text)
+	}
+	return text
+}
+
+// formatDuration rounds a duration for display, "-" if it is unknown
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
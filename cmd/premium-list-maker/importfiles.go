@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/importer"
+)
+
+// importFileFilter selects the files import reads from a folder
+type importFileFilter struct {
+	recursive bool
+	include   []string
+	exclude   []string
+}
+
+// validate checks the --include and --exclude patterns
+func (f importFileFilter) validate() error {
+	for _, pattern := range append(append([]string(nil), f.include...), f.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether one of the patterns matches a slash-separated relative path
+// Patterns with a / match the whole path, others only its last element, e.g. *.csv matches vendor/a.csv
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			name = rel
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the import files of a folder as slash-separated paths relative to it, ordered by path
+// --exclude also skips whole folders when recursing
+func (f importFileFilter) find(folder string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(folder, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == folder {
+			return nil
+		}
+		rel, err := filepath.Rel(folder, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if !f.recursive || matchAny(f.exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !importer.IsImportFile(d.Name()) || matchAny(f.exclude, rel) {
+			return nil
+		}
+		if len(f.include) > 0 && !matchAny(f.include, rel) {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// importFileTag returns the tag of a file found in the import folder: its relative path without import extension,
+// e.g. vendor-a/3 letter for vendor-a/3 letter.csv
+func importFileTag(rel string) string {
+	tag := importer.FileTag(rel)
+	if dir := path.Dir(rel); dir != "." {
+		tag = dir + "/" + tag
+	}
+	return tag
+}
+
+// checkImportFolder returns an error if folder isn't a readable directory
+func checkImportFolder(folder string) error {
+	info, err := os.Stat(folder)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", folder)
+	}
+	return nil
+}
+
+// fileImport is the outcome of importing a file of the folder
+type fileImport struct {
+	started  bool // False for the files left out because the import was canceled
+	stats    *importer.ImportStats
+	err      error
+	duration time.Duration
+}
+
+// importFolderFiles imports the files with importOne, workers at a time, and hands their outcomes to report in
+// file order on the calling goroutine, so report needs no locking; once ctx is done no more files are started
+func importFolderFiles(ctx context.Context, files []string, workers int, importOne func(file string) fileImport, report func(file string, result fileImport)) {
+	results := make([]chan fileImport, len(files))
+	for i := range results {
+		results[i] = make(chan fileImport, 1)
+	}
+
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := range files {
+			if ctx.Err() == nil {
+				select {
+				case next <- i:
+					continue
+				case <-ctx.Done():
+				}
+			}
+			for ; i < len(files); i++ {
+				results[i] <- fileImport{}
+			}
+			return
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				results[i] <- importOne(files[i])
+			}
+		}()
+	}
+
+	for i, file := range files {
+		report(file, <-results[i])
+	}
+}
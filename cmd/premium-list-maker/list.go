@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listTags      []string
+	listMinLength int
+	listMaxLength int
+	listPrefix    string
+	listContains  string
+	listLimit     int
+	listOffset    int
+	listColumns   []string
+	listJSON      bool
+	listCount     bool
+)
+
+// listColumnNames are the columns list can print
+var listColumnNames = []string{"id", "label", "length", "tags"}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Browse the labels in the database",
+		Long: `List labels with their length and tags, ordered by label, to check what an import stored without opening sqlite3.
+Filters combine: --tag (repeatable, all must match), --min-length/--max-length, --prefix, --contains and --where.
+Pages are --limit labels long; --offset skips to later pages.`,
+		Example: `  premium-list-maker list --tag fashion --max-length 5
+  premium-list-maker list --where "city:* AND NOT registered" --columns label,tags --limit 0`,
+		Args: cobra.NoArgs,
+		RunE: runList,
+	}
+	cmd.Flags().StringSliceVar(&listTags, "tag", nil, "Only labels carrying this tag (repeatable, all must match)")
+	cmd.Flags().IntVar(&listMinLength, "min-length", 0, "Only labels of at least this many characters")
+	cmd.Flags().IntVar(&listMaxLength, "max-length", 0, "Only labels of at most this many characters")
+	cmd.Flags().StringVar(&listPrefix, "prefix", "", "Only labels starting with this text")
+	cmd.Flags().StringVar(&listContains, "contains", "", "Only labels containing this text")
+	cmd.Flags().IntVar(&listLimit, "limit", 50, "Labels per page, 0 for all")
+	cmd.Flags().IntVar(&listOffset, "offset", 0, "Labels to skip, e.g. 50 for the second page of 50")
+	cmd.Flags().StringSliceVar(&listColumns, "columns", []string{"label", "length", "tags"}, "Columns to print: "+strings.Join(listColumnNames, ", "))
+	cmd.Flags().BoolVar(&listJSON, "json", false, "Print the labels as JSON")
+	cmd.Flags().BoolVar(&listCount, "count", false, "Only print the number of matching labels")
+	addWhereFlag(cmd, "Only labels matching this filter expression")
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	if listLimit < 0 || listOffset < 0 || listMinLength < 0 || listMaxLength < 0 {
+		return fmt.Errorf("--limit, --offset, --min-length and --max-length can't be negative")
+	}
+	for _, column := range listColumns {
+		if !slices.Contains(listColumnNames, column) {
+			return fmt.Errorf("unknown column %q (must be one of %s)", column, strings.Join(listColumnNames, ", "))
+		}
+	}
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+	filter := db.LabelFilter{
+		Tags:      listTags,
+		MinLength: listMinLength,
+		MaxLength: listMaxLength,
+		Prefix:    strings.ToLower(listPrefix),
+		Contains:  strings.ToLower(listContains),
+		Where:     where,
+		Limit:     listLimit,
+		Offset:    listOffset,
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	total, err := database.CountLabels(filter)
+	if err != nil {
+		return err
+	}
+	if listCount {
+		fmt.Println(total)
+		return nil
+	}
+	labels, err := database.ListLabels(filter)
+	if err != nil {
+		return err
+	}
+
+	if listJSON {
+		data, err := json.MarshalIndent(labels, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(labels) == 0 {
+		fmt.Printf("No labels (%d match)\n", total)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(listColumns, "\t")))
+	for _, l := range labels {
+		fmt.Fprintln(tw, strings.Join(listRow(l), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nLabels %d-%d of %d", listOffset+1, listOffset+len(labels), total)
+	if next := listOffset + len(labels); next < total {
+		fmt.Printf(" (next page: --offset %d)", next)
+	}
+	fmt.Println()
+	return nil
+}
+
+// listRow returns the --columns of a label
+func listRow(l models.Label) []string {
+	row := make([]string, len(listColumns))
+	for i, column := range listColumns {
+		switch column {
+		case "id":
+			row[i] = fmt.Sprint(l.ID)
+		case "label":
+			row[i] = l.Label
+		case "length":
+			row[i] = fmt.Sprint(l.Length)
+		case "tags":
+			row[i] = strings.Join(l.Tags, ",")
+		}
+	}
+	return row
+}
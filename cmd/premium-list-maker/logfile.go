@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"premium-list-maker/internal/logfile"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logFilePath  string
+	logMaxSizeMB int
+	logMaxFiles  int
+
+	// runLog writes to --log-file only, e.g. every skipped row, whatever the console shows
+	// It discards everything when no log file is set
+	runLog = log.New(io.Discard, "", 0)
+
+	// stopLogCapture restores the console and closes the log file, nil if none is open
+	stopLogCapture func(code int)
+)
+
+// addLogFlags adds the global log file flags
+func addLogFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&logFilePath, "log-file", "", "Append the full run log (console output and every skipped row) to this file")
+	cmd.PersistentFlags().IntVar(&logMaxSizeMB, "log-max-size", 10, "Rotate the log file when it grows past this many MB")
+	cmd.PersistentFlags().IntVar(&logMaxFiles, "log-max-files", 5, "Rotated log files to keep (<log-file>.1 is the newest)")
+}
+
+// startLog opens --log-file and copies stdout and stderr into it for the rest of the run
+func startLog(cmd *cobra.Command) error {
+	if logFilePath == "" || stopLogCapture != nil {
+		return nil
+	}
+	w, err := logfile.Open(logFilePath, int64(logMaxSizeMB)*1024*1024, logMaxFiles)
+	if err != nil {
+		return err
+	}
+	runLog = log.New(w, "", log.LstdFlags)
+	runLog.Printf("=== %s %s (version %s)", cmd.Root().Name(), strings.Join(os.Args[1:], " "), version)
+
+	stopStdout, err := captureOutput(&os.Stdout)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	stopStderr, err := captureOutput(&os.Stderr)
+	if err != nil {
+		stopStdout()
+		w.Close()
+		return err
+	}
+	stopLogCapture = func(code int) {
+		stopStdout()
+		stopStderr()
+		runLog.Printf("=== exit status %d", code)
+		runLog.SetOutput(io.Discard)
+		w.Close()
+	}
+	return nil
+}
+
+// stopLog ends the log with the exit status of the run
+func stopLog(code int) {
+	if stopLogCapture != nil {
+		stopLogCapture(code)
+		stopLogCapture = nil
+	}
+}
+
+// captureOutput replaces *f with a pipe whose output goes to the original file and, line by line, to runLog
+// The returned function restores *f once everything written so far has been copied
+func captureOutput(f **os.File) (func(), error) {
+	console := *f
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+	*f = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 32*1024)
+		var pending []byte
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				// The console gets output as written, e.g. progress without a newline
+				console.Write(buf[:n])
+				pending = logLines(append(pending, ansiEscape.ReplaceAll(buf[:n], nil)...))
+			}
+			if err != nil {
+				if len(bytes.TrimSpace(pending)) > 0 {
+					runLog.Print(string(pending))
+				}
+				r.Close()
+				return
+			}
+		}
+	}()
+	return func() {
+		*f = console
+		w.Close()
+		<-done
+	}, nil
+}
+
+// logLines logs the complete lines in data, split at \n or \r, and returns the rest
+func logLines(data []byte) []byte {
+	for {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			return data
+		}
+		if line := bytes.TrimRight(data[:i], " "); len(line) > 0 {
+			runLog.Print(string(line))
+		}
+		data = data[i+1:]
+	}
+}
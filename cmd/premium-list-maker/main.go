@@ -1,16 +1,26 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
+	"premium-list-maker/internal/archive"
+	"premium-list-maker/internal/atomicfile"
 	"premium-list-maker/internal/db"
 	"premium-list-maker/internal/generator"
 	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagexpr"
+	"premium-list-maker/internal/tagger"
+	"premium-list-maker/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
@@ -18,6 +28,33 @@ import (
 var (
 	dbPath string
 
+	// --detailed-exit-code of import and generate
+	importDetailedExitCode   bool
+	generateDetailedExitCode bool
+
+	// importErrorReport is the --error-report file of import
+	importErrorReport string
+	// importAllowHuge lifts the file size limit of import
+	importAllowHuge bool
+	// importFiles selects the files of the import folder
+	importFiles importFileFilter
+	// importWorkers is the number of files import imports at once
+	importWorkers int
+	// importResume records checkpoints and resumes the files an interrupted import left unfinished
+	importResume bool
+	// Column layout of the CSV files of import
+	importLabelColumn  int
+	importTagsColumn   int
+	importTagSeparator string
+
+	// Flags of generate
+	generateNoManifest    bool
+	generateNoVerify      bool
+	generateReproducible  bool
+	generateEmbedMetadata bool
+	generateEOL           string
+	generateBOM           bool
+
 	// Build information (injected by GoReleaser)
 	version = "dev"
 	commit  = "none"
@@ -32,7 +69,7 @@ type FileImportStats struct {
 	ExistingLabels int // Already existed
 	Skipped        int
 	HeaderSkipped  bool
-	Errors         []string
+	Errors         []importer.ImportError
 	Duration       time.Duration
 }
 
@@ -44,12 +81,14 @@ type TotalStats struct {
 	NewLabels      int // Newly inserted labels
 	ExistingLabels int // Labels that already existed
 	LabelsSkipped  int
-	TotalErrors    []string
+	TotalErrors    []error
 	MaxMemoryMB    uint64
 	FileStats      []FileImportStats
 }
 
 func main() {
+	db.ToolVersion = version
+
 	rootCmd := &cobra.Command{
 		Use:   "premium-list-maker",
 		Short: "Generate premium lists for domain registries",
@@ -57,18 +96,106 @@ func main() {
 	}
 
 	// Global flag for database path
-	rootCmd.PersistentFlags().StringVarP(&dbPath, "db", "d", "premium.db", "path to SQLite database file")
+	rootCmd.PersistentFlags().StringVarP(&dbPath, "db", "d", "premium.db", "path to SQLite database file, or the connection string of a PostgreSQL database (see --db-driver)")
+
+	// Global config file, e.g. for email notifications of unattended runs
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", os.Getenv("PREMIUM_LIST_CONFIG"), "JSON config file (defaults to $PREMIUM_LIST_CONFIG)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+		setupConsole()
+		if err := startLog(cmd); err != nil {
+			return err
+		}
+		if err := resolveNaming(cmd); err != nil {
+			return err
+		}
+		return resolveTuning(cmd)
+	}
+
+	// Global SQLite tuning flags
+	addTuningFlags(rootCmd)
+
+	// Global log file flags, for unattended runs
+	addLogFlags(rootCmd)
+
+	// Global console flags
+	addConsoleFlags(rootCmd)
+
+	// Global naming flags of auto-named files
+	addNamingFlags(rootCmd)
+
+	// Global webhook flags, fired when an import or generation finishes
+	rootCmd.PersistentFlags().StringArrayVar(&webhookURLs, "webhook", nil, "URL to POST a JSON notification to when an import or generation finishes (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&webhookSecret, "webhook-secret", os.Getenv("PREMIUM_LIST_WEBHOOK_SECRET"), "Secret used to sign webhook payloads with HMAC-SHA256 (defaults to $PREMIUM_LIST_WEBHOOK_SECRET)")
 
 	// Import command
+	var execTaggerCmd string
+	var rankThresholds []int
+	var tagProfanity bool
+	var profanityList string
+	var countLines bool
+
 	importCmd := &cobra.Command{
 		Use:   "import <folder>",
-		Short: "Import labels from all CSV files in a folder",
-		Long:  "Import domain labels from all CSV files in the specified folder. The first column should contain the label. Automatically adds length-based tags and filename-based tags.",
+		Short: "Import labels from all CSV, JSON and Excel files in a folder",
+		Long:  "Import domain labels from all CSV, JSON, JSONL and Excel (.xlsx) files in the specified folder. The first column of a CSV file or sheet should contain the label; JSON records are {\"label\": ..., \"tags\": [...]} objects, whose tags are added too. CSV and JSON files compressed with gzip (.gz) or zstd (.zst) are decompressed while they are read. Automatically adds length-based tags and filename-based tags (<file>:<sheet> for the sheets of a workbook).",
 		Args:  cobra.ExactArgs(1),
-		RunE:  runImport,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(cmd, args, execTaggerCmd, rankThresholds, tagProfanity, profanityList, countLines)
+		},
 	}
+	importCmd.Flags().StringVar(&execTaggerCmd, "exec-tagger", "", "External tagger program (reads labels on stdin, writes comma-separated tags per line on stdout)")
+	importCmd.Flags().IntSliceVar(&rankThresholds, "rank-tags", nil, "Add rank:topN tags based on line position for ranked source files (e.g. 1000,10000)")
+	importCmd.Flags().BoolVar(&tagProfanity, "tag-profanity", false, "Tag labels containing profanity or adult terms as 'profanity'")
+	importCmd.Flags().StringVar(&profanityList, "profanity-list", "", "Custom word list for --tag-profanity (one term per line, =term for whole parts only, !word for allowed words; defaults to built-in list)")
+	importCmd.Flags().BoolVar(&countLines, "count-lines", false, "Count the lines of each file before importing it instead of estimating them from the file size (reads every file twice)")
+	importCmd.Flags().BoolVar(&importAllowHuge, "allow-huge", false, fmt.Sprintf("Import files larger than %d GB, which are skipped by default as they are usually not label lists", importer.DefaultMaxFileSize>>30))
+	importCmd.Flags().BoolVarP(&importFiles.recursive, "recursive", "r", false, "Also import the files of subfolders, tagged by their path relative to the folder (e.g. vendor-a/3 letter)")
+	importCmd.Flags().StringSliceVar(&importFiles.include, "include", nil, "Only import files matching this glob (repeatable); patterns with a / match the relative path, others the file name")
+	importCmd.Flags().StringSliceVar(&importFiles.exclude, "exclude", nil, "Skip files and subfolders matching this glob (repeatable); patterns with a / match the relative path, others the name")
+	importCmd.Flags().IntVarP(&importWorkers, "workers", "w", 1, "Import this many files at once, each in its own transaction; the files share the cores and take turns writing (no progress with more than 1)")
+	importCmd.Flags().BoolVar(&importResume, "resume", false, "Record checkpoints at every commit, and resume files an interrupted --resume import left unfinished after their last committed line")
+	importCmd.Flags().IntVar(&importLabelColumn, "label-column", 1, "Column of the labels in CSV files (1 is the first)")
+	importCmd.Flags().IntVar(&importTagsColumn, "tags-column", 0, "Column of CSV files holding tags to add to the label of the row, 0 for none")
+	importCmd.Flags().StringVar(&importTagSeparator, "tag-separator", importer.DefaultTagSeparator, "Separator of the tags in the --tags-column")
+	importCmd.Flags().StringVar(&importErrorReport, "error-report", "", "Also write the errors to this file, e.g. to attach it to email summaries (they are always kept in the import history)")
+	importCmd.Flags().BoolVar(&importDetailedExitCode, "detailed-exit-code", false, "Exit with code 3 when rows or files were skipped (0 = clean, 1 = error)")
 	rootCmd.AddCommand(importCmd)
 
+	// Import history and undo commands
+	historyCmd := newHistoryCmd()
+	rootCmd.AddCommand(historyCmd)
+	undoImportCmd := newUndoImportCmd()
+	rootCmd.AddCommand(undoImportCmd)
+
+	// Label browsing command
+	listCmd := newListCmd()
+	rootCmd.AddCommand(listCmd)
+
+	// Label search command
+	searchCmd := newSearchCmd()
+	rootCmd.AddCommand(searchCmd)
+
+	// Database statistics command
+	statsCmd := newStatsCmd()
+	rootCmd.AddCommand(statsCmd)
+
+	// Label export command
+	exportCmd := newExportCmd()
+	rootCmd.AddCommand(exportCmd)
+
+	// Premium feed import command
+	importFeedCmd := newImportFeedCmd()
+	rootCmd.AddCommand(importFeedCmd)
+
+	// Sales import and price suggestion commands
+	importSalesCmd := newImportSalesCmd()
+	rootCmd.AddCommand(importSalesCmd)
+	suggestPricesCmd := newSuggestPricesCmd()
+	rootCmd.AddCommand(suggestPricesCmd)
+
 	// Tag command
 	tagCmd := &cobra.Command{
 		Use:   "tag <label> <tag1> [tag2...]",
@@ -77,25 +204,61 @@ func main() {
 		Args:  cobra.MinimumNArgs(2),
 		RunE:  runTag,
 	}
+	tagCmd.AddCommand(newTagRenameCmd())
+	tagCmd.AddCommand(newTagMergeCmd())
+	tagCmd.AddCommand(newTagDeleteCmd())
 	rootCmd.AddCommand(tagCmd)
 
 	// Generate command
 	var format string
 	var tld string
+	var excludeTags []string
+	var uploads []string
 
 	generateCmd := &cobra.Command{
 		Use:   "generate <tiers.json> <output.csv>",
 		Short: "Generate premium list from tiers configuration",
-		Long:  "Generate a premium list CSV by matching labels to tiers. Highest tier wins in case of conflicts.",
+		Long:  "Generate a premium list CSV by matching labels to tiers. Highest tier wins in case of conflicts. With --format xlsx, a workbook with a summary sheet and one sheet per tier (label, tags, prices) is written instead.",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGenerate(cmd, args, format, tld)
+			return runGenerate(cmd, args, format, tld, excludeTags, uploads)
 		},
 	}
-	generateCmd.Flags().StringVar(&format, "format", "default", "Output format (default, cnic-new)")
+	generateCmd.Flags().StringVar(&format, "format", "default", "Output format (default, cnic-new, xlsx)")
 	generateCmd.Flags().StringVar(&tld, "tld", "", "TLD/Suffix (required for cnic-new format)")
+	generateCmd.Flags().StringSliceVar(&excludeTags, "exclude-tags", nil, "Leave out labels with any of these tags (e.g. registered)")
+	generateCmd.Flags().StringArrayVar(&uploads, "upload", nil, "Upload the list after a successful generation (sftp://user@host/path, ftps://user@host/path, s3://bucket/prefix/ or gsheets://<spreadsheet-id>/<tab>, repeatable)")
+	generateCmd.Flags().StringVar(&generateArchive, "archive", "", "Also store the list in this archive directory (see the archive command)")
+	generateCmd.Flags().BoolVar(&generateNoManifest, "no-manifest", false, "Don't write the <output>.manifest.json sidecar")
+	generateCmd.Flags().BoolVar(&generateNoVerify, "no-verify", false, "Don't read the list back and check it against the database after writing it")
+	generateCmd.Flags().BoolVar(&generateReproducible, "reproducible", false, "Write byte-identical output for identical inputs (labels sorted, pinned exchange rates required)")
+	generateCmd.Flags().BoolVar(&generateEmbedMetadata, "embed-metadata", false, "Write the tool version, tiers checksum and options as # comment lines before the header")
+	generateCmd.Flags().StringVar(&generateEOL, "eol", "lf", "Line endings of CSV output: lf or crlf (for upload portals that reject LF-only files)")
+	generateCmd.Flags().BoolVar(&generateBOM, "bom", false, "Start CSV output with a UTF-8 byte order mark, so Excel on Windows reads it as UTF-8")
+	generateCmd.Flags().BoolVar(&generateDetailedExitCode, "detailed-exit-code", false, "Exit with code 3 when the list is empty or the tiers file has warnings (0 = clean, 1 = error)")
+	addUploadFlags(generateCmd)
+	addFXFlags(generateCmd)
+	addPriceFormatFlags(generateCmd)
+	addQuotingFlags(generateCmd)
+	addWhereFlag(generateCmd, "Only list labels matching this filter expression")
 	rootCmd.AddCommand(generateCmd)
 
+	// Tier validation command
+	validateTiersCmd := newValidateTiersCmd()
+	rootCmd.AddCommand(validateTiersCmd)
+
+	// Tier simulation command
+	simulateCmd := newSimulateCmd()
+	rootCmd.AddCommand(simulateCmd)
+
+	// Tier resolution tracing command
+	explainCmd := newExplainCmd()
+	rootCmd.AddCommand(explainCmd)
+
+	// Archive command
+	archiveCmd := newArchiveCmd()
+	rootCmd.AddCommand(archiveCmd)
+
 	// Split XLSX command
 	splitXlsxCmd := &cobra.Command{
 		Use:   "split-xlsx <xlsx-file> <output-dir>",
@@ -107,12 +270,69 @@ func main() {
 		},
 	}
 	splitXlsxCmd.Flags().StringVar(&format, "format", "default", "Output format (default, andy)")
+	addQuotingFlags(splitXlsxCmd)
 	rootCmd.AddCommand(splitXlsxCmd)
 
+	// Publish command
+	publishCmd := newPublishCmd()
+	rootCmd.AddCommand(publishCmd)
+
+	// Registry price push command
+	pushPricesCmd := newPushPricesCmd()
+	rootCmd.AddCommand(pushPricesCmd)
+
+	// Escrow deposit export command
+	exportEscrowCmd := newExportEscrowCmd()
+	rootCmd.AddCommand(exportEscrowCmd)
+
+	// Blocklist export command
+	exportBlocklistCmd := newExportBlocklistCmd()
+	rootCmd.AddCommand(exportBlocklistCmd)
+
+	// Inventory report export command
+	exportReportCmd := newExportReportCmd()
+	rootCmd.AddCommand(exportReportCmd)
+
 	// Deduplicate command
 	deduplicateCmd := newDeduplicateCmd()
 	rootCmd.AddCommand(deduplicateCmd)
 
+	// Consistency check command
+	consistencyCmd := newConsistencyCmd()
+	rootCmd.AddCommand(consistencyCmd)
+
+	// RDAP enrichment command
+	enrichRDAPCmd := newEnrichRDAPCmd()
+	rootCmd.AddCommand(enrichRDAPCmd)
+
+	// DNS check command
+	dnsCheckCmd := newDNSCheckCmd()
+	rootCmd.AddCommand(dnsCheckCmd)
+
+	// Zone monitor command
+	zoneMonitorCmd := newZoneMonitorCmd()
+	rootCmd.AddCommand(zoneMonitorCmd)
+
+	// Zone file import command
+	importZoneCmd := newImportZoneCmd()
+	rootCmd.AddCommand(importZoneCmd)
+
+	// Dropped domains feed command
+	importDropsCmd := newImportDropsCmd()
+	rootCmd.AddCommand(importDropsCmd)
+
+	// Serve command
+	serveCmd := newServeCmd()
+	rootCmd.AddCommand(serveCmd)
+
+	// Schema migration command
+	migrateCmd := newMigrateCmd()
+	rootCmd.AddCommand(migrateCmd)
+
+	// Benchmark command
+	benchCmd := newBenchCmd()
+	rootCmd.AddCommand(benchCmd)
+
 	// Version command
 	versionCmd := &cobra.Command{
 		Use:   "version",
@@ -121,88 +341,250 @@ func main() {
 			fmt.Printf("premium-list-maker version %s\n", version)
 			fmt.Printf("commit: %s\n", commit)
 			fmt.Printf("built at: %s\n", date)
+			fmt.Printf("database schema: %d\n", db.SchemaVersion)
 		},
 	}
 	rootCmd.AddCommand(versionCmd)
 
+	code := exitClean
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			code = exitFailure
+		}
 	}
+	stopLog(code)
+	os.Exit(code)
+}
+
+// Exit codes of import, generate and deduplicate with --detailed-exit-code
+// Automation can proceed on 0, review on 2 and 3, and page someone on 1
+const (
+	exitClean    = 0 // Completed without findings or warnings
+	exitFailure  = 1 // Hard failure, the run did not complete
+	exitFindings = 2 // Completed with results to review, e.g. labels removed by deduplicate
+	exitWarnings = 3 // Completed, but rows or files were skipped or the inputs look wrong
+)
+
+// exitError requests a specific non-zero exit code for a run that otherwise succeeded
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// newExitError creates an exitError and silences cobra's error and usage output for it
+func newExitError(code int, cmd *cobra.Command) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitError{code: code}
 }
 
-func runImport(cmd *cobra.Command, args []string) error {
+func runImport(cmd *cobra.Command, args []string, execTaggerCmd string, rankThresholds []int, tagProfanity bool, profanityList string, countLines bool) error {
 	startTime := time.Now()
 	folderPath := args[0]
+	if err := importFiles.validate(); err != nil {
+		return err
+	}
+	if importLabelColumn < 1 || importTagsColumn < 0 {
+		return fmt.Errorf("--label-column must be at least 1 and --tags-column at least 0")
+	}
+	if importTagsColumn == importLabelColumn {
+		return fmt.Errorf("--tags-column can't be the --label-column")
+	}
+	if importTagSeparator == "" {
+		return fmt.Errorf("--tag-separator can't be empty")
+	}
+	if importWorkers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
 
 	// Open database
-	database, err := db.New(dbPath)
+	database, err := openDatabase(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer database.Close()
 
-	// Read directory
-	entries, err := os.ReadDir(folderPath)
+	// Find all CSV, JSON, JSONL and Excel files, as paths relative to the folder
+	if err := checkImportFolder(folderPath); err != nil {
+		return fmt.Errorf("failed to read folder: %w", err)
+	}
+	csvFiles, err := importFiles.find(folderPath)
 	if err != nil {
 		return fmt.Errorf("failed to read folder: %w", err)
 	}
 
-	// Find all CSV files
-	var csvFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".csv") {
-			csvFiles = append(csvFiles, entry.Name())
-		}
+	if len(csvFiles) == 0 {
+		return fmt.Errorf("no CSV, JSON or Excel files found in folder: %s", folderPath)
 	}
 
-	if len(csvFiles) == 0 {
-		return fmt.Errorf("no CSV files found in folder: %s", folderPath)
+	fmt.Printf("Found %d file(s) to import\n", len(csvFiles))
+
+	// Load profanity word list
+	var profanityTagger *tagger.WordListTagger
+	if tagProfanity || profanityList != "" {
+		profanityTagger, err = tagger.NewProfanityTagger(profanityList)
+		if err != nil {
+			return fmt.Errorf("failed to load profanity list: %w", err)
+		}
 	}
 
-	fmt.Printf("Found %d CSV file(s) to import\n", len(csvFiles))
+	// Start external tagger once for all files
+	var execTagger *tagger.ExecTagger
+	if execTaggerCmd != "" {
+		execTagger, err = tagger.NewExecTagger(execTaggerCmd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := execTagger.Close(); err != nil {
+				fmt.Printf("%s %v\n", yellow("Warning:"), err)
+			}
+		}()
+	}
 
 	// Track overall statistics
 	totalStats := TotalStats{
-		TotalErrors: make([]string, 0),
+		TotalErrors: make([]error, 0),
 		FileStats:   make([]FileImportStats, 0),
 	}
 
-	// Import each CSV file
-	for _, csvFile := range csvFiles {
-		csvPath := filepath.Join(folderPath, csvFile)
+	// Ctrl-C stops the import between batches, keeping what was committed
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		// Extract filename tag (filename without .csv extension)
-		filenameTag := strings.TrimSuffix(csvFile, ".csv")
-		filenameTag = strings.TrimSuffix(filenameTag, ".CSV")
+	// Record the run as an import session, which undo-import can take back
+	csvPaths := make([]string, len(csvFiles))
+	for i, csvFile := range csvFiles {
+		csvPaths[i] = filepath.Join(folderPath, filepath.FromSlash(csvFile))
+	}
+	importID, err := database.StartImport(csvPaths, startTime)
+	if err != nil {
+		return err
+	}
+	store := database.InImport(importID)
 
-		// Count lines in file for display
-		lineCount, err := importer.CountCSVLines(csvPath)
-		if err != nil {
-			// If we can't count lines, just proceed without the count
-			fmt.Printf("\nImporting %s (tag: %s)...\n", csvFile, filenameTag)
+	maxFileSize := int64(importer.DefaultMaxFileSize)
+	if importAllowHuge {
+		maxFileSize = -1
+	}
+
+	// importOne imports a file; with several workers the cores are shared between the files and the progress,
+	// which doesn't name the file, is left out
+	importOne := func(csvFile string) fileImport {
+		csvPath := filepath.Join(folderPath, filepath.FromSlash(csvFile))
+
+		// Extract filename tag (relative path without extension)
+		filenameTag := importFileTag(csvFile)
+
+		// Estimate lines in file for display and the progress bar, or count them if asked to; workbooks are tagged by sheet
+		lines := 0
+		if strings.EqualFold(filepath.Ext(csvFile), ".xlsx") {
+			fmt.Printf("\nImporting %s (tags: %s:<sheet>)...\n", csvFile, filenameTag)
+		} else if countLines {
+			lineCount, err := importer.CountCSVLines(csvPath)
+			if err != nil {
+				// If we can't count lines, just proceed without the count
+				fmt.Printf("\nImporting %s (tag: %s)...\n", csvFile, filenameTag)
+			} else {
+				fmt.Printf("\nImporting %s (tag: %s, %d lines)...\n", csvFile, filenameTag, lineCount)
+				lines = lineCount
+			}
+		} else if lineCount, err := importer.EstimateCSVLines(csvPath); err == nil {
+			fmt.Printf("\nImporting %s (tag: %s, ~%d lines)...\n", csvFile, filenameTag, lineCount)
+			lines = lineCount
 		} else {
-			fmt.Printf("\nImporting %s (tag: %s, %d lines)...\n", csvFile, filenameTag, lineCount)
+			fmt.Printf("\nImporting %s (tag: %s)...\n", csvFile, filenameTag)
 		}
 
 		fileStartTime := time.Now()
 
 		// Import with auto-tag always enabled and filename tag
-		stats, err := importer.ImportCSV(database, csvPath, true, filenameTag)
+		opts := []importer.ImportOption{
+			importer.WithMaxFileSize(maxFileSize),
+			importer.WithAutoTag(),
+			importer.WithTag(filenameTag),
+			importer.WithLabelColumn(importLabelColumn),
+			importer.WithTagsColumn(importTagsColumn),
+			importer.WithTagSeparator(importTagSeparator),
+			importer.WithExecTagger(execTagger),
+			importer.WithRankThresholds(rankThresholds),
+			importer.WithProfanityTagger(profanityTagger),
+			importer.WithContext(ctx),
+		}
+		if importResume {
+			opts = append(opts, importer.WithResume())
+		}
+		endProgress := func() {}
+		if importWorkers > 1 {
+			opts = append(opts, importer.WithWorkers(max(1, runtime.NumCPU()/importWorkers)))
+		} else {
+			var report progress.Func
+			report, endProgress = newProgress(lines)
+			opts = append(opts, importer.WithProgress(report))
+		}
+		stats, err := importer.ImportFile(store, csvPath, opts...)
+		endProgress()
+		return fileImport{started: true, stats: stats, err: err, duration: time.Since(fileStartTime)}
+	}
+
+	// Import the files, --workers at a time, and add up their results in file order
+	canceled := false
+	importFolderFiles(ctx, csvFiles, importWorkers, importOne, func(csvFile string, result fileImport) {
+		if !result.started {
+			return
+		}
+		stats, err := result.stats, result.err
+		if stats != nil && stats.Resumed > 0 {
+			fmt.Printf("Resumed %s after line %d, which an interrupted import committed\n", csvFile, stats.Resumed)
+		}
+		if stats != nil && stats.Canceled {
+			fmt.Printf("Import of %s canceled, %d label(s) of it were committed\n", csvFile, stats.Imported)
+			if importResume {
+				fmt.Printf("Run the import again to resume %s where it stopped\n", csvFile)
+			}
+			totalStats.LabelsImported += stats.Imported
+			totalStats.NewLabels += stats.NewLabels
+			totalStats.ExistingLabels += stats.ExistingLabels
+			totalStats.TotalErrors = append(totalStats.TotalErrors, fmt.Errorf("%s: %w", csvFile, err))
+			canceled = true
+			return
+		}
+		if errors.Is(err, importer.ErrTooLarge) {
+			err = fmt.Errorf("%w; import it anyway with --allow-huge", err)
+		}
+		if errors.Is(err, importer.ErrNotCSV) || errors.Is(err, importer.ErrNotJSON) || errors.Is(err, importer.ErrTooLarge) {
+			fmt.Printf("%s %s: %v\n", yellow("Skipping"), csvFile, err)
+			totalStats.FilesSkipped++
+			totalStats.TotalErrors = append(totalStats.TotalErrors, fmt.Errorf("%s: %w", csvFile, err))
+			return
+		}
 		if err != nil {
 			fmt.Printf("Error importing %s: %v\n", csvFile, err)
 			totalStats.FilesSkipped++
-			totalStats.TotalErrors = append(totalStats.TotalErrors, fmt.Sprintf("%s: %v", csvFile, err))
-			continue
+			totalStats.TotalErrors = append(totalStats.TotalErrors, fmt.Errorf("%s: %w", csvFile, err))
+			return
+		}
+
+		for _, sheet := range stats.SkippedSheets {
+			fmt.Printf("%s sheet '%s' of %s: the first column doesn't appear to contain domain labels\n", yellow("Skipping"), sheet, csvFile)
 		}
 
-		fileDuration := time.Since(fileStartTime)
 		totalStats.FilesProcessed++
 		totalStats.LabelsImported += stats.Imported
 		totalStats.NewLabels += stats.NewLabels
 		totalStats.ExistingLabels += stats.ExistingLabels
 		totalStats.LabelsSkipped += stats.Skipped
-		totalStats.TotalErrors = append(totalStats.TotalErrors, stats.Errors...)
+		for _, importErr := range stats.Errors {
+			totalStats.TotalErrors = append(totalStats.TotalErrors, importErr)
+			runLog.Print(importErr)
+		}
 		if stats.MaxMemoryMB > totalStats.MaxMemoryMB {
 			totalStats.MaxMemoryMB = stats.MaxMemoryMB
 		}
@@ -215,8 +597,28 @@ func runImport(cmd *cobra.Command, args []string) error {
 			Skipped:        stats.Skipped,
 			HeaderSkipped:  stats.HeaderSkipped,
 			Errors:         stats.Errors,
-			Duration:       fileDuration,
+			Duration:       result.duration,
 		})
+	})
+
+	importStatus := db.ImportCompleted
+	if canceled {
+		importStatus = db.ImportCanceled
+	}
+	importErrors := make([]string, len(totalStats.TotalErrors))
+	for i, err := range totalStats.TotalErrors {
+		importErrors[i] = err.Error()
+	}
+	counts := db.ImportCounts{
+		FilesProcessed:  totalStats.FilesProcessed,
+		FilesSkipped:    totalStats.FilesSkipped,
+		LabelsProcessed: totalStats.LabelsImported,
+		NewLabels:       totalStats.NewLabels,
+		ExistingLabels:  totalStats.ExistingLabels,
+		LabelsSkipped:   totalStats.LabelsSkipped,
+	}
+	if err := database.FinishImport(importID, importStatus, counts, importErrors, time.Now()); err != nil {
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
 	}
 
 	// Final memory check
@@ -229,102 +631,116 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	// Print comprehensive summary report
 	totalDuration := time.Since(startTime)
-	printSummaryReport(&totalStats, totalDuration, len(csvFiles))
+	errorReport := printSummaryReport(&totalStats, totalDuration, len(csvFiles), importID, cmd.Root().Name())
 
+	notifyEvent(webhook.Event{
+		Event:       webhook.EventImportCompleted,
+		Database:    databaseName(),
+		ErrorReport: errorReport,
+		Stats: webhook.ImportStats{
+			Files:           len(csvFiles),
+			FilesSkipped:    totalStats.FilesSkipped,
+			LabelsProcessed: totalStats.LabelsImported,
+			NewLabels:       totalStats.NewLabels,
+			ExistingLabels:  totalStats.ExistingLabels,
+			LabelsSkipped:   totalStats.LabelsSkipped,
+			Errors:          len(totalStats.TotalErrors),
+			DurationMS:      totalDuration.Milliseconds(),
+		},
+	})
+
+	if canceled {
+		return fmt.Errorf("import canceled")
+	}
+	if importDetailedExitCode && (totalStats.FilesSkipped > 0 || totalStats.LabelsSkipped > 0 || len(totalStats.TotalErrors) > 0) {
+		return newExitError(exitWarnings, cmd)
+	}
 	return nil
 }
 
-func printSummaryReport(stats *TotalStats, totalDuration time.Duration, totalFiles int) {
+// printSummaryReport prints the import summary and writes the --error-report file, returning its path if one was written
+func printSummaryReport(stats *TotalStats, totalDuration time.Duration, totalFiles int, importID int64, rootName string) string {
+	var reportPath string
 	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("IMPORT SUMMARY REPORT")
+	fmt.Println(bold("IMPORT SUMMARY REPORT"))
 	fmt.Println(strings.Repeat("=", 80))
 
-	fmt.Printf("\n📊 Overall Statistics:\n")
+	fmt.Printf("\n%s\n", heading("📊", "Overall Statistics:"))
 	fmt.Printf("  Total Files:           %d\n", totalFiles)
 	fmt.Printf("  Files Processed:       %d\n", stats.FilesProcessed)
-	fmt.Printf("  Files Skipped:         %d\n", stats.FilesSkipped)
+	fmt.Printf("  Files Skipped:         %s\n", errorCount(stats.FilesSkipped))
 	fmt.Printf("  Labels Processed:      %d\n", stats.LabelsImported)
 	if stats.ExistingLabels > 0 {
-		fmt.Printf("    - New Labels:        %d\n", stats.NewLabels)
+		fmt.Printf("    - New Labels:        %s\n", green(fmt.Sprint(stats.NewLabels)))
 		fmt.Printf("    - Existing Labels:   %d (already in database)\n", stats.ExistingLabels)
 	} else {
-		fmt.Printf("  New Labels:            %d\n", stats.NewLabels)
+		fmt.Printf("  New Labels:            %s\n", green(fmt.Sprint(stats.NewLabels)))
 	}
-	fmt.Printf("  Labels Skipped:        %d\n", stats.LabelsSkipped)
+	fmt.Printf("  Labels Skipped:        %s\n", warnCount(stats.LabelsSkipped))
 	fmt.Printf("  Total Runtime:         %v\n", totalDuration.Round(time.Second))
 	fmt.Printf("  Peak Memory Usage:     %d MB\n", stats.MaxMemoryMB)
 
 	if len(stats.FileStats) > 0 {
-		fmt.Printf("\n📁 Per-File Breakdown:\n")
+		fmt.Printf("\n%s\n", heading("📁", "Per-File Breakdown:"))
 		for _, fileStat := range stats.FileStats {
 			fmt.Printf("  %s:\n", fileStat.Filename)
 			if fileStat.ExistingLabels > 0 {
-				fmt.Printf("    Processed: %d (New: %d, Existing: %d), Skipped: %d, Duration: %v\n",
-					fileStat.Imported, fileStat.NewLabels, fileStat.ExistingLabels, fileStat.Skipped, fileStat.Duration.Round(time.Second))
+				fmt.Printf("    Processed: %d (New: %d, Existing: %d), Skipped: %s, Duration: %v\n",
+					fileStat.Imported, fileStat.NewLabels, fileStat.ExistingLabels, warnCount(fileStat.Skipped), fileStat.Duration.Round(time.Second))
 			} else {
-				fmt.Printf("    Imported: %d, Skipped: %d, Duration: %v\n",
-					fileStat.Imported, fileStat.Skipped, fileStat.Duration.Round(time.Second))
+				fmt.Printf("    Imported: %d, Skipped: %s, Duration: %v\n",
+					fileStat.Imported, warnCount(fileStat.Skipped), fileStat.Duration.Round(time.Second))
 			}
 			if fileStat.HeaderSkipped {
 				fmt.Printf("    (Header row skipped)\n")
 			}
 			if len(fileStat.Errors) > 0 {
-				fmt.Printf("    Errors: %d\n", len(fileStat.Errors))
+				fmt.Printf("    Errors: %s\n", errorCount(len(fileStat.Errors)))
 			}
 		}
 	}
 
 	if len(stats.TotalErrors) > 0 {
-		fmt.Printf("\n⚠️  Errors Encountered: %d\n", len(stats.TotalErrors))
+		fmt.Printf("\n%s %s\n", heading("⚠️ ", "Errors Encountered:"), errorCount(len(stats.TotalErrors)))
 
-		// Create error report file
-		timestamp := time.Now().Format("20060102_150405")
-		reportFilename := fmt.Sprintf("import_errors_%s.txt", timestamp)
+		// Print a few for immediate feedback; the import history has them all
+		limit := 5
+		if len(stats.TotalErrors) < limit {
+			limit = len(stats.TotalErrors)
+		}
+		for i := 0; i < limit; i++ {
+			fmt.Printf("    - %s\n", stats.TotalErrors[i])
+		}
+		if len(stats.TotalErrors) > limit {
+			fmt.Printf("    ... (%d more errors)\n", len(stats.TotalErrors)-limit)
+		}
+		fmt.Printf("    --> Full error list: %s history %d\n", rootName, importID)
 
-		file, err := os.Create(reportFilename)
-		if err != nil {
-			fmt.Printf("    Failed to create error report file: %v\n", err)
-			// Fallback to printing first 10
-			if len(stats.TotalErrors) <= 10 {
-				for _, err := range stats.TotalErrors {
-					fmt.Printf("    - %s\n", err)
-				}
+		if importErrorReport != "" {
+			if err := writeErrorReport(importErrorReport, stats.TotalErrors); err != nil {
+				fmt.Printf("    %s\n", red(fmt.Sprintf("Failed to write error report file: %v", err)))
 			} else {
-				for _, err := range stats.TotalErrors[:10] {
-					fmt.Printf("    - %s\n", err)
-				}
-				fmt.Printf("    ... and %d more errors\n", len(stats.TotalErrors)-10)
-			}
-		} else {
-			defer file.Close()
-
-			// Write header
-			fmt.Fprintf(file, "IMPORT ERROR REPORT\n")
-			fmt.Fprintf(file, "Generated: %s\n", time.Now().Format(time.RFC1123))
-			fmt.Fprintf(file, "Total Errors: %d\n\n", len(stats.TotalErrors))
-
-			// Write all errors
-			for _, errStr := range stats.TotalErrors {
-				fmt.Fprintf(file, "- %s\n", errStr)
-			}
-
-			fmt.Printf("    --> Full error list saved to: %s\n", reportFilename)
-
-			// Still print a few for immediate feedback
-			limit := 5
-			if len(stats.TotalErrors) < limit {
-				limit = len(stats.TotalErrors)
-			}
-			for i := 0; i < limit; i++ {
-				fmt.Printf("    - %s\n", stats.TotalErrors[i])
-			}
-			if len(stats.TotalErrors) > limit {
-				fmt.Printf("    ... (%d more errors in report file)\n", len(stats.TotalErrors)-limit)
+				fmt.Printf("    --> Error list saved to: %s\n", importErrorReport)
+				reportPath = importErrorReport
 			}
 		}
 	}
 
+	fmt.Printf("\n%s (undo with: %s undo-import %d)\n", heading("🔖", fmt.Sprintf("Import session %d", importID)), rootName, importID)
 	fmt.Println(strings.Repeat("=", 80))
+	return reportPath
+}
+
+// writeErrorReport writes the errors of an import to a text file
+func writeErrorReport(path string, errs []error) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IMPORT ERROR REPORT\n")
+	fmt.Fprintf(&b, "Generated: %s\n", time.Now().Format(time.RFC1123))
+	fmt.Fprintf(&b, "Total Errors: %d\n\n", len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(&b, "- %s\n", err)
+	}
+	return atomicfile.WriteFile(path, []byte(b.String()))
 }
 
 func runTag(cmd *cobra.Command, args []string) error {
@@ -332,7 +748,7 @@ func runTag(cmd *cobra.Command, args []string) error {
 	tags := args[1:]
 
 	// Open database
-	database, err := db.New(dbPath)
+	database, err := openDatabase(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -364,10 +780,51 @@ func runTag(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runGenerate(cmd *cobra.Command, args []string, format, tld string) error {
+func runGenerate(cmd *cobra.Command, args []string, format, tld string, excludeTags, uploads []string) error {
 	tiersPath := args[0]
 	outputPath := args[1]
 
+	// Never publish a list generated from a broken tiers file
+	if len(uploads) > 0 {
+		data, err := os.ReadFile(tiersPath)
+		if err != nil {
+			return fmt.Errorf("failed to read tiers file: %w", err)
+		}
+		tiers, err := generator.ParseTiers(data)
+		if err != nil {
+			return err
+		}
+		if validation := generator.ValidateTiers(tiers, nil); !validation.Valid {
+			return fmt.Errorf("tiers file is invalid, not generating: %s", strings.Join(validation.Errors, "; "))
+		}
+	}
+
+	var crlf bool
+	switch strings.ToLower(generateEOL) {
+	case "lf":
+	case "crlf":
+		crlf = true
+	default:
+		return fmt.Errorf("invalid --eol %q (must be lf or crlf)", generateEOL)
+	}
+
+	quoting, err := quotingOption()
+	if err != nil {
+		return err
+	}
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+
+	if generateArchive != "" && format == "xlsx" {
+		return fmt.Errorf("--archive needs a CSV format, not xlsx")
+	}
+	// Latest rates change during the day, so the same inputs would price differently
+	if generateReproducible && fxCurrency != "" && fxOpts.Date == "" && fxRatesFile == "" {
+		return fmt.Errorf("--reproducible with --currency needs pinned exchange rates (--fx-date or --fx-rates)")
+	}
+
 	// Ensure output directory exists
 	outputDir := filepath.Dir(outputPath)
 	if outputDir != "" && outputDir != "." {
@@ -376,27 +833,208 @@ func runGenerate(cmd *cobra.Command, args []string, format, tld string) error {
 		}
 	}
 
+	// Fetch exchange rates before touching the output
+	rates, err := loadRates()
+	if err != nil {
+		return err
+	}
+
 	// Open database
-	database, err := db.New(dbPath)
+	database, err := openDatabase(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer database.Close()
 
 	// Generate premium list
-	if err := generator.GeneratePremiumList(database, tiersPath, outputPath, format, tld); err != nil {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts := generator.Options{
+		Format:      format,
+		TLD:         tld,
+		ExcludeTags: excludeTags,
+		Where:       where,
+		Currency:    fxCurrency,
+		Rates:       rates,
+		Context:     ctx,
+
+		PriceFormat:  priceFormatOption(),
+		Reproducible: generateReproducible,
+		Verify:       !generateNoVerify,
+		CRLF:         crlf,
+		BOM:          generateBOM,
+		Quoting:      quoting,
+	}
+	if generateEmbedMetadata {
+		if opts.Metadata, err = generateMetadata(tiersPath, opts); err != nil {
+			return err
+		}
+	}
+	report, endProgress := newProgress(0)
+	opts.Progress = report
+	result, err := generator.GeneratePremiumListWithOptions(database, tiersPath, outputPath, opts)
+	endProgress()
+	if err != nil {
 		return err
 	}
+	printGenerateResult(result, excludeTags, where)
 
+	if !generateNoManifest {
+		manifest, err := generator.NewManifest(result, outputPath, tiersPath, opts)
+		if err != nil {
+			return err
+		}
+		manifest.Database = databaseName()
+		manifest.ToolVersion = version
+		manifestPath := generator.ManifestPath(outputPath)
+		if err := generator.WriteManifest(manifestPath, manifest); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		fmt.Printf("Manifest saved to %s\n", manifestPath)
+	}
+
+	if generateArchive != "" {
+		a, err := archive.Open(generateArchive)
+		if err != nil {
+			return err
+		}
+		entry, err := a.AddCSV(outputPath, tld, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to archive list: %w", err)
+		}
+		printArchived(entry)
+	}
+
+	if notificationsEnabled() {
+		notifyEvent(webhook.Event{
+			Event:      webhook.EventGenerateCompleted,
+			Database:   databaseName(),
+			OutputPath: outputPath,
+			SHA256:     result.SHA256,
+			Stats: webhook.GenerateStats{
+				Format:     format,
+				TLD:        tld,
+				SizeBytes:  result.SizeBytes,
+				DurationMS: result.DurationMS,
+			},
+		})
+	}
+
+	if err := uploadFile(outputPath, uploads); err != nil {
+		return err
+	}
+
+	if generateDetailedExitCode {
+		warnings, err := generateWarnings(tiersPath, result)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Printf("%s %s\n", yellow("Warning:"), w)
+		}
+		if len(warnings) > 0 {
+			return newExitError(exitWarnings, cmd)
+		}
+	}
 	return nil
 }
 
+// generateWarnings returns what makes a generated list suspect without failing it:
+// an empty list and the warnings of the tiers file
+func generateWarnings(tiersPath string, result *generator.GenerateResult) ([]string, error) {
+	tiers, err := generator.LoadTiers(tiersPath)
+	if err != nil {
+		return nil, err
+	}
+	warnings := generator.ValidateTiers(tiers, nil).Warnings
+	if result.Entries == 0 {
+		warnings = append(warnings, "the list has no entries")
+	}
+	return warnings, nil
+}
+
+// generateMetadata returns the metadata lines embedded with --embed-metadata
+// They describe the inputs only, so a reproducible list stays identical between runs
+func generateMetadata(tiersPath string, opts generator.Options) ([]string, error) {
+	tiersSum, _, err := webhook.FileChecksum(tiersPath)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{
+		"generated by premium-list-maker " + version,
+		"format: " + opts.Format,
+		fmt.Sprintf("tiers: %s sha256:%s", filepath.Base(tiersPath), tiersSum),
+	}
+	if opts.TLD != "" {
+		lines = append(lines, "tld: "+opts.TLD)
+	}
+	if len(opts.ExcludeTags) > 0 {
+		lines = append(lines, "exclude-tags: "+strings.Join(opts.ExcludeTags, ","))
+	}
+	if opts.Where != nil {
+		lines = append(lines, "where: "+opts.Where.String())
+	}
+	if opts.Rates != nil {
+		lines = append(lines, fmt.Sprintf("currency: %s at %s rates of %s", strings.ToUpper(opts.Currency), opts.Rates.Provider, opts.Rates.Date))
+	}
+	if f := opts.PriceFormat; f != nil {
+		lines = append(lines, fmt.Sprintf("prices: %d decimals, decimal separator %q, thousands separator %q, minor units %t", f.Decimals, f.DecimalSep, f.ThousandsSep, f.MinorUnits))
+	}
+	return lines, nil
+}
+
+// printGenerateResult prints the summary of a generated premium list
+func printGenerateResult(result *generator.GenerateResult, excludeTags []string, where tagexpr.Expr) {
+	fmt.Printf("Generated premium list with %s entries (format: %s)\n", green(fmt.Sprint(result.Entries)), result.Format)
+	tiers := make([]int, 0, len(result.TierCounts))
+	for tier := range result.TierCounts {
+		tiers = append(tiers, tier)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(tiers)))
+	for _, tier := range tiers {
+		if tier == 0 {
+			fmt.Printf("  Price overrides only: %d\n", result.TierCounts[tier])
+		} else {
+			fmt.Printf("  Tier %d: %d\n", tier, result.TierCounts[tier])
+		}
+	}
+	if result.Rates != nil {
+		fmt.Printf("Prices converted to %s at %s rates of %s\n", result.Currency, result.Rates.Provider, result.Rates.Date)
+	}
+	if result.Excluded > 0 {
+		var reasons []string
+		if len(excludeTags) > 0 {
+			reasons = append(reasons, "tagged "+strings.Join(excludeTags, ", "))
+		}
+		if where != nil {
+			reasons = append(reasons, "not matching "+where.String())
+		}
+		fmt.Printf("Excluded %d label(s) %s\n", result.Excluded, strings.Join(reasons, " or "))
+	}
+	if result.Unmatched > 0 {
+		fmt.Printf("%s label(s) matched no tier\n", warnCount(result.Unmatched))
+	}
+	fmt.Printf("SHA-256: %s (%d bytes, %v)\n", result.SHA256, result.SizeBytes, result.Duration.Round(time.Millisecond))
+}
+
 func runSplitXLSX(cmd *cobra.Command, args []string, format string) error {
 	xlsxPath := args[0]
 	outputDir := args[1]
 
+	quoting, err := quotingOption()
+	if err != nil {
+		return err
+	}
+	tiersName, err := autoName("{name}-{date}", time.Now(), ".json", map[string]string{
+		"name":  "tiers",
+		"input": strings.TrimSuffix(filepath.Base(xlsxPath), filepath.Ext(xlsxPath)),
+	})
+	if err != nil {
+		return err
+	}
+
 	// Split XLSX file
-	if err := importer.SplitXLSX(xlsxPath, outputDir, format); err != nil {
+	if err := importer.SplitXLSX(xlsxPath, outputDir, importer.SplitOptions{Format: format, TiersName: tiersName, Quoting: quoting}); err != nil {
 		return err
 	}
 
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateVacuum bool
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate <migration>",
+		Short: "Convert the database to an optional schema layout",
+		Long: `Apply an optional schema migration to the database. Available migrations:
+
+  without-rowid  Rebuild label_tags as a WITHOUT ROWID table clustered on (label_id, tag_id), with a covering
+                 (tag_id, label_id) index. This shrinks the file and speeds up tag-based generation on large
+                 databases. Run it on a new database to start with this layout.
+
+Migrations that are already applied are skipped. Back up the database first; the rebuild needs free disk space
+of about the size of label_tags.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"without-rowid"},
+		RunE:      runMigrate,
+	}
+	cmd.Flags().BoolVar(&migrateVacuum, "vacuum", true, "Vacuum the database afterwards to return the freed space to the file system")
+	return cmd
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	if args[0] != "without-rowid" {
+		return fmt.Errorf("unknown migration %q (available: without-rowid)", args[0])
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sizeBefore, err := database.Size()
+	if err != nil {
+		return err
+	}
+
+	migrated, err := database.MigrateLabelTagsWithoutRowID()
+	if err != nil {
+		return err
+	}
+	if !migrated {
+		fmt.Printf("label_tags already uses the %s layout, nothing to do\n", db.LayoutWithoutRowID)
+		return nil
+	}
+	if migrateVacuum {
+		if err := database.Vacuum(); err != nil {
+			return err
+		}
+	}
+
+	sizeAfter, err := database.Size()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Migrated label_tags to the %s layout (database %.1f MB -> %.1f MB)\n",
+		db.LayoutWithoutRowID, float64(sizeBefore)/1024/1024, float64(sizeAfter)/1024/1024)
+	return nil
+}
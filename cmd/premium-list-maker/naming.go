@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"premium-list-maker/internal/naming"
+)
+
+// Naming flags of auto-named files; unset flags fall back to the config file
+var (
+	nameTemplate string
+	nameTimezone string
+
+	// nameLocation is the time zone of {date}, nil to keep the time zone of each file's own time
+	nameLocation *time.Location
+)
+
+// namingConfig is the naming section of the config file
+type namingConfig struct {
+	Template string `json:"template,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// addNamingFlags adds the flags naming auto-named files to the root command
+func addNamingFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&nameTemplate, "name-template", "", "Name files that aren't given a path (reports, deduplicate outputs, tiers JSON) by this template, e.g. {name}-{date:2006-01-02}-{tld}")
+	flags.StringVar(&nameTimezone, "name-tz", "", "Time zone of {date} in file names, e.g. UTC or Europe/Berlin (default: local time)")
+}
+
+// resolveNaming combines the config file and the naming flags
+func resolveNaming(cmd *cobra.Command) error {
+	if cfg := appConfig.Naming; cfg != nil {
+		if !cmd.Flags().Changed("name-template") {
+			nameTemplate = cfg.Template
+		}
+		if !cmd.Flags().Changed("name-tz") {
+			nameTimezone = cfg.Timezone
+		}
+	}
+	if nameTemplate != "" {
+		if err := naming.Validate(nameTemplate); err != nil {
+			return err
+		}
+	}
+	nameLocation = nil
+	if nameTimezone != "" {
+		loc, err := time.LoadLocation(nameTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid --name-tz %q: %w", nameTimezone, err)
+		}
+		nameLocation = loc
+	}
+	return nil
+}
+
+// autoName names a file that wasn't given a path: by --name-template, or by def without one, followed by ext
+// t is the time the file is about, vars the values of the other placeholders
+func autoName(def string, t time.Time, ext string, vars map[string]string) (string, error) {
+	template := def
+	if nameTemplate != "" {
+		template = nameTemplate
+	}
+	if nameLocation != nil {
+		t = t.In(nameLocation)
+	}
+	name, err := naming.Expand(template, t, vars)
+	if err != nil {
+		return "", err
+	}
+	return name + ext, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var priceFormat = generator.DefaultPriceFormat
+
+// addPriceFormatFlags adds the flags formatting the prices of a generated list
+func addPriceFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&priceFormat.Decimals, "decimals", priceFormat.Decimals, "Digits after the decimal separator (with --minor-units: exponent of the minor unit)")
+	cmd.Flags().StringVar(&priceFormat.DecimalSep, "decimal-separator", priceFormat.DecimalSep, "Decimal separator of prices, e.g. , for 1.000,00")
+	cmd.Flags().StringVar(&priceFormat.ThousandsSep, "thousands-separator", priceFormat.ThousandsSep, "Separator between groups of thousands in prices (default: none)")
+	cmd.Flags().BoolVar(&priceFormat.MinorUnits, "minor-units", false, "Write prices as integers in minor units, e.g. 1000.00 as 100000")
+}
+
+// priceFormatOption returns the price format for generator.Options, nil for the default
+func priceFormatOption() *generator.PriceFormat {
+	if priceFormat == generator.DefaultPriceFormat {
+		return nil
+	}
+	f := priceFormat
+	return &f
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"premium-list-maker/internal/publish"
+
+	"github.com/spf13/cobra"
+)
+
+var uploadOpts publish.Options
+
+// addUploadFlags adds the credential flags shared by generate --upload and publish
+func addUploadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&uploadOpts.KeyFile, "upload-key", "", "SSH private key for sftp:// uploads (default: SSH agent, ~/.ssh/id_ed25519, ~/.ssh/id_rsa)")
+	cmd.Flags().StringVar(&uploadOpts.KnownHostsFile, "upload-known-hosts", "", "known_hosts file to verify sftp:// servers (default: ~/.ssh/known_hosts)")
+	cmd.Flags().StringVar(&uploadOpts.CAFile, "upload-ca", "", "PEM CA certificates to verify ftps:// servers (default: the system roots)")
+	cmd.Flags().StringVar(&uploadOpts.GoogleCredentialsFile, "google-credentials", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"), "Google service account key for gsheets:// uploads (defaults to $GOOGLE_APPLICATION_CREDENTIALS)")
+	cmd.Flags().StringVar(&uploadOpts.S3Region, "s3-region", os.Getenv("AWS_REGION"), "AWS region for s3:// uploads (defaults to $AWS_REGION, then us-east-1)")
+	cmd.Flags().StringVar(&uploadOpts.S3Endpoint, "s3-endpoint", s3EndpointFromEnv(), "S3-compatible endpoint for s3:// uploads, e.g. MinIO (defaults to $AWS_ENDPOINT_URL_S3)")
+	uploadOpts.Password = os.Getenv("PREMIUM_LIST_UPLOAD_PASSWORD")
+}
+
+func newPublishCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish <file> <destination> [destination...]",
+		Short: "Upload a generated list to SFTP or FTPS drops, an S3 bucket or a Google Sheet",
+		Long:  "Upload a file to one or more destinations (sftp://user@host/path, ftps://user@host/path, s3://bucket/key or gsheets://<spreadsheet-id>/<tab>). A destination ending in / keeps the file name. Passwords can be set in $PREMIUM_LIST_UPLOAD_PASSWORD.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uploadFile(args[0], args[1:])
+		},
+	}
+	addUploadFlags(cmd)
+	return cmd
+}
+
+// uploadFile uploads a file to every destination, stopping at the first failure
+func uploadFile(path string, destinations []string) error {
+	return uploadFiles([]string{path}, destinations)
+}
+
+// uploadFiles uploads several files to every destination, stopping at the first failure
+func uploadFiles(paths []string, destinations []string) error {
+	for _, destination := range destinations {
+		uploaded, err := publish.UploadFiles(context.Background(), paths, destination, uploadOpts)
+		if err != nil {
+			return err
+		}
+		for i, path := range paths {
+			fmt.Printf("Uploaded %s to %s\n", path, uploaded[i])
+		}
+	}
+	return nil
+}
+
+// s3EndpointFromEnv returns the S3 endpoint override of the AWS environment variables, if any
+func s3EndpointFromEnv() string {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_S3"); endpoint != "" {
+		return endpoint
+	}
+	return os.Getenv("AWS_ENDPOINT_URL")
+}
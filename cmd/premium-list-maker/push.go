@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/publish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pushProvider     string
+	pushTLD          string
+	pushAPIURL       string
+	pushAPIKey       string
+	pushDryRun       bool
+	pushErrorsOutput string
+)
+
+func newPushPricesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push-prices <premium-list.csv>",
+		Short: "Submit a generated premium list to a registry backend API",
+		Long:  "Submit every price of a generated premium list (default or cnic-new format) to a registry provider's API, reporting the labels the registry rejected.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPushPrices,
+	}
+
+	cmd.Flags().StringVar(&pushProvider, "provider", "cnic", "Registry provider (cnic)")
+	cmd.Flags().StringVar(&pushTLD, "tld", "", "TLD the prices are for")
+	cmd.Flags().StringVar(&pushAPIURL, "api-url", "", "Base URL of the provider's API")
+	cmd.Flags().StringVar(&pushAPIKey, "api-key", os.Getenv("PREMIUM_LIST_REGISTRY_API_KEY"), "API key (defaults to $PREMIUM_LIST_REGISTRY_API_KEY)")
+	cmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "Print what would be submitted without calling the API")
+	cmd.Flags().StringVar(&pushErrorsOutput, "errors-output", "", "Write rejected prices (label, type, error) to this CSV")
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runPushPrices(cmd *cobra.Command, args []string) error {
+	entries, err := generator.LoadPremiumList(args[0])
+	if err != nil {
+		return err
+	}
+	prices := publish.RegistryPrices(entries, pushTLD)
+	fmt.Printf("Loaded %d price(s) for %d label(s) from %s\n", len(prices), len(entries), args[0])
+
+	if pushDryRun {
+		for _, price := range prices {
+			fmt.Printf("  %s %s %s %.2f\n", price.Domain, price.Type, price.Currency, price.Amount)
+		}
+		fmt.Printf("Dry run: %d price(s) would be submitted to %s\n", len(prices), pushProvider)
+		return nil
+	}
+
+	var publisher publish.RegistryPublisher
+	switch pushProvider {
+	case "cnic":
+		if pushAPIURL == "" || pushAPIKey == "" {
+			return fmt.Errorf("--api-url and --api-key are required for the cnic provider")
+		}
+		publisher = publish.NewCentralNicPublisher(pushAPIURL, pushAPIKey)
+	default:
+		return fmt.Errorf("unknown provider: %s (expected cnic)", pushProvider)
+	}
+
+	result, err := publish.PushPrices(context.Background(), publisher, prices, func(done int) {
+		if done%1000 == 0 {
+			fmt.Printf("  [Heartbeat] Submitted %d/%d prices\n", done, len(prices))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Submitted %d price(s), %d rejected\n", result.Submitted, result.Failed)
+	for i, priceErr := range result.Errors {
+		if i == 10 {
+			fmt.Printf("  ... and %d more\n", len(result.Errors)-10)
+			break
+		}
+		fmt.Printf("  - %s (%s): %v\n", priceErr.Label, priceErr.Type, priceErr.Err)
+	}
+
+	if pushErrorsOutput != "" && len(result.Errors) > 0 {
+		if err := writePriceErrors(pushErrorsOutput, result.Errors); err != nil {
+			return err
+		}
+		fmt.Printf("Rejected prices saved to: %s\n", pushErrorsOutput)
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d price(s) were rejected by the registry", result.Failed)
+	}
+	return nil
+}
+
+// writePriceErrors writes rejected prices as a CSV report
+func writePriceErrors(path string, errs []publish.PriceError) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create errors output: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"label", "type", "error"})
+	for _, priceErr := range errs {
+		writer.Write([]string{priceErr.Label, priceErr.Type, priceErr.Err.Error()})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write errors output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"premium-list-maker/internal/csvout"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	quotingMode string
+	quoteChar   string
+	escapeChar  string
+)
+
+// addQuotingFlags adds the flags quoting the CSV files a command writes
+func addQuotingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&quotingMode, "quoting", csvout.QuoteMinimal, "Quoting of CSV fields: minimal (only where needed), all (every field) or none (fail on fields that need quotes)")
+	cmd.Flags().StringVar(&quoteChar, "quote-char", `"`, "Character quoting CSV fields")
+	cmd.Flags().StringVar(&escapeChar, "escape-char", "", "Character escaping quotes inside quoted fields, e.g. \\ (default: quotes are doubled)")
+}
+
+// quotingOption returns the quoting set by the quoting flags
+func quotingOption() (csvout.Quoting, error) {
+	q := csvout.Quoting{Mode: quotingMode}
+	var err error
+	if q.Quote, err = singleRune("--quote-char", quoteChar); err != nil {
+		return q, err
+	}
+	if escapeChar != "" {
+		if q.Escape, err = singleRune("--escape-char", escapeChar); err != nil {
+			return q, err
+		}
+	}
+	return q, q.Validate()
+}
+
+// singleRune returns the character of a flag that takes exactly one
+func singleRune(flag, s string) (rune, error) {
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("%s must be a single character, got %q", flag, s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"premium-list-maker/internal/availability"
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rdapTLD         string
+	rdapServer      string
+	rdapTag         string
+	rdapFilterTags  []string
+	rdapConcurrency int
+	rdapRate        float64
+)
+
+func newEnrichRDAPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enrich-rdap",
+		Short: "Tag labels that are registered under a TLD, with their registration year, using RDAP",
+		Long:  "Look up every label (or every label carrying --filter-tag) under --tld over RDAP, and tag registered names with 'registered' and 'registered:<year>' of their registration date.",
+		Args:  cobra.NoArgs,
+		RunE:  runEnrichRDAP,
+	}
+
+	cmd.Flags().StringVar(&rdapTLD, "tld", "", "TLD to look the labels up under")
+	cmd.Flags().StringVar(&rdapServer, "rdap-server", "", "RDAP base URL (default: looked up in the IANA bootstrap registry)")
+	cmd.Flags().StringVar(&rdapTag, "tag", "registered", "Tag for registered labels; the year tag is <tag>:<year>")
+	cmd.Flags().StringSliceVar(&rdapFilterTags, "filter-tag", nil, "Only look up labels carrying all of these tags (e.g. premium candidates)")
+	cmd.Flags().IntVar(&rdapConcurrency, "concurrency", 5, "Number of parallel RDAP lookups")
+	cmd.Flags().Float64Var(&rdapRate, "rate", 10, "Maximum RDAP lookups per second (0 = unlimited); registries rate limit aggressively")
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runEnrichRDAP(cmd *cobra.Command, args []string) error {
+	tld := strings.Trim(strings.ToLower(rdapTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	if rdapTag == "" {
+		return fmt.Errorf("--tag must not be empty")
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	labels, err := database.ListLabels(db.LabelFilter{Tags: rdapFilterTags})
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		fmt.Println("No labels to look up.")
+		return nil
+	}
+
+	ctx := context.Background()
+	checker, err := availability.NewRDAPChecker(ctx, tld, rdapServer, rdapConcurrency, rdapRate)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Label + "." + tld
+	}
+
+	fmt.Printf("Looking up %d label(s) at %s...\n", len(names), checker.BaseURL)
+
+	var registered []string
+	byYear := make(map[int][]string)
+	var checked, unknown int
+	err = checker.CheckNames(ctx, names, func(result availability.Result) {
+		checked++
+		label := strings.TrimSuffix(strings.ToLower(result.Name), "."+tld)
+		switch {
+		case result.Err != nil:
+			unknown++
+			if unknown <= 5 {
+				fmt.Printf("  Warning: could not look up %s: %v\n", result.Name, result.Err)
+			}
+		case result.Registered:
+			registered = append(registered, label)
+			if !result.Created.IsZero() {
+				year := result.Created.Year()
+				byYear[year] = append(byYear[year], label)
+			}
+		}
+		if checked%1000 == 0 {
+			fmt.Printf("  [Heartbeat] Looked up %d/%d names\n", checked, len(names))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	tagged, err := database.TagLabels(registered, rdapTag)
+	if err != nil {
+		return fmt.Errorf("failed to tag registered labels: %w", err)
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	for _, year := range years {
+		if _, err := database.TagLabels(byYear[year], rdapTag+":"+strconv.Itoa(year)); err != nil {
+			return fmt.Errorf("failed to tag registration year: %w", err)
+		}
+	}
+
+	fmt.Printf("Found %d registered label(s), %d newly tagged '%s' (%d could not be looked up).\n", len(registered), tagged, rdapTag, unknown)
+	for _, year := range years {
+		fmt.Printf("  %s:%d: %d\n", rdapTag, year, len(byYear[year]))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/export"
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportTLD           string
+	reportPeriod        string
+	reportPriceBands    []float64
+	reportExcludeTags   []string
+	reportRegisteredTag string
+	reportUploads       []string
+)
+
+func newExportReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-report <tiers.json> <output.csv>",
+		Short: "Write the monthly premium inventory report by tier and price band",
+		Long: `Summarize the premium names matched by the tiers into one row per tier, registration price band and currency,
+with the number of names, how many are registered, and the registration and renewal totals, followed by a total row per currency.
+Pass the same --exclude-tags as generate so the report matches the published list.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runExportReport,
+	}
+
+	cmd.Flags().StringVar(&reportTLD, "tld", "", "TLD of the report")
+	cmd.Flags().StringVar(&reportPeriod, "period", "", "Reporting period, YYYY-MM (default: the current month)")
+	cmd.Flags().Float64SliceVar(&reportPriceBands, "price-bands", export.DefaultPriceBands, "Registration price band boundaries")
+	cmd.Flags().StringSliceVar(&reportExcludeTags, "exclude-tags", nil, "Leave out labels with any of these tags, as in generate")
+	cmd.Flags().StringVar(&reportRegisteredTag, "registered-tag", "registered", "Tag of registered labels, counted in the registered column")
+	cmd.Flags().StringArrayVar(&reportUploads, "upload", nil, "Upload the report after it is written (sftp://, ftps://, s3:// or gsheets:// URI, repeatable)")
+	addUploadFlags(cmd)
+	addQuotingFlags(cmd)
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runExportReport(cmd *cobra.Command, args []string) error {
+	tiersPath, outputPath := args[0], args[1]
+
+	tld := strings.Trim(strings.ToLower(reportTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	period := reportPeriod
+	if period == "" {
+		period = time.Now().UTC().Format("2006-01")
+	} else if _, err := time.Parse("2006-01", period); err != nil {
+		return fmt.Errorf("invalid --period %q: expected YYYY-MM", period)
+	}
+	bands := append([]float64(nil), reportPriceBands...)
+	sort.Float64s(bands)
+	quoting, err := quotingOption()
+	if err != nil {
+		return err
+	}
+
+	tiers, err := generator.LoadTiers(tiersPath)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	entries, excluded, err := generator.MatchLabels(database, tiers, reportExcludeTags)
+	if err != nil {
+		return err
+	}
+
+	var registered func(string) bool
+	if reportRegisteredTag != "" {
+		labels, err := database.ListLabels(db.LabelFilter{Tags: []string{reportRegisteredTag}})
+		if err != nil {
+			return err
+		}
+		set := make(map[string]bool, len(labels))
+		for _, l := range labels {
+			set[l.Label] = true
+		}
+		registered = func(label string) bool { return set[label] }
+	}
+
+	rows := export.InventoryReport(entries, bands, registered)
+	if err := export.WriteInventoryReport(outputPath, period, tld, rows, quoting); err != nil {
+		return err
+	}
+
+	if excluded > 0 {
+		fmt.Printf("Excluded %d label(s) by tags\n", excluded)
+	}
+	fmt.Printf("Inventory report for %s (%s): %d premium name(s) in %d row(s)\n", tld, period, len(entries), len(rows))
+	fmt.Printf("Report saved to: %s\n", outputPath)
+	return uploadFile(outputPath, reportUploads)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/pricing"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	salesSource   string
+	salesCurrency string
+
+	suggestTiers          string
+	suggestTags           []string
+	suggestCurrency       string
+	suggestMinComparables int
+	suggestSince          string
+)
+
+func newImportSalesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-sales <sales.csv>",
+		Short: "Import historic aftermarket sales for price suggestions",
+		Long: `Import historic sales data: a CSV with a header row and label (or domain), price and date columns,
+and optionally a currency column. Sales of full domain names are stored by their label, whatever the TLD.
+Importing the same sales again is a no-op.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportSales,
+	}
+
+	cmd.Flags().StringVar(&salesSource, "source", "", "Name of the sales data, e.g. the marketplace (default: the file name)")
+	cmd.Flags().StringVar(&salesCurrency, "currency", "", "Currency of prices that don't name one")
+
+	return cmd
+}
+
+func runImportSales(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	source := salesSource
+	if source == "" {
+		source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open sales data: %w", err)
+	}
+	defer file.Close()
+
+	var sales []db.Sale
+	var errors []string
+	err = importer.ParseSales(file, strings.ToUpper(salesCurrency), func(sale db.Sale) error {
+		sale.Source = source
+		sales = append(sales, sale)
+		return nil
+	}, func(line int, err error) {
+		errors = append(errors, fmt.Sprintf("line %d: %v", line, err))
+	})
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	inserted, err := database.InsertSales(sales)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d sale(s) from %s (%d already imported)\n", inserted, source, len(sales)-inserted)
+	if len(errors) > 0 {
+		fmt.Printf("Skipped %d row(s):\n", len(errors))
+		for _, e := range errors {
+			fmt.Printf("  %s\n", e)
+		}
+	}
+	return nil
+}
+
+func newSuggestPricesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest-prices <output.csv>",
+		Short: "Propose prices and tiers from comparable aftermarket sales",
+		Long: `Propose a price for every candidate label from the imported sales, and with --tiers the tier priced
+closest to it. A label that sold before (in any TLD) is priced on its own sales, other labels on the median
+of the sales of labels with the same length and shape. The suggestions are written as a CSV for review,
+next to the label's current tier and price; nothing in the database is changed.
+
+Candidates are the labels matched by --tiers and/or carrying all --tag tags, or every label without either.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSuggestPrices,
+	}
+
+	cmd.Flags().StringVar(&suggestTiers, "tiers", "", "Tiers file: its labels are the candidates and its tiers are proposed")
+	cmd.Flags().StringSliceVar(&suggestTags, "tag", nil, "Only suggest prices for labels carrying all of these tags")
+	cmd.Flags().StringVar(&suggestCurrency, "currency", "USD", "Currency of the suggestions; sales in other currencies are ignored")
+	cmd.Flags().IntVar(&suggestMinComparables, "min-comparables", 3, "Minimum number of comparable sales for a suggestion")
+	cmd.Flags().StringVar(&suggestSince, "since", "", "Only use sales on or after this date, YYYY-MM-DD")
+
+	return cmd
+}
+
+func runSuggestPrices(cmd *cobra.Command, args []string) error {
+	outputPath := args[0]
+	currency := strings.ToUpper(suggestCurrency)
+
+	var since time.Time
+	if suggestSince != "" {
+		parsed, err := time.Parse("2006-01-02", suggestSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: expected YYYY-MM-DD", suggestSince)
+		}
+		since = parsed
+	}
+
+	var tiers []models.Tier
+	if suggestTiers != "" {
+		var err error
+		if tiers, err = generator.LoadTiers(suggestTiers); err != nil {
+			return err
+		}
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sales, err := database.GetSales(since)
+	if err != nil {
+		return err
+	}
+	if len(sales) == 0 {
+		return fmt.Errorf("no sales to compare with; import them with import-sales first")
+	}
+
+	// The current tier and price of the candidates
+	current := make(map[string]generator.PremiumListEntry)
+	if tiers != nil {
+		entries, _, err := generator.MatchLabels(database, tiers, nil)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			current[e.Label] = e
+		}
+	}
+
+	var candidates []string
+	if tiers != nil && len(suggestTags) == 0 {
+		for label := range current {
+			candidates = append(candidates, label)
+		}
+	} else {
+		labels, err := database.ListLabels(db.LabelFilter{Tags: suggestTags})
+		if err != nil {
+			return err
+		}
+		for _, l := range labels {
+			if _, ok := current[l.Label]; ok || tiers == nil {
+				candidates = append(candidates, l.Label)
+			}
+		}
+	}
+
+	suggestions := pricing.Suggest(candidates, sales, pricing.Options{
+		Currency:       currency,
+		MinComparables: suggestMinComparables,
+		Tiers:          tiers,
+	})
+	for i := range suggestions {
+		if entry, ok := current[suggestions[i].Label]; ok {
+			suggestions[i].CurrentTier = entry.Tier
+			if entry.Currency == currency {
+				suggestions[i].CurrentPrice = entry.PriceReg
+			}
+		}
+	}
+
+	if err := pricing.WriteSuggestions(outputPath, suggestions); err != nil {
+		return err
+	}
+
+	fmt.Printf("Suggested prices for %d of %d label(s) from %d sale(s)\n", len(suggestions), len(candidates), len(sales))
+	fmt.Printf("Suggestions saved to: %s\n", outputPath)
+	return nil
+}
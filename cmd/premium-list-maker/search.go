@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchRegex bool
+	searchTags  []string
+	searchLimit int
+)
+
+// errSearchLimit stops the search once --limit labels are found
+var errSearchLimit = errors.New("search limit reached")
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <pattern>",
+		Short: "Find labels matching a glob or regular expression",
+		Long: `Print the labels matching a pattern with their tags, ordered by label.
+The pattern is a glob matching the whole label: * matches any run of characters and ? a single one, so *crypto* finds
+every label containing crypto. With --regex it is a Go regular expression matching anywhere in the label; anchor it
+with ^ and $ to match the whole label. --tag (repeatable, all must match) and --where only search labels with those tags.`,
+		Example: `  premium-list-maker search "*crypto*"
+  premium-list-maker search --regex "^[a-z]{3}[0-9]$" --tag brand`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSearch,
+	}
+	cmd.Flags().BoolVar(&searchRegex, "regex", false, "Treat the pattern as a regular expression")
+	cmd.Flags().StringSliceVar(&searchTags, "tag", nil, "Only labels carrying this tag (repeatable, all must match)")
+	cmd.Flags().IntVar(&searchLimit, "limit", 0, "Stop after this many labels, 0 for all")
+	addWhereFlag(cmd, "Only labels matching this filter expression")
+	return cmd
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	if searchLimit < 0 {
+		return fmt.Errorf("--limit can't be negative")
+	}
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+	filter := db.LabelFilter{Tags: searchTags, Where: where}
+
+	// Globs are matched by the database; regular expressions while streaming the labels
+	match := func(string) bool { return true }
+	if searchRegex {
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		match = re.MatchString
+	} else {
+		filter.Glob = strings.ToLower(args[0])
+		filter.Limit = searchLimit
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := 0
+	err = database.EachLabel(filter, func(l models.Label) error {
+		if !match(l.Label) {
+			return nil
+		}
+		if found == 0 {
+			fmt.Fprintln(tw, "LABEL\tTAGS")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", l.Label, strings.Join(l.Tags, ","))
+		found++
+		if searchLimit > 0 && found == searchLimit {
+			return errSearchLimit
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSearchLimit) {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if found == 0 {
+		fmt.Println("No labels match")
+		return nil
+	}
+	if errors.Is(err, errSearchLimit) {
+		fmt.Printf("\nFirst %d matching label(s) (--limit)\n", found)
+	} else {
+		fmt.Printf("\n%d label(s) match\n", found)
+	}
+	return nil
+}
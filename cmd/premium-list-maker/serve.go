@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/metrics"
+	"premium-list-maker/internal/server"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	serveAddr         string
+	serveGRPCAddr     string
+	serveJobDir       string
+	serveJobRetention time.Duration
+
+	serveDatabasesFile string
+
+	serveShutdownTimeout time.Duration
+	serveDrainDelay      time.Duration
+
+	serveAPIKeys       []string
+	serveAPIKeysFile   string
+	serveOIDCIssuer    string
+	serveOIDCAudience  string
+	serveOIDCRoleClaim string
+	serveOIDCWriteRole string
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start an HTTP server exposing the label database as a REST API",
+		Long:  "Start an HTTP server with endpoints for label and tag management, label search, tier validation, and premium list generation.",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+
+	cmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&serveGRPCAddr, "grpc-addr", "", "Address for the gRPC API (disabled if empty, e.g. :9090)")
+	cmd.Flags().DurationVar(&serveShutdownTimeout, "shutdown-timeout", 30*time.Second, "Time to let running jobs and requests finish on SIGTERM/SIGINT")
+	cmd.Flags().DurationVar(&serveDrainDelay, "drain-delay", 5*time.Second, "Time /readyz fails before the listeners close, so load balancers stop routing new requests")
+	cmd.Flags().StringVar(&serveDatabasesFile, "databases", "", "JSON file mapping TLDs to databases, to serve several databases under /api/tlds/{tld}/ (overrides --db)")
+	cmd.Flags().StringVar(&serveJobDir, "job-dir", "", "Directory for background job files and generated lists (default: premium-list-jobs in the system temp dir)")
+	cmd.Flags().DurationVar(&serveJobRetention, "job-retention", 7*24*time.Hour, "Time finished jobs and their files are kept before they are deleted (0 keeps them)")
+
+	// Authentication
+	cmd.Flags().StringArrayVar(&serveAPIKeys, "api-key", nil, "API key with its role as key:role, role is read or write (repeatable)")
+	cmd.Flags().StringVar(&serveAPIKeysFile, "api-keys-file", "", "File with one \"key role\" pair per line")
+	cmd.Flags().StringVar(&serveOIDCIssuer, "oidc-issuer", "", "OIDC issuer URL for bearer token authentication")
+	cmd.Flags().StringVar(&serveOIDCAudience, "oidc-audience", "", "Expected audience (client ID) of OIDC tokens, required with --oidc-issuer")
+	cmd.Flags().StringVar(&serveOIDCRoleClaim, "oidc-role-claim", "roles", "OIDC token claim holding the caller's roles")
+	cmd.Flags().StringVar(&serveOIDCWriteRole, "oidc-write-role", "", "Role in the role claim granting write access (other valid tokens are read-only)")
+
+	return cmd
+}
+
+// serveHandler is the HTTP handler of a single database (*server.Server) or a portfolio (*server.Portfolio)
+type serveHandler interface {
+	http.Handler
+	Drain()
+	Shutdown(ctx context.Context) error
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	auth, err := newServeAuthenticator(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if auth == nil {
+		fmt.Println("Warning: authentication is disabled, anyone who can reach the server has write access (use --api-key or --oidc-issuer)")
+	}
+
+	opts := server.Options{
+		Notifier: newWebhookNotifier(),
+		Auth:     auth,
+		JobDir:   serveJobDir,
+
+		JobRetention: serveJobRetention,
+	}
+
+	var handler serveHandler
+	var grpcBackend *server.Server // Server behind the gRPC API (nil if there is none)
+	if serveDatabasesFile != "" {
+		portfolio, closeDatabases, err := openPortfolio(serveDatabasesFile, opts)
+		if err != nil {
+			return err
+		}
+		defer closeDatabases()
+		handler, grpcBackend = portfolio, portfolio.Default()
+		fmt.Printf("Serving %d database(s) (%s) on %s\n", len(portfolio.TLDs()), strings.Join(portfolio.TLDs(), ", "), serveAddr)
+	} else {
+		database, err := openDatabase(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		if err := metrics.RegisterDB(database, ""); err != nil {
+			return fmt.Errorf("failed to register database metrics: %w", err)
+		}
+
+		srv, err := server.New(database, opts)
+		if err != nil {
+			return err
+		}
+		handler, grpcBackend = srv, srv
+		fmt.Printf("Serving %s on %s\n", databaseName(), serveAddr)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if serveGRPCAddr != "" {
+		if grpcBackend == nil {
+			return fmt.Errorf("--grpc-addr with --databases requires a default database in the databases file")
+		}
+		listener, err := net.Listen("tcp", serveGRPCAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", serveGRPCAddr, err)
+		}
+		grpcServer = grpcBackend.NewGRPCServer()
+
+		fmt.Printf("Serving gRPC API on %s\n", serveGRPCAddr)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				errCh <- fmt.Errorf("gRPC server failed: %w", err)
+			}
+		}()
+	}
+
+	httpServer := &http.Server{Addr: serveAddr, Handler: handler}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	return shutdownServe(handler, httpServer, grpcServer)
+}
+
+// openPortfolio opens every database listed in the databases file and creates their servers
+// The returned close function closes the databases
+func openPortfolio(path string, opts server.Options) (*server.Portfolio, func(), error) {
+	config, err := server.LoadPortfolioConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	databases := make(map[string]*db.DB, len(config.Databases))
+	closeDatabases := func() {
+		for _, database := range databases {
+			database.Close()
+		}
+	}
+	for _, entry := range config.Databases {
+		database, err := openDatabase(entry.Path)
+		if err != nil {
+			closeDatabases()
+			return nil, nil, fmt.Errorf("failed to open database for %s: %w", entry.TLD, err)
+		}
+		databases[entry.TLD] = database
+
+		if err := metrics.RegisterDB(database, entry.TLD); err != nil {
+			closeDatabases()
+			return nil, nil, fmt.Errorf("failed to register database metrics: %w", err)
+		}
+	}
+
+	portfolio, err := server.NewPortfolio(databases, config.Default, opts)
+	if err != nil {
+		closeDatabases()
+		return nil, nil, err
+	}
+	return portfolio, closeDatabases, nil
+}
+
+// shutdownServe stops the servers gracefully
+// Readiness fails first so the load balancer stops routing, then running jobs and requests
+// are given --shutdown-timeout to finish. After that the running job is canceled and waited
+// for, which rolls back its open transaction, and the remaining connections are closed
+func shutdownServe(srv serveHandler, httpServer *http.Server, grpcServer *grpc.Server) error {
+	fmt.Println("Shutting down...")
+	srv.Drain()
+	time.Sleep(serveDrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if err := srv.Shutdown(ctx); err != nil {
+		shutdownErr = fmt.Errorf("running job did not finish in time: %w", err)
+	}
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		httpServer.Close()
+		if shutdownErr == nil {
+			shutdownErr = fmt.Errorf("requests did not finish in time: %w", err)
+		}
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+	fmt.Println("Shutdown complete")
+	return nil
+}
+
+// newServeAuthenticator builds the authenticator from the auth flags (nil if none are set)
+func newServeAuthenticator(ctx context.Context) (*server.Authenticator, error) {
+	apiKeys := serveAPIKeys
+	if serveAPIKeysFile != "" {
+		fileKeys, err := server.LoadAPIKeysFile(serveAPIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, fileKeys...)
+	}
+	if len(apiKeys) == 0 && serveOIDCIssuer == "" {
+		return nil, nil
+	}
+
+	var oidcConfig *server.OIDCConfig
+	if serveOIDCIssuer != "" {
+		if serveOIDCAudience == "" {
+			return nil, fmt.Errorf("--oidc-audience is required with --oidc-issuer")
+		}
+		oidcConfig = &server.OIDCConfig{
+			Issuer:    serveOIDCIssuer,
+			Audience:  serveOIDCAudience,
+			RoleClaim: serveOIDCRoleClaim,
+			WriteRole: serveOIDCWriteRole,
+		}
+	}
+	return server.NewAuthenticator(ctx, apiKeys, oidcConfig)
+}
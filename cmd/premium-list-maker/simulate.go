@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateExcludeTags []string
+	simulateAssumptions generator.Assumptions
+	simulateJSON        bool
+)
+
+func newSimulateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate <tiers.json>",
+		Short: "Report what a tiers file would capture and earn, without writing a list",
+		Long: `Match the labels in the database against a tiers file as generate would, and report how many labels each tier
+captures, how many match several tiers, and the projected revenue: every name sells in the first year with probability
+--sell-through at its registration price, and is renewed every following year with probability --renewal-rate at its
+renewal price, over --years years. Revenue is summed per currency unless --currency converts the prices.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSimulate,
+	}
+	cmd.Flags().StringSliceVar(&simulateExcludeTags, "exclude-tags", nil, "Leave out labels with any of these tags (e.g. registered), as generate does")
+	cmd.Flags().Float64Var(&simulateAssumptions.SellThrough, "sell-through", 0.05, "Share of the names registered in the first year")
+	cmd.Flags().Float64Var(&simulateAssumptions.RenewalRate, "renewal-rate", 0.8, "Share of the registered names renewed every following year")
+	cmd.Flags().IntVar(&simulateAssumptions.Years, "years", 1, "Years of revenue to project")
+	cmd.Flags().BoolVar(&simulateJSON, "json", false, "Print the result as JSON")
+	addWhereFlag(cmd, "Only count labels matching this filter expression, as generate does")
+	addFXFlags(cmd)
+	return cmd
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	tiers, err := generator.LoadTiers(args[0])
+	if err != nil {
+		return err
+	}
+	if validation := generator.ValidateTiers(tiers, nil); !validation.Valid {
+		return fmt.Errorf("tiers file is invalid: %s", strings.Join(validation.Errors, "; "))
+	}
+
+	where, err := whereOption()
+	if err != nil {
+		return err
+	}
+	rates, err := loadRates()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sim, err := generator.Simulate(database, tiers, generator.Options{
+		ExcludeTags: simulateExcludeTags,
+		Where:       where,
+		Currency:    fxCurrency,
+		Rates:       rates,
+	}, simulateAssumptions)
+	if err != nil {
+		return err
+	}
+
+	if simulateJSON {
+		data, err := json.MarshalIndent(sim, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printSimulation(sim)
+	return nil
+}
+
+// printSimulation prints the capture and revenue per tier, the overlaps and the totals
+func printSimulation(sim *generator.Simulation) {
+	a := sim.Assumptions
+	fmt.Printf("Assumptions: %.1f%% sell-through, %.1f%% renewal rate, %d year(s)\n\n", a.SellThrough*100, a.RenewalRate*100, a.Years)
+
+	fmt.Printf("%-18s %-8s %10s %16s\n", "TIER", "CURRENCY", "LABELS", "REVENUE")
+	for _, t := range sim.Tiers {
+		name := fmt.Sprintf("Tier %d", t.Tier)
+		if t.Tier == 0 {
+			name = "Overrides only"
+		}
+		fmt.Printf("%-18s %-8s %10d %16.2f\n", name, t.Currency, t.Labels, t.Revenue)
+	}
+
+	currencies := make([]string, 0, len(sim.Revenue))
+	for currency := range sim.Revenue {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		fmt.Printf("%-18s %-8s %10s %16.2f\n", "Total", currency, "", sim.Revenue[currency])
+	}
+
+	fmt.Printf("\n%d label(s) in the list, %d excluded, %d matched no tier\n", sim.Entries, sim.Excluded, sim.Unmatched)
+	if len(sim.Overlaps) > 0 {
+		fmt.Printf("\nLabels matching several tiers (the highest wins):\n")
+		for _, o := range sim.Overlaps {
+			fmt.Printf("  Tiers %-14s %10d label(s) get tier %d\n", joinTiers(o.Tiers), o.Labels, o.Winner)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var statsOutput string
+
+// dbStats is the JSON output of stats
+type dbStats struct {
+	Labels         int              `json:"labels"`
+	UntaggedLabels int              `json:"untagged_labels"`
+	Tags           int              `json:"tags"`
+	SizeBytes      int64            `json:"size_bytes"`
+	Lengths        []lengthCount    `json:"lengths"`
+	LabelsPerTag   []models.TagInfo `json:"labels_per_tag"`
+}
+
+// lengthCount is the number of labels of one length
+type lengthCount struct {
+	Length int `json:"length"`
+	Labels int `json:"labels"`
+}
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the contents of the database",
+		Long: `Print the number of labels, labels without tags, tags and the database size, the length distribution and
+the number of labels per tag (most used first), to sanity-check the database before generating a list.`,
+		Example: `  premium-list-maker stats
+  premium-list-maker stats --output json`,
+		Args: cobra.NoArgs,
+		RunE: runStats,
+	}
+	cmd.Flags().StringVar(&statsOutput, "output", "text", "Output format: text or json")
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsOutput != "text" && statsOutput != "json" {
+		return fmt.Errorf("unknown output format %q (must be text or json)", statsOutput)
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	var stats dbStats
+	if stats.Labels, err = database.CountLabels(db.LabelFilter{}); err != nil {
+		return err
+	}
+	if stats.UntaggedLabels, err = database.CountUntaggedLabels(); err != nil {
+		return err
+	}
+	if stats.SizeBytes, err = database.Size(); err != nil {
+		return err
+	}
+	lengths, err := database.CountLabelsByLength()
+	if err != nil {
+		return err
+	}
+	stats.Lengths = make([]lengthCount, 0, len(lengths))
+	for length, count := range lengths {
+		stats.Lengths = append(stats.Lengths, lengthCount{Length: length, Labels: count})
+	}
+	sort.Slice(stats.Lengths, func(i, j int) bool { return stats.Lengths[i].Length < stats.Lengths[j].Length })
+	if stats.LabelsPerTag, err = database.ListTags(); err != nil {
+		return err
+	}
+	stats.Tags = len(stats.LabelsPerTag)
+	sort.SliceStable(stats.LabelsPerTag, func(i, j int) bool {
+		return stats.LabelsPerTag[i].LabelCount > stats.LabelsPerTag[j].LabelCount
+	})
+
+	if statsOutput == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Database:         %s\n", databaseName())
+	fmt.Printf("Size:             %s\n", formatSize(stats.SizeBytes))
+	fmt.Printf("Labels:           %d\n", stats.Labels)
+	fmt.Printf("Untagged Labels:  %d\n", stats.UntaggedLabels)
+	fmt.Printf("Tags:             %d\n", stats.Tags)
+
+	if len(stats.Lengths) > 0 {
+		fmt.Printf("\nLength Distribution:\n")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		for _, l := range stats.Lengths {
+			fmt.Fprintf(tw, "  %d\t%d\t%.1f%%\t\n", l.Length, l.Labels, percent(l.Labels, stats.Labels))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(stats.LabelsPerTag) > 0 {
+		fmt.Printf("\nLabels per Tag:\n")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, t := range stats.LabelsPerTag {
+			fmt.Fprintf(tw, "  %s\t%d\n", t.Name, t.LabelCount)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// percent returns n as a percentage of total
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// formatSize formats a size in bytes with a binary unit, e.g. 1.5 MB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var tagDeletePruneUntagged bool
+
+func newTagRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <tag> <new-name>",
+		Short: "Rename a tag",
+		Long: `Rename a tag, e.g. to fix the typo or casing of a tag taken from a file name.
+If a tag called <new-name> exists already, the labels of <tag> are merged into it and <tag> is deleted.`,
+		Example: `  premium-list-maker tag rename Geo-list geo-list`,
+		Args:    cobra.ExactArgs(2),
+		RunE:    runTagRename,
+	}
+}
+
+func runTagRename(cmd *cobra.Command, args []string) error {
+	name, newName := args[0], args[1]
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	merged, err := database.RenameTag(name, newName)
+	if errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("tag '%s' doesn't exist", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if merged {
+		fmt.Printf("Merged tag '%s' into existing tag '%s'\n", name, newName)
+	} else {
+		fmt.Printf("Renamed tag '%s' to '%s'\n", name, newName)
+	}
+	return nil
+}
+
+func newTagMergeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <target> <source> [source...]",
+		Short: "Merge tags into one",
+		Long: `Move the labels of the source tags to the target tag and delete the sources, e.g. to consolidate the tags of overlapping imports.
+The target is created if it doesn't exist. Nothing changes if a source doesn't exist.`,
+		Example: `  premium-list-maker tag merge premium premium1 premium_v2 premium-final`,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    runTagMerge,
+	}
+}
+
+func runTagMerge(cmd *cobra.Command, args []string) error {
+	target, sources := args[0], args[1:]
+	if slices.Contains(sources, target) {
+		return fmt.Errorf("can't merge tag '%s' into itself", target)
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	gained, err := database.MergeTags(target, sources)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Merged %d tag(s) into '%s' (%d label(s) newly tagged)\n", len(sources), target, gained)
+	return nil
+}
+
+func newTagDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <tag>",
+		Short: "Delete a tag",
+		Long: `Delete a tag and remove it from its labels. The labels stay in the database unless --prune-untagged is set,
+which also deletes the labels (and their price overrides) that are left without any tag.`,
+		Example: `  premium-list-maker tag delete premium-final --prune-untagged`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runTagDelete,
+	}
+	cmd.Flags().BoolVar(&tagDeletePruneUntagged, "prune-untagged", false, "Also delete the labels left without any tag")
+	return cmd
+}
+
+func runTagDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	pruned := 0
+	if tagDeletePruneUntagged {
+		pruned, err = database.DeleteTagAndUntaggedLabels(name)
+	} else {
+		err = database.DeleteTag(name)
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("tag '%s' doesn't exist", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted tag '%s'\n", name)
+	if tagDeletePruneUntagged {
+		fmt.Printf("Deleted %d label(s) left without tags\n", pruned)
+	}
+	return nil
+}
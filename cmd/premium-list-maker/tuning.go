@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"premium-list-maker/internal/db"
+)
+
+// SQLite tuning flags; unset flags fall back to the config file, then to the profile
+var (
+	tuningProfile     string
+	cacheSizeKB       int
+	mmapSizeMB        int
+	synchronous       string
+	walAutocheckpoint int
+)
+
+// sqliteConfig is the sqlite section of the config file
+type sqliteConfig struct {
+	Tuning            string  `json:"tuning,omitempty"`
+	CacheSizeKB       *int    `json:"cache_size_kb,omitempty"`
+	MmapSizeMB        *int    `json:"mmap_size_mb,omitempty"`
+	Synchronous       *string `json:"synchronous,omitempty"`
+	WALAutocheckpoint *int    `json:"wal_autocheckpoint,omitempty"`
+}
+
+// dbTuning is the tuning databases are opened with, set by resolveTuning
+var dbTuning db.Tuning
+
+// dbDriver is the database driver set by --db-driver
+var dbDriver string
+
+// addTuningFlags adds the SQLite tuning flags to the root command
+func addTuningFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&dbDriver, "db-driver", db.DriverSQLite, "Database driver: sqlite, or postgres with --db a connection string (needs a build with -tags postgres); the tuning flags only apply to sqlite")
+	flags.StringVar(&tuningProfile, "tuning", "", "SQLite tuning profile: default, bulk-load (large caches, no fsync) or safe (small caches, full fsync)")
+	flags.IntVar(&cacheSizeKB, "cache-size", 0, "SQLite page cache in KB (overrides the profile)")
+	flags.IntVar(&mmapSizeMB, "mmap-size", 0, "SQLite memory-mapped I/O in MB, 0 disables it (overrides the profile)")
+	flags.StringVar(&synchronous, "synchronous", "", "SQLite synchronous mode: OFF, NORMAL or FULL (overrides the profile)")
+	flags.IntVar(&walAutocheckpoint, "wal-autocheckpoint", 0, "WAL pages before an automatic checkpoint, 0 disables them (overrides the profile)")
+}
+
+// resolveTuning combines the profile, the config file and the flags into dbTuning
+func resolveTuning(cmd *cobra.Command) error {
+	cfg := sqliteConfig{}
+	if appConfig.SQLite != nil {
+		cfg = *appConfig.SQLite
+	}
+
+	profile := db.TuningDefault
+	if cfg.Tuning != "" {
+		profile = cfg.Tuning
+	}
+	if cmd.Flags().Changed("tuning") {
+		profile = tuningProfile
+	}
+	t, err := db.TuningProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	if cfg.CacheSizeKB != nil {
+		t.CacheSizeKB = *cfg.CacheSizeKB
+	}
+	if cfg.MmapSizeMB != nil {
+		t.MmapSizeMB = *cfg.MmapSizeMB
+	}
+	if cfg.Synchronous != nil {
+		t.Synchronous = *cfg.Synchronous
+	}
+	if cfg.WALAutocheckpoint != nil {
+		t.WALAutocheckpoint = *cfg.WALAutocheckpoint
+	}
+
+	if cmd.Flags().Changed("cache-size") {
+		t.CacheSizeKB = cacheSizeKB
+	}
+	if cmd.Flags().Changed("mmap-size") {
+		t.MmapSizeMB = mmapSizeMB
+	}
+	if cmd.Flags().Changed("synchronous") {
+		t.Synchronous = synchronous
+	}
+	if cmd.Flags().Changed("wal-autocheckpoint") {
+		t.WALAutocheckpoint = walAutocheckpoint
+	}
+	dbTuning = t
+	return nil
+}
+
+// openDatabase opens a database of the --db-driver with the tuning of the flags and config file
+// path is the database file for SQLite and the connection string for PostgreSQL
+func openDatabase(path string) (*db.DB, error) {
+	return openDriverDatabase(dbDriver, path)
+}
+
+// openDriverDatabase opens a database of driver, e.g. SQLite for a temporary database
+func openDriverDatabase(driver, path string) (*db.DB, error) {
+	tuning := dbTuning
+	if tuning == (db.Tuning{}) {
+		tuning, _ = db.TuningProfile(db.TuningDefault)
+	}
+	name := redactDatabase(path)
+	database, err := db.Open(driver, path, tuning)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	// Tell the user an older database was upgraded in place
+	meta, err := database.Metadata()
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if meta.UpgradedFrom > 0 {
+		fmt.Fprintf(os.Stderr, "Upgraded %s from schema version %d to %d\n",
+			name, meta.UpgradedFrom, meta.SchemaVersion)
+	}
+	return database, nil
+}
+
+// serverDatabase reports whether --db names a database server rather than a file
+func serverDatabase() bool {
+	driver := strings.ToLower(dbDriver)
+	return driver != "" && driver != db.DriverSQLite
+}
+
+// databaseName is --db as shown to the user and recorded in manifests, without the password of a connection string
+func databaseName() string {
+	return redactDatabase(dbPath)
+}
+
+// passwordSetting matches the password of a key=value connection string
+var passwordSetting = regexp.MustCompile(`(?i)\bpassword\s*=\s*('[^']*'|\S+)`)
+
+// redactDatabase hides the password of a PostgreSQL connection string, as a URL or key=value settings
+func redactDatabase(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" && u.User != nil {
+		return u.Redacted()
+	}
+	return passwordSetting.ReplaceAllString(path, "password=xxxxx")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"premium-list-maker/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// undoImportForce undoes an import session that is still recorded as running
+var undoImportForce bool
+
+func newUndoImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo-import <session-id>",
+		Short: "Remove the labels and tags an import session created",
+		Long: `Undo an import session, as printed by import: remove the label-tag associations it created, then the labels
+and tags it created that nothing else uses anymore. Labels that other imports, tag commands or price overrides
+still use are kept, as are associations that another import added too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runUndoImport,
+	}
+	cmd.Flags().BoolVar(&undoImportForce, "force", false, "Undo a session still recorded as running, e.g. after the import crashed")
+	return cmd
+}
+
+func runUndoImport(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("invalid import session ID %q", args[0])
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	session, err := database.GetImport(id)
+	if errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("import session %d not found", id)
+	}
+	if err != nil {
+		return err
+	}
+	switch session.Status {
+	case db.ImportUndone:
+		return fmt.Errorf("import session %d was already undone on %s", id, session.UndoneAt.Format(time.RFC3339))
+	case db.ImportRunning:
+		if !undoImportForce {
+			return fmt.Errorf("import session %d is still running; use --force if the import was interrupted", id)
+		}
+	}
+
+	result, err := database.UndoImport(id, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Undid import session %d of %s (%d file(s))\n", id, session.StartedAt.Local().Format(time.RFC3339), len(session.Files))
+	fmt.Printf("  Removed %d label(s), %d tag(s) and %d association(s)\n", result.Labels, result.Tags, result.Associations)
+	if result.LabelsKept > 0 || result.TagsKept > 0 {
+		fmt.Printf("  Kept %d label(s) and %d tag(s) still in use elsewhere\n", result.LabelsKept, result.TagsKept)
+	}
+	if result.Checkpoints > 0 {
+		fmt.Printf("  Forgot %d checkpoint(s) of interrupted files, which the next import reads from the start\n", result.Checkpoints)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"premium-list-maker/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateJSON             bool
+	validateDetailedExitCode bool
+)
+
+func newValidateTiersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-tiers <tiers.json>",
+		Short: "Check a tiers file and show which labels match several tiers",
+		Long: `Check a tiers file for errors (duplicate tier numbers, missing tags or currencies, negative prices) and warnings
+(tiers without prices, tags claimed by several tiers, tags not in the database). With a database, the labels matching
+more than one tier are counted per set of tiers, with the tier they get (the highest), so surprising prices are caught
+before generation.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runValidateTiers,
+	}
+	cmd.Flags().BoolVar(&validateJSON, "json", false, "Print the result as JSON")
+	cmd.Flags().BoolVar(&validateDetailedExitCode, "detailed-exit-code", false, "Exit with code 3 when there are warnings (0 = clean, 1 = invalid or error)")
+	return cmd
+}
+
+func runValidateTiers(cmd *cobra.Command, args []string) error {
+	tiers, err := generator.LoadTiers(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The database is optional: without one only the file itself is checked
+	var validation *generator.TierValidation
+	if _, err := os.Stat(dbPath); err == nil || serverDatabase() {
+		database, err := openDatabase(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		tags, err := database.ListTags()
+		if err != nil {
+			return err
+		}
+		knownTags := make(map[string]bool, len(tags))
+		for _, t := range tags {
+			knownTags[t.Name] = true
+		}
+		validation = generator.ValidateTiers(tiers, knownTags)
+		if validation.Valid {
+			overlaps, err := generator.AnalyzeOverlaps(database, tiers)
+			if err != nil {
+				return err
+			}
+			validation.AddOverlaps(overlaps)
+		}
+	} else {
+		validation = generator.ValidateTiers(tiers, nil)
+		if !validateJSON {
+			fmt.Printf("Database %s not found, checking the tiers file only\n", dbPath)
+		}
+	}
+
+	if validateJSON {
+		data, err := json.MarshalIndent(validation, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		printTierValidation(validation)
+	}
+
+	if !validation.Valid {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("tiers file is invalid: %s", strings.Join(validation.Errors, "; "))
+	}
+	if validateDetailedExitCode && len(validation.Warnings) > 0 {
+		return newExitError(exitWarnings, cmd)
+	}
+	return nil
+}
+
+// printTierValidation prints the errors, warnings and overlaps of a validation
+func printTierValidation(v *generator.TierValidation) {
+	for _, e := range v.Errors {
+		fmt.Printf("%s   %s\n", red("Error:"), e)
+	}
+	for _, w := range v.Warnings {
+		fmt.Printf("%s %s\n", yellow("Warning:"), w)
+	}
+	if len(v.Overlaps) > 0 {
+		fmt.Printf("\nLabels matching several tiers:\n")
+		fmt.Printf("  %-20s %-8s %10s  %s\n", "TIERS", "WINNER", "LABELS", "EXAMPLES")
+		for _, o := range v.Overlaps {
+			fmt.Printf("  %-20s %-8d %10d  %s\n", joinTiers(o.Tiers), o.Winner, o.Labels, strings.Join(o.Examples, ", "))
+		}
+	}
+	if v.Valid && len(v.Warnings) == 0 {
+		fmt.Println(green("Tiers file is valid"))
+	}
+}
+
+// joinTiers formats tier numbers as "3, 1"
+func joinTiers(tiers []int) string {
+	s := make([]string, len(tiers))
+	for i, t := range tiers {
+		s[i] = fmt.Sprint(t)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"premium-list-maker/internal/notify"
+	"premium-list-maker/internal/webhook"
+)
+
+var (
+	webhookURLs   []string
+	webhookSecret string
+)
+
+// newWebhookNotifier creates a notifier from the global webhook flags (nil if none are configured)
+func newWebhookNotifier() *webhook.Notifier {
+	return webhook.NewNotifier(webhookURLs, webhookSecret)
+}
+
+// notificationsEnabled reports whether any webhook, email or chat notification is configured
+func notificationsEnabled() bool {
+	return len(webhookURLs) > 0 || appConfig.Email != nil || len(appConfig.Chat) > 0
+}
+
+// notifyEvent sends an event to the configured webhooks, email recipients and chat channels
+// Delivery failures are reported but never fail the command
+func notifyEvent(event webhook.Event) {
+	if err := newWebhookNotifier().Notify(event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	emailer, err := notify.NewEmailer(appConfig.Email)
+	if err == nil {
+		err = emailer.Notify(event)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	chat, err := notify.NewChatNotifier(appConfig.Chat)
+	if err == nil {
+		err = chat.Notify(event)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
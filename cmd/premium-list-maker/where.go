@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"premium-list-maker/internal/tagexpr"
+
+	"github.com/spf13/cobra"
+)
+
+var whereExpr string
+
+// addWhereFlag adds the --where flag selecting labels by a filter expression
+func addWhereFlag(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringVar(&whereExpr, "where", "", usage+`, e.g. "brand AND NOT registered AND len <= 5" (tags may use * and ? wildcards)`)
+}
+
+// whereOption returns the filter expression of --where, nil without one
+func whereOption() (tagexpr.Expr, error) {
+	if whereExpr == "" {
+		return nil, nil
+	}
+	where, err := tagexpr.Parse(whereExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --where: %w", err)
+	}
+	return where, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var zoneImportTag string
+
+func newImportZoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-zone <zone-file>",
+		Short: "Import the delegated labels of a DNS zone file with a tag",
+		Long: `Import every label delegated in a DNS master zone file (the labels directly under the zone origin, e.g.
+"example" for example.shop. in the shop zone) and tag them --tag, so that tiers and filters can tell them apart.
+Labels new to the database get the usual length and shape tags, like import. The run is recorded as an import
+session that undo-import can take back.
+
+The tag is only added: to drop it from labels that have since left the zone, run "tag delete <tag>" first.`,
+		Example: `  premium-list-maker import-zone shop.zone
+  premium-list-maker import-zone shop.txt --tag delegated --zone-origin shop`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportZone,
+	}
+	cmd.Flags().StringVar(&zoneImportTag, "tag", "in-zone", "Tag for the labels in the zone")
+	cmd.Flags().StringVar(&zoneOrigin, "zone-origin", "", "Zone origin (defaults to the file's $ORIGIN or SOA owner)")
+	return cmd
+}
+
+func runImportZone(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	path := args[0]
+	if zoneImportTag == "" {
+		return fmt.Errorf("--tag must not be empty")
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	// Ctrl-C stops the import between batches, keeping what was committed
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	importID, err := database.StartImport([]string{path}, start)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Importing %s (tag: %s)...\n", path, zoneImportTag)
+	// Zone files of large TLDs are bigger than the label lists import guards against
+	lines, _ := importer.EstimateCSVLines(path)
+	report, endProgress := newProgress(lines)
+	stats, importErr := importer.ImportZone(database.InImport(importID), path, zoneOrigin,
+		importer.WithMaxFileSize(-1),
+		importer.WithAutoTag(),
+		importer.WithTag(zoneImportTag),
+		importer.WithProgress(report),
+		importer.WithContext(ctx),
+	)
+	endProgress()
+
+	status := db.ImportCompleted
+	counts := db.ImportCounts{FilesProcessed: 1}
+	var errs []string
+	if stats != nil {
+		counts.LabelsProcessed, counts.NewLabels, counts.ExistingLabels, counts.LabelsSkipped = stats.Imported, stats.NewLabels, stats.ExistingLabels, stats.Skipped
+		for _, e := range stats.Errors {
+			errs = append(errs, e.Error())
+			runLog.Print(e)
+		}
+		if stats.Canceled {
+			status = db.ImportCanceled
+		}
+	}
+	if importErr != nil && (stats == nil || !stats.Canceled) {
+		counts.FilesProcessed, counts.FilesSkipped = 0, 1
+		errs = append(errs, importErr.Error())
+	}
+	if err := database.FinishImport(importID, status, counts, errs, time.Now()); err != nil {
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
+	}
+	if importErr != nil {
+		return importErr
+	}
+
+	fmt.Printf("Imported %d label(s) in the zone (%d new, %d existing) and tagged them '%s' in %s\n",
+		stats.Imported, stats.NewLabels, stats.ExistingLabels, zoneImportTag, formatDuration(time.Since(start)))
+	if len(stats.Errors) > 0 {
+		fmt.Printf("Skipped %d label(s):\n", len(stats.Errors))
+		for i, e := range stats.Errors {
+			if i == 10 {
+				fmt.Printf("  ... and %d more (premium-list-maker history %d)\n", len(stats.Errors)-i, importID)
+				break
+			}
+			fmt.Printf("  %s\n", e)
+		}
+	}
+	fmt.Printf("Import session %d (undo with: premium-list-maker undo-import %d)\n", importID, importID)
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorTLD      string
+	monitorPrevious string
+	monitorTiers    string
+	monitorTag      string
+	monitorReport   string
+	monitorDryRun   bool
+)
+
+func newZoneMonitorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "zone-monitor <zone-file>",
+		Short: "Tag premium labels newly registered since the previous zone file and report them as sold",
+		Long: `Compare today's zone file against --previous (or, without it, against the labels already tagged --tag),
+tag every premium label that newly appears in the zone with 'registered' and 'registered:<year>',
+and write a sold premium names report.
+
+Premium labels are the labels matched by --tiers, or every label in the database without it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runZoneMonitor,
+	}
+
+	cmd.Flags().StringVar(&monitorTLD, "tld", "", "TLD of the zone")
+	cmd.Flags().StringVar(&monitorPrevious, "previous", "", "Previous zone file to diff against (default: compare with the labels tagged --tag)")
+	cmd.Flags().StringVar(&zoneOrigin, "zone-origin", "", "Zone origin (defaults to the file's $ORIGIN or SOA owner)")
+	cmd.Flags().StringVar(&monitorTiers, "tiers", "", "Tiers file defining the premium labels and their prices")
+	cmd.Flags().StringVar(&monitorTag, "tag", "registered", "Tag for registered labels; the year tag is <tag>:<year>")
+	cmd.Flags().StringVar(&monitorReport, "report", "", "Path for the sold premium names report (default: sold-premiums-<tld>-<date>.csv, or named by --name-template)")
+	cmd.Flags().BoolVar(&monitorDryRun, "dry-run", false, "Write the report without tagging the database")
+	cmd.MarkFlagRequired("tld")
+
+	return cmd
+}
+
+func runZoneMonitor(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	tld := strings.Trim(strings.ToLower(monitorTLD), ".")
+	if tld == "" {
+		return fmt.Errorf("--tld is required")
+	}
+	if monitorTag == "" {
+		return fmt.Errorf("--tag must not be empty")
+	}
+	if zoneOrigin == "" {
+		zoneOrigin = tld
+	}
+
+	database, err := openDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	labelTags, err := database.GetAllLabelsWithTags()
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	// The premium labels, with their prices if tiers are given
+	premium := make(map[string]*generator.PremiumListEntry)
+	if monitorTiers != "" {
+		tiers, err := generator.LoadTiers(monitorTiers)
+		if err != nil {
+			return err
+		}
+		entries, _, err := generator.MatchLabels(database, tiers, nil)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			premium[entries[i].Label] = &entries[i]
+		}
+	} else {
+		for label := range labelTags {
+			premium[label] = &generator.PremiumListEntry{Label: label}
+		}
+	}
+
+	// Premium labels that were registered before today's zone
+	baseline := "database"
+	var registeredBefore map[string]bool
+	if monitorPrevious != "" {
+		baseline = monitorPrevious
+		if registeredBefore, err = zonePremiums(monitorPrevious, premium); err != nil {
+			return err
+		}
+	} else {
+		registeredBefore = taggedPremiums(premium, labelTags, monitorTag)
+	}
+
+	registered, err := zonePremiums(args[0], premium)
+	if err != nil {
+		return err
+	}
+	sold := newlyRegistered(registered, registeredBefore)
+
+	fmt.Printf("Found %d newly registered premium label(s) in %s (%d premium labels, baseline: %s)\n", len(sold), args[0], len(premium), baseline)
+
+	reportPath := monitorReport
+	if reportPath == "" {
+		if reportPath, err = autoName("{name}-{tld}-{date:20060102}", start, ".csv", map[string]string{"name": "sold-premiums", "tld": tld}); err != nil {
+			return err
+		}
+	}
+	if err := writePremiumNamesReport(reportPath, tld, sold, premium); err != nil {
+		return err
+	}
+	fmt.Printf("Sold premium names report saved to: %s\n", reportPath)
+
+	if monitorDryRun {
+		fmt.Println("Dry run: database not tagged.")
+	} else if len(sold) > 0 {
+		tagged, err := database.TagLabels(sold, monitorTag)
+		if err != nil {
+			return fmt.Errorf("failed to tag registered labels: %w", err)
+		}
+		yearTag := monitorTag + ":" + strconv.Itoa(start.Year())
+		if _, err := database.TagLabels(sold, yearTag); err != nil {
+			return fmt.Errorf("failed to tag registration year: %w", err)
+		}
+		fmt.Printf("Tagged %d label(s) '%s' and '%s'\n", tagged, monitorTag, yearTag)
+	}
+
+	notifyEvent(webhook.Event{
+		Event:      webhook.EventZoneMonitored,
+		Database:   databaseName(),
+		OutputPath: reportPath,
+		Stats: webhook.ZoneMonitorStats{
+			TLD:             tld,
+			Baseline:        baseline,
+			PremiumLabels:   len(premium),
+			NewlyRegistered: len(sold),
+			DurationMS:      time.Since(start).Milliseconds(),
+		},
+	})
+	return nil
+}
+
+// forEachZoneLabel streams the normalized second-level labels of a zone file to fn
+func forEachZoneLabel(path string, fn func(label string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return importer.ParseZoneSLDs(file, zoneOrigin, func(label string) error {
+		fn(importer.NormalizeLabel(label))
+		return nil
+	})
+}
+
+// zonePremiums returns the premium labels delegated in the zone file at path
+func zonePremiums(path string, premium map[string]*generator.PremiumListEntry) (map[string]bool, error) {
+	registered := make(map[string]bool)
+	err := forEachZoneLabel(path, func(label string) {
+		if premium[label] != nil {
+			registered[label] = true
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return registered, nil
+}
+
+// taggedPremiums returns the premium labels carrying tag in the database
+func taggedPremiums(premium map[string]*generator.PremiumListEntry, labelTags map[string][]string, tag string) map[string]bool {
+	tagged := make(map[string]bool)
+	for label := range premium {
+		if hasTag(labelTags[label], tag) {
+			tagged[label] = true
+		}
+	}
+	return tagged
+}
+
+// newlyRegistered returns the labels registered now that weren't before, sorted
+// Labels that were dropped from the zone since are not reported
+func newlyRegistered(now, before map[string]bool) []string {
+	var labels []string
+	for label := range now {
+		if !before[label] {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writePremiumNamesReport writes premium labels as domain names with their tier and prices
+func writePremiumNamesReport(path, tld string, labels []string, premium map[string]*generator.PremiumListEntry) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"domain", "tier", "currency", "registration", "renewal", "restore"})
+	for _, label := range labels {
+		entry := premium[label]
+		tier := ""
+		if entry.Tier > 0 {
+			tier = strconv.Itoa(entry.Tier)
+		}
+		writer.Write([]string{label + "." + tld, tier, entry.Currency, formatOptionalPrice(entry.PriceReg), formatOptionalPrice(entry.PriceRen), formatOptionalPrice(entry.PriceRes)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// formatOptionalPrice formats a price with two decimals, empty if unset
+func formatOptionalPrice(price *float64) string {
+	if price == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*price, 'f', 2, 64)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"premium-list-maker/internal/generator"
+)
+
+func TestZoneMonitorDiff(t *testing.T) {
+	dir := t.TempDir()
+	yesterday := filepath.Join(dir, "shop-yesterday.zone")
+	today := filepath.Join(dir, "shop-today.zone")
+	writeZone := func(path, records string) {
+		t.Helper()
+		zone := "$ORIGIN shop.\n@ 3600 IN SOA ns1.nic.shop. hostmaster.nic.shop. 1 3600 900 604800 86400\n" + records
+		if err := os.WriteFile(path, []byte(zone), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeZone(yesterday, `shoes IN NS ns1.example.com.
+hats IN NS ns1.example.com.
+other IN NS ns1.example.com.
+`)
+	// hats was dropped, Bags is new with several records, other and newcomer aren't premium
+	writeZone(today, `shoes IN NS ns1.example.com.
+Bags IN NS ns1.example.com.
+bags IN NS ns2.example.com.
+bags IN DS 12345 13 2 0123456789abcdef
+ns1.bags IN A 192.0.2.1
+paris.shop. IN NS ns1.example.com.
+other IN NS ns1.example.com.
+newcomer IN NS ns1.example.com.
+`)
+
+	premium := make(map[string]*generator.PremiumListEntry)
+	for _, label := range []string{"shoes", "hats", "bags", "paris", "rome"} {
+		premium[label] = &generator.PremiumListEntry{Label: label}
+	}
+
+	registered, err := zonePremiums(today, premium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"shoes": true, "bags": true, "paris": true}; !reflect.DeepEqual(registered, want) {
+		t.Errorf("premiums in today's zone = %v, want %v", registered, want)
+	}
+
+	// Against the previous zone
+	before, err := zonePremiums(yesterday, premium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sold := newlyRegistered(registered, before); !reflect.DeepEqual(sold, []string{"bags", "paris"}) {
+		t.Errorf("sold since the previous zone = %v, want [bags paris]", sold)
+	}
+
+	// Against the labels tagged registered in the database; a year tag alone doesn't count
+	labelTags := map[string][]string{
+		"shoes": {"fashion", "registered", "registered:2025"},
+		"paris": {"registered"},
+		"bags":  {"registered:2024"},
+		"other": {"registered"},
+	}
+	before = taggedPremiums(premium, labelTags, "registered")
+	if want := map[string]bool{"shoes": true, "paris": true}; !reflect.DeepEqual(before, want) {
+		t.Errorf("tagged premiums = %v, want %v", before, want)
+	}
+	if sold := newlyRegistered(registered, before); !reflect.DeepEqual(sold, []string{"bags"}) {
+		t.Errorf("sold since the database = %v, want [bags]", sold)
+	}
+
+	// Nothing is sold against the same zone
+	if sold := newlyRegistered(registered, registered); len(sold) != 0 {
+		t.Errorf("sold against the same zone = %v", sold)
+	}
+	if _, err := zonePremiums(filepath.Join(dir, "missing.zone"), premium); err == nil {
+		t.Error("zonePremiums of a missing file succeeded")
+	}
+}
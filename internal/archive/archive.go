@@ -0,0 +1,310 @@
+// Package archive keeps generated premium lists in a compact store: every list is a zstd-compressed
+// JSONL file with one object per row, and an index file records when each list was generated, so
+// past lists can be looked up by date instead of keeping thousands of loose CSVs around
+package archive
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/csvout"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// indexFile is the name of the index in the archive directory
+const indexFile = "index.jsonl"
+
+// ErrNotFound is returned when no archived list matches a lookup
+var ErrNotFound = errors.New("no archived list found")
+
+// Entry describes an archived list in the index
+type Entry struct {
+	ID        string    `json:"id"` // Unique within the archive, e.g. shop-20240301T120000Z
+	Name      string    `json:"name"`
+	TLD       string    `json:"tld,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Rows      int       `json:"rows"`
+	Columns   []string  `json:"columns"`
+	SHA256    string    `json:"sha256"`     // Of the original CSV
+	File      string    `json:"file"`       // Relative to the archive directory
+	SizeBytes int64     `json:"size_bytes"` // Of the compressed file
+}
+
+// Archive is a directory of archived lists
+type Archive struct {
+	dir string
+}
+
+// Open opens the archive in dir, creating the directory if needed
+func Open(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// AddCSV archives a generated CSV list as of createdAt
+// The first row of the CSV is taken as the column names
+func (a *Archive) AddCSV(csvPath, tld string, createdAt time.Time) (*Entry, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open list: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	// Lists written with generate --bom start with a byte order mark, which isn't part of the first column name
+	in := bufio.NewReader(io.TeeReader(file, hash))
+	if b, err := in.Peek(3); err == nil && string(b) == "\ufeff" {
+		in.Discard(3)
+	}
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#' // Metadata embedded by generate --embed-metadata
+	columns, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("list %s is empty", csvPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list header: %w", err)
+	}
+	columns = append([]string(nil), columns...)
+
+	createdAt = createdAt.UTC()
+	name := strings.TrimSuffix(filepath.Base(csvPath), filepath.Ext(csvPath))
+	entry := &Entry{
+		ID:        name + "-" + createdAt.Format("20060102T150405Z"),
+		Name:      name,
+		TLD:       tld,
+		CreatedAt: createdAt,
+		Columns:   columns,
+	}
+	entry.File = filepath.Join(createdAt.Format("2006"), entry.ID+".jsonl.zst")
+
+	// Write next to the final name and rename, so a failed archive leaves no partial list
+	path := filepath.Join(a.dir, entry.File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("list %s is already archived", entry.ID)
+	}
+	tmpPath := path + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	rows, err := writeRows(out, reader, columns)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Hash the rest of the file, in case the reader stopped short of it
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	entry.Rows = rows
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	entry.SizeBytes = info.Size()
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("failed to store archive file: %w", err)
+	}
+	if err := a.appendIndex(entry); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return entry, nil
+}
+
+// writeRows writes the CSV rows as zstd-compressed JSON objects keyed by column
+func writeRows(w io.Writer, reader *csv.Reader, columns []string) (int, error) {
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return 0, err
+	}
+	buf := bufio.NewWriter(enc)
+	jsonEnc := json.NewEncoder(buf)
+
+	rows := 0
+	row := make(map[string]string, len(columns))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			enc.Close()
+			return 0, fmt.Errorf("failed to read list: %w", err)
+		}
+		clear(row)
+		for i, value := range record {
+			if i < len(columns) {
+				row[columns[i]] = value
+			}
+		}
+		if err := jsonEnc.Encode(row); err != nil {
+			enc.Close()
+			return 0, fmt.Errorf("failed to write archive: %w", err)
+		}
+		rows++
+	}
+	if err := buf.Flush(); err != nil {
+		enc.Close()
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return rows, nil
+}
+
+// appendIndex adds an entry to the index
+func (a *Archive) appendIndex(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(a.dir, indexFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive index: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return f.Close()
+}
+
+// Entries returns the archived lists, oldest first
+func (a *Archive) Entries() ([]Entry, error) {
+	f, err := os.Open(filepath.Join(a.dir, indexFile))
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer f.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse archive index: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+// Find returns the list with the given ID
+func (a *Archive) Find(id string) (*Entry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w with ID %s", ErrNotFound, id)
+}
+
+// At returns the list that was current at t: the last one archived at or before t
+// If tld is not empty, only lists of that TLD are considered
+func (a *Archive) At(t time.Time, tld string) (*Entry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !e.CreatedAt.After(t) && (tld == "" || e.TLD == tld) {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("%w as of %s", ErrNotFound, t.Format(time.RFC3339))
+}
+
+// Rows calls fn with every row of an archived list, keyed by column
+func (a *Archive) Rows(e *Entry, fn func(row map[string]string) error) error {
+	f, err := os.Open(filepath.Join(a.dir, e.File))
+	if err != nil {
+		return fmt.Errorf("failed to open archived list: %w", err)
+	}
+	defer f.Close()
+
+	dec, err := zstd.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to open archived list: %w", err)
+	}
+	defer dec.Close()
+
+	jsonDec := json.NewDecoder(bufio.NewReader(dec))
+	for {
+		var row map[string]string
+		if err := jsonDec.Decode(&row); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read archived list %s: %w", e.ID, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// WriteCSV writes an archived list as CSV with its original columns, quoted by quoting
+// If keep is not nil, only the rows it returns true for are written
+func (a *Archive) WriteCSV(e *Entry, w io.Writer, quoting csvout.Quoting, keep func(row map[string]string) bool) error {
+	writer := csvout.NewWriter(w, quoting)
+	if err := writer.Write(e.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(e.Columns))
+	err := a.Rows(e, func(row map[string]string) error {
+		if keep != nil && !keep(row) {
+			return nil
+		}
+		for i, column := range e.Columns {
+			record[i] = row[column]
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
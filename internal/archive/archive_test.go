@@ -0,0 +1,70 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/csvout"
+)
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := Open(filepath.Join(dir, "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	march := filepath.Join(dir, "shop.csv")
+	if err := os.WriteFile(march, []byte("label,price\nshoes,100\nhats,50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := archive.AddCSV(march, "shop", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("AddCSV failed: %v", err)
+	}
+	if first.Rows != 2 || first.ID != "shop-20240301T120000Z" || first.SHA256 == "" {
+		t.Errorf("entry = %+v", first)
+	}
+	if err := os.WriteFile(march, []byte("label,price\nshoes,120\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.AddCSV(march, "shop", time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The list current in mid-March is the first one
+	entry, err := archive.At(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := archive.WriteCSV(entry, &buf, csvout.Quoting{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "label,price\nshoes,100\nhats,50\n" {
+		t.Errorf("list = %q", buf.String())
+	}
+
+	buf.Reset()
+	entry, err = archive.At(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.WriteCSV(entry, &buf, csvout.Quoting{}, func(row map[string]string) bool { return row["label"] == "shoes" }); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "label,price\nshoes,120\n" {
+		t.Errorf("list = %q", buf.String())
+	}
+
+	if _, err := archive.At(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if _, err := archive.At(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "app"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound for another TLD", err)
+	}
+}
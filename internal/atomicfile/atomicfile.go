@@ -0,0 +1,76 @@
+// Package atomicfile writes files through a temporary file next to the destination that is renamed
+// over it once complete, so a crash or error mid-write never leaves a truncated file behind
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is a file being written in place of path
+type File struct {
+	*os.File
+	path string
+	done bool
+}
+
+// Create starts writing path; nothing appears at path until Commit
+func Create(path string) (*File, error) {
+	// Same directory, so the rename doesn't cross file systems
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: f, path: path}, nil
+}
+
+// Commit syncs and closes the file and renames it to its final path
+// On error the temporary file is removed and any previous file at path is left untouched
+func (f *File) Commit() error {
+	if f.done {
+		return fmt.Errorf("%s is already committed or aborted", f.path)
+	}
+	f.done = true
+
+	err := f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// CreateTemp makes the file private; give it the permissions of os.Create
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Abort closes and removes the temporary file
+// It does nothing after Commit, so it can be deferred right after Create
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// WriteFile writes data to path atomically, like os.WriteFile
+func WriteFile(path string, data []byte) error {
+	f, err := Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Commit()
+}
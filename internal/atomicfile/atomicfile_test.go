@@ -0,0 +1,74 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.csv")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Abort()
+	if _, err := f.WriteString("new\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old file stays until the commit
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Errorf("before commit got %q, want old content", data)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("after commit got %q, want new content", data)
+	}
+	assertOnlyFile(t, dir, "list.csv")
+}
+
+func TestAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.csv")
+
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("partial")
+	f.Abort()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("aborted file exists: %v", err)
+	}
+	assertOnlyFile(t, dir)
+
+	// Committing after an abort fails
+	if err := f.Commit(); err == nil {
+		t.Error("expected an error committing an aborted file")
+	}
+}
+
+func assertOnlyFile(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(names) {
+		t.Fatalf("directory has %d entries, want %v", len(entries), names)
+	}
+	for i, e := range entries {
+		if e.Name() != names[i] {
+			t.Errorf("entry %d is %s, want %s", i, e.Name(), names[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package availability
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the availability outcome for a single domain name
+type Result struct {
+	Name       string
+	Registered bool
+	Created    time.Time // Registration date, if the checker reports it (RDAP)
+	Err        error     // Set when the status could not be determined
+}
+
+// Checker checks whether domain names are already registered or delegated
+// CheckNames calls fn once for every name, in no particular order
+type Checker interface {
+	CheckNames(ctx context.Context, names []string, fn func(Result)) error
+}
+
+// newLimiter returns a channel that yields at most rate ticks per second
+// A rate <= 0 means unlimited and returns nil
+func newLimiter(ctx context.Context, rate float64) <-chan time.Time {
+	if rate <= 0 {
+		return nil
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	go func() {
+		<-ctx.Done()
+		ticker.Stop()
+	}()
+	return ticker.C
+}
+
+// wait blocks until the limiter allows another request or the context is done
+func wait(ctx context.Context, limiter <-chan time.Time) error {
+	if limiter == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-limiter:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkConcurrently runs check for every name on a pool of workers, respecting the rate limit
+// fn is called from the calling goroutine only
+func checkConcurrently(ctx context.Context, names []string, concurrency int, rate float64, check func(context.Context, string) Result, fn func(Result)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limiter := newLimiter(ctx, rate)
+	jobs := make(chan string)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				results <- check(ctx, name)
+			}
+		}()
+	}
+
+	// Feed names respecting the rate limit
+	go func() {
+		defer close(jobs)
+		for _, name := range names {
+			if err := wait(ctx, limiter); err != nil {
+				return
+			}
+			select {
+			case jobs <- name:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		fn(result)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,192 @@
+package availability
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Record types a DNSChecker looks up
+const (
+	DNSRecordNS   = "ns"   // Delegated when the name has NS records
+	DNSRecordSOA  = "soa"  // Delegated when the name is the apex of a zone (has its own SOA)
+	DNSRecordBoth = "both" // Delegated when either lookup finds the name
+)
+
+// DNSChecker treats a name as registered when it has NS records (i.e. it is delegated)
+// With Record set to soa or both, a name that is the apex of its own zone counts as delegated too
+type DNSChecker struct {
+	Resolver    *net.Resolver
+	Server      string        // Resolver address (host:port) for SOA queries; empty = first nameserver in /etc/resolv.conf
+	Record      string        // ns (default), soa or both
+	Concurrency int           // Number of parallel lookups
+	Rate        float64       // Maximum lookups per second (0 = unlimited)
+	Timeout     time.Duration // Per-lookup timeout
+}
+
+// NewDNSChecker creates a DNS checker
+// If resolverAddr is empty, the system resolver is used, otherwise all queries go to resolverAddr (host:port)
+func NewDNSChecker(resolverAddr string, concurrency int, rate float64) *DNSChecker {
+	resolver := net.DefaultResolver
+	if resolverAddr != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, resolverAddr)
+			},
+		}
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &DNSChecker{
+		Resolver:    resolver,
+		Server:      resolverAddr,
+		Record:      DNSRecordNS,
+		Concurrency: concurrency,
+		Rate:        rate,
+		Timeout:     5 * time.Second,
+	}
+}
+
+// CheckNames looks up NS (and/or SOA) records for every name using a pool of workers
+func (c *DNSChecker) CheckNames(ctx context.Context, names []string, fn func(Result)) error {
+	switch c.Record {
+	case "", DNSRecordNS, DNSRecordSOA, DNSRecordBoth:
+	default:
+		return fmt.Errorf("unknown DNS record type: %s (expected ns, soa or both)", c.Record)
+	}
+	if c.Record != DNSRecordNS && c.Record != "" && c.Server == "" {
+		server, err := systemNameserver()
+		if err != nil {
+			return err
+		}
+		c.Server = server
+	}
+	return checkConcurrently(ctx, names, c.Concurrency, c.Rate, c.checkName, fn)
+}
+
+// checkName performs the configured lookups for a single name
+func (c *DNSChecker) checkName(ctx context.Context, name string) Result {
+	lookupCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var delegated bool
+	var err error
+	switch c.Record {
+	case DNSRecordSOA:
+		delegated, err = c.lookupSOA(lookupCtx, name)
+	case DNSRecordBoth:
+		delegated, err = c.lookupNS(lookupCtx, name)
+		if err == nil && !delegated {
+			delegated, err = c.lookupSOA(lookupCtx, name)
+		}
+	default:
+		delegated, err = c.lookupNS(lookupCtx, name)
+	}
+	if err != nil {
+		return Result{Name: name, Err: err}
+	}
+	return Result{Name: name, Registered: delegated}
+}
+
+// lookupNS reports whether name has NS records
+func (c *DNSChecker) lookupNS(ctx context.Context, name string) (bool, error) {
+	ns, err := c.Resolver.LookupNS(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			// NXDOMAIN or no NS records: not delegated
+			return false, nil
+		}
+		return false, err
+	}
+	return len(ns) > 0, nil
+}
+
+// lookupSOA reports whether name is the apex of a zone, i.e. the answer holds an SOA record owned by name
+// The standard library has no SOA lookup, so the query is sent to c.Server directly over UDP
+func (c *DNSChecker) lookupSOA(ctx context.Context, name string) (bool, error) {
+	fqdn := strings.TrimSuffix(name, ".") + "."
+	qname, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		return false, err
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: qname, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return false, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", c.Server)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(packed); err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return false, err
+		}
+		var resp dnsmessage.Message
+		if err := resp.Unpack(buf[:n]); err != nil || resp.ID != id {
+			// Ignore stray or malformed packets and keep waiting for our reply
+			continue
+		}
+		switch resp.RCode {
+		case dnsmessage.RCodeSuccess:
+		case dnsmessage.RCodeNameError:
+			return false, nil
+		default:
+			return false, fmt.Errorf("SOA lookup for %s failed: %s", name, resp.RCode)
+		}
+		for _, answer := range resp.Answers {
+			if answer.Header.Type == dnsmessage.TypeSOA && strings.EqualFold(answer.Header.Name.String(), fqdn) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// systemNameserver returns the first nameserver of /etc/resolv.conf as host:port
+func systemNameserver() (string, error) {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "", fmt.Errorf("no resolver configured for SOA lookups: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+	return "", fmt.Errorf("no nameserver in /etc/resolv.conf; pass a resolver address for SOA lookups")
+}
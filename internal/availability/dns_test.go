@@ -0,0 +1,155 @@
+package availability
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers queries on a local UDP socket with the rcode and answers of answer
+func serveDNS(t *testing.T, answer func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource)) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			rcode, answers := answer(query.Questions[0])
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true, RCode: rcode},
+				Questions: query.Questions[:1],
+				Answers:   answers,
+			}
+			packed, _ := resp.Pack()
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// soaRecord is an SOA answer making name the apex of a zone
+func soaRecord(name dnsmessage.Name) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET},
+		Body: &dnsmessage.SOAResource{
+			NS:   dnsmessage.MustNewName("ns1.example."),
+			MBox: dnsmessage.MustNewName("hostmaster.example."),
+		},
+	}
+}
+
+// serveSOA answers SOA queries on a local UDP socket: zones are apexes, everything else is NXDOMAIN
+func serveSOA(t *testing.T, zones ...string) string {
+	return serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		for _, zone := range zones {
+			if q.Name.String() == zone {
+				return dnsmessage.RCodeSuccess, []dnsmessage.Resource{soaRecord(q.Name)}
+			}
+		}
+		return dnsmessage.RCodeNameError, nil
+	})
+}
+
+func TestDNSCheckerSOA(t *testing.T) {
+	checker := NewDNSChecker(serveSOA(t, "shoes.shop."), 2, 0)
+	checker.Record = DNSRecordSOA
+
+	results := make(map[string]Result)
+	err := checker.CheckNames(context.Background(), []string{"shoes.shop", "bags.shop"}, func(r Result) {
+		results[r.Name] = r
+	})
+	if err != nil {
+		t.Fatalf("CheckNames: %v", err)
+	}
+
+	if r := results["shoes.shop"]; r.Err != nil || !r.Registered {
+		t.Errorf("shoes.shop = %+v, want registered", r)
+	}
+	if r := results["bags.shop"]; r.Err != nil || r.Registered {
+		t.Errorf("bags.shop = %+v, want available", r)
+	}
+}
+
+func TestDNSCheckerRecords(t *testing.T) {
+	// shoes is delegated with its own zone, hats only has NS records and bags only an SOA
+	// broken fails on every server, and the other names don't exist
+	addr := serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		name := q.Name.String()
+		switch {
+		case strings.HasPrefix(name, "broken."):
+			return dnsmessage.RCodeServerFailure, nil
+		case q.Type == dnsmessage.TypeNS && (name == "shoes.shop." || name == "hats.shop."):
+			return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns1.example.")},
+			}}
+		case q.Type == dnsmessage.TypeSOA && (name == "shoes.shop." || name == "bags.shop."):
+			return dnsmessage.RCodeSuccess, []dnsmessage.Resource{soaRecord(q.Name)}
+		case name == "shoes.shop." || name == "hats.shop." || name == "bags.shop.":
+			// The name exists without records of the type
+			return dnsmessage.RCodeSuccess, nil
+		}
+		return dnsmessage.RCodeNameError, nil
+	})
+
+	names := []string{"shoes.shop", "hats.shop", "bags.shop", "free.shop", "broken.shop"}
+	tests := []struct {
+		record     string
+		registered string // Names found delegated, the others must be available except for broken
+	}{
+		{DNSRecordNS, "hats.shop,shoes.shop"},
+		{DNSRecordSOA, "bags.shop,shoes.shop"},
+		{DNSRecordBoth, "bags.shop,hats.shop,shoes.shop"},
+	}
+	for _, tt := range tests {
+		checker := NewDNSChecker(addr, 3, 0)
+		checker.Record = tt.record
+		checker.Timeout = 2 * time.Second
+
+		results := make(map[string]Result)
+		err := checker.CheckNames(context.Background(), names, func(r Result) {
+			results[r.Name] = r
+		})
+		if err != nil {
+			t.Fatalf("%s: CheckNames: %v", tt.record, err)
+		}
+		if len(results) != len(names) {
+			t.Errorf("%s: %d results for %d names", tt.record, len(results), len(names))
+		}
+		for _, name := range names {
+			r := results[name]
+			switch {
+			case name == "broken.shop":
+				if r.Err == nil {
+					t.Errorf("%s: broken.shop = %+v, want an error", tt.record, r)
+				}
+			case r.Err != nil:
+				t.Errorf("%s: %s: %v", tt.record, name, r.Err)
+			case r.Registered != strings.Contains(","+tt.registered+",", ","+name+","):
+				t.Errorf("%s: %s registered = %v", tt.record, name, r.Registered)
+			}
+		}
+	}
+
+	checker := NewDNSChecker(addr, 1, 0)
+	checker.Record = "mx"
+	if err := checker.CheckNames(context.Background(), names, func(Result) {}); err == nil {
+		t.Error("CheckNames with an unknown record type succeeded")
+	}
+}
@@ -0,0 +1,208 @@
+package availability
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// EPPBatchSize is the number of names sent in a single <check> command
+const EPPBatchSize = 10
+
+// EPPConfig holds the connection settings for an EPP session
+type EPPConfig struct {
+	Server   string // host:port, usually port 700
+	Username string
+	Password string
+	CertFile string // Optional client certificate
+	KeyFile  string
+	Rate     float64 // Maximum check commands per second (0 = unlimited)
+}
+
+// EPPChecker checks availability with <domain:check> commands over a single EPP session
+type EPPChecker struct {
+	config EPPConfig
+	conn   net.Conn
+	trID   int
+}
+
+// eppResponse holds the parts of an EPP response we care about
+type eppResponse struct {
+	Results []struct {
+		Code int    `xml:"code,attr"`
+		Msg  string `xml:"msg"`
+	} `xml:"response>result"`
+	Checks []struct {
+		Name struct {
+			Avail string `xml:"avail,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"name"`
+	} `xml:"response>resData>chkData>cd"`
+}
+
+// NewEPPChecker connects to the EPP server and logs in
+func NewEPPChecker(config EPPConfig) (*EPPChecker, error) {
+	host, _, err := net.SplitHostPort(config.Server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EPP server address %s: %w", config.Server, err)
+	}
+
+	tlsConfig := &tls.Config{ServerName: host}
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load EPP client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", config.Server, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to EPP server: %w", err)
+	}
+
+	c := &EPPChecker{config: config, conn: conn}
+
+	// Server sends a greeting on connect
+	if _, err := c.readFrame(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read EPP greeting: %w", err)
+	}
+
+	login := fmt.Sprintf(`<login><clID>%s</clID><pw>%s</pw><options><version>1.0</version><lang>en</lang></options>`+
+		`<svcs><objURI>urn:ietf:params:xml:ns:domain-1.0</objURI></svcs></login>`,
+		escapeXML(config.Username), escapeXML(config.Password))
+	if _, err := c.command(login); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("EPP login failed: %w", err)
+	}
+
+	return c, nil
+}
+
+// CheckNames sends batched <domain:check> commands and reports availability for every name
+func (c *EPPChecker) CheckNames(ctx context.Context, names []string, fn func(Result)) error {
+	limiter := newLimiter(ctx, c.config.Rate)
+
+	for i := 0; i < len(names); i += EPPBatchSize {
+		end := i + EPPBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		batch := names[i:end]
+
+		if err := wait(ctx, limiter); err != nil {
+			return err
+		}
+
+		var body bytes.Buffer
+		body.WriteString(`<check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+		for _, name := range batch {
+			body.WriteString("<domain:name>" + escapeXML(name) + "</domain:name>")
+		}
+		body.WriteString(`</domain:check></check>`)
+
+		resp, err := c.command(body.String())
+		if err != nil {
+			// Report the whole batch as unknown and keep going
+			for _, name := range batch {
+				fn(Result{Name: name, Err: err})
+			}
+			continue
+		}
+
+		seen := make(map[string]bool, len(batch))
+		for _, cd := range resp.Checks {
+			name := cd.Name.Value
+			seen[name] = true
+			fn(Result{Name: name, Registered: cd.Name.Avail == "0" || cd.Name.Avail == "false"})
+		}
+		for _, name := range batch {
+			if !seen[name] {
+				fn(Result{Name: name, Err: fmt.Errorf("no check data returned")})
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close logs out and closes the connection
+func (c *EPPChecker) Close() error {
+	c.command("<logout/>")
+	return c.conn.Close()
+}
+
+// command wraps body in an EPP command envelope, sends it and parses the response
+func (c *EPPChecker) command(body string) (*eppResponse, error) {
+	c.trID++
+	frame := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+
+		`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command>%s<clTRID>plm-%d</clTRID></command></epp>`,
+		body, c.trID)
+
+	if err := c.writeFrame([]byte(frame)); err != nil {
+		return nil, err
+	}
+
+	data, err := c.readFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp eppResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse EPP response: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("EPP response has no result")
+	}
+	// 1xxx codes indicate success
+	if code := resp.Results[0].Code; code < 1000 || code >= 2000 {
+		return nil, fmt.Errorf("EPP error %d: %s", code, resp.Results[0].Msg)
+	}
+
+	return &resp, nil
+}
+
+// writeFrame writes a length-prefixed EPP frame (RFC 5734)
+func (c *EPPChecker) writeFrame(data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(data)+4))
+	if _, err := c.conn.Write(append(header, data...)); err != nil {
+		return fmt.Errorf("failed to write EPP frame: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads a length-prefixed EPP frame (RFC 5734)
+func (c *EPPChecker) readFrame() ([]byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, fmt.Errorf("failed to read EPP frame header: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length < 4 || length > 16*1024*1024 {
+		return nil, fmt.Errorf("invalid EPP frame length: %d", length)
+	}
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(c.conn, data); err != nil {
+		return nil, fmt.Errorf("failed to read EPP frame: %w", err)
+	}
+	return data, nil
+}
+
+// escapeXML escapes a string for use as XML character data
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
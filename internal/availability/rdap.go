@@ -0,0 +1,147 @@
+package availability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RDAPBootstrapURL is the IANA bootstrap registry mapping TLDs to RDAP servers
+const RDAPBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+// RDAPChecker looks up domain objects over RDAP
+// A name is registered when the server returns it, and its registration event gives the creation date
+type RDAPChecker struct {
+	BaseURL     string // RDAP base URL of the TLD's registry, ending in "/"
+	Client      *http.Client
+	Concurrency int     // Number of parallel lookups
+	Rate        float64 // Maximum lookups per second (0 = unlimited)
+}
+
+// NewRDAPChecker creates an RDAP checker for the TLD
+// If baseURL is empty, the TLD's server is looked up in the IANA bootstrap registry
+func NewRDAPChecker(ctx context.Context, tld, baseURL string, concurrency int, rate float64) (*RDAPChecker, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	if baseURL == "" {
+		var err error
+		baseURL, err = lookupRDAPServer(ctx, client, tld)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	return &RDAPChecker{
+		BaseURL:     baseURL,
+		Client:      client,
+		Concurrency: concurrency,
+		Rate:        rate,
+	}, nil
+}
+
+// rdapBootstrap is the IANA DNS bootstrap file: services are [[tlds...], [urls...]] pairs
+type rdapBootstrap struct {
+	Services [][][]string `json:"services"`
+}
+
+// lookupRDAPServer finds the RDAP base URL of a TLD in the IANA bootstrap registry
+func lookupRDAPServer(ctx context.Context, client *http.Client, tld string) (string, error) {
+	tld = strings.Trim(strings.ToLower(tld), ".")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, RDAPBootstrapURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch RDAP bootstrap registry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch RDAP bootstrap registry: %s", resp.Status)
+	}
+
+	var bootstrap rdapBootstrap
+	if err := json.NewDecoder(resp.Body).Decode(&bootstrap); err != nil {
+		return "", fmt.Errorf("failed to parse RDAP bootstrap registry: %w", err)
+	}
+
+	for _, service := range bootstrap.Services {
+		if len(service) != 2 {
+			continue
+		}
+		for _, entry := range service[0] {
+			if strings.EqualFold(entry, tld) {
+				// Prefer https, the registry lists it first in practice but doesn't guarantee it
+				for _, url := range service[1] {
+					if strings.HasPrefix(url, "https://") {
+						return url, nil
+					}
+				}
+				if len(service[1]) > 0 {
+					return service[1][0], nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("no RDAP server for .%s in the IANA bootstrap registry (use --rdap-server)", tld)
+}
+
+// rdapDomain is the part of an RDAP domain object we use
+type rdapDomain struct {
+	Events []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+}
+
+// CheckNames looks up every name using a pool of workers
+func (c *RDAPChecker) CheckNames(ctx context.Context, names []string, fn func(Result)) error {
+	return checkConcurrently(ctx, names, c.Concurrency, c.Rate, c.checkName, fn)
+}
+
+// checkName performs a single RDAP domain lookup
+func (c *RDAPChecker) checkName(ctx context.Context, name string) Result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"domain/"+name, nil)
+	if err != nil {
+		return Result{Name: name, Err: err}
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Result{Name: name, Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		io.Copy(io.Discard, resp.Body)
+		return Result{Name: name}
+	default:
+		return Result{Name: name, Err: fmt.Errorf("RDAP server returned %s", resp.Status)}
+	}
+
+	var domain rdapDomain
+	if err := json.NewDecoder(resp.Body).Decode(&domain); err != nil {
+		return Result{Name: name, Err: fmt.Errorf("invalid RDAP response: %w", err)}
+	}
+
+	result := Result{Name: name, Registered: true}
+	for _, event := range domain.Events {
+		if event.Action == "registration" {
+			if created, err := time.Parse(time.RFC3339, event.Date); err == nil {
+				result.Created = created
+			}
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package availability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRDAPChecker(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domain/taken.shop":
+			w.Header().Set("Content-Type", "application/rdap+json")
+			w.Write([]byte(`{"objectClassName": "domain", "events": [
+				{"eventAction": "last changed", "eventDate": "2024-03-01T00:00:00Z"},
+				{"eventAction": "registration", "eventDate": "2021-06-15T10:20:30Z"}]}`))
+		case "/domain/free.shop":
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	checker, err := NewRDAPChecker(context.Background(), "shop", ts.URL, 2, 0)
+	if err != nil {
+		t.Fatalf("NewRDAPChecker: %v", err)
+	}
+
+	results := make(map[string]Result)
+	err = checker.CheckNames(context.Background(), []string{"taken.shop", "free.shop", "limited.shop"}, func(r Result) {
+		results[r.Name] = r
+	})
+	if err != nil {
+		t.Fatalf("CheckNames: %v", err)
+	}
+
+	if r := results["taken.shop"]; !r.Registered || r.Created.Year() != 2021 {
+		t.Errorf("taken.shop = %+v, want registered in 2021", r)
+	}
+	if r := results["free.shop"]; r.Registered || r.Err != nil {
+		t.Errorf("free.shop = %+v, want available", r)
+	}
+	if r := results["limited.shop"]; r.Err == nil {
+		t.Errorf("limited.shop = %+v, want error", r)
+	}
+}
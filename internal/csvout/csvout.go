@@ -0,0 +1,162 @@
+// Package csvout writes CSV quoted the way downstream parsers require: minimally like encoding/csv,
+// every field quoted, or not quoted at all, with configurable quote and escape characters
+package csvout
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Quoting modes
+const (
+	QuoteMinimal = "minimal" // Quote fields containing a separator, quote, line break or leading space, like encoding/csv
+	QuoteAll     = "all"     // Quote every field
+	QuoteNone    = "none"    // Never quote; fields that would need quotes are an error
+)
+
+// ErrNeedsQuoting is returned by a QuoteNone writer for a field that can't be written unquoted
+var ErrNeedsQuoting = errors.New("field needs quoting")
+
+// Quoting controls how fields are quoted; the zero value quotes like encoding/csv
+type Quoting struct {
+	Mode   string // QuoteMinimal (default), QuoteAll or QuoteNone
+	Quote  rune   // Quote character (default ")
+	Escape rune   // Written before a quote inside a quoted field (default: the quote, i.e. quotes are doubled)
+}
+
+// IsDefault reports whether q quotes like encoding/csv
+func (q Quoting) IsDefault() bool {
+	q = q.withDefaults()
+	return q.Mode == QuoteMinimal && q.Quote == '"' && q.Escape == '"'
+}
+
+// Validate checks the mode and that the quote and escape characters can't be mistaken for field content
+func (q Quoting) Validate() error {
+	q = q.withDefaults()
+	switch q.Mode {
+	case QuoteMinimal, QuoteAll, QuoteNone:
+	default:
+		return fmt.Errorf("invalid quoting %q (must be %s, %s or %s)", q.Mode, QuoteMinimal, QuoteAll, QuoteNone)
+	}
+	for _, r := range []rune{q.Quote, q.Escape} {
+		if r == ',' || r == '\r' || r == '\n' || unicode.IsSpace(r) || r == utf8.RuneError {
+			return fmt.Errorf("invalid quote or escape character %q", r)
+		}
+	}
+	return nil
+}
+
+func (q Quoting) withDefaults() Quoting {
+	if q.Mode == "" {
+		q.Mode = QuoteMinimal
+	}
+	if q.Quote == 0 {
+		q.Quote = '"'
+	}
+	if q.Escape == 0 {
+		q.Escape = q.Quote
+	}
+	return q
+}
+
+// Writer writes CSV records like encoding/csv.Writer, quoting fields as its Quoting says
+type Writer struct {
+	Comma   rune // Field separator (default ,)
+	UseCRLF bool // End lines with \r\n instead of \n
+
+	q   Quoting
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter returns a Writer writing to w
+func NewWriter(w io.Writer, q Quoting) *Writer {
+	return &Writer{Comma: ',', q: q.withDefaults(), w: bufio.NewWriter(w)}
+}
+
+// Write writes a single record; like encoding/csv, it is buffered until Flush
+func (w *Writer) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	for i, field := range record {
+		if i > 0 {
+			w.w.WriteRune(w.Comma)
+		}
+		quote := w.q.Mode == QuoteAll
+		if w.needsQuotes(field) {
+			if w.q.Mode == QuoteNone {
+				w.err = fmt.Errorf("%w: %q contains a separator, quote or line break and quoting is off", ErrNeedsQuoting, field)
+				return w.err
+			}
+			quote = true
+		}
+		if !quote {
+			w.w.WriteString(field)
+			continue
+		}
+
+		w.w.WriteRune(w.q.Quote)
+		for _, r := range field {
+			switch {
+			case r == w.q.Quote || (r == w.q.Escape && w.q.Escape != w.q.Quote):
+				w.w.WriteRune(w.q.Escape)
+				w.w.WriteRune(r)
+			case r == '\r':
+				if !w.UseCRLF {
+					w.w.WriteByte('\r')
+				}
+			case r == '\n':
+				if w.UseCRLF {
+					w.w.WriteString("\r\n")
+				} else {
+					w.w.WriteByte('\n')
+				}
+			default:
+				w.w.WriteRune(r)
+			}
+		}
+		w.w.WriteRune(w.q.Quote)
+	}
+	if w.UseCRLF {
+		_, w.err = w.w.WriteString("\r\n")
+	} else {
+		w.err = w.w.WriteByte('\n')
+	}
+	return w.err
+}
+
+// needsQuotes reports whether field must be quoted to read back as written, by the rules of encoding/csv
+func (w *Writer) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` {
+		return true
+	}
+	if strings.ContainsRune(field, w.Comma) || strings.ContainsRune(field, w.q.Quote) || strings.ContainsAny(field, "\r\n") {
+		return true
+	}
+	if w.q.Escape != w.q.Quote && strings.ContainsRune(field, w.q.Escape) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *Writer) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error reports any error of a previous Write or Flush
+func (w *Writer) Error() error {
+	return w.err
+}
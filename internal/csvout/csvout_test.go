@@ -0,0 +1,101 @@
+package csvout
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+)
+
+func write(t *testing.T, q Quoting, crlf bool, records ...[]string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf, q)
+	w.UseCRLF = crlf
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func TestWriter_MinimalMatchesEncodingCSV(t *testing.T) {
+	records := [][]string{
+		{"Label", "Tier", "price_reg"},
+		{"shoes", "1", "1.000,50"},
+		{`say "hi"`, "", " leading"},
+		{"two\nlines", "cr\r\nlf", `\.`},
+		{"café", "trailing ", "tab\t"},
+	}
+	for _, crlf := range []bool{false, true} {
+		var want bytes.Buffer
+		cw := csv.NewWriter(&want)
+		cw.UseCRLF = crlf
+		if err := cw.WriteAll(records); err != nil {
+			t.Fatal(err)
+		}
+		got, err := write(t, Quoting{}, crlf, records...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want.String() {
+			t.Errorf("crlf=%t: got %q, want %q", crlf, got, want.String())
+		}
+	}
+}
+
+func TestWriter_Quoting(t *testing.T) {
+	record := []string{"shoes", "", `a "b"`}
+	tests := []struct {
+		q    Quoting
+		want string
+	}{
+		{Quoting{Mode: QuoteAll}, `"shoes","","a ""b"""` + "\n"},
+		{Quoting{Mode: QuoteAll, Escape: '\\'}, `"shoes","","a \"b\""` + "\n"},
+		{Quoting{Mode: QuoteAll, Quote: '\''}, `'shoes','','a "b"'` + "\n"},
+		{Quoting{Quote: '\'', Escape: '\\'}, `shoes,,a "b"` + "\n"},
+		{Quoting{Mode: QuoteNone}, "shoes,\n"},
+	}
+	for _, tt := range tests {
+		r := record
+		if tt.q.Mode == QuoteNone {
+			r = record[:2]
+		}
+		got, err := write(t, tt.q, false, r)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.q, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.q, got, tt.want)
+		}
+	}
+
+	// A backslash is escaped itself when it escapes quotes
+	got, _ := write(t, Quoting{Escape: '\\'}, false, []string{`c:\tmp`})
+	if want := `"c:\\tmp"` + "\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := write(t, Quoting{Mode: QuoteNone}, false, record); !errors.Is(err, ErrNeedsQuoting) {
+		t.Errorf("err = %v, want ErrNeedsQuoting", err)
+	}
+}
+
+func TestQuoting_Validate(t *testing.T) {
+	for _, q := range []Quoting{{}, {Mode: QuoteAll, Quote: '\''}, {Mode: QuoteNone}} {
+		if err := q.Validate(); err != nil {
+			t.Errorf("%+v: %v", q, err)
+		}
+	}
+	for _, q := range []Quoting{{Mode: "some"}, {Quote: ','}, {Escape: ' '}} {
+		if err := q.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", q)
+		}
+	}
+	if !(Quoting{}).IsDefault() || (Quoting{Mode: QuoteAll}).IsDefault() {
+		t.Error("IsDefault is wrong")
+	}
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxParams is the number of parameters SQLite accepts per statement
+const maxParams = 999
+
+// placeholderLists caches the placeholder lists of bulk statements, which are rebuilt for every chunk otherwise
+var placeholderLists sync.Map
+
+type placeholderKey struct {
+	tuple string
+	n     int
+}
+
+// placeholders returns n copies of tuple separated by commas, e.g. "(?, ?),(?, ?)"
+func placeholders(tuple string, n int) string {
+	key := placeholderKey{tuple, n}
+	if list, ok := placeholderLists.Load(key); ok {
+		return list.(string)
+	}
+	list := strings.TrimSuffix(strings.Repeat(tuple+",", n), ",")
+	placeholderLists.Store(key, list)
+	return list
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Checkpoints let an interrupted import resume: every commit of an import records the last line of the file it
+// covers, under the hash of the file, and the commit of the last line deletes it again
+
+// ImportCheckpoint returns the last line an interrupted import of the file with this hash committed, 0 for none
+func (db *DB) ImportCheckpoint(fileHash string) (int, error) {
+	var line int
+	err := db.conn.QueryRow("SELECT line FROM import_checkpoints WHERE file_hash = ?", fileHash).Scan(&line)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read import checkpoint: %w", err)
+	}
+	return line, nil
+}
+
+// saveCheckpointTx records the last committed line of a file in import session importID
+func saveCheckpointTx(tx *Tx, fileHash, file string, line int, importID int64) error {
+	_, err := tx.Exec(`INSERT INTO import_checkpoints (file_hash, file, line, import_id, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (file_hash) DO UPDATE SET file = excluded.file, line = excluded.line,
+			import_id = excluded.import_id, updated_at = excluded.updated_at`,
+		fileHash, file, line, importArg(importID), formatTime(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to save import checkpoint: %w", err)
+	}
+	return nil
+}
+
+// deleteCheckpointTx forgets the checkpoint of a file
+func deleteCheckpointTx(tx *Tx, fileHash string) error {
+	if _, err := tx.Exec("DELETE FROM import_checkpoints WHERE file_hash = ?", fileHash); err != nil {
+		return fmt.Errorf("failed to delete import checkpoint: %w", err)
+	}
+	return nil
+}
@@ -4,14 +4,20 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"sync"
 
 	_ "modernc.org/sqlite"
-
 )
 
 // DB wraps the database connection
 type DB struct {
-	conn *sql.DB
+	conn *conn
+
+	// importMu makes the import transactions of this process take turns, see BeginImport
+	importMu sync.Mutex
+
+	// upgradedFrom is the schema version the database had before this open upgraded it, 0 if it didn't
+	upgradedFrom int
 }
 
 // LabelData represents a label to be inserted
@@ -26,23 +32,49 @@ type TagAssociation struct {
 	TagID   int64
 }
 
-// New creates a new database connection and initializes the schema
+// New creates a new database connection with the default tuning and initializes the schema
 func New(dbPath string) (*DB, error) {
-	conn, err := sql.Open("sqlite", dbPath)
+	return NewWithTuning(dbPath, tuningProfiles[TuningDefault])
+}
+
+// NewWithTuning creates a new database connection with the given SQLite settings and initializes the schema
+func NewWithTuning(dbPath string, tuning Tuning) (*DB, error) {
+	return Open(DriverSQLite, dbPath, tuning)
+}
+
+// Open opens the database of a driver and initializes the schema
+// For SQLite, dsn is the path of the database file and tuning its settings; for PostgreSQL, dsn is a connection
+// string, e.g. postgres://user@host/premium, and the tuning is left to the server
+func Open(driver, dsn string, tuning Tuning) (*DB, error) {
+	d, err := dialectOf(driver)
+	if err != nil {
+		return nil, err
+	}
+	if d.name == DriverSQLite {
+		if err := tuning.validate(); err != nil {
+			return nil, err
+		}
+		// The tuning goes into the DSN so that every pooled connection gets it
+		dsn = tuning.dsn(dsn)
+	}
+
+	pool, err := sql.Open(d.sqlDriver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	db := &DB{conn: conn}
+	db := &DB{conn: &conn{DB: pool, dialect: d}}
 
 	// Optimize SQLite for bulk inserts
-	if err := db.optimizeForBulkInsert(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to optimize database: %w", err)
+	if d.name == DriverSQLite {
+		if err := db.optimizeForBulkInsert(); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to optimize database: %w", err)
+		}
 	}
 
 	if err := db.initSchema(); err != nil {
-		conn.Close()
+		pool.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
@@ -52,12 +84,9 @@ func New(dbPath string) (*DB, error) {
 // optimizeForBulkInsert sets SQLite pragmas for better bulk insert performance
 func (db *DB) optimizeForBulkInsert() error {
 	pragmas := []string{
-		"PRAGMA journal_mode = WAL",    // Write-Ahead Logging for better concurrency
-		"PRAGMA synchronous = NORMAL",  // Faster than FULL, still safe
-		"PRAGMA cache_size = -64000",   // 64MB cache (negative = KB)
-		"PRAGMA temp_store = MEMORY",   // Store temp tables in memory
-		"PRAGMA mmap_size = 268435456", // 256MB memory-mapped I/O
-		"PRAGMA foreign_keys = ON",     // Keep foreign keys enabled
+		"PRAGMA journal_mode = WAL",  // Write-Ahead Logging for better concurrency
+		"PRAGMA temp_store = MEMORY", // Store temp tables in memory
+		"PRAGMA foreign_keys = ON",   // Keep foreign keys enabled
 	}
 
 	for _, pragma := range pragmas {
@@ -76,6 +105,12 @@ func (db *DB) Close() error {
 
 // initSchema creates the database tables if they don't exist
 func (db *DB) initSchema() error {
+	// Refuse a newer schema before changing anything in it
+	previous, err := db.checkSchemaVersion()
+	if err != nil {
+		return err
+	}
+
 	schema := `
 	CREATE TABLE IF NOT EXISTS labels (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -84,6 +119,7 @@ func (db *DB) initSchema() error {
 	);
 	
 	CREATE INDEX IF NOT EXISTS idx_labels_label ON labels(label);
+	CREATE INDEX IF NOT EXISTS idx_labels_length ON labels(length);
 
 	CREATE TABLE IF NOT EXISTS tags (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -98,31 +134,110 @@ func (db *DB) initSchema() error {
 		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
 	);
 
-	CREATE INDEX IF NOT EXISTS idx_label_tags_label_id ON label_tags(label_id);
-	CREATE INDEX IF NOT EXISTS idx_label_tags_tag_id ON label_tags(tag_id);
+	CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		status TEXT NOT NULL,
+		params TEXT NOT NULL DEFAULT '',
+		result TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		artifact TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL,
+		started_at TEXT NOT NULL DEFAULT '',
+		finished_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs(created_at);
+
+	CREATE TABLE IF NOT EXISTS price_overrides (
+		label_id INTEGER PRIMARY KEY,
+		currency TEXT NOT NULL,
+		price_reg REAL,
+		price_ren REAL,
+		price_res REAL,
+		source TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS sales (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		label TEXT NOT NULL,
+		price REAL NOT NULL,
+		currency TEXT NOT NULL,
+		sold_on TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		UNIQUE (label, price, currency, sold_on, source)
+	);
+
+	CREATE TABLE IF NOT EXISTS imports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		files TEXT NOT NULL DEFAULT '[]',
+		status TEXT NOT NULL,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL DEFAULT '',
+		undone_at TEXT NOT NULL DEFAULT '',
+		files_processed INTEGER NOT NULL DEFAULT 0,
+		files_skipped INTEGER NOT NULL DEFAULT 0,
+		labels_processed INTEGER NOT NULL DEFAULT 0,
+		new_labels INTEGER NOT NULL DEFAULT 0,
+		existing_labels INTEGER NOT NULL DEFAULT 0,
+		labels_skipped INTEGER NOT NULL DEFAULT 0,
+		error_count INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS import_errors (
+		import_id INTEGER NOT NULL,
+		seq INTEGER NOT NULL,
+		message TEXT NOT NULL,
+		PRIMARY KEY (import_id, seq),
+		FOREIGN KEY (import_id) REFERENCES imports(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS import_checkpoints (
+		file_hash TEXT PRIMARY KEY,
+		file TEXT NOT NULL,
+		line INTEGER NOT NULL,
+		import_id INTEGER,
+		updated_at TEXT NOT NULL
+	);
 	`
 
-	_, err := db.conn.Exec(schema)
-	return err
+	if _, err := db.conn.Exec(db.conn.dialect.ddl(schema)); err != nil {
+		return err
+	}
+	if err := db.addColumns(); err != nil {
+		return err
+	}
+
+	// Only the rowid layout of label_tags needs the single-column indexes
+	layout, err := db.LabelTagsLayout()
+	if err != nil {
+		return err
+	}
+	if layout == LayoutRowID {
+		_, err = db.conn.Exec(`
+	CREATE INDEX IF NOT EXISTS idx_label_tags_label_id ON label_tags(label_id);
+	CREATE INDEX IF NOT EXISTS idx_label_tags_tag_id ON label_tags(tag_id);
+	`)
+		if err != nil {
+			return err
+		}
+	} else if _, err := db.conn.Exec("CREATE INDEX IF NOT EXISTS idx_label_tags_tag_label ON label_tags(tag_id, label_id)"); err != nil {
+		return err
+	}
+	return db.recordSchemaVersion(previous)
 }
 
 // InsertLabel inserts a label into the database, returns the label ID
 func (db *DB) InsertLabel(label string, length int) (int64, error) {
-	result, err := db.conn.Exec(
-		"INSERT OR IGNORE INTO labels (label, length) VALUES (?, ?)",
+	var id int64
+	err := db.conn.QueryRow(
+		"INSERT INTO labels (label, length) VALUES (?, ?) ON CONFLICT DO NOTHING RETURNING id",
 		label, length,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("failed to insert label: %w", err)
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get last insert id: %w", err)
-	}
+	).Scan(&id)
 
-	// If ID is 0, label already exists, so fetch it
-	if id == 0 {
+	// If no row was inserted, label already exists, so fetch it
+	if err == sql.ErrNoRows {
 		err = db.conn.QueryRow(
 			"SELECT id FROM labels WHERE label = ?",
 			label,
@@ -130,6 +245,8 @@ func (db *DB) InsertLabel(label string, length int) (int64, error) {
 		if err != nil {
 			return 0, fmt.Errorf("failed to fetch existing label: %w", err)
 		}
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to insert label: %w", err)
 	}
 
 	return id, nil
@@ -146,17 +263,13 @@ func (db *DB) GetOrCreateTag(tagName string) (int64, error) {
 
 	if err == sql.ErrNoRows {
 		// Tag doesn't exist, create it
-		result, err := db.conn.Exec(
-			"INSERT INTO tags (name) VALUES (?)",
+		err = db.conn.QueryRow(
+			"INSERT INTO tags (name) VALUES (?) RETURNING id",
 			tagName,
-		)
+		).Scan(&tagID)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create tag: %w", err)
 		}
-		tagID, err = result.LastInsertId()
-		if err != nil {
-			return 0, fmt.Errorf("failed to get tag id: %w", err)
-		}
 		return tagID, nil
 	} else if err != nil {
 		return 0, fmt.Errorf("failed to query tag: %w", err)
@@ -167,7 +280,12 @@ func (db *DB) GetOrCreateTag(tagName string) (int64, error) {
 
 // GetOrCreateTagTx gets a tag ID, creating the tag if it doesn't exist
 // This version uses the provided transaction and should be called inside a transaction
-func GetOrCreateTagTx(tx *sql.Tx, tagName string) (int64, error) {
+func GetOrCreateTagTx(tx *Tx, tagName string) (int64, error) {
+	return getOrCreateTagTx(tx, tagName, 0)
+}
+
+// getOrCreateTagTx is GetOrCreateTagTx recording a created tag as created by import session importID
+func getOrCreateTagTx(tx *Tx, tagName string, importID int64) (int64, error) {
 	var tagID int64
 	err := tx.QueryRow(
 		"SELECT id FROM tags WHERE name = ?",
@@ -176,17 +294,13 @@ func GetOrCreateTagTx(tx *sql.Tx, tagName string) (int64, error) {
 
 	if err == sql.ErrNoRows {
 		// Tag doesn't exist, create it
-		result, err := tx.Exec(
-			"INSERT INTO tags (name) VALUES (?)",
-			tagName,
-		)
+		err = tx.QueryRow(
+			"INSERT INTO tags (name, import_id) VALUES (?, ?) RETURNING id",
+			tagName, importArg(importID),
+		).Scan(&tagID)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create tag: %w", err)
 		}
-		tagID, err = result.LastInsertId()
-		if err != nil {
-			return 0, fmt.Errorf("failed to get tag id: %w", err)
-		}
 		return tagID, nil
 	} else if err != nil {
 		return 0, fmt.Errorf("failed to query tag: %w", err)
@@ -198,7 +312,7 @@ func GetOrCreateTagTx(tx *sql.Tx, tagName string) (int64, error) {
 // AddTagToLabel adds a tag to a label
 func (db *DB) AddTagToLabel(labelID, tagID int64) error {
 	_, err := db.conn.Exec(
-		"INSERT OR IGNORE INTO label_tags (label_id, tag_id) VALUES (?, ?)",
+		"INSERT INTO label_tags (label_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
 		labelID, tagID,
 	)
 	if err != nil {
@@ -208,32 +322,51 @@ func (db *DB) AddTagToLabel(labelID, tagID int64) error {
 }
 
 // BeginTransaction starts a new transaction
-func (db *DB) BeginTransaction() (*sql.Tx, error) {
+func (db *DB) BeginTransaction() (*Tx, error) {
 	return db.conn.Begin()
 }
 
-// LoadAllLabelIDs loads all existing label IDs into a map for fast lookup
+// lookupLabelIDs returns the IDs of those labels that exist, looked up on the label index
 // Returns a map of label -> labelID
-func LoadAllLabelIDs(tx *sql.Tx) (map[string]int64, error) {
+func lookupLabelIDs(tx *Tx, labels []LabelData) (map[string]int64, error) {
 	labelMap := make(map[string]int64)
 
-	rows, err := tx.Query("SELECT id, label FROM labels")
-	if err != nil {
-		return nil, fmt.Errorf("failed to query labels: %w", err)
-	}
-	defer rows.Close()
+	// SQLite supports up to 999 parameters, so we may need to chunk
+	args := make([]interface{}, 0, maxParams)
+	query := func() error {
+		rows, err := tx.Query("SELECT id, label FROM labels WHERE label IN ("+placeholders("?", len(args))+")", args...)
+		if err != nil {
+			return fmt.Errorf("failed to query labels: %w", err)
+		}
+		defer rows.Close()
 
-	for rows.Next() {
-		var id int64
-		var label string
-		if err := rows.Scan(&id, &label); err != nil {
-			return nil, fmt.Errorf("failed to scan label: %w", err)
+		for rows.Next() {
+			var id int64
+			var label string
+			if err := rows.Scan(&id, &label); err != nil {
+				return fmt.Errorf("failed to scan label: %w", err)
+			}
+			labelMap[label] = id
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating labels: %w", err)
 		}
-		labelMap[label] = id
+		args = args[:0]
+		return nil
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating labels: %w", err)
+	for _, l := range labels {
+		args = append(args, l.Label)
+		if len(args) == maxParams {
+			if err := query(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) > 0 {
+		if err := query(); err != nil {
+			return nil, err
+		}
 	}
 
 	return labelMap, nil
@@ -241,7 +374,7 @@ func LoadAllLabelIDs(tx *sql.Tx) (map[string]int64, error) {
 
 // LoadAllTagIDs loads all existing tag IDs into a map for fast lookup
 // Returns a map of tag name -> tagID
-func LoadAllTagIDs(tx *sql.Tx) (map[string]int64, error) {
+func LoadAllTagIDs(tx *Tx) (map[string]int64, error) {
 	tagMap := make(map[string]int64)
 
 	rows, err := tx.Query("SELECT id, name FROM tags")
@@ -274,14 +407,24 @@ type BulkInsertResult struct {
 }
 
 // BulkInsertLabels inserts multiple labels efficiently
-// existingLabelMap should contain all existing label IDs (pre-loaded)
+// Existing labels are looked up on the label index, so memory use doesn't grow with the database
 // Separates new labels from existing ones and uses bulk INSERT for new labels only
 // Returns a map of label -> labelID and counts of new vs existing labels
-func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap map[string]int64) (*BulkInsertResult, error) {
+func (db *DB) BulkInsertLabels(tx *Tx, labels []LabelData) (*BulkInsertResult, error) {
+	return db.bulkInsertLabels(tx, labels, 0)
+}
+
+// bulkInsertLabels is BulkInsertLabels recording the new labels as created by import session importID
+func (db *DB) bulkInsertLabels(tx *Tx, labels []LabelData, importID int64) (*BulkInsertResult, error) {
 	if len(labels) == 0 {
 		return &BulkInsertResult{LabelMap: make(map[string]int64)}, nil
 	}
 
+	existingLabelMap, err := lookupLabelIDs(tx, labels)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &BulkInsertResult{
 		LabelMap: make(map[string]int64, len(labels)),
 	}
@@ -290,12 +433,12 @@ func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap
 	newLabels := make([]LabelData, 0, len(labels))
 
 	// Track labels seen in this batch to avoid duplicates within the insert
-	seenInBatch := make(map[string]bool)
+	seenInBatch := make(map[string]bool, len(labels))
 
 	for _, l := range labels {
 		// Check if it exists in DB
 		if id, exists := existingLabelMap[l.Label]; exists {
-			// Label already exists - use pre-loaded ID
+			// Label already exists - use its ID
 			result.LabelMap[l.Label] = id
 			result.ExistingCount++
 		} else {
@@ -322,10 +465,11 @@ func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap
 
 	// Build bulk INSERT with VALUES clause for new labels
 	// SQLite supports up to 999 parameters, so we may need to chunk
-	const maxParams = 999
-	const valuesPerRow = 2                            // label and length
-	const maxRowsPerInsert = maxParams / valuesPerRow // 499 rows per insert
+	const valuesPerRow = 3                            // label, length and import_id
+	const maxRowsPerInsert = maxParams / valuesPerRow // 333 rows per insert
 
+	// The arguments are reused across chunks
+	args := make([]interface{}, 0, maxRowsPerInsert*valuesPerRow)
 	for i := 0; i < len(newLabels); i += maxRowsPerInsert {
 		end := i + maxRowsPerInsert
 		if end > len(newLabels) {
@@ -333,45 +477,32 @@ func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap
 		}
 		chunk := newLabels[i:end]
 
-		// Build INSERT statement with VALUES clause
-		query := "INSERT INTO labels (label, length) VALUES "
-		args := make([]interface{}, 0, len(chunk)*2)
-
-		for j, l := range chunk {
-			if j > 0 {
-				query += ","
-			}
-			query += "(?, ?)"
-			args = append(args, l.Label, l.Length)
+		// RETURNING gives the IDs of the inserted rows; a label another connection inserted meanwhile is skipped
+		query := "INSERT INTO labels (label, length, import_id) VALUES " + placeholders("(?, ?, ?)", len(chunk)) +
+			" ON CONFLICT (label) DO NOTHING RETURNING id, label"
+		args = args[:0]
+		for _, l := range chunk {
+			args = append(args, l.Label, l.Length, importArg(importID))
 		}
 
-		// Use RETURNING id to get the exact IDs of inserted rows
-		query += " RETURNING id"
-
 		// Execute bulk insert
 		rows, err := tx.Query(query, args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to bulk insert labels: %w", err)
 		}
 
-		// Scan returned IDs
-		// The IDs are returned in the same order as the inserts
-		// We trust SQLite to maintain this order for the RETURNING clause on INSERT
-		idx := 0
+		// Scan returned IDs with their labels, as the order of RETURNING rows isn't guaranteed
+		inserted := 0
 		for rows.Next() {
-			if idx >= len(chunk) {
-				rows.Close()
-				return nil, fmt.Errorf("retrieved more IDs than inserted rows")
-			}
-
 			var id int64
-			if err := rows.Scan(&id); err != nil {
+			var label string
+			if err := rows.Scan(&id, &label); err != nil {
 				rows.Close()
 				return nil, fmt.Errorf("failed to scan returned id: %w", err)
 			}
 
-			result.LabelMap[chunk[idx].Label] = id
-			idx++
+			result.LabelMap[label] = id
+			inserted++
 		}
 		rows.Close()
 
@@ -379,8 +510,26 @@ func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap
 			return nil, fmt.Errorf("error iterating returned ids: %w", err)
 		}
 
-		if idx != len(chunk) {
-			return nil, fmt.Errorf("expected %d IDs, got %d", len(chunk), idx)
+		// Labels inserted by another connection since the lookup exist now
+		if inserted < len(chunk) {
+			skipped := make([]LabelData, 0, len(chunk)-inserted)
+			for _, l := range chunk {
+				if _, ok := result.LabelMap[l.Label]; !ok {
+					skipped = append(skipped, l)
+				}
+			}
+			existing, err := lookupLabelIDs(tx, skipped)
+			if err != nil {
+				return nil, err
+			}
+			if len(existing) != len(skipped) {
+				return nil, fmt.Errorf("expected %d IDs, got %d", len(skipped), len(existing))
+			}
+			for label, id := range existing {
+				result.LabelMap[label] = id
+			}
+			result.NewCount -= len(skipped)
+			result.ExistingCount += len(skipped)
 		}
 	}
 
@@ -388,18 +537,36 @@ func (db *DB) BulkInsertLabels(tx *sql.Tx, labels []LabelData, existingLabelMap
 }
 
 // BulkAddTagsToLabels adds multiple tag associations efficiently using bulk INSERT
-// Uses INSERT OR IGNORE to handle duplicates idempotently
+// Existing associations are left alone, so duplicates are handled idempotently
 // Foreign key constraints are validated automatically by SQLite
-func (db *DB) BulkAddTagsToLabels(tx *sql.Tx, associations []TagAssociation) error {
+func (db *DB) BulkAddTagsToLabels(tx *Tx, associations []TagAssociation) error {
+	return db.bulkAddTagsToLabels(tx, associations, 0)
+}
+
+// bulkAddTagsToLabels is BulkAddTagsToLabels recording new associations as created by import session importID
+// An association that exists already becomes shared if it belongs to another session, see labelTagsUpsert
+func (db *DB) bulkAddTagsToLabels(tx *Tx, associations []TagAssociation, importID int64) error {
 	if len(associations) == 0 {
 		return nil
 	}
 
+	// An upsert may not update a row twice, so repeated associations (e.g. of a label listed twice) are dropped
+	seen := make(map[TagAssociation]bool, len(associations))
+	unique := associations[:0:0]
+	for _, assoc := range associations {
+		if !seen[assoc] {
+			seen[assoc] = true
+			unique = append(unique, assoc)
+		}
+	}
+	associations = unique
+
 	// SQLite supports up to 999 parameters, so we may need to chunk
-	const maxParams = 999
-	const valuesPerRow = 2                            // label_id and tag_id
-	const maxRowsPerInsert = maxParams / valuesPerRow // 499 rows per insert
+	const valuesPerRow = 3                            // label_id, tag_id and import_id
+	const maxRowsPerInsert = maxParams / valuesPerRow // 333 rows per insert
 
+	// The arguments are reused across chunks
+	args := make([]interface{}, 0, maxRowsPerInsert*valuesPerRow)
 	for i := 0; i < len(associations); i += maxRowsPerInsert {
 		end := i + maxRowsPerInsert
 		if end > len(associations) {
@@ -407,16 +574,10 @@ func (db *DB) BulkAddTagsToLabels(tx *sql.Tx, associations []TagAssociation) err
 		}
 		chunk := associations[i:end]
 
-		// Build INSERT statement with VALUES clause
-		query := "INSERT OR IGNORE INTO label_tags (label_id, tag_id) VALUES "
-		args := make([]interface{}, 0, len(chunk)*2)
-
-		for j, assoc := range chunk {
-			if j > 0 {
-				query += ","
-			}
-			query += "(?, ?)"
-			args = append(args, assoc.LabelID, assoc.TagID)
+		query := "INSERT INTO label_tags (label_id, tag_id, import_id) VALUES " + placeholders("(?, ?, ?)", len(chunk)) + labelTagsUpsert
+		args = args[:0]
+		for _, assoc := range chunk {
+			args = append(args, assoc.LabelID, assoc.TagID, importArg(importID))
 		}
 
 		// Execute bulk insert
@@ -428,6 +589,78 @@ func (db *DB) BulkAddTagsToLabels(tx *sql.Tx, associations []TagAssociation) err
 	return nil
 }
 
+// TagLabels adds a tag to the given labels in a single transaction, creating missing labels
+// Returns the number of labels that didn't have the tag yet
+func (db *DB) TagLabels(labels []string, tagName string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tagID, err := GetOrCreateTagTx(tx, tagName)
+	if err != nil {
+		return 0, err
+	}
+
+	tagged := 0
+	for _, label := range labels {
+		if _, err := tx.Exec(
+			"INSERT INTO labels (label, length) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			label, len(label),
+		); err != nil {
+			return 0, fmt.Errorf("failed to insert label: %w", err)
+		}
+
+		result, err := tx.Exec(
+			"INSERT INTO label_tags (label_id, tag_id) SELECT id, CAST(? AS BIGINT) FROM labels WHERE label = ? ON CONFLICT DO NOTHING",
+			tagID, label,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add tag to label: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			tagged += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return tagged, nil
+}
+
+// UntagLabels removes a tag from the given labels in a single transaction
+// Returns the number of labels that had the tag
+func (db *DB) UntagLabels(labels []string, tagName string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	untagged := 0
+	for _, label := range labels {
+		result, err := tx.Exec(`
+			DELETE FROM label_tags
+			WHERE label_id = (SELECT id FROM labels WHERE label = ?)
+			AND tag_id = (SELECT id FROM tags WHERE name = ?)`, label, tagName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove tag from label: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			untagged += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return untagged, nil
+}
+
 // GetLabelID gets the ID of a label by its name
 func (db *DB) GetLabelID(label string) (int64, error) {
 	var id int64
@@ -447,7 +680,7 @@ func (db *DB) GetLabelID(label string) (int64, error) {
 // GetAllLabelsWithTags returns all labels with their associated tags
 func (db *DB) GetAllLabelsWithTags() (map[string][]string, error) {
 	query := `
-		SELECT l.label, COALESCE(GROUP_CONCAT(t.name), '') as tags
+		SELECT l.label, COALESCE(` + db.conn.dialect.tagList + `, '') as tags
 		FROM labels l
 		LEFT JOIN label_tags lt ON l.id = lt.label_id
 		LEFT JOIN tags t ON lt.tag_id = t.id
@@ -470,8 +703,7 @@ func (db *DB) GetAllLabelsWithTags() (map[string][]string, error) {
 
 		var tags []string
 		if tagsStr != "" {
-			// Split comma-separated tags
-			// SQLite GROUP_CONCAT uses comma by default
+			// Split the tag list, see tagListSeparator
 			for _, tag := range splitTags(tagsStr) {
 				if tag != "" {
 					tags = append(tags, tag)
@@ -488,12 +720,16 @@ func (db *DB) GetAllLabelsWithTags() (map[string][]string, error) {
 	return labels, nil
 }
 
-// splitTags splits a comma-separated string of tags
+// tagListSeparator separates the tag names the dialect's tagList aggregates for a label, char(31) in SQL
+// Tag names may contain commas, e.g. from a tags column or a sheet name, but not this control character
+const tagListSeparator = "\x1f"
+
+// splitTags splits a tag list built with tagListSeparator
 func splitTags(s string) []string {
 	if s == "" {
 		return nil
 	}
-	parts := strings.Split(s, ",")
+	parts := strings.Split(s, tagListSeparator)
 	tags := make([]string, 0, len(parts))
 	for _, part := range parts {
 		trimmed := strings.TrimSpace(part)
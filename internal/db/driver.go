@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Database drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// ErrUnsupportedDriver is returned by CheckDriver for drivers this build can't open databases with
+var ErrUnsupportedDriver = errors.New("unsupported database driver")
+
+// importLockKey is the PostgreSQL advisory lock import transactions take turns at, see BeginImport
+const importLockKey = 0x706c6d
+
+// dialect is the SQL of a database backend where the backends differ
+// Queries are written in SQLite's SQL with ? placeholders, which the connection numbers for backends that need it
+type dialect struct {
+	name      string // DriverSQLite or DriverPostgres
+	sqlDriver string // database/sql driver
+	numbered  bool   // Placeholders are $1, $2, ...
+
+	tagList    string            // Aggregate of the names t.name of a label's tags, separated by tagListSeparator
+	noLimit    string            // LIMIT of a query with an OFFSET only
+	tableCount string            // Counts the tables named ?
+	size       string            // Selects the size of the database in bytes
+	importLock string            // Run first by import transactions to take turns with other processes, "" for none
+	types      map[string]string // Translates the column types of the schema matched by schemaTypes, nil to keep them
+}
+
+// schemaTypes matches the SQLite column types and table options dialect.types translates
+var schemaTypes = regexp.MustCompile(`\bINTEGER PRIMARY KEY AUTOINCREMENT\b|\bINTEGER\b|\bREAL\b|\bTEXT\b|\) WITHOUT ROWID\b`)
+
+// dialects are the dialects by driver name
+var dialects = map[string]*dialect{
+	DriverSQLite: {
+		name:       DriverSQLite,
+		sqlDriver:  "sqlite",
+		tagList:    "GROUP_CONCAT(t.name, char(31))",
+		noLimit:    "-1",
+		tableCount: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?",
+		size:       "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()",
+	},
+	// Columns are compared bytewise like in SQLite, so labels sort the same on both
+	DriverPostgres: {
+		name:       DriverPostgres,
+		sqlDriver:  "pgx",
+		numbered:   true,
+		tagList:    "string_agg(t.name, chr(31))",
+		noLimit:    "ALL",
+		tableCount: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?",
+		size:       "SELECT pg_database_size(current_database())",
+		importLock: fmt.Sprintf("SELECT pg_advisory_xact_lock(%d)", importLockKey),
+		types: map[string]string{
+			"INTEGER PRIMARY KEY AUTOINCREMENT": "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+			"INTEGER":                           "BIGINT",
+			"REAL":                              "DOUBLE PRECISION",
+			"TEXT":                              `TEXT COLLATE "C"`,
+			") WITHOUT ROWID":                   ")",
+		},
+	},
+}
+
+// dialectOf returns the dialect of a driver, failing for drivers this build has no database/sql driver for
+func dialectOf(driver string) (*dialect, error) {
+	switch strings.ToLower(driver) {
+	case "", DriverSQLite:
+		return dialects[DriverSQLite], nil
+	case DriverPostgres, "postgresql", "pgx":
+		d := dialects[DriverPostgres]
+		if !slices.Contains(sql.Drivers(), d.sqlDriver) {
+			return nil, fmt.Errorf("%w: this build has no PostgreSQL driver; build it with -tags postgres (see the README)", ErrUnsupportedDriver)
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("%w %q (must be %s or %s)", ErrUnsupportedDriver, driver, DriverSQLite, DriverPostgres)
+	}
+}
+
+// CheckDriver reports whether this build can open databases with driver
+func CheckDriver(driver string) error {
+	_, err := dialectOf(driver)
+	return err
+}
+
+// rebind rewrites the ? and ?N placeholders of a query as $N for dialects that number them
+// Like in SQLite, a ? after a ?N is numbered after the largest N so far
+func (d *dialect) rebind(query string) string {
+	if !d.numbered || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			b.WriteByte('$')
+			if j > i+1 {
+				b.WriteString(query[i+1 : j])
+				if k, _ := strconv.Atoi(query[i+1 : j]); k > n {
+					n = k
+				}
+			} else {
+				n++
+				b.WriteString(strconv.Itoa(n))
+			}
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// ddl translates the column types of schema statements, as whole words outside string literals
+func (d *dialect) ddl(schema string) string {
+	if d.types == nil {
+		return schema
+	}
+	// The odd parts are inside quotes; a '' escape inside a literal gives an empty even part
+	parts := strings.Split(schema, "'")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = schemaTypes.ReplaceAllStringFunc(parts[i], func(t string) string { return d.types[t] })
+	}
+	return strings.Join(parts, "'")
+}
+
+// glob returns the condition that column matches a label pattern, whose * matches any run of characters and
+// ? a single one, and its argument
+func (d *dialect) glob(column, pattern string) (string, interface{}) {
+	if d.name == DriverPostgres {
+		like := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_").Replace(pattern)
+		return column + ` LIKE ? ESCAPE '\'`, like
+	}
+	return column + " GLOB ?", escapeGlob(pattern)
+}
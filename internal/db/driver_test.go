@@ -0,0 +1,115 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	pg := dialects[DriverPostgres]
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT id FROM labels", "SELECT id FROM labels"},
+		{"SELECT id FROM labels WHERE label = ? AND length > ?", "SELECT id FROM labels WHERE label = $1 AND length > $2"},
+		{untaggedByDelete, strings.ReplaceAll(untaggedByDelete, "?1", "$1")},
+		{"SELECT ?2, ?1, ?", "SELECT $2, $1, $3"},
+		{"SELECT '?' || ?", "SELECT '?' || $1"},
+		{"SELECT 'it''s ?', ?", "SELECT 'it''s ?', $1"},
+		{"INSERT INTO t VALUES (?, ?), (?, ?)", "INSERT INTO t VALUES ($1, $2), ($3, $4)"},
+	}
+	for _, tt := range tests {
+		if got := pg.rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	// SQLite takes the placeholders as they are
+	query := "SELECT ?2, ?1, ?"
+	if got := dialects[DriverSQLite].rebind(query); got != query {
+		t.Errorf("sqlite rebind(%q) = %q", query, got)
+	}
+}
+
+func TestDialect_Glob(t *testing.T) {
+	cond, arg := dialects[DriverSQLite].glob("l.label", "a[b*?")
+	if cond != "l.label GLOB ?" || arg != "a[[]b*?" {
+		t.Errorf("sqlite glob = %q, %q", cond, arg)
+	}
+
+	cond, arg = dialects[DriverPostgres].glob("l.label", `a*b?_%\`)
+	if cond != `l.label LIKE ? ESCAPE '\'` || arg != `a%b_\_\%\\` {
+		t.Errorf("postgres glob = %q, %q", cond, arg)
+	}
+
+	// SQLite evaluates LIKE ... ESCAPE like PostgreSQL for lowercase ASCII, so check what the patterns match
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tests := []struct {
+		pattern, label string
+		want           bool
+	}{
+		{"shoe*", "shoes", true},
+		{"shoe*", "shoe", true},
+		{"shoe*", "hats", false},
+		{"?at", "hat", true},
+		{"?at", "at", false},
+		{"*-*", "ab-cd", true},
+		{"a_b", "a_b", true},
+		{"a_b", "axb", false},
+		{"100%", "100x", false},
+		{"100%", "100%", true},
+		{`a\b`, `a\b`, true},
+	}
+	for _, tt := range tests {
+		cond, arg := dialects[DriverPostgres].glob("?", tt.pattern)
+		var n int
+		if err := conn.QueryRow("SELECT COUNT(*) WHERE "+cond, tt.label, arg).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if got := n == 1; got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestDialect_DDL(t *testing.T) {
+	schema := `CREATE TABLE IF NOT EXISTS things (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT UNIQUE NOT NULL,
+		context TEXT NOT NULL DEFAULT 'TEXT and INTEGER',
+		note TEXT NOT NULL DEFAULT 'it''s REAL',
+		TEXTURE INTEGER,
+		price REAL
+	) WITHOUT ROWID`
+	want := `CREATE TABLE IF NOT EXISTS things (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		name TEXT COLLATE "C" UNIQUE NOT NULL,
+		context TEXT COLLATE "C" NOT NULL DEFAULT 'TEXT and INTEGER',
+		note TEXT COLLATE "C" NOT NULL DEFAULT 'it''s REAL',
+		TEXTURE BIGINT,
+		price DOUBLE PRECISION
+	)`
+	if got := dialects[DriverPostgres].ddl(schema); got != want {
+		t.Errorf("postgres ddl =\n%s\nwant\n%s", got, want)
+	}
+	if got := dialects[DriverSQLite].ddl(schema); got != schema {
+		t.Errorf("sqlite ddl changed the schema:\n%s", got)
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	for _, driver := range []string{"", "sqlite", "SQLite"} {
+		d, err := dialectOf(driver)
+		if err != nil || d.name != DriverSQLite {
+			t.Errorf("dialectOf(%q) = %v, %v", driver, d, err)
+		}
+	}
+	if _, err := dialectOf("mysql"); err == nil {
+		t.Error("dialectOf(mysql) succeeded")
+	}
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Statuses of an import session
+const (
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportCanceled  = "canceled"
+	ImportUndone    = "undone"
+)
+
+// labelTagsUpsert makes an association that another import (or none) adds again shared,
+// so undoing the import that created it keeps it
+const labelTagsUpsert = ` ON CONFLICT (label_id, tag_id) DO UPDATE SET import_id = NULL
+		WHERE label_tags.import_id IS NOT NULL AND label_tags.import_id IS DISTINCT FROM excluded.import_id`
+
+// importColumns is the column list shared by import session queries
+const importColumns = `id, files, status, started_at, finished_at, undone_at, files_processed, files_skipped,
+	labels_processed, new_labels, existing_labels, labels_skipped, error_count`
+
+// ImportCounts are the outcome of an import session
+type ImportCounts struct {
+	FilesProcessed  int `json:"files_processed"`
+	FilesSkipped    int `json:"files_skipped"`
+	LabelsProcessed int `json:"labels_processed"`
+	NewLabels       int `json:"new_labels"`
+	ExistingLabels  int `json:"existing_labels"`
+	LabelsSkipped   int `json:"labels_skipped"`
+}
+
+// ImportSession is a recorded import run
+type ImportSession struct {
+	ID         int64      `json:"id"`
+	Files      []string   `json:"files"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	UndoneAt   *time.Time `json:"undone_at,omitempty"`
+	ImportCounts
+	ErrorCount int `json:"error_count"`
+}
+
+// Duration returns how long the import ran, 0 while it is running
+func (s *ImportSession) Duration() time.Duration {
+	if s.FinishedAt == nil {
+		return 0
+	}
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// UndoResult counts what UndoImport removed
+type UndoResult struct {
+	Associations int64 // Label-tag associations removed
+	Labels       int64 // Labels removed
+	Tags         int64 // Tags removed
+	LabelsKept   int64 // Labels created by the import that other imports, edits or price overrides still use
+	TagsKept     int64 // Tags created by the import that other labels still have
+	Checkpoints  int64 // Checkpoints of interrupted files forgotten, so their next import starts over
+}
+
+// importArg is the import_id value of rows created in import session id, NULL outside of one
+func importArg(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// StartImport records the start of an import session of files and returns its ID
+func (db *DB) StartImport(files []string, startedAt time.Time) (int64, error) {
+	data, err := json.Marshal(files)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = db.conn.QueryRow(
+		"INSERT INTO imports (files, status, started_at) VALUES (?, ?, ?) RETURNING id",
+		string(data), ImportRunning, formatTime(startedAt)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start import session: %w", err)
+	}
+	return id, nil
+}
+
+// FinishImport records the final status, counts and error messages of an import session
+func (db *DB) FinishImport(id int64, status string, counts ImportCounts, errs []string, finishedAt time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE imports SET status = ?, finished_at = ?, files_processed = ?, files_skipped = ?,
+		labels_processed = ?, new_labels = ?, existing_labels = ?, labels_skipped = ?, error_count = ? WHERE id = ?`,
+		status, formatTime(finishedAt), counts.FilesProcessed, counts.FilesSkipped,
+		counts.LabelsProcessed, counts.NewLabels, counts.ExistingLabels, counts.LabelsSkipped, len(errs), id)
+	if err != nil {
+		return fmt.Errorf("failed to finish import session: %w", err)
+	}
+
+	// SQLite supports up to 999 parameters, so we may need to chunk
+	const maxRowsPerInsert = maxParams / 3
+	args := make([]interface{}, 0, maxRowsPerInsert*3)
+	for i := 0; i < len(errs); i += maxRowsPerInsert {
+		chunk := errs[i:min(i+maxRowsPerInsert, len(errs))]
+		args = args[:0]
+		for j, msg := range chunk {
+			args = append(args, id, i+j+1, msg)
+		}
+		query := "INSERT INTO import_errors (import_id, seq, message) VALUES " + placeholders("(?, ?, ?)", len(chunk)) +
+			" ON CONFLICT (import_id, seq) DO UPDATE SET message = excluded.message"
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to record import errors: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetImport returns an import session by ID
+func (db *DB) GetImport(id int64) (*ImportSession, error) {
+	s, err := scanImport(db.conn.QueryRow("SELECT "+importColumns+" FROM imports WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import session: %w", err)
+	}
+	return s, nil
+}
+
+// ListImports returns the most recent import sessions, newest first
+func (db *DB) ListImports(limit int) ([]ImportSession, error) {
+	rows, err := db.conn.Query("SELECT "+importColumns+" FROM imports ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]ImportSession, 0)
+	for rows.Next() {
+		s, err := scanImport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan import session: %w", err)
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// ImportErrors returns the error messages recorded for an import session, in the order they occurred
+func (db *DB) ImportErrors(id int64) ([]string, error) {
+	rows, err := db.conn.Query("SELECT message FROM import_errors WHERE import_id = ? ORDER BY seq", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import errors: %w", err)
+	}
+	defer rows.Close()
+
+	errs := make([]string, 0)
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, fmt.Errorf("failed to scan import error: %w", err)
+		}
+		errs = append(errs, msg)
+	}
+	return errs, rows.Err()
+}
+
+// scanImport scans a row selected with importColumns
+func scanImport(row interface{ Scan(...interface{}) error }) (*ImportSession, error) {
+	var s ImportSession
+	var files, startedAt, finishedAt, undoneAt string
+	err := row.Scan(&s.ID, &files, &s.Status, &startedAt, &finishedAt, &undoneAt, &s.FilesProcessed, &s.FilesSkipped,
+		&s.LabelsProcessed, &s.NewLabels, &s.ExistingLabels, &s.LabelsSkipped, &s.ErrorCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(files), &s.Files); err != nil {
+		return nil, fmt.Errorf("failed to parse files of import session %d: %w", s.ID, err)
+	}
+	s.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+	s.FinishedAt = parseOptionalTime(finishedAt)
+	s.UndoneAt = parseOptionalTime(undoneAt)
+	return &s, nil
+}
+
+// UndoImport removes what import session id created and nothing else uses:
+// its label-tag associations, then its labels left without tags or price override and its tags left without labels
+// Labels and tags it created that are still in use are kept and no longer attributed to it
+func (db *DB) UndoImport(id int64, undoneAt time.Time) (*UndoResult, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result UndoResult
+	steps := []struct {
+		query string
+		count *int64
+	}{
+		{"DELETE FROM label_tags WHERE import_id = ?", &result.Associations},
+		{`DELETE FROM labels WHERE import_id = ?
+			AND NOT EXISTS (SELECT 1 FROM label_tags lt WHERE lt.label_id = labels.id)
+			AND NOT EXISTS (SELECT 1 FROM price_overrides po WHERE po.label_id = labels.id)`, &result.Labels},
+		{"UPDATE labels SET import_id = NULL WHERE import_id = ?", &result.LabelsKept},
+		{`DELETE FROM tags WHERE import_id = ?
+			AND NOT EXISTS (SELECT 1 FROM label_tags lt WHERE lt.tag_id = tags.id)`, &result.Tags},
+		{"UPDATE tags SET import_id = NULL WHERE import_id = ?", &result.TagsKept},
+	}
+	for _, step := range steps {
+		res, err := tx.Exec(step.query, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to undo import session %d: %w", id, err)
+		}
+		if *step.count, err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resuming any interrupted file could now skip lines whose labels are gone
+	res, err := tx.Exec("DELETE FROM import_checkpoints")
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete import checkpoints: %w", err)
+	}
+	if result.Checkpoints, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE imports SET status = ?, undone_at = ? WHERE id = ?", ImportUndone, formatTime(undoneAt), id); err != nil {
+		return nil, fmt.Errorf("failed to update import session: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &result, nil
+}
+
+// InImport returns the database as a Store whose imports record the labels, tags and associations
+// they create as created by import session id, so UndoImport can remove them
+func (db *DB) InImport(id int64) Store {
+	return importStore{DB: db, importID: id}
+}
+
+// importStore is a DB whose import transactions belong to an import session
+type importStore struct {
+	*DB
+	importID int64
+}
+
+func (s importStore) BeginImport() (ImportTx, error) {
+	return s.beginImport(s.importID)
+}
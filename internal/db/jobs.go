@@ -0,0 +1,169 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"premium-list-maker/internal/models"
+)
+
+// timeFormat is a fixed-width RFC 3339 layout so stored timestamps sort correctly as text
+const timeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// jobColumns is the column list shared by job queries
+const jobColumns = "id, type, status, params, result, error, artifact, created_at, started_at, finished_at"
+
+// CreateJob inserts a new queued job
+func (db *DB) CreateJob(job *models.Job) error {
+	_, err := db.conn.Exec(
+		"INSERT INTO jobs (id, type, status, params, created_at) VALUES (?, ?, ?, ?, ?)",
+		job.ID, job.Type, job.Status, string(job.Params), formatTime(job.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// StartJob marks a queued job as running
+// It returns false if the job is no longer queued (e.g. it was canceled)
+func (db *DB) StartJob(id string, startedAt time.Time) (bool, error) {
+	res, err := db.conn.Exec(
+		"UPDATE jobs SET status = ?, started_at = ? WHERE id = ? AND status = ?",
+		models.JobRunning, formatTime(startedAt), id, models.JobQueued)
+	if err != nil {
+		return false, fmt.Errorf("failed to start job: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// FinishJob records the final status, result and artifact of a job
+func (db *DB) FinishJob(id, status string, result []byte, errMsg, artifact string, finishedAt time.Time) error {
+	_, err := db.conn.Exec(
+		"UPDATE jobs SET status = ?, result = ?, error = ?, artifact = ?, finished_at = ? WHERE id = ?",
+		status, string(result), errMsg, artifact, formatTime(finishedAt), id)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// CancelQueuedJob cancels a job that hasn't started yet
+// It returns false if the job is not queued; ErrNotFound if it doesn't exist
+func (db *DB) CancelQueuedJob(id string, finishedAt time.Time) (bool, error) {
+	res, err := db.conn.Exec(
+		"UPDATE jobs SET status = ?, finished_at = ? WHERE id = ? AND status = ?",
+		models.JobCanceled, formatTime(finishedAt), id, models.JobQueued)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return true, nil
+	}
+	if _, err := db.GetJob(id); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// FailUnfinishedJobs marks queued and running jobs as failed, e.g. after a server restart
+func (db *DB) FailUnfinishedJobs(reason string, finishedAt time.Time) (int, error) {
+	res, err := db.conn.Exec(
+		"UPDATE jobs SET status = ?, error = ?, finished_at = ? WHERE status IN (?, ?)",
+		models.JobFailed, reason, formatTime(finishedAt), models.JobQueued, models.JobRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update unfinished jobs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// DeleteFinishedJobs deletes the jobs that finished before the given time and returns their IDs
+func (db *DB) DeleteFinishedJobs(before time.Time) ([]string, error) {
+	rows, err := db.conn.Query(
+		"DELETE FROM jobs WHERE finished_at <> '' AND finished_at < ? AND status NOT IN (?, ?) RETURNING id",
+		formatTime(before), models.JobQueued, models.JobRunning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetJob returns a job by ID
+func (db *DB) GetJob(id string) (*models.Job, error) {
+	row := db.conn.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = ?", id)
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs returns the most recent jobs, newest first
+func (db *DB) ListJobs(limit int) ([]models.Job, error) {
+	rows, err := db.conn.Query("SELECT "+jobColumns+" FROM jobs ORDER BY created_at DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]models.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// scanJob scans a row selected with jobColumns
+func scanJob(row interface{ Scan(...interface{}) error }) (*models.Job, error) {
+	var job models.Job
+	var params, result, createdAt, startedAt, finishedAt string
+	if err := row.Scan(&job.ID, &job.Type, &job.Status, &params, &result, &job.Error, &job.Artifact, &createdAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	if params != "" {
+		job.Params = []byte(params)
+	}
+	if result != "" {
+		job.Result = []byte(result)
+	}
+	job.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	job.StartedAt = parseOptionalTime(startedAt)
+	job.FinishedAt = parseOptionalTime(finishedAt)
+	return &job, nil
+}
+
+// formatTime formats a timestamp for storage
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// parseOptionalTime parses a stored timestamp, returning nil if it is empty
+func parseOptionalTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the schema this build creates and understands
+// Bump it with every change to the tables, so older builds refuse databases they would misread:
+//
+//	1: labels, tags, label_tags, jobs, price_overrides and sales
+//	2: import sessions (imports, import_errors and the import_id columns)
+//	3: the metadata table
+//	4: import checkpoints
+const SchemaVersion = 4
+
+// ToolVersion is the version of the program using the database, recorded in the metadata
+// Programs set it before opening a database, e.g. from their build information
+var ToolVersion = "dev"
+
+// ErrNewerSchema is returned when opening a database whose schema is newer than this build understands
+var ErrNewerSchema = errors.New("database schema is newer than this version supports")
+
+// Metadata keys
+const (
+	metaSchemaVersion = "schema_version"
+	metaCreatedWith   = "created_with"
+	metaCreatedAt     = "created_at"
+	metaUpgradedWith  = "upgraded_with"
+	metaUpgradedAt    = "upgraded_at"
+)
+
+// Metadata describes a database and the versions of the tool that wrote it
+type Metadata struct {
+	SchemaVersion int
+	CreatedWith   string     // Tool version that created the database, empty for databases older than the metadata
+	CreatedAt     *time.Time // Nil for databases older than the metadata
+	UpgradedWith  string     // Tool version that last upgraded the schema, empty if it never was
+	UpgradedAt    *time.Time
+	UpgradedFrom  int // Schema version before this open upgraded it, 0 if it didn't
+}
+
+// checkSchemaVersion reads the schema version of the database before anything else touches the schema
+// It returns 0 for a new database and fails with ErrNewerSchema for one this build doesn't understand
+func (db *DB) checkSchemaVersion() (int, error) {
+	if _, err := db.conn.Exec(db.conn.dialect.ddl("CREATE TABLE IF NOT EXISTS metadata (key TEXT PRIMARY KEY, value TEXT NOT NULL)")); err != nil {
+		return 0, fmt.Errorf("failed to create metadata table: %w", err)
+	}
+	meta, err := db.readMetadata()
+	if err != nil {
+		return 0, err
+	}
+	if meta[metaSchemaVersion] == "" {
+		// Databases from before the metadata: version 2 if they have import sessions, 1 if they have labels at all
+		for _, t := range []struct {
+			table   string
+			version int
+		}{{"imports", 2}, {"labels", 1}} {
+			var n int
+			if err := db.conn.QueryRow(db.conn.dialect.tableCount, t.table).Scan(&n); err != nil {
+				return 0, fmt.Errorf("failed to read schema: %w", err)
+			}
+			if n > 0 {
+				return t.version, nil
+			}
+		}
+		return 0, nil
+	}
+
+	var version int
+	if _, err := fmt.Sscan(meta[metaSchemaVersion], &version); err != nil {
+		return 0, fmt.Errorf("invalid schema version %q in metadata", meta[metaSchemaVersion])
+	}
+	if version > SchemaVersion {
+		writtenWith := meta[metaUpgradedWith]
+		if writtenWith == "" {
+			writtenWith = meta[metaCreatedWith]
+		}
+		return 0, fmt.Errorf("%w: the database has schema version %d (written by version %s), this version (%s) supports up to %d; upgrade premium-list-maker to open it",
+			ErrNewerSchema, version, writtenWith, ToolVersion, SchemaVersion)
+	}
+	return version, nil
+}
+
+// recordSchemaVersion records the current schema version, and the tool version that created or upgraded the database
+func (db *DB) recordSchemaVersion(previous int) error {
+	if previous == SchemaVersion {
+		return nil
+	}
+	now := formatTime(time.Now())
+	values := map[string]string{metaSchemaVersion: fmt.Sprint(SchemaVersion)}
+	if previous == 0 {
+		values[metaCreatedWith] = ToolVersion
+		values[metaCreatedAt] = now
+	} else {
+		values[metaUpgradedWith] = ToolVersion
+		values[metaUpgradedAt] = now
+		db.upgradedFrom = previous
+	}
+	for key, value := range values {
+		if _, err := db.conn.Exec("INSERT INTO metadata (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value", key, value); err != nil {
+			return fmt.Errorf("failed to write metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// readMetadata returns all metadata values by key
+func (db *DB) readMetadata() (map[string]string, error) {
+	rows, err := db.conn.Query("SELECT key, value FROM metadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read metadata: %w", err)
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+// Metadata returns the schema version of the database and the versions of the tool that wrote it
+func (db *DB) Metadata() (*Metadata, error) {
+	meta, err := db.readMetadata()
+	if err != nil {
+		return nil, err
+	}
+	m := &Metadata{
+		CreatedWith:  meta[metaCreatedWith],
+		CreatedAt:    parseOptionalTime(meta[metaCreatedAt]),
+		UpgradedWith: meta[metaUpgradedWith],
+		UpgradedAt:   parseOptionalTime(meta[metaUpgradedAt]),
+		UpgradedFrom: db.upgradedFrom,
+	}
+	if _, err := fmt.Sscan(meta[metaSchemaVersion], &m.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("invalid schema version %q in metadata", meta[metaSchemaVersion])
+	}
+	return m, nil
+}
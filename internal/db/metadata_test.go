@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	exec := func(path, query string) {
+		t.Helper()
+		conn, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if _, err := conn.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new database records the schema and tool version that created it
+	path := filepath.Join(dir, "new.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := db.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.SchemaVersion != SchemaVersion || meta.CreatedWith != ToolVersion || meta.CreatedAt == nil || meta.UpgradedFrom != 0 {
+		t.Errorf("new database metadata = %+v", meta)
+	}
+	db.Close()
+
+	// A database written by a newer version is refused
+	exec(path, "UPDATE metadata SET value = '99' WHERE key = 'schema_version'")
+	if _, err := New(path); !errors.Is(err, ErrNewerSchema) || !strings.Contains(err.Error(), "schema version 99") {
+		t.Errorf("newer schema: err = %v, want ErrNewerSchema", err)
+	}
+
+	// A database from before the metadata and import sessions is upgraded
+	legacy := filepath.Join(dir, "legacy.db")
+	exec(legacy, "CREATE TABLE labels (id INTEGER PRIMARY KEY AUTOINCREMENT, label TEXT UNIQUE NOT NULL, length INTEGER NOT NULL)")
+	db, err = New(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	meta, err = db.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.SchemaVersion != SchemaVersion || meta.UpgradedFrom != 1 || meta.UpgradedWith != ToolVersion || meta.CreatedWith != "" {
+		t.Errorf("upgraded database metadata = %+v", meta)
+	}
+
+	// Imports write the added import_id columns of the upgraded database
+	tx, err := db.BeginImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	inserted, err := tx.InsertLabels([]LabelData{{Label: "shoes", Length: 5}})
+	if err != nil {
+		t.Fatalf("import into upgraded database: %v", err)
+	}
+	tagID, err := tx.GetOrCreateTag("len:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.StageLabels([]int64{inserted.LabelMap["shoes"]}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.TagStaged(tagID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layouts of the label_tags table
+const (
+	LayoutRowID        = "rowid"         // The original layout: a rowid table with an index per column
+	LayoutWithoutRowID = "without-rowid" // Clustered on (label_id, tag_id) with a covering (tag_id, label_id) index
+)
+
+// LabelTagsLayout returns the layout of the label_tags table
+// PostgreSQL tables have no rowid, so their label_tags has the (label_id, tag_id) key and (tag_id, label_id) index
+// of the without-rowid layout from the start
+func (db *DB) LabelTagsLayout() (string, error) {
+	if db.conn.dialect.name == DriverPostgres {
+		return LayoutWithoutRowID, nil
+	}
+	var sql string
+	if err := db.conn.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'label_tags'").Scan(&sql); err != nil {
+		return "", fmt.Errorf("failed to read label_tags schema: %w", err)
+	}
+	if strings.Contains(strings.ToUpper(sql), "WITHOUT ROWID") {
+		return LayoutWithoutRowID, nil
+	}
+	return LayoutRowID, nil
+}
+
+// MigrateLabelTagsWithoutRowID rebuilds label_tags as a WITHOUT ROWID table
+// The table is then its own (label_id, tag_id) index, and a (tag_id, label_id) index covers lookups by tag,
+// which drops the rowids and the two single-column indexes from the file
+// Returns false if the table already has that layout
+func (db *DB) MigrateLabelTagsWithoutRowID() (bool, error) {
+	layout, err := db.LabelTagsLayout()
+	if err != nil {
+		return false, err
+	}
+	if layout == LayoutWithoutRowID {
+		return false, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`CREATE TABLE label_tags_new (
+		label_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		import_id INTEGER,
+		PRIMARY KEY (label_id, tag_id),
+		FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE,
+		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+	) WITHOUT ROWID`,
+		"INSERT INTO label_tags_new (label_id, tag_id, import_id) SELECT label_id, tag_id, import_id FROM label_tags ORDER BY label_id, tag_id",
+		"DROP TABLE label_tags",
+		"ALTER TABLE label_tags_new RENAME TO label_tags",
+		"CREATE INDEX idx_label_tags_tag_label ON label_tags(tag_id, label_id)",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return false, fmt.Errorf("failed to migrate label_tags: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit migration: %w", err)
+	}
+	return true, nil
+}
+
+// addedColumns are columns added to existing tables over time, which addColumns adds to older databases
+var addedColumns = []struct{ table, column, decl string }{
+	{"labels", "import_id", "INTEGER"},
+	{"tags", "import_id", "INTEGER"},
+	{"label_tags", "import_id", "INTEGER"},
+	{"imports", "files_processed", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "files_skipped", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "labels_processed", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "new_labels", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "existing_labels", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "labels_skipped", "INTEGER NOT NULL DEFAULT 0"},
+	{"imports", "error_count", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// addColumns adds the columns of addedColumns that a database created by an older version lacks
+func (db *DB) addColumns() error {
+	for _, c := range addedColumns {
+		if db.conn.dialect.name == DriverPostgres {
+			stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", c.table, c.column, db.conn.dialect.ddl(c.decl))
+			if _, err := db.conn.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to add %s to %s: %w", c.column, c.table, err)
+			}
+			continue
+		}
+		var count int
+		if err := db.conn.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.table, c.column).Scan(&count); err != nil {
+			return fmt.Errorf("failed to read %s schema: %w", c.table, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.decl)); err != nil {
+			return fmt.Errorf("failed to add %s to %s: %w", c.column, c.table, err)
+		}
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file, returning the space freed by deletions and migrations to the file system
+func (db *DB) Vacuum() error {
+	if _, err := db.conn.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
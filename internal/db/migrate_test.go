@@ -0,0 +1,146 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrateLabelTagsWithoutRowID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { db.Close() }()
+
+	if layout, err := db.LabelTagsLayout(); err != nil || layout != LayoutRowID {
+		t.Fatalf("new database layout = %q, %v", layout, err)
+	}
+
+	// Associations of an import session and ones added outside of any
+	importID, err := db.StartImport([]string{"labels.csv"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.InImport(importID).BeginImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inserted, err := tx.InsertLabels([]LabelData{{Label: "shoes", Length: 5}, {Label: "hats", Length: 4}, {Label: "bags", Length: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fashion, err := tx.GetOrCreateTag("fashion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brand, err := tx.GetOrCreateTag("brand")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.AddTags([]TagAssociation{
+		{LabelID: inserted.LabelMap["shoes"], TagID: fashion},
+		{LabelID: inserted.LabelMap["shoes"], TagID: brand},
+		{LabelID: inserted.LabelMap["hats"], TagID: fashion},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddTagToLabel(inserted.LabelMap["bags"], brand); err != nil {
+		t.Fatal(err)
+	}
+	before := labelTagRows(t, db)
+	if before[labelTagKey{inserted.LabelMap["shoes"], fashion}] != importID || before[labelTagKey{inserted.LabelMap["bags"], brand}] != 0 {
+		t.Fatalf("label_tags before the migration = %v", before)
+	}
+
+	migrated, err := db.MigrateLabelTagsWithoutRowID()
+	if err != nil || !migrated {
+		t.Fatalf("MigrateLabelTagsWithoutRowID = %v, %v", migrated, err)
+	}
+	if layout, err := db.LabelTagsLayout(); err != nil || layout != LayoutWithoutRowID {
+		t.Errorf("migrated layout = %q, %v", layout, err)
+	}
+	after := labelTagRows(t, db)
+	if len(after) != len(before) || len(after) != 4 {
+		t.Errorf("associations = %v, want %v", after, before)
+	}
+	for key, importID := range before {
+		if got, ok := after[key]; !ok || got != importID {
+			t.Errorf("association %v: import_id = %v (present %v), want %v", key, got, ok, importID)
+		}
+	}
+
+	// The covering index replaces the single-column ones
+	indexes := map[string]bool{}
+	rows, err := db.conn.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'label_tags'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		indexes[name] = true
+	}
+	rows.Close()
+	if !indexes["idx_label_tags_tag_label"] || indexes["idx_label_tags_label_id"] || indexes["idx_label_tags_tag_id"] {
+		t.Errorf("label_tags indexes = %v", indexes)
+	}
+
+	if migrated, err := db.MigrateLabelTagsWithoutRowID(); err != nil || migrated {
+		t.Errorf("second MigrateLabelTagsWithoutRowID = %v, %v", migrated, err)
+	}
+
+	// The migrated database opens again, and the import can still be undone
+	db.Close()
+	db, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels["shoes"]) != 2 || len(labels["bags"]) != 1 {
+		t.Errorf("labels = %v", labels)
+	}
+	undo, err := db.UndoImport(importID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Associations != 3 {
+		t.Errorf("undo = %+v", undo)
+	}
+}
+
+// labelTagKey is a label_tags row without its import_id
+type labelTagKey struct{ labelID, tagID int64 }
+
+// labelTagRows returns the import_id of every label_tags row, 0 for none
+func labelTagRows(t *testing.T, db *DB) map[labelTagKey]int64 {
+	t.Helper()
+	rows, err := db.conn.Query("SELECT label_id, tag_id, COALESCE(import_id, 0) FROM label_tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	result := make(map[labelTagKey]int64)
+	for rows.Next() {
+		var key labelTagKey
+		var importID int64
+		if err := rows.Scan(&key.labelID, &key.tagID, &importID); err != nil {
+			t.Fatal(err)
+		}
+		result[key] = importID
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
@@ -0,0 +1,8 @@
+//go:build postgres
+
+package db
+
+// The PostgreSQL driver is only linked into builds with the postgres tag:
+//
+//	go build -tags postgres ./cmd/premium-list-maker
+import _ "github.com/jackc/pgx/v5/stdlib"
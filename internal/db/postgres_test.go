@@ -0,0 +1,216 @@
+//go:build postgres
+
+package db_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// openPostgres opens a database in a new schema of the PostgreSQL database of $PLM_TEST_POSTGRES_DSN,
+// dropped when the test ends, and skips the test without it
+func openPostgres(t *testing.T) *db.DB {
+	t.Helper()
+	dsn := os.Getenv("PLM_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("PLM_TEST_POSTGRES_DSN is not set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("plm_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+	tuning, _ := db.TuningProfile(db.TuningDefault)
+	database, err := db.Open(db.DriverPostgres, dsn, tuning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestPostgres_ImportListMergeGenerate(t *testing.T) {
+	database := openPostgres(t)
+
+	importID, err := database.StartImport([]string{"labels.csv"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := "label,tags\nshoes,fashion|brand\nhats,\"summer, 2024\"\nbags,fashion\nabxc,\nshoe,brand\n"
+	stats, err := importer.ImportCSVReader(database.InImport(importID), strings.NewReader(csv),
+		importer.WithTagsColumn(2),
+		importer.WithTagSeparator("|"),
+		importer.WithTag("catalog"),
+		importer.WithBatchSize(2),
+	)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if stats.NewLabels != 5 || len(stats.Errors) != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// Importing the labels again finds them all
+	stats, err = importer.ImportCSVReader(database, strings.NewReader(csv), importer.WithTagsColumn(2), importer.WithTagSeparator("|"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.NewLabels != 0 || stats.ExistingLabels != 5 {
+		t.Errorf("second import stats = %+v", stats)
+	}
+
+	labels, err := database.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := labels["hats"]; strings.Join(tags, ",") != "catalog,summer, 2024" {
+		t.Errorf("hats tags = %q", tags)
+	}
+
+	// Globs are translated to LIKE, where _ must only match itself
+	list, err := database.ListLabels(db.LabelFilter{Glob: "shoe*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labelNames(list); got != "shoe,shoes" {
+		t.Errorf("glob shoe* = %s", got)
+	}
+	list, err = database.ListLabels(db.LabelFilter{Glob: "a?_c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labelNames(list); got != "" {
+		t.Errorf("glob a?_c = %s", got)
+	}
+	list, err = database.ListLabels(db.LabelFilter{Where: tagexpr.MustParse("fash* AND NOT brand")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labelNames(list); got != "bags" {
+		t.Errorf("where = %s", got)
+	}
+	// An offset without a limit takes LIMIT ALL
+	list, err = database.ListLabels(db.LabelFilter{Offset: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labelNames(list); got != "shoe,shoes" {
+		t.Errorf("offset 3 = %s", got)
+	}
+
+	merged, err := database.MergeTags("fashion", []string{"brand"})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	// shoes has both tags, so only shoe gains fashion
+	if merged != 1 {
+		t.Errorf("merged = %d", merged)
+	}
+	deleted, err := database.DeleteTagAndUntaggedLabels("summer, 2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Errorf("deleted = %d, hats still has catalog", deleted)
+	}
+
+	price := 100.0
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+	path := filepath.Join(t.TempDir(), "premium.csv")
+	result, err := generator.GenerateFromTiers(database, tiers, path, generator.Options{Format: "default"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if result.Entries != 3 {
+		t.Errorf("entries = %d", result.Entries)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{"bags", "shoe", "shoes"} {
+		if !strings.Contains(string(out), label+",") {
+			t.Errorf("list lacks %s:\n%s", label, out)
+		}
+	}
+
+	undo, err := database.UndoImport(importID, time.Now())
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if undo.Labels+undo.LabelsKept != 5 {
+		t.Errorf("undo = %+v", undo)
+	}
+}
+
+func TestPostgres_ConcurrentImports(t *testing.T) {
+	database := openPostgres(t)
+
+	// The import transactions take turns at the advisory lock instead of failing on each other's labels
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var csv strings.Builder
+			for n := 0; n < 200; n++ {
+				fmt.Fprintf(&csv, "label%d\n", n)
+			}
+			_, errs[i] = importer.ImportCSVReader(database, strings.NewReader(csv.String()),
+				importer.WithTag(fmt.Sprintf("run%d", i)), importer.WithBatchSize(50))
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("import %d: %v", i, err)
+		}
+	}
+
+	labels, err := database.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 200 || len(labels["label7"]) != 4 {
+		t.Errorf("%d labels, label7 tags = %v", len(labels), labels["label7"])
+	}
+}
+
+func labelNames(labels []models.Label) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Label
+	}
+	return strings.Join(names, ",")
+}
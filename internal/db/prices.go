@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PriceOverride is a per-label price that takes precedence over the prices of the label's tier
+type PriceOverride struct {
+	Label    string
+	Currency string
+	PriceReg *float64
+	PriceRen *float64
+	PriceRes *float64
+	Source   string // Feed or partner the price came from
+}
+
+// SetPriceOverridesTx stores price overrides, replacing any existing override of the same labels
+// labelIDs maps every label in overrides to its ID
+func SetPriceOverridesTx(tx *Tx, overrides []PriceOverride, labelIDs map[string]int64) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO price_overrides (label_id, currency, price_reg, price_ren, price_res, source)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (label_id) DO UPDATE SET currency = excluded.currency, price_reg = excluded.price_reg,
+			price_ren = excluded.price_ren, price_res = excluded.price_res, source = excluded.source`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare price override insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, o := range overrides {
+		id, ok := labelIDs[o.Label]
+		if !ok {
+			return fmt.Errorf("label not found: %s", o.Label)
+		}
+		if _, err := stmt.Exec(id, o.Currency, o.PriceReg, o.PriceRen, o.PriceRes, o.Source); err != nil {
+			return fmt.Errorf("failed to store price override of %s: %w", o.Label, err)
+		}
+	}
+	return nil
+}
+
+// GetPriceOverrides returns all price overrides keyed by label
+func (db *DB) GetPriceOverrides() (map[string]PriceOverride, error) {
+	rows, err := db.conn.Query(`
+		SELECT l.label, p.currency, p.price_reg, p.price_ren, p.price_res, p.source
+		FROM price_overrides p
+		JOIN labels l ON l.id = p.label_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]PriceOverride)
+	for rows.Next() {
+		var o PriceOverride
+		var reg, ren, res sql.NullFloat64
+		if err := rows.Scan(&o.Label, &o.Currency, &reg, &ren, &res, &o.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan price override: %w", err)
+		}
+		o.PriceReg, o.PriceRen, o.PriceRes = nullFloat(reg), nullFloat(ren), nullFloat(res)
+		overrides[o.Label] = o
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// GetPriceOverride returns the price override of a label, ErrNotFound if it has none
+func (db *DB) GetPriceOverride(label string) (*PriceOverride, error) {
+	o := PriceOverride{Label: label}
+	var reg, ren, res sql.NullFloat64
+	err := db.conn.QueryRow(`
+		SELECT p.currency, p.price_reg, p.price_ren, p.price_res, p.source
+		FROM price_overrides p
+		JOIN labels l ON l.id = p.label_id
+		WHERE l.label = ?`, label).Scan(&o.Currency, &reg, &ren, &res, &o.Source)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price override: %w", err)
+	}
+	o.PriceReg, o.PriceRen, o.PriceRes = nullFloat(reg), nullFloat(ren), nullFloat(res)
+	return &o, nil
+}
+
+// DeletePriceOverridesTx removes the price overrides of a source and returns how many were removed
+func DeletePriceOverridesTx(tx *Tx, source string) (int, error) {
+	res, err := tx.Exec("DELETE FROM price_overrides WHERE source = ?", source)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete price overrides: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func nullFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
@@ -0,0 +1,597 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// ErrNotFound is returned when a label or tag doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// LabelFilter restricts which labels are returned by ListLabels and CountLabels
+type LabelFilter struct {
+	Tags      []string // Labels must carry all of these tags
+	MinLength int      // 0 = no minimum
+	MaxLength int      // 0 = no maximum
+	Prefix    string
+	Contains  string
+	Glob      string       // Whole-label pattern, * matching any run of characters and ? a single one
+	Where     tagexpr.Expr // Filter expression, e.g. brand AND NOT registered AND len <= 5
+	Limit     int          // 0 = no limit
+	Offset    int
+}
+
+// whereClause builds the WHERE clause and arguments for a label filter in dialect d
+func (f LabelFilter) whereClause(d *dialect) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if len(f.Tags) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(f.Tags)), ",")
+		conditions = append(conditions, fmt.Sprintf(`l.id IN (
+			SELECT lt.label_id FROM label_tags lt
+			JOIN tags t ON t.id = lt.tag_id
+			WHERE t.name IN (%s)
+			GROUP BY lt.label_id
+			HAVING COUNT(DISTINCT t.id) = ?)`, placeholders))
+		for _, tag := range f.Tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(f.Tags))
+	}
+	if f.MinLength > 0 {
+		conditions = append(conditions, "l.length >= ?")
+		args = append(args, f.MinLength)
+	}
+	if f.MaxLength > 0 {
+		conditions = append(conditions, "l.length <= ?")
+		args = append(args, f.MaxLength)
+	}
+	if f.Prefix != "" {
+		conditions = append(conditions, `l.label LIKE ? ESCAPE '\'`)
+		args = append(args, escapeLike(f.Prefix)+"%")
+	}
+	if f.Contains != "" {
+		conditions = append(conditions, `l.label LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(f.Contains)+"%")
+	}
+	if f.Glob != "" {
+		condition, arg := d.glob("l.label", f.Glob)
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if f.Where != nil {
+		condition, whereArgs, err := exprSQL(f.Where, d)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, whereArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// exprSQL translates a filter expression to an SQL condition on labels l in dialect d
+// It fails for expressions it has no translation for
+func exprSQL(e tagexpr.Expr, d *dialect) (string, []interface{}, error) {
+	switch e := e.(type) {
+	case tagexpr.And:
+		return binaryExprSQL(e.Left, "AND", e.Right, d)
+	case tagexpr.Or:
+		return binaryExprSQL(e.Left, "OR", e.Right, d)
+	case tagexpr.Not:
+		x, args, err := exprSQL(e.X, d)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + x, args, nil
+	case tagexpr.Length:
+		op := e.Op
+		if op == "!=" {
+			op = "<>"
+		}
+		return "l.length " + op + " ?", []interface{}{e.N}, nil
+	case tagexpr.Tag:
+		if e.IsWildcard() {
+			condition, arg := d.glob("t.name", e.Pattern)
+			return `EXISTS (SELECT 1 FROM label_tags lt JOIN tags t ON t.id = lt.tag_id
+				WHERE lt.label_id = l.id AND ` + condition + ")", []interface{}{arg}, nil
+		}
+		return `EXISTS (SELECT 1 FROM label_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.label_id = l.id AND t.name = ?)`, []interface{}{e.Pattern}, nil
+	default:
+		return "", nil, fmt.Errorf("filter expression %T can't be translated to SQL", e)
+	}
+}
+
+// binaryExprSQL joins the conditions of left and right with the SQL operator op
+func binaryExprSQL(left tagexpr.Expr, op string, right tagexpr.Expr, d *dialect) (string, []interface{}, error) {
+	leftSQL, args, err := exprSQL(left, d)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSQL, rightArgs, err := exprSQL(right, d)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + leftSQL + " " + op + " " + rightSQL + ")", append(args, rightArgs...), nil
+}
+
+// escapeGlob escapes the GLOB character class bracket, the only GLOB syntax filter expressions and label patterns don't have
+func escapeGlob(s string) string {
+	return strings.ReplaceAll(s, "[", "[[]")
+}
+
+// escapeLike escapes LIKE wildcards so the value matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ListLabels returns labels with their tags matching the filter, ordered by label
+func (db *DB) ListLabels(filter LabelFilter) ([]models.Label, error) {
+	labels := make([]models.Label, 0)
+	err := db.EachLabel(filter, func(l models.Label) error {
+		labels = append(labels, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// EachLabel calls fn for every label matching the filter with its tags, ordered by label, reading them from
+// a cursor so memory doesn't grow with the result; an error of fn stops the iteration and is returned
+// fn must not use the database, whose connection the cursor may hold
+func (db *DB) EachLabel(filter LabelFilter, fn func(models.Label) error) error {
+	where, args, err := filter.whereClause(db.conn.dialect)
+	if err != nil {
+		return err
+	}
+	query := `
+		SELECT l.id, l.label, l.length,
+			COALESCE((SELECT ` + db.conn.dialect.tagList + ` FROM label_tags lt JOIN tags t ON t.id = lt.tag_id WHERE lt.label_id = l.id), '')
+		FROM labels l` + where + " ORDER BY l.label"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	} else if filter.Offset > 0 {
+		query += " LIMIT " + db.conn.dialect.noLimit + " OFFSET ?"
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.Label
+		var tagsStr string
+		if err := rows.Scan(&l.ID, &l.Label, &l.Length, &tagsStr); err != nil {
+			return fmt.Errorf("failed to scan label: %w", err)
+		}
+		l.Tags = splitTags(tagsStr)
+		if l.Tags == nil {
+			l.Tags = []string{}
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating labels: %w", err)
+	}
+	return nil
+}
+
+// EachLabelWithTags calls fn for every label with its tag names in label order, reading them from a cursor
+// so memory doesn't grow with the database; an error of fn stops the iteration and is returned
+// fn must not use the database, whose connection the cursor may hold
+func (db *DB) EachLabelWithTags(fn func(label string, tags []string) error) error {
+	// The correlated subquery walks the label index in order instead of grouping the whole join first
+	rows, err := db.conn.Query(`
+		SELECT l.label,
+			COALESCE((SELECT ` + db.conn.dialect.tagList + ` FROM label_tags lt JOIN tags t ON t.id = lt.tag_id WHERE lt.label_id = l.id), '')
+		FROM labels l ORDER BY l.label`)
+	if err != nil {
+		return fmt.Errorf("failed to query labels: %w", err)
+	}
+	defer rows.Close()
+
+	var label, tagsStr string
+	for rows.Next() {
+		if err := rows.Scan(&label, &tagsStr); err != nil {
+			return fmt.Errorf("failed to scan label: %w", err)
+		}
+		if err := fn(label, splitTags(tagsStr)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating labels: %w", err)
+	}
+	return nil
+}
+
+// CountLabels returns the number of labels matching the filter (ignoring limit and offset)
+func (db *DB) CountLabels(filter LabelFilter) (int, error) {
+	where, args, err := filter.whereClause(db.conn.dialect)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM labels l"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count labels: %w", err)
+	}
+	return count, nil
+}
+
+// LabelsByLength returns the labels of minLength to maxLength characters, ordered by length and label
+// A bound of 0 means no bound; the range is read from the length index
+func (db *DB) LabelsByLength(minLength, maxLength int) ([]string, error) {
+	where, args, err := LabelFilter{MinLength: minLength, MaxLength: maxLength}.whereClause(db.conn.dialect)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query("SELECT l.label FROM labels l"+where+" ORDER BY l.length, l.label", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := make([]string, 0)
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating labels: %w", err)
+	}
+	return labels, nil
+}
+
+// CountLabelsByLength returns the number of labels of each length, counted on the length index
+func (db *DB) CountLabelsByLength() (map[int]int, error) {
+	rows, err := db.conn.Query("SELECT length, COUNT(*) FROM labels GROUP BY length")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count labels: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var length, count int
+		if err := rows.Scan(&length, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan label count: %w", err)
+		}
+		counts[length] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating label counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CountUntaggedLabels returns the number of labels without any tag
+func (db *DB) CountUntaggedLabels() (int, error) {
+	var count int
+	err := db.conn.QueryRow("SELECT COUNT(*) FROM labels l WHERE NOT EXISTS (SELECT 1 FROM label_tags lt WHERE lt.label_id = l.id)").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count untagged labels: %w", err)
+	}
+	return count, nil
+}
+
+// GetLabel returns a single label with its tags
+func (db *DB) GetLabel(label string) (*models.Label, error) {
+	var l models.Label
+	var tagsStr string
+	err := db.conn.QueryRow(`
+		SELECT l.id, l.label, l.length,
+			COALESCE((SELECT `+db.conn.dialect.tagList+` FROM label_tags lt JOIN tags t ON t.id = lt.tag_id WHERE lt.label_id = l.id), '')
+		FROM labels l WHERE l.label = ?`, label).Scan(&l.ID, &l.Label, &l.Length, &tagsStr)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query label: %w", err)
+	}
+
+	l.Tags = splitTags(tagsStr)
+	if l.Tags == nil {
+		l.Tags = []string{}
+	}
+	return &l, nil
+}
+
+// DeleteLabel deletes a label, its tag associations and its price override
+func (db *DB) DeleteLabel(label string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Delete associations explicitly since foreign_keys is a per-connection pragma
+	if _, err := tx.Exec("DELETE FROM label_tags WHERE label_id IN (SELECT id FROM labels WHERE label = ?)", label); err != nil {
+		return fmt.Errorf("failed to delete label tags: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM price_overrides WHERE label_id IN (SELECT id FROM labels WHERE label = ?)", label); err != nil {
+		return fmt.Errorf("failed to delete price override: %w", err)
+	}
+	result, err := tx.Exec("DELETE FROM labels WHERE label = ?", label)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// RemoveTagFromLabel removes a tag from a label
+func (db *DB) RemoveTagFromLabel(label, tagName string) error {
+	result, err := db.conn.Exec(`
+		DELETE FROM label_tags
+		WHERE label_id = (SELECT id FROM labels WHERE label = ?)
+		AND tag_id = (SELECT id FROM tags WHERE name = ?)`, label, tagName)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag from label: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListTags returns all tags with their label counts, ordered by name
+func (db *DB) ListTags() ([]models.TagInfo, error) {
+	rows, err := db.conn.Query(`
+		SELECT t.id, t.name, COUNT(lt.label_id)
+		FROM tags t
+		LEFT JOIN label_tags lt ON lt.tag_id = t.id
+		GROUP BY t.id, t.name
+		ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.TagInfo, 0)
+	for rows.Next() {
+		var t models.TagInfo
+		if err := rows.Scan(&t.ID, &t.Name, &t.LabelCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames a tag
+// If the target name is already taken, the tag's label associations are merged into that tag and the tag is deleted
+// It reports whether the tag was merged
+func (db *DB) RenameTag(oldName, newName string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	oldID, err := tagID(tx, oldName)
+	if err != nil {
+		return false, err
+	}
+	targetID, err := tagID(tx, newName)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	merged := err == nil && targetID != oldID
+	if merged {
+		if _, err := mergeTag(tx, oldID, targetID); err != nil {
+			return false, err
+		}
+	} else if _, err := tx.Exec("UPDATE tags SET name = ? WHERE id = ?", newName, oldID); err != nil {
+		return false, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return merged, tx.Commit()
+}
+
+// MergeTags moves the label associations of the source tags to the target tag and deletes the sources in one
+// transaction; the target is created if it doesn't exist
+// It returns the number of labels that gained the target tag, and ErrNotFound if a source doesn't exist
+func (db *DB) MergeTags(target string, sources []string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING", target); err != nil {
+		return 0, fmt.Errorf("failed to create tag: %w", err)
+	}
+	targetID, err := tagID(tx, target)
+	if err != nil {
+		return 0, err
+	}
+	gained := 0
+	merged := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if merged[source] {
+			continue
+		}
+		merged[source] = true
+		sourceID, err := tagID(tx, source)
+		if err != nil {
+			return 0, fmt.Errorf("tag %s: %w", source, err)
+		}
+		if sourceID == targetID {
+			continue
+		}
+		n, err := mergeTag(tx, sourceID, targetID)
+		if err != nil {
+			return 0, err
+		}
+		gained += n
+	}
+
+	return gained, tx.Commit()
+}
+
+// tagID returns the ID of a tag, ErrNotFound if it doesn't exist
+func tagID(tx *Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query tag %s: %w", name, err)
+	}
+	return id, nil
+}
+
+// mergeTag moves the label associations of tag sourceID to tag targetID and deletes the source tag
+// Associations keep their import session unless the label already had the target tag, see labelTagsUpsert
+// It returns the number of labels that gained the target tag
+func mergeTag(tx *Tx, sourceID, targetID int64) (int, error) {
+	before, err := countTagLabels(tx, targetID)
+	if err != nil {
+		return 0, err
+	}
+	// WHERE true keeps SQLite from reading ON CONFLICT as a join constraint
+	if _, err := tx.Exec(`
+		INSERT INTO label_tags (label_id, tag_id, import_id)
+		SELECT label_id, CAST(? AS BIGINT), import_id FROM label_tags WHERE tag_id = ? AND true`+labelTagsUpsert, targetID, sourceID); err != nil {
+		return 0, fmt.Errorf("failed to move tag associations: %w", err)
+	}
+	after, err := countTagLabels(tx, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM label_tags WHERE tag_id = ?", sourceID); err != nil {
+		return 0, fmt.Errorf("failed to delete tag associations: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", sourceID); err != nil {
+		return 0, fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return after - before, nil
+}
+
+// countTagLabels returns the number of labels carrying a tag
+func countTagLabels(tx *Tx, id int64) (int, error) {
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM label_tags WHERE tag_id = ?", id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tag associations: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteTag deletes a tag and all its label associations
+func (db *DB) DeleteTag(name string) error {
+	_, err := db.deleteTag(name, false)
+	return err
+}
+
+// DeleteTagAndUntaggedLabels deletes a tag like DeleteTag, and the labels left without any tag along with
+// their price overrides
+// It returns the number of labels deleted
+func (db *DB) DeleteTagAndUntaggedLabels(name string) (int, error) {
+	return db.deleteTag(name, true)
+}
+
+// untaggedByDelete selects the IDs of the labels whose only tag is tag_id ?
+const untaggedByDelete = `
+	SELECT lt.label_id FROM label_tags lt
+	WHERE lt.tag_id = ?1
+	AND NOT EXISTS (SELECT 1 FROM label_tags other WHERE other.label_id = lt.label_id AND other.tag_id != ?1)`
+
+func (db *DB) deleteTag(name string, pruneUntagged bool) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := tagID(tx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	if pruneUntagged {
+		if _, err := tx.Exec("DELETE FROM price_overrides WHERE label_id IN ("+untaggedByDelete+")", id); err != nil {
+			return 0, fmt.Errorf("failed to delete price overrides: %w", err)
+		}
+		result, err := tx.Exec("DELETE FROM labels WHERE id IN ("+untaggedByDelete+")", id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete untagged labels: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		pruned = int(n)
+	}
+
+	// Delete associations explicitly since foreign_keys is a per-connection pragma
+	if _, err := tx.Exec("DELETE FROM label_tags WHERE tag_id = ?", id); err != nil {
+		return 0, fmt.Errorf("failed to delete tag associations: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", id); err != nil {
+		return 0, fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return pruned, tx.Commit()
+}
+
+// CountTags returns the number of tags
+func (db *DB) CountTags() (int, error) {
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM tags").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tags: %w", err)
+	}
+	return count, nil
+}
+
+// Size returns the size of the database in bytes
+func (db *DB) Size() (int64, error) {
+	var size int64
+	if err := db.conn.QueryRow(db.conn.dialect.size).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get database size: %w", err)
+	}
+	return size, nil
+}
+
+// Ping checks that the database is reachable and its schema is readable
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	var n int
+	if err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM (SELECT 1 FROM labels LIMIT 1) AS probe").Scan(&n); err != nil {
+		return fmt.Errorf("failed to query database: %w", err)
+	}
+	return nil
+}
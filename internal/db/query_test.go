@@ -0,0 +1,241 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// newTestDB opens a new database in the test's temp dir, closed when the test ends
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tagLabels adds a tag to labels, creating them, like an import with the tag
+func tagLabels(t *testing.T, db *DB, tag string, labels ...string) {
+	t.Helper()
+	if _, err := db.TagLabels(labels, tag); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// labelNames returns the names of labels joined by commas
+func labelNames(labels []models.Label) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Label
+	}
+	return strings.Join(names, ",")
+}
+
+func TestRenameTag_Merge(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "Geo-list", "shoes", "bags")
+	tagLabels(t, db, "geo-list", "bags", "hats")
+
+	merged, err := db.RenameTag("Geo-list", "geo-list")
+	if err != nil || !merged {
+		t.Fatalf("RenameTag = %t, %v; want merged", merged, err)
+	}
+	labels, err := db.ListLabels(LabelFilter{Tags: []string{"geo-list"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := labelNames(labels); names != "bags,hats,shoes" {
+		t.Errorf("geo-list labels = %v, want bags,hats,shoes", names)
+	}
+	if n, err := db.CountTags(); err != nil || n != 1 {
+		t.Errorf("CountTags = %d, %v; want only geo-list left", n, err)
+	}
+
+	if merged, err := db.RenameTag("geo-list", "places"); err != nil || merged {
+		t.Errorf("RenameTag to a new name = %t, %v; want renamed", merged, err)
+	}
+	if _, err := db.RenameTag("geo-list", "places"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RenameTag of a missing tag: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "premium1", "shoes", "bags")
+	tagLabels(t, db, "premium_v2", "bags", "hats")
+	tagLabels(t, db, "other", "socks")
+
+	if _, err := db.MergeTags("premium", []string{"premium1", "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("MergeTags with a missing source: err = %v, want ErrNotFound", err)
+	}
+	if n, err := db.CountTags(); err != nil || n != 3 {
+		t.Errorf("CountTags after a failed merge = %d, %v; want nothing changed", n, err)
+	}
+
+	gained, err := db.MergeTags("premium", []string{"premium1", "premium_v2", "premium1"})
+	if err != nil || gained != 3 {
+		t.Fatalf("MergeTags = %d, %v; want 3 labels", gained, err)
+	}
+	tags, err := db.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tag := range tags {
+		got = append(got, fmt.Sprintf("%s:%d", tag.Name, tag.LabelCount))
+	}
+	if strings.Join(got, ",") != "other:1,premium:3" {
+		t.Errorf("tags = %v, want other:1,premium:3", got)
+	}
+}
+
+func TestDeleteTagAndUntaggedLabels(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "stale", "shoes", "bags")
+	tagLabels(t, db, "fashion", "bags", "hats")
+
+	pruned, err := db.DeleteTagAndUntaggedLabels("stale")
+	if err != nil || pruned != 1 {
+		t.Fatalf("DeleteTagAndUntaggedLabels = %d, %v; want shoes deleted", pruned, err)
+	}
+	labels, err := db.ListLabels(LabelFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range labels {
+		got = append(got, l.Label+":"+strings.Join(l.Tags, ";"))
+	}
+	if strings.Join(got, ",") != "bags:fashion,hats:fashion" {
+		t.Errorf("labels = %v, want bags and hats tagged fashion", got)
+	}
+
+	if _, err := db.DeleteTagAndUntaggedLabels("stale"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleting a missing tag: err = %v, want ErrNotFound", err)
+	}
+	if err := db.DeleteTag("fashion"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.CountLabels(LabelFilter{}); err != nil || n != 2 {
+		t.Errorf("CountLabels after DeleteTag = %d, %v; want labels kept", n, err)
+	}
+	if n, err := db.CountUntaggedLabels(); err != nil || n != 2 {
+		t.Errorf("CountUntaggedLabels = %d, %v; want 2", n, err)
+	}
+}
+
+func TestEachLabelWithTags(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "fashion", "shoes", "bags", "hats")
+
+	var labels []string
+	err := db.EachLabelWithTags(func(label string, tags []string) error {
+		if len(tags) != 1 || tags[0] != "fashion" {
+			t.Errorf("%s tags = %v", label, tags)
+		}
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil || strings.Join(labels, ",") != "bags,hats,shoes" {
+		t.Errorf("labels = %v, %v; want bags,hats,shoes in order", labels, err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = db.EachLabelWithTags(func(string, []string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestListLabels_Where(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "fashion", "shoes", "hats", "bags")
+	tagLabels(t, db, "city:europe", "paris", "rome")
+	for _, label := range []string{"shoes", "hats", "bags", "paris", "rome"} {
+		tagLabels(t, db, fmt.Sprintf("len:%d", len(label)), label)
+	}
+	all, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The SQL translation selects exactly the labels the expression matches
+	for _, expr := range []string{
+		"fashion",
+		"fashion AND len <= 4",
+		"city:* OR len = 5",
+		"NOT fashion",
+		"!(city:* || fashion) || len != 4",
+		"len:? AND NOT fashion",
+		"city:eu[rope]",
+	} {
+		where := tagexpr.MustParse(expr)
+		labels, err := db.ListLabels(LabelFilter{Where: where})
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		var want []string
+		for label, tags := range all {
+			if where.Match(label, tags) {
+				want = append(want, label)
+			}
+		}
+		sort.Strings(want)
+		if got := labelNames(labels); got != strings.Join(want, ",") {
+			t.Errorf("%s: got %v, want %v", expr, got, want)
+		}
+	}
+}
+
+// unknownExpr is a filter expression exprSQL has no translation for
+type unknownExpr struct{}
+
+func (unknownExpr) Match(string, []string) bool { return true }
+func (unknownExpr) String() string              { return "unknown" }
+
+func TestListLabels_UnknownExpr(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "fashion", "shoes")
+
+	where := tagexpr.And{Left: tagexpr.MustParse("fashion"), Right: tagexpr.Not{X: unknownExpr{}}}
+	if _, err := db.ListLabels(LabelFilter{Where: where}); err == nil {
+		t.Error("ListLabels with an unknown expression succeeded")
+	}
+	if _, err := db.CountLabels(LabelFilter{Where: unknownExpr{}}); err == nil {
+		t.Error("CountLabels with an unknown expression succeeded")
+	}
+}
+
+func TestListLabels_Glob(t *testing.T) {
+	db := newTestDB(t)
+	tagLabels(t, db, "fashion", "crypto", "bitcrypto", "cryptos", "shoes")
+
+	for pattern, want := range map[string]string{
+		"*crypto*": "bitcrypto,crypto,cryptos",
+		"crypto?":  "cryptos",
+		"crypto":   "crypto",
+		"[c]rypto": "", // Brackets match literally
+		"*shoe":    "",
+	} {
+		labels, err := db.ListLabels(LabelFilter{Glob: pattern})
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		if got := labelNames(labels); got != want {
+			t.Errorf("%s: got %v, want %s", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sale is a historic aftermarket sale of a label, in any TLD
+// Sales are kept apart from the labels table since most sold names are not premium labels of ours
+type Sale struct {
+	Label    string
+	Price    float64
+	Currency string
+	SoldOn   time.Time
+	Source   string
+}
+
+// InsertSales stores sales in one transaction, skipping sales that were already imported
+// Returns the number of new sales
+func (db *DB) InsertSales(sales []Sale) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("INSERT INTO sales (label, price, currency, sold_on, source) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare sale insert: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, s := range sales {
+		result, err := stmt.Exec(s.Label, s.Price, s.Currency, s.SoldOn.Format("2006-01-02"), s.Source)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert sale of %s: %w", s.Label, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return inserted, nil
+}
+
+// GetSales returns the sales on or after since (all sales if since is zero), oldest first
+func (db *DB) GetSales(since time.Time) ([]Sale, error) {
+	from := ""
+	if !since.IsZero() {
+		from = since.Format("2006-01-02")
+	}
+	rows, err := db.conn.Query(
+		"SELECT label, price, currency, sold_on, source FROM sales WHERE sold_on >= ? ORDER BY sold_on, id", from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sales: %w", err)
+	}
+	defer rows.Close()
+
+	var sales []Sale
+	for rows.Next() {
+		var s Sale
+		var soldOn string
+		if err := rows.Scan(&s.Label, &s.Price, &s.Currency, &soldOn, &s.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan sale: %w", err)
+		}
+		if s.SoldOn, err = time.Parse("2006-01-02", soldOn); err != nil {
+			return nil, fmt.Errorf("invalid sale date %q: %w", soldOn, err)
+		}
+		sales = append(sales, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sales: %w", err)
+	}
+	return sales, nil
+}
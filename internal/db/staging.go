@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Set-based tagging for imports: the IDs of a batch are staged in a temporary table, and a tag shared by
+// many labels is then added with one INSERT ... SELECT instead of one row of parameters per association
+
+// StageLabelsTx replaces the labels staged on the connection of tx
+func StageLabelsTx(tx *Tx, labelIDs []int64) error {
+	// Temporary tables belong to the connection, so create it on first use by each connection
+	if _, err := tx.Exec(tx.dialect.ddl("CREATE TEMP TABLE IF NOT EXISTS import_batch (label_id INTEGER PRIMARY KEY)")); err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM import_batch"); err != nil {
+		return fmt.Errorf("failed to clear staging table: %w", err)
+	}
+
+	// SQLite supports up to 999 parameters, so we may need to chunk
+	args := make([]interface{}, 0, maxParams)
+	for i := 0; i < len(labelIDs); i += maxParams {
+		chunk := labelIDs[i:min(i+maxParams, len(labelIDs))]
+		args = args[:0]
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		query := "INSERT INTO import_batch (label_id) VALUES " + placeholders("(?)", len(chunk)) + " ON CONFLICT DO NOTHING"
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to stage labels: %w", err)
+		}
+	}
+	return nil
+}
+
+// TagStagedTx adds a tag to all labels staged on the connection of tx
+func TagStagedTx(tx *Tx, tagID int64) error {
+	return tagStagedTx(tx, tagID, 0)
+}
+
+// tagStagedTx is TagStagedTx recording new associations as created by import session importID
+func tagStagedTx(tx *Tx, tagID, importID int64) error {
+	// WHERE true tells the parser that ON CONFLICT belongs to the INSERT, not to a join
+	query := "INSERT INTO label_tags (label_id, tag_id, import_id) SELECT label_id, CAST(? AS BIGINT), CAST(? AS BIGINT) FROM import_batch WHERE true" + labelTagsUpsert
+	if _, err := tx.Exec(query, tagID, importArg(importID)); err != nil {
+		return fmt.Errorf("failed to tag staged labels: %w", err)
+	}
+	return nil
+}
+
+// TagStagedByLengthTx adds to each label staged on the connection of tx the tag of its length
+// Labels of lengths without a tag in tagIDs are left alone
+func TagStagedByLengthTx(tx *Tx, tagIDs map[int]int64) error {
+	return tagStagedByLengthTx(tx, tagIDs, 0)
+}
+
+// tagStagedByLengthTx is TagStagedByLengthTx recording new associations as created by import session importID
+func tagStagedByLengthTx(tx *Tx, tagIDs map[int]int64, importID int64) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	var cases strings.Builder
+	args := make([]interface{}, 0, len(tagIDs)*2+1)
+	args = append(args, importArg(importID))
+	for length, tagID := range tagIDs {
+		cases.WriteString(" WHEN ? THEN CAST(? AS BIGINT)")
+		args = append(args, length, tagID)
+	}
+	query := `INSERT INTO label_tags (label_id, tag_id, import_id)
+		SELECT label_id, tag_id, CAST(? AS BIGINT) FROM (
+			SELECT b.label_id, CASE l.length` + cases.String() + ` END AS tag_id
+			FROM import_batch b JOIN labels l ON l.id = b.label_id) AS staged
+		WHERE tag_id IS NOT NULL` + labelTagsUpsert
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to add length tags to staged labels: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Store is the label storage the importer and generator work on
+// DB implements it on SQLite and PostgreSQL; caches and test doubles can stand in for it
+type Store interface {
+	// BeginImport starts a write transaction for a bulk import
+	BeginImport() (ImportTx, error)
+	// GetAllLabelsWithTags returns every label with its tag names
+	GetAllLabelsWithTags() (map[string][]string, error)
+	// EachLabelWithTags calls fn for every label with its tag names in label order, without loading them all;
+	// an error of fn stops the iteration and is returned
+	EachLabelWithTags(fn func(label string, tags []string) error) error
+	// GetPriceOverrides returns the price overrides by label
+	GetPriceOverrides() (map[string]PriceOverride, error)
+	// ImportCheckpoint returns the last line an interrupted import of the file with this hash committed, 0 for none
+	ImportCheckpoint(fileHash string) (int, error)
+}
+
+// ImportTx is a write transaction of a bulk import
+type ImportTx interface {
+	// TagIDs returns the IDs of all tags by name
+	TagIDs() (map[string]int64, error)
+	// GetOrCreateTag returns the ID of a tag, creating it if needed
+	GetOrCreateTag(name string) (int64, error)
+	// InsertLabels inserts the missing labels and returns the IDs of all, see DB.BulkInsertLabels
+	InsertLabels(labels []LabelData) (*BulkInsertResult, error)
+	// AddTags associates tags with labels, ignoring existing associations
+	AddTags(associations []TagAssociation) error
+	// StageLabels replaces the staged labels, which TagStaged and TagStagedByLength tag in one statement
+	StageLabels(labelIDs []int64) error
+	// TagStaged adds a tag to all staged labels
+	TagStaged(tagID int64) error
+	// TagStagedByLength adds to each staged label the tag of its length, if tagIDs has one
+	TagStagedByLength(tagIDs map[int]int64) error
+	// SaveCheckpoint records that the lines of a file up to line are imported, when the transaction commits
+	SaveCheckpoint(fileHash, file string, line int) error
+	// DeleteCheckpoint forgets the checkpoint of a file, once it is imported completely
+	DeleteCheckpoint(fileHash string) error
+	// Savepoint runs fn in a savepoint and undoes its statements if it fails, keeping the transaction usable;
+	// PostgreSQL aborts the whole transaction at a failed statement otherwise
+	Savepoint(fn func() error) error
+	Commit() error
+	Rollback() error
+}
+
+// importBusyWait is how long BeginImport retries while other processes keep the database busy
+const importBusyWait = 5 * time.Minute
+
+// BeginImport starts a transaction for a bulk import
+// The import transactions of the process take turns in the order they begin, so that concurrent imports interleave
+// their commit intervals instead of starving each other; while other processes keep a SQLite database busy it
+// retries, and on PostgreSQL it waits for the import transactions of other processes at an advisory lock
+func (db *DB) BeginImport() (ImportTx, error) {
+	return db.beginImport(0)
+}
+
+// beginImport starts an import transaction whose created rows belong to import session importID
+func (db *DB) beginImport(importID int64) (ImportTx, error) {
+	db.importMu.Lock()
+	deadline := time.Now().Add(importBusyWait)
+	for {
+		tx, err := db.BeginTransaction()
+		if err == nil {
+			if err = db.lockImport(tx); err == nil {
+				return &sqlImportTx{db: db, tx: tx, importID: importID}, nil
+			}
+			tx.Rollback()
+		}
+		if !isBusy(err) || time.Now().After(deadline) {
+			db.importMu.Unlock()
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// lockImport takes the lock of the dialect that import transactions of all processes take turns at, if it has one
+// It is released when the transaction ends
+func (db *DB) lockImport(tx *Tx) error {
+	if db.conn.dialect.importLock == "" {
+		return nil
+	}
+	if _, err := tx.Exec(db.conn.dialect.importLock); err != nil {
+		return fmt.Errorf("failed to lock imports: %w", err)
+	}
+	return nil
+}
+
+// isBusy reports whether err is SQLite's database is locked error, which the busy timeout gave up on
+func isBusy(err error) bool {
+	var e *sqlite.Error
+	return errors.As(err, &e) && e.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// sqlImportTx is an ImportTx on a database transaction
+type sqlImportTx struct {
+	db       *DB
+	tx       *Tx
+	importID int64 // Import session the created rows belong to, 0 for none
+	ended    bool  // Commit or Rollback passed the turn on
+}
+
+// end passes the turn on to the next import transaction, once
+func (t *sqlImportTx) end() {
+	if !t.ended {
+		t.ended = true
+		t.db.importMu.Unlock()
+	}
+}
+
+func (t *sqlImportTx) TagIDs() (map[string]int64, error) {
+	return LoadAllTagIDs(t.tx)
+}
+
+func (t *sqlImportTx) GetOrCreateTag(name string) (int64, error) {
+	return getOrCreateTagTx(t.tx, name, t.importID)
+}
+
+func (t *sqlImportTx) InsertLabels(labels []LabelData) (*BulkInsertResult, error) {
+	return t.db.bulkInsertLabels(t.tx, labels, t.importID)
+}
+
+func (t *sqlImportTx) AddTags(associations []TagAssociation) error {
+	return t.db.bulkAddTagsToLabels(t.tx, associations, t.importID)
+}
+
+func (t *sqlImportTx) StageLabels(labelIDs []int64) error {
+	return StageLabelsTx(t.tx, labelIDs)
+}
+
+func (t *sqlImportTx) TagStaged(tagID int64) error {
+	return tagStagedTx(t.tx, tagID, t.importID)
+}
+
+func (t *sqlImportTx) TagStagedByLength(tagIDs map[int]int64) error {
+	return tagStagedByLengthTx(t.tx, tagIDs, t.importID)
+}
+
+func (t *sqlImportTx) SaveCheckpoint(fileHash, file string, line int) error {
+	return saveCheckpointTx(t.tx, fileHash, file, line, t.importID)
+}
+
+func (t *sqlImportTx) DeleteCheckpoint(fileHash string) error {
+	return deleteCheckpointTx(t.tx, fileHash)
+}
+
+func (t *sqlImportTx) Savepoint(fn func() error) error {
+	if _, err := t.tx.Exec("SAVEPOINT batch"); err != nil {
+		return fmt.Errorf("failed to start savepoint: %w", err)
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := t.tx.Exec("ROLLBACK TO SAVEPOINT batch"); rbErr != nil {
+			return fmt.Errorf("%w (and failed to roll back to the savepoint: %v)", err, rbErr)
+		}
+		t.tx.Exec("RELEASE SAVEPOINT batch")
+		return err
+	}
+	if _, err := t.tx.Exec("RELEASE SAVEPOINT batch"); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+func (t *sqlImportTx) Commit() error {
+	defer t.end()
+	return t.tx.Commit()
+}
+
+func (t *sqlImportTx) Rollback() error {
+	defer t.end()
+	return t.tx.Rollback()
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tuning profiles
+const (
+	TuningDefault  = "default"   // Balanced settings for imports and queries on a workstation
+	TuningBulkLoad = "bulk-load" // Large caches and no fsync, for loading big files on a machine with memory to spare
+	TuningSafe     = "safe"      // Small caches and full fsync, for small machines and databases that must survive power loss
+)
+
+// Tuning holds the SQLite settings applied when a database is opened
+type Tuning struct {
+	CacheSizeKB       int    // Page cache per connection in KB
+	MmapSizeMB        int    // Memory-mapped I/O in MB, 0 disables it
+	Synchronous       string // OFF, NORMAL or FULL
+	WALAutocheckpoint int    // Pages in the WAL before it is checkpointed, 0 disables automatic checkpoints
+}
+
+// tuningProfiles are the tunings of the profiles
+var tuningProfiles = map[string]Tuning{
+	TuningDefault:  {CacheSizeKB: 64000, MmapSizeMB: 256, Synchronous: "NORMAL", WALAutocheckpoint: 1000},
+	TuningBulkLoad: {CacheSizeKB: 1024000, MmapSizeMB: 1024, Synchronous: "OFF", WALAutocheckpoint: 100000},
+	TuningSafe:     {CacheSizeKB: 16000, MmapSizeMB: 0, Synchronous: "FULL", WALAutocheckpoint: 1000},
+}
+
+// TuningProfile returns the tuning of a profile
+func TuningProfile(name string) (Tuning, error) {
+	t, ok := tuningProfiles[name]
+	if !ok {
+		return Tuning{}, fmt.Errorf("unknown tuning profile %q (use %s, %s or %s)", name, TuningDefault, TuningBulkLoad, TuningSafe)
+	}
+	return t, nil
+}
+
+// validate checks the values of the tuning
+func (t Tuning) validate() error {
+	switch strings.ToUpper(t.Synchronous) {
+	case "OFF", "NORMAL", "FULL":
+	default:
+		return fmt.Errorf("invalid synchronous mode %q (use OFF, NORMAL or FULL)", t.Synchronous)
+	}
+	if t.CacheSizeKB <= 0 || t.MmapSizeMB < 0 || t.WALAutocheckpoint < 0 {
+		return fmt.Errorf("cache size must be positive and mmap size and WAL autocheckpoint not negative")
+	}
+	return nil
+}
+
+// busyTimeout is how long a statement waits for another connection to release the database before it fails as busy
+const busyTimeout = 5 * time.Second
+
+// dsn adds the tuning pragmas to the database path, which the driver runs on every new connection
+// Transactions take the write lock when they begin (all of them write), so that concurrent writers wait for each
+// other up to busyTimeout instead of failing when they upgrade a read snapshot another writer has changed
+func (t Tuning) dsn(dbPath string) string {
+	pragmas := []string{
+		fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
+		fmt.Sprintf("synchronous(%s)", strings.ToUpper(t.Synchronous)),
+		fmt.Sprintf("cache_size(-%d)", t.CacheSizeKB), // Negative = KB
+		fmt.Sprintf("mmap_size(%d)", int64(t.MmapSizeMB)*1024*1024),
+		fmt.Sprintf("wal_autocheckpoint(%d)", t.WALAutocheckpoint),
+	}
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	return dbPath + sep + "_txlock=immediate&_pragma=" + strings.Join(pragmas, "&_pragma=")
+}
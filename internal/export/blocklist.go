@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/generator"
+)
+
+// Blocklist formats
+const (
+	BlocklistZone = "zone" // $INCLUDE-able records under the TLD origin
+	BlocklistRPZ  = "rpz"  // Response policy zone answering NXDOMAIN for listed names
+)
+
+// Blocklist describes a blocklist export
+type Blocklist struct {
+	TLD         string
+	Format      string    // BlocklistZone or BlocklistRPZ
+	GeneratedAt time.Time // Used in the header comment and the RPZ serial
+	TTL         int
+	RPZOrigin   string // Origin of the RPZ zone, e.g. rpz.example.net
+	Nameserver  string // NS of the RPZ zone, default localhost.
+}
+
+// blockedName is a listed label with the reason it is blocked
+type blockedName struct {
+	label  string
+	reason string
+}
+
+// WriteBlocklist writes the premium and reserved labels as a blocklist, sorted by label
+// A label that is both premium and reserved is listed once, as reserved
+// In the zone format every name gets a TXT record with its reason (e.g. "premium tier 3" or "reserved founders"),
+// in the RPZ format a CNAME to the root, the NXDOMAIN policy
+// Returns the number of names listed
+func WriteBlocklist(path string, list Blocklist, entries []generator.PremiumListEntry, reserved []ReservedName) (int, error) {
+	if list.Format != BlocklistZone && list.Format != BlocklistRPZ {
+		return 0, fmt.Errorf("unknown blocklist format %q (use %s or %s)", list.Format, BlocklistZone, BlocklistRPZ)
+	}
+
+	names := make(map[string]string, len(entries)+len(reserved))
+	for _, e := range entries {
+		names[e.Label] = fmt.Sprintf("premium tier %d", e.Tier)
+	}
+	for _, r := range reserved {
+		names[r.Label] = "reserved " + r.Reason
+	}
+	blocked := make([]blockedName, 0, len(names))
+	for label, reason := range names {
+		blocked = append(blocked, blockedName{label, reason})
+	}
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].label < blocked[j].label })
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create blocklist: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	generated := list.GeneratedAt.UTC()
+	fmt.Fprintf(w, "; Premium and reserved names of .%s, blocked until pricing goes live\n", list.TLD)
+	fmt.Fprintf(w, "; Generated %s by premium-list-maker, %d name(s)\n", generated.Format(time.RFC3339), len(blocked))
+
+	switch list.Format {
+	case BlocklistZone:
+		for _, b := range blocked {
+			fmt.Fprintf(w, "%s\t%d\tIN\tTXT\t%s\n", b.label, list.TTL, quoteTXT(b.reason))
+		}
+	case BlocklistRPZ:
+		origin := strings.TrimSuffix(list.RPZOrigin, ".") + "."
+		ns := list.Nameserver
+		if ns == "" {
+			ns = "localhost."
+		}
+		// The serial is the generation time, so every export supersedes the previous one
+		serial := generated.Unix()
+		fmt.Fprintf(w, "$ORIGIN %s\n$TTL %d\n", origin, list.TTL)
+		fmt.Fprintf(w, "@\tIN\tSOA\t%s hostmaster.%s %d 3600 600 86400 %d\n", ns, origin, serial, list.TTL)
+		fmt.Fprintf(w, "@\tIN\tNS\t%s\n", ns)
+		for _, b := range blocked {
+			fmt.Fprintf(w, "; %s\n%s.%s\tCNAME\t.\n", b.reason, b.label, list.TLD)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write blocklist: %w", err)
+	}
+	return len(blocked), file.Close()
+}
+
+// quoteTXT quotes a TXT record string, escaping quotes and backslashes
+func quoteTXT(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
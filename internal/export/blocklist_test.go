@@ -0,0 +1,44 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/generator"
+)
+
+func TestWriteBlocklist(t *testing.T) {
+	dir := t.TempDir()
+	entries := []generator.PremiumListEntry{{Label: "shoes", Tier: 2}, {Label: "nic", Tier: 1}}
+	reserved := []ReservedName{{Label: "nic", Reason: "registry"}}
+	generated := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	zonePath := filepath.Join(dir, "blocked.zone")
+	n, err := WriteBlocklist(zonePath, Blocklist{TLD: "shop", Format: BlocklistZone, GeneratedAt: generated, TTL: 300}, entries, reserved)
+	if err != nil || n != 2 {
+		t.Fatalf("WriteBlocklist = %d, %v", n, err)
+	}
+	data, _ := os.ReadFile(zonePath)
+	if !strings.HasSuffix(string(data), "nic\t300\tIN\tTXT\t\"reserved registry\"\nshoes\t300\tIN\tTXT\t\"premium tier 2\"\n") {
+		t.Errorf("zone blocklist =\n%s", data)
+	}
+
+	rpzPath := filepath.Join(dir, "blocked.rpz")
+	list := Blocklist{TLD: "shop", Format: BlocklistRPZ, GeneratedAt: generated, TTL: 300, RPZOrigin: "rpz.shop"}
+	if _, err := WriteBlocklist(rpzPath, list, entries, reserved); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(rpzPath)
+	for _, want := range []string{"$ORIGIN rpz.shop.\n", "@\tIN\tSOA\tlocalhost. hostmaster.rpz.shop. 1709251200 ", "shoes.shop\tCNAME\t.\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("RPZ blocklist misses %q:\n%s", want, data)
+		}
+	}
+
+	if _, err := WriteBlocklist(rpzPath, Blocklist{Format: "hosts"}, entries, nil); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,165 @@
+// Package export writes the database in the fixed layouts expected by compliance and provisioning systems
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/webhook"
+)
+
+// EscrowVersion identifies the layout of escrow deposits, recorded in the manifest
+const EscrowVersion = "1"
+
+// EscrowPremiumColumns are the fixed columns of the premium names file
+var EscrowPremiumColumns = []string{"domain", "tier", "currency", "registration", "renewal", "restore"}
+
+// EscrowReservedColumns are the fixed columns of the reserved names file
+var EscrowReservedColumns = []string{"domain", "reason"}
+
+// ReservedName is a label withheld from registration, with the tag that reserves it
+type ReservedName struct {
+	Label  string
+	Reason string
+}
+
+// EscrowDeposit describes one deposit: a premium file, a reserved file and a manifest
+type EscrowDeposit struct {
+	TLD       string
+	Watermark time.Time // Point in time the deposit represents; its UTC date is used in the file names
+	Sequence  int       // Deposit sequence number, increases with every deposit
+	Revision  int       // Revision of a deposit resubmitted for the same sequence
+}
+
+// EscrowFile is a file of a deposit as listed in its manifest
+type EscrowFile struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"` // premium or reserved
+	Columns []string `json:"columns"`
+	Rows    int      `json:"rows"`
+	Size    int64    `json:"size_bytes"`
+	SHA256  string   `json:"sha256"`
+}
+
+// EscrowManifest is the JSON manifest written next to the deposit files
+type EscrowManifest struct {
+	Version   string       `json:"version"`
+	TLD       string       `json:"tld"`
+	Watermark time.Time    `json:"watermark"`
+	Sequence  int          `json:"sequence"`
+	Revision  int          `json:"revision"`
+	Files     []EscrowFile `json:"files"`
+}
+
+// FileName returns the name of a deposit file following the <tld>_<YYYY-MM-DD>_<type>_S<sequence>_R<revision>.<ext>
+// convention of registry data escrow deposits
+func (d EscrowDeposit) FileName(fileType, ext string) string {
+	return fmt.Sprintf("%s_%s_%s_S%d_R%d.%s", d.TLD, d.Watermark.UTC().Format("2006-01-02"), fileType, d.Sequence, d.Revision, ext)
+}
+
+// WriteEscrowDeposit writes the premium and reserved files and the manifest into dir
+// Rows are sorted by domain, so identical inputs produce identical files
+func WriteEscrowDeposit(dir string, deposit EscrowDeposit, entries []generator.PremiumListEntry, reserved []ReservedName) (*EscrowManifest, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	manifest := &EscrowManifest{
+		Version:   EscrowVersion,
+		TLD:       deposit.TLD,
+		Watermark: deposit.Watermark.UTC(),
+		Sequence:  deposit.Sequence,
+		Revision:  deposit.Revision,
+	}
+
+	sorted := make([]generator.PremiumListEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Label < sorted[j].Label })
+	premiumRows := make([][]string, len(sorted))
+	for i, entry := range sorted {
+		premiumRows[i] = []string{
+			entry.Label + "." + deposit.TLD,
+			strconv.Itoa(entry.Tier),
+			entry.Currency,
+			formatPrice(entry.PriceReg),
+			formatPrice(entry.PriceRen),
+			formatPrice(entry.PriceRes),
+		}
+	}
+
+	sortedReserved := make([]ReservedName, len(reserved))
+	copy(sortedReserved, reserved)
+	sort.Slice(sortedReserved, func(i, j int) bool { return sortedReserved[i].Label < sortedReserved[j].Label })
+	reservedRows := make([][]string, len(sortedReserved))
+	for i, r := range sortedReserved {
+		reservedRows[i] = []string{r.Label + "." + deposit.TLD, r.Reason}
+	}
+
+	for _, f := range []struct {
+		fileType string
+		columns  []string
+		rows     [][]string
+	}{
+		{"premium", EscrowPremiumColumns, premiumRows},
+		{"reserved", EscrowReservedColumns, reservedRows},
+	} {
+		file, err := writeDepositCSV(dir, deposit.FileName(f.fileType, "csv"), f.columns, f.rows)
+		if err != nil {
+			return nil, "", err
+		}
+		file.Type = f.fileType
+		manifest.Files = append(manifest.Files, *file)
+	}
+
+	manifestPath := filepath.Join(dir, deposit.FileName("manifest", "json"))
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return manifest, manifestPath, nil
+}
+
+// writeDepositCSV writes a deposit CSV with a header row and returns its manifest entry
+func writeDepositCSV(dir, name string, columns []string, rows [][]string) (*EscrowFile, error) {
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	writer := csv.NewWriter(file)
+	writer.Write(columns)
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	checksum, size, err := webhook.FileChecksum(path)
+	if err != nil {
+		return nil, err
+	}
+	return &EscrowFile{Name: name, Columns: columns, Rows: len(rows), Size: size, SHA256: checksum}, nil
+}
+
+// formatPrice formats an optional price with two decimals, empty if unset
+func formatPrice(price *float64) string {
+	if price == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*price, 'f', 2, 64)
+}
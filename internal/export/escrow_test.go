@@ -0,0 +1,41 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/generator"
+)
+
+func TestWriteEscrowDeposit(t *testing.T) {
+	dir := t.TempDir()
+	reg, ren := 100.0, 50.0
+	entries := []generator.PremiumListEntry{
+		{Label: "shoes", Tier: 2, PriceReg: &reg, PriceRen: &ren, Currency: "USD"},
+		{Label: "bags", Tier: 1, PriceReg: &reg, Currency: "USD"},
+	}
+	reserved := []ReservedName{{Label: "nic", Reason: "reserved"}}
+	deposit := EscrowDeposit{TLD: "shop", Watermark: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Sequence: 7, Revision: 1}
+
+	manifest, manifestPath, err := WriteEscrowDeposit(dir, deposit, entries, reserved)
+	if err != nil {
+		t.Fatalf("WriteEscrowDeposit: %v", err)
+	}
+	if filepath.Base(manifestPath) != "shop_2024-03-01_manifest_S7_R1.json" {
+		t.Errorf("manifest path = %s", manifestPath)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[0].Rows != 2 || manifest.Files[1].Rows != 1 {
+		t.Fatalf("files = %+v", manifest.Files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "shop_2024-03-01_premium_S7_R1.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "domain,tier,currency,registration,renewal,restore\nbags.shop,1,USD,100.00,,\nshoes.shop,2,USD,100.00,50.00,\n"
+	if string(data) != want {
+		t.Errorf("premium file =\n%s\nwant\n%s", data, want)
+	}
+}
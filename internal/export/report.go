@@ -0,0 +1,171 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"premium-list-maker/internal/csvout"
+	"premium-list-maker/internal/generator"
+)
+
+// DefaultPriceBands are the registration price band boundaries used when none are configured
+var DefaultPriceBands = []float64{100, 500, 1000, 5000, 10000}
+
+// InventoryReportColumns are the fixed columns of the inventory report
+var InventoryReportColumns = []string{"period", "tld", "tier", "price_band", "currency", "names", "registered", "available", "registration_total", "renewal_total"}
+
+// InventoryRow summarizes the premium names of one tier, price band and currency
+type InventoryRow struct {
+	Tier              int
+	PriceBand         string
+	Currency          string
+	Names             int
+	Registered        int
+	RegistrationTotal float64
+	RenewalTotal      float64
+}
+
+// Available returns the number of names in the row that are not registered
+func (r InventoryRow) Available() int {
+	return r.Names - r.Registered
+}
+
+// InventoryReport groups premium list entries by tier (highest first), registration price band and currency
+// registered (if not nil) reports whether a label is already registered
+// bands are ascending boundaries: with 100 and 500 the bands are <100, 100-500 and 500+
+func InventoryReport(entries []generator.PremiumListEntry, bands []float64, registered func(label string) bool) []InventoryRow {
+	type key struct {
+		tier     int
+		band     int
+		currency string
+	}
+	rows := make(map[key]*InventoryRow)
+	for _, entry := range entries {
+		band := priceBand(entry.PriceReg, bands)
+		k := key{entry.Tier, band, entry.Currency}
+		row, ok := rows[k]
+		if !ok {
+			row = &InventoryRow{Tier: entry.Tier, PriceBand: priceBandName(band, bands), Currency: entry.Currency}
+			rows[k] = row
+		}
+		row.Names++
+		if registered != nil && registered(entry.Label) {
+			row.Registered++
+		}
+		if entry.PriceReg != nil {
+			row.RegistrationTotal += *entry.PriceReg
+		}
+		if entry.PriceRen != nil {
+			row.RenewalTotal += *entry.PriceRen
+		}
+	}
+
+	keys := make([]key, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tier != keys[j].tier {
+			return keys[i].tier > keys[j].tier
+		}
+		if keys[i].band != keys[j].band {
+			return keys[i].band < keys[j].band
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	report := make([]InventoryRow, len(keys))
+	for i, k := range keys {
+		report[i] = *rows[k]
+	}
+	return report
+}
+
+// WriteInventoryReport writes the report as CSV, followed by a total row per currency
+func WriteInventoryReport(path, period, tld string, rows []InventoryRow, quoting csvout.Quoting) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	writer := csvout.NewWriter(file, quoting)
+	writer.Write(InventoryReportColumns)
+
+	totals := make(map[string]*InventoryRow)
+	for _, row := range rows {
+		writer.Write(inventoryRecord(period, tld, strconv.Itoa(row.Tier), row))
+		total, ok := totals[row.Currency]
+		if !ok {
+			total = &InventoryRow{PriceBand: "all", Currency: row.Currency}
+			totals[row.Currency] = total
+		}
+		total.Names += row.Names
+		total.Registered += row.Registered
+		total.RegistrationTotal += row.RegistrationTotal
+		total.RenewalTotal += row.RenewalTotal
+	}
+
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		writer.Write(inventoryRecord(period, tld, "total", *totals[currency]))
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// inventoryRecord formats a report row
+func inventoryRecord(period, tld, tier string, row InventoryRow) []string {
+	return []string{
+		period,
+		tld,
+		tier,
+		row.PriceBand,
+		row.Currency,
+		strconv.Itoa(row.Names),
+		strconv.Itoa(row.Registered),
+		strconv.Itoa(row.Available()),
+		strconv.FormatFloat(row.RegistrationTotal, 'f', 2, 64),
+		strconv.FormatFloat(row.RenewalTotal, 'f', 2, 64),
+	}
+}
+
+// priceBand returns the index of the band containing price: 0 below the first boundary, len(bands) above the last,
+// and -1 without a price
+func priceBand(price *float64, bands []float64) int {
+	if price == nil {
+		return -1
+	}
+	return sort.Search(len(bands), func(i int) bool { return *price < bands[i] })
+}
+
+// priceBandName names a band by its boundaries, e.g. <100, 100-500 or 10000+
+func priceBandName(band int, bands []float64) string {
+	switch {
+	case band < 0:
+		return "none"
+	case len(bands) == 0:
+		return "all"
+	case band == 0:
+		return "<" + formatBound(bands[0])
+	case band == len(bands):
+		return formatBound(bands[len(bands)-1]) + "+"
+	default:
+		return formatBound(bands[band-1]) + "-" + formatBound(bands[band])
+	}
+}
+
+// formatBound formats a band boundary without trailing zeros
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
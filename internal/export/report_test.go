@@ -0,0 +1,35 @@
+package export
+
+import (
+	"testing"
+
+	"premium-list-maker/internal/generator"
+)
+
+func TestInventoryReport(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	entries := []generator.PremiumListEntry{
+		{Label: "a", Tier: 1, PriceReg: price(50), Currency: "USD"},
+		{Label: "b", Tier: 1, PriceReg: price(150), PriceRen: price(20), Currency: "USD"},
+		{Label: "c", Tier: 1, PriceReg: price(499), PriceRen: price(20), Currency: "USD"},
+		{Label: "d", Tier: 3, PriceReg: price(20000), Currency: "USD"},
+		{Label: "e", Tier: 3, Currency: "USD"},
+	}
+	registered := func(label string) bool { return label == "c" }
+
+	rows := InventoryReport(entries, []float64{100, 500, 10000}, registered)
+	want := []InventoryRow{
+		{Tier: 3, PriceBand: "none", Currency: "USD", Names: 1},
+		{Tier: 3, PriceBand: "10000+", Currency: "USD", Names: 1, RegistrationTotal: 20000},
+		{Tier: 1, PriceBand: "<100", Currency: "USD", Names: 1, RegistrationTotal: 50},
+		{Tier: 1, PriceBand: "100-500", Currency: "USD", Names: 2, Registered: 1, RegistrationTotal: 649, RenewalTotal: 40},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderECB               = "ecb"
+	ProviderOpenExchangeRates = "openexchangerates"
+)
+
+// Default endpoints of the providers
+const (
+	ECBBaseURL               = "https://www.ecb.europa.eu/stats/eurofxref"
+	OpenExchangeRatesBaseURL = "https://openexchangerates.org/api"
+)
+
+// DefaultMaxAge is how long cached latest rates are used before they are fetched again
+const DefaultMaxAge = 6 * time.Hour
+
+// Rates are exchange rates against a base currency: Rates[c] units of c buy one unit of Base
+type Rates struct {
+	Provider  string             `json:"provider"`
+	Base      string             `json:"base"`
+	Date      string             `json:"date"` // Date the rates apply to, YYYY-MM-DD
+	FetchedAt time.Time          `json:"fetched_at"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// rate returns the rate of a currency, 1 for the base currency
+func (r *Rates) rate(currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if currency == strings.ToUpper(r.Base) {
+		return 1, nil
+	}
+	rate, ok := r.Rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no %s exchange rate for %s on %s", r.Provider, currency, r.Date)
+	}
+	return rate, nil
+}
+
+// Convert converts an amount between currencies, rounded to cents
+func (r *Rates) Convert(amount float64, from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+	fromRate, err := r.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(amount/fromRate*toRate*100) / 100, nil
+}
+
+// Options selects the provider and date of the rates
+type Options struct {
+	Provider string        // ProviderECB or ProviderOpenExchangeRates
+	AppID    string        // openexchangerates app ID
+	Date     string        // Pinned date, YYYY-MM-DD; empty for the latest rates
+	CacheDir string        // Directory for cached rates; empty disables caching
+	MaxAge   time.Duration // Age up to which cached latest rates are used (default DefaultMaxAge)
+	BaseURL  string        // Provider endpoint (default: the provider's public API)
+	Client   *http.Client
+}
+
+// Fetch returns the exchange rates of a provider, from the cache if possible
+// Rates of a pinned date never change, so they are cached for good; latest rates for MaxAge
+// If the provider can't be reached, stale cached rates are used rather than failing
+func Fetch(ctx context.Context, opts Options) (*Rates, error) {
+	if opts.Date != "" {
+		if _, err := time.Parse("2006-01-02", opts.Date); err != nil {
+			return nil, fmt.Errorf("invalid exchange rate date %q: expected YYYY-MM-DD", opts.Date)
+		}
+	}
+	maxAge := opts.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	cachePath := ""
+	var cached *Rates
+	if opts.CacheDir != "" {
+		date := opts.Date
+		if date == "" {
+			date = "latest"
+		}
+		cachePath = filepath.Join(opts.CacheDir, opts.Provider+"-"+date+".json")
+		cached = readCache(cachePath)
+		if cached != nil && (opts.Date != "" || time.Since(cached.FetchedAt) < maxAge) {
+			return cached, nil
+		}
+	}
+
+	var rates *Rates
+	var err error
+	switch opts.Provider {
+	case ProviderECB:
+		rates, err = fetchECB(ctx, opts)
+	case ProviderOpenExchangeRates:
+		rates, err = fetchOpenExchangeRates(ctx, opts)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q (use %s or %s)", opts.Provider, ProviderECB, ProviderOpenExchangeRates)
+	}
+	if err != nil {
+		if cached != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using cached rates of %s\n", err, cached.Date)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	rates.FetchedAt = time.Now().UTC()
+	if cachePath != "" {
+		if err := writeCache(cachePath, rates); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to cache exchange rates: %v\n", err)
+		}
+	}
+	return rates, nil
+}
+
+// LoadFile loads rates from a JSON file in the cache format, for offline or audited runs
+func LoadFile(path string) (*Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+	if rates.Base == "" || len(rates.Rates) == 0 {
+		return nil, fmt.Errorf("rates file %s has no base currency or rates", path)
+	}
+	if rates.Provider == "" {
+		rates.Provider = "file"
+	}
+	return &rates, nil
+}
+
+// ecbEnvelope is the eurofxref XML document: a cube per day with a cube per currency
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// fetchECB fetches the euro reference rates of the European Central Bank
+// For a pinned date the rates of the last publication on or before it are used, as none are published on weekends and holidays
+func fetchECB(ctx context.Context, opts Options) (*Rates, error) {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = ECBBaseURL
+	}
+	document := "/eurofxref-daily.xml"
+	if opts.Date != "" {
+		document = "/eurofxref-hist.xml"
+	}
+
+	body, err := get(ctx, opts.Client, baseURL+document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ECB rates: %w", err)
+	}
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse ECB rates: %w", err)
+	}
+
+	// Days are listed newest first
+	for _, day := range envelope.Days {
+		if opts.Date != "" && day.Time > opts.Date {
+			continue
+		}
+		rates := &Rates{Provider: ProviderECB, Base: "EUR", Date: day.Time, Rates: make(map[string]float64, len(day.Rates))}
+		for _, r := range day.Rates {
+			rates.Rates[r.Currency] = r.Rate
+		}
+		return rates, nil
+	}
+	if opts.Date != "" {
+		return nil, fmt.Errorf("no ECB rates on or before %s", opts.Date)
+	}
+	return nil, fmt.Errorf("no rates in the ECB response")
+}
+
+// fetchOpenExchangeRates fetches the latest or historical rates of openexchangerates.org (USD based)
+func fetchOpenExchangeRates(ctx context.Context, opts Options) (*Rates, error) {
+	if opts.AppID == "" {
+		return nil, fmt.Errorf("an app ID is required for %s", ProviderOpenExchangeRates)
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = OpenExchangeRatesBaseURL
+	}
+	document := "/latest.json"
+	if opts.Date != "" {
+		document = "/historical/" + opts.Date + ".json"
+	}
+
+	body, err := get(ctx, opts.Client, baseURL+document+"?app_id="+neturl.QueryEscape(opts.AppID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s rates: %w", ProviderOpenExchangeRates, err)
+	}
+	var response struct {
+		Timestamp int64              `json:"timestamp"`
+		Base      string             `json:"base"`
+		Rates     map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse %s rates: %w", ProviderOpenExchangeRates, err)
+	}
+	if response.Base == "" || len(response.Rates) == 0 {
+		return nil, fmt.Errorf("no rates in the %s response", ProviderOpenExchangeRates)
+	}
+	return &Rates{
+		Provider: ProviderOpenExchangeRates,
+		Base:     response.Base,
+		Date:     time.Unix(response.Timestamp, 0).UTC().Format("2006-01-02"),
+		Rates:    response.Rates,
+	}, nil
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Leave out the URL, it may carry the app ID
+		if urlErr, ok := err.(*neturl.Error); ok {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// readCache returns the cached rates, or nil if there are none
+func readCache(path string) *Rates {
+	rates, err := LoadFile(path)
+	if err != nil {
+		return nil
+	}
+	return rates
+}
+
+func writeCache(path string, rates *Rates) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
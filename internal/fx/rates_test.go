@@ -0,0 +1,86 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const ecbHistory = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2025-02-03">
+			<Cube currency="USD" rate="1.0300"/>
+			<Cube currency="GBP" rate="0.8300"/>
+		</Cube>
+		<Cube time="2025-01-31">
+			<Cube currency="USD" rate="1.0000"/>
+			<Cube currency="GBP" rate="0.8000"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestFetchECBPinnedDate(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/eurofxref-hist.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(ecbHistory))
+	}))
+	defer ts.Close()
+
+	// Saturday: the rates of the Friday before apply
+	opts := Options{Provider: ProviderECB, Date: "2025-02-01", BaseURL: ts.URL, CacheDir: t.TempDir()}
+	rates, err := Fetch(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if rates.Base != "EUR" || rates.Date != "2025-01-31" || rates.Rates["USD"] != 1.0 {
+		t.Errorf("rates = %+v", rates)
+	}
+
+	usd, err := rates.Convert(100, "GBP", "USD")
+	if err != nil || usd != 125 {
+		t.Errorf("Convert(100 GBP to USD) = %v, %v; want 125", usd, err)
+	}
+	if _, err := rates.Convert(100, "EUR", "JPY"); err == nil {
+		t.Error("expected an error for a currency without a rate")
+	}
+
+	// Pinned rates come from the cache from now on
+	if _, err := Fetch(context.Background(), opts); err != nil {
+		t.Fatalf("Fetch from cache: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestFetchOpenExchangeRates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest.json" || r.URL.Query().Get("app_id") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"timestamp": 1738368000, "base": "USD", "rates": {"EUR": 0.5}}`))
+	}))
+	defer ts.Close()
+
+	rates, err := Fetch(context.Background(), Options{Provider: ProviderOpenExchangeRates, AppID: "key", BaseURL: ts.URL})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	eur, _ := rates.Convert(10, "USD", "EUR")
+	if rates.Date != "2025-02-01" || eur != 5 {
+		t.Errorf("rates = %+v, 10 USD = %v EUR", rates, eur)
+	}
+
+	if _, err := Fetch(context.Background(), Options{Provider: ProviderOpenExchangeRates, AppID: "wrong", BaseURL: ts.URL}); err == nil {
+		t.Error("expected an error for a rejected app ID")
+	}
+}
@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConsistencyIssue describes a label that differs between premium lists
+type ConsistencyIssue struct {
+	Label   string
+	Kind    string // "missing" or "price_mismatch"
+	Details string
+}
+
+// LoadPremiumList reads a generated premium list (default or cnic-new format) into entries keyed by label
+// The format is detected from the header row
+func LoadPremiumList(path string) (map[string]*PremiumListEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open premium list: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(skipBOM(file))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#' // Metadata embedded by generate --embed-metadata
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, col := range header {
+		columns[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+
+	_, hasTier := columns["tier"]
+	_, hasType := columns["type"]
+	switch {
+	case hasTier:
+		return readDefaultList(reader, columns)
+	case hasType:
+		return readCNicNewList(reader, columns)
+	default:
+		return nil, fmt.Errorf("unrecognized premium list format in %s", path)
+	}
+}
+
+// readDefaultList reads rows in the default format (Label, Tier, price_reg, price_ren, price_res, currency)
+func readDefaultList(reader *csv.Reader, columns map[string]int) (map[string]*PremiumListEntry, error) {
+	entries := make(map[string]*PremiumListEntry)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		label := strings.ToLower(strings.TrimSpace(field(record, columns, "label")))
+		if label == "" {
+			continue
+		}
+
+		tier, _ := strconv.Atoi(field(record, columns, "tier"))
+		entries[label] = &PremiumListEntry{
+			Label:    label,
+			Tier:     tier,
+			PriceReg: parsePrice(field(record, columns, "price_reg")),
+			PriceRen: parsePrice(field(record, columns, "price_ren")),
+			PriceRes: parsePrice(field(record, columns, "price_res")),
+			Currency: strings.ToUpper(field(record, columns, "currency")),
+		}
+	}
+	return entries, nil
+}
+
+// readCNicNewList reads rows in the cnic-new format (label, suffix, type, currency, amount)
+// Each label has one row per price type, which are merged into a single entry
+func readCNicNewList(reader *csv.Reader, columns map[string]int) (map[string]*PremiumListEntry, error) {
+	entries := make(map[string]*PremiumListEntry)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		label := strings.ToLower(strings.TrimSpace(field(record, columns, "label")))
+		if label == "" {
+			continue
+		}
+
+		entry, ok := entries[label]
+		if !ok {
+			entry = &PremiumListEntry{Label: label, Currency: strings.ToUpper(field(record, columns, "currency"))}
+			entries[label] = entry
+		}
+
+		price := parsePrice(field(record, columns, "amount"))
+		switch strings.ToLower(field(record, columns, "type")) {
+		case "registration":
+			entry.PriceReg = price
+		case "renewal":
+			entry.PriceRen = price
+		case "restore":
+			entry.PriceRes = price
+		}
+	}
+	return entries, nil
+}
+
+// CheckConsistency compares premium lists keyed by name and reports labels that are
+// missing from some lists or priced differently
+// Issues are sorted by label
+func CheckConsistency(lists map[string]map[string]*PremiumListEntry) []ConsistencyIssue {
+	names := make([]string, 0, len(lists))
+	allLabels := make(map[string]bool)
+	for name, entries := range lists {
+		names = append(names, name)
+		for label := range entries {
+			allLabels[label] = true
+		}
+	}
+	sort.Strings(names)
+
+	labels := make([]string, 0, len(allLabels))
+	for label := range allLabels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var issues []ConsistencyIssue
+	for _, label := range labels {
+		var present, missing []string
+		for _, name := range names {
+			if _, ok := lists[name][label]; ok {
+				present = append(present, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) > 0 {
+			issues = append(issues, ConsistencyIssue{
+				Label:   label,
+				Kind:    "missing",
+				Details: fmt.Sprintf("present in %s; missing in %s", strings.Join(present, ", "), strings.Join(missing, ", ")),
+			})
+		}
+
+		// Compare prices across the lists that contain the label
+		if len(present) > 1 {
+			reference := lists[present[0]][label]
+			var diffs []string
+			for _, name := range present[1:] {
+				entry := lists[name][label]
+				if !samePricing(reference, entry) {
+					diffs = append(diffs, fmt.Sprintf("%s: %s", name, describePricing(entry)))
+				}
+			}
+			if len(diffs) > 0 {
+				issues = append(issues, ConsistencyIssue{
+					Label:   label,
+					Kind:    "price_mismatch",
+					Details: fmt.Sprintf("%s: %s vs %s", present[0], describePricing(reference), strings.Join(diffs, "; ")),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// samePricing compares prices and currency (and tier when both lists carry one)
+func samePricing(a, b *PremiumListEntry) bool {
+	if a.Tier != 0 && b.Tier != 0 && a.Tier != b.Tier {
+		return false
+	}
+	return a.Currency == b.Currency &&
+		floatPtrToString(a.PriceReg) == floatPtrToString(b.PriceReg) &&
+		floatPtrToString(a.PriceRen) == floatPtrToString(b.PriceRen) &&
+		floatPtrToString(a.PriceRes) == floatPtrToString(b.PriceRes)
+}
+
+// describePricing formats an entry's pricing for reports
+func describePricing(e *PremiumListEntry) string {
+	desc := fmt.Sprintf("reg=%s ren=%s res=%s %s", floatPtrToString(e.PriceReg), floatPtrToString(e.PriceRen), floatPtrToString(e.PriceRes), e.Currency)
+	if e.Tier != 0 {
+		desc = fmt.Sprintf("tier %d ", e.Tier) + desc
+	}
+	return desc
+}
+
+// field returns the value of a named column, or an empty string if missing
+func field(record []string, columns map[string]int, name string) string {
+	idx, ok := columns[name]
+	if !ok || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// parsePrice parses a price cell, returning nil for empty or invalid values
+func parsePrice(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
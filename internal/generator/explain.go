@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+// TierMatch is a tier matching a label, with the tags it matched on
+type TierMatch struct {
+	Tier        int      `json:"tier"`
+	MatchedTags []string `json:"matched_tags"`
+}
+
+// Explanation is the decision trail of a label: how generate would list and price it
+type Explanation struct {
+	Label      string            `json:"label"`
+	Tags       []string          `json:"tags"`
+	ExcludedBy []string          `json:"excluded_by,omitempty"` // Excluded tags the label carries
+	FilteredBy string            `json:"filtered_by,omitempty"` // The filter expression the label doesn't match
+	Matches    []TierMatch       `json:"matches"`               // Highest tier first
+	Tier       int               `json:"tier"`                  // Winning tier, 0 if none
+	Override   *db.PriceOverride `json:"override,omitempty"`
+	Listed     bool              `json:"listed"`
+	Entry      *PremiumListEntry `json:"entry,omitempty"` // Final prices if listed
+	Steps      []string          `json:"steps"`           // The decisions in words
+}
+
+// Explain traces how the rules of GenerateTo apply to a label with tags and an optional price override
+// opts.ExcludeTags, opts.Where, opts.Currency and opts.Rates apply as they would to the generated list
+func Explain(label string, tags []string, override *db.PriceOverride, tiers []models.Tier, opts Options) (*Explanation, error) {
+	tags = append([]string(nil), tags...)
+	sort.Strings(tags)
+	e := &Explanation{Label: label, Tags: tags, Matches: make([]TierMatch, 0), Override: override}
+	step := func(format string, args ...any) { e.Steps = append(e.Steps, fmt.Sprintf(format, args...)) }
+
+	tagSet := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tagSet[tag] = true
+	}
+	for _, tag := range opts.ExcludeTags {
+		if tagSet[tag] {
+			e.ExcludedBy = append(e.ExcludedBy, tag)
+		}
+	}
+
+	for _, tier := range tiers {
+		var matched []string
+		for _, tag := range tier.Tags {
+			if tagSet[tag] {
+				matched = append(matched, tag)
+			}
+		}
+		if len(matched) > 0 {
+			e.Matches = append(e.Matches, TierMatch{Tier: tier.Tier, MatchedTags: matched})
+		}
+	}
+	sort.SliceStable(e.Matches, func(i, j int) bool { return e.Matches[i].Tier > e.Matches[j].Tier })
+	for _, m := range e.Matches {
+		step("matches tier %d on tag(s) %s", m.Tier, strings.Join(m.MatchedTags, ", "))
+	}
+
+	if len(e.ExcludedBy) > 0 {
+		step("excluded: carries excluded tag(s) %s, so it is left out of the list", strings.Join(e.ExcludedBy, ", "))
+		return e, nil
+	}
+	if opts.Where != nil && !opts.Where.Match(label, tags) {
+		e.FilteredBy = opts.Where.String()
+		step("excluded: doesn't match the filter %s, so it is left out of the list", e.FilteredBy)
+		return e, nil
+	}
+
+	best := findBestTier(tags, tiers)
+	switch {
+	case best != nil && len(e.Matches) > 1:
+		step("tier %d wins: the highest of %d matching tiers", best.Tier, len(e.Matches))
+	case best != nil:
+		step("tier %d is the only matching tier", best.Tier)
+	default:
+		step("matches no tier")
+	}
+
+	var entry PremiumListEntry
+	switch {
+	case override != nil:
+		entry = PremiumListEntry{Label: label, PriceReg: override.PriceReg, PriceRen: override.PriceRen, PriceRes: override.PriceRes, Currency: override.Currency}
+		if best != nil {
+			entry.Tier = best.Tier
+			step("price override from %s replaces the prices of tier %d", override.Source, best.Tier)
+		} else {
+			step("price override from %s lists it without a tier", override.Source)
+		}
+	case best != nil:
+		entry = PremiumListEntry{Label: label, Tier: best.Tier, PriceReg: best.PriceReg, PriceRen: best.PriceRen, PriceRes: best.PriceRes, Currency: best.Currency}
+	default:
+		step("not listed: no tier and no price override")
+		return e, nil
+	}
+
+	if opts.Currency != "" && !strings.EqualFold(entry.Currency, opts.Currency) {
+		from := entry.Currency
+		entries := []PremiumListEntry{entry}
+		if err := ConvertEntries(entries, opts.Currency, opts.Rates); err != nil {
+			return nil, err
+		}
+		entry = entries[0]
+		step("prices converted from %s to %s", strings.ToUpper(from), entry.Currency)
+	}
+
+	e.Tier, e.Listed, e.Entry = entry.Tier, true, &entry
+	prices := DefaultPriceFormat
+	if opts.PriceFormat != nil {
+		prices = *opts.PriceFormat
+	}
+	step("listed at registration %s, renewal %s, restore %s %s",
+		orUnset(prices.formatPtr(entry.PriceReg)), orUnset(prices.formatPtr(entry.PriceRen)), orUnset(prices.formatPtr(entry.PriceRes)), strings.ToUpper(entry.Currency))
+	return e, nil
+}
+
+// orUnset returns s, or "unset" if it is empty
+func orUnset(s string) string {
+	if s == "" {
+		return "unset"
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+func TestExplain(t *testing.T) {
+	low, high, partner := 100.0, 500.0, 75.0
+	tiers := []models.Tier{
+		{Tier: 1, Tags: []string{"fashion", "short"}, Currency: "USD", PriceReg: &low},
+		{Tier: 2, Tags: []string{"premium"}, Currency: "USD", PriceReg: &high},
+	}
+
+	e, err := Explain("shoes", []string{"short", "premium", "fashion"}, nil, tiers, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Listed || e.Tier != 2 || len(e.Matches) != 2 || strings.Join(e.Matches[1].MatchedTags, ",") != "fashion,short" {
+		t.Errorf("explanation = %+v", e)
+	}
+	steps := strings.Join(e.Steps, "\n")
+	for _, want := range []string{"tier 2 wins: the highest of 2 matching tiers", "listed at registration 500.00, renewal unset, restore unset USD"} {
+		if !strings.Contains(steps, want) {
+			t.Errorf("steps lack %q:\n%s", want, steps)
+		}
+	}
+
+	// A price override replaces the tier prices
+	override := &db.PriceOverride{Label: "bags", Currency: "EUR", PriceReg: &partner, Source: "partner"}
+	e, err = Explain("bags", []string{"fashion"}, override, tiers, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Listed || e.Tier != 1 || *e.Entry.PriceReg != 75 || e.Entry.Currency != "EUR" {
+		t.Errorf("override explanation = %+v", e)
+	}
+
+	// Exclusions come before everything else
+	e, err = Explain("hats", []string{"fashion", "registered"}, nil, tiers, Options{ExcludeTags: []string{"registered"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Listed || len(e.ExcludedBy) != 1 {
+		t.Errorf("excluded explanation = %+v", e)
+	}
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"premium-list-maker/internal/atomicfile"
+)
+
+// Manifest is written next to a generated list, so a published list can be traced to its inputs
+type Manifest struct {
+	Output       string      `json:"output"` // File name of the list, relative to the manifest
+	SHA256       string      `json:"sha256"`
+	SizeBytes    int64       `json:"size_bytes"`
+	Format       string      `json:"format"`
+	TLD          string      `json:"tld,omitempty"`
+	Entries      int         `json:"entries"`
+	TierCounts   map[int]int `json:"tier_counts"`
+	ExcludeTags  []string    `json:"exclude_tags,omitempty"`
+	Where        string      `json:"where,omitempty"`
+	Currency     string      `json:"currency,omitempty"`
+	FXProvider   string      `json:"fx_provider,omitempty"`
+	FXDate       string      `json:"fx_date,omitempty"`
+	Reproducible bool        `json:"reproducible,omitempty"`
+	TiersFile    string      `json:"tiers_file"`
+	TiersSHA256  string      `json:"tiers_sha256"`
+	Database     string      `json:"database"`
+	ToolVersion  string      `json:"tool_version"`
+	GeneratedAt  time.Time   `json:"generated_at"`
+}
+
+// ManifestPath returns the path of the manifest of a list
+func ManifestPath(outputPath string) string {
+	return outputPath + ".manifest.json"
+}
+
+// NewManifest describes a list generated from tiersPath with opts
+// The caller fills in Database and ToolVersion
+func NewManifest(result *GenerateResult, outputPath, tiersPath string, opts Options) (*Manifest, error) {
+	tiers, err := os.ReadFile(tiersPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tiers file: %w", err)
+	}
+	tiersSum := sha256.Sum256(tiers)
+
+	m := &Manifest{
+		Output:       filepath.Base(outputPath),
+		SHA256:       result.SHA256,
+		SizeBytes:    result.SizeBytes,
+		Format:       result.Format,
+		TLD:          opts.TLD,
+		Entries:      result.Entries,
+		TierCounts:   result.TierCounts,
+		ExcludeTags:  opts.ExcludeTags,
+		Currency:     result.Currency,
+		TiersFile:    tiersPath,
+		TiersSHA256:  hex.EncodeToString(tiersSum[:]),
+		Reproducible: opts.Reproducible,
+		GeneratedAt:  time.Now().UTC(),
+	}
+	if opts.Where != nil {
+		m.Where = opts.Where.String()
+	}
+	if result.Rates != nil {
+		m.FXProvider = result.Rates.Provider
+		m.FXDate = result.Rates.Date
+	}
+	return m, nil
+}
+
+// WriteManifest writes a manifest as indented JSON
+func WriteManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'))
+}
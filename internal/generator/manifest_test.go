@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	tiersPath := filepath.Join(dir, "tiers.json")
+	if err := os.WriteFile(tiersPath, []byte("[]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, "premium.csv")
+	result := &GenerateResult{Format: "cnic-new", Entries: 2, TierCounts: map[int]int{1: 2}, SHA256: "abc", SizeBytes: 42}
+
+	m, err := NewManifest(result, outputPath, tiersPath, Options{TLD: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ToolVersion = "1.2.3"
+	if err := WriteManifest(ManifestPath(outputPath), m); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "premium.csv.manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Output != "premium.csv" || got.SHA256 != "abc" || got.Entries != 2 || got.TLD != "shop" || got.ToolVersion != "1.2.3" {
+		t.Errorf("manifest = %+v", got)
+	}
+	// SHA-256 of "[]\n"
+	if got.TiersSHA256 != "37517e5f3dc66819f61f5a7bb8ace1921282415f10551d2defa5c3eb0985b570" || got.GeneratedAt.IsZero() {
+		t.Errorf("tiers checksum %q, generated at %v", got.TiersSHA256, got.GeneratedAt)
+	}
+}
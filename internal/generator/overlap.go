@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+// overlapExamples is the number of example labels kept per overlap
+const overlapExamples = 5
+
+// TierOverlap is a set of tiers that some labels match all of
+type TierOverlap struct {
+	Tiers    []int    `json:"tiers"`    // Highest first
+	Winner   int      `json:"winner"`   // Tier the labels get: the highest
+	Labels   int      `json:"labels"`   // Labels matching exactly these tiers
+	Examples []string `json:"examples"` // Some of the labels, sorted
+}
+
+// AnalyzeOverlaps finds the labels in the database that match more than one tier,
+// grouped by the tiers they match, most labels first
+func AnalyzeOverlaps(store db.Store, tiers []models.Tier) ([]TierOverlap, error) {
+	labelsWithTags, err := store.GetAllLabelsWithTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	byKey := make(map[string]*TierOverlap)
+	for label, tags := range labelsWithTags {
+		tagSet := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			tagSet[tag] = true
+		}
+		var matched []int
+		for _, tier := range tiers {
+			if hasMatchingTag(tier.Tags, tagSet) {
+				matched = append(matched, tier.Tier)
+			}
+		}
+		if len(matched) < 2 {
+			continue
+		}
+
+		sort.Sort(sort.Reverse(sort.IntSlice(matched)))
+		key := joinInts(matched, ",")
+		overlap, ok := byKey[key]
+		if !ok {
+			overlap = &TierOverlap{Tiers: matched, Winner: matched[0], Examples: make([]string, 0, overlapExamples)}
+			byKey[key] = overlap
+		}
+		overlap.Labels++
+		overlap.Examples = keepSmallest(overlap.Examples, label, overlapExamples)
+	}
+
+	overlaps := make([]TierOverlap, 0, len(byKey))
+	for _, o := range byKey {
+		overlaps = append(overlaps, *o)
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		if overlaps[i].Labels != overlaps[j].Labels {
+			return overlaps[i].Labels > overlaps[j].Labels
+		}
+		return joinInts(overlaps[i].Tiers, ",") < joinInts(overlaps[j].Tiers, ",")
+	})
+	return overlaps, nil
+}
+
+// AddOverlaps adds the overlaps to the validation, with a warning for each
+func (v *TierValidation) AddOverlaps(overlaps []TierOverlap) {
+	v.Overlaps = overlaps
+	for _, o := range overlaps {
+		v.Warnings = append(v.Warnings, fmt.Sprintf("%d label(s) match tiers %s and get tier %d, e.g. %s",
+			o.Labels, joinInts(o.Tiers, ", "), o.Winner, strings.Join(o.Examples, ", ")))
+	}
+}
+
+// keepSmallest adds label to the sorted examples, keeping the n smallest
+func keepSmallest(examples []string, label string, n int) []string {
+	i := sort.SearchStrings(examples, label)
+	if i >= n {
+		return examples
+	}
+	if len(examples) < n {
+		examples = append(examples, "")
+	}
+	copy(examples[i+1:], examples[i:])
+	examples[i] = label
+	return examples
+}
+
+// joinInts joins numbers with sep
+func joinInts(nums []int, sep string) string {
+	s := make([]string, len(nums))
+	for i, n := range nums {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/models"
+)
+
+func TestAnalyzeOverlaps(t *testing.T) {
+	store := &memStore{labels: map[string][]string{
+		"shoes": {"fashion", "premium"},
+		"boots": {"fashion", "premium"},
+		"hats":  {"fashion"},
+		"gold":  {"premium", "finance"},
+	}}
+	tiers := []models.Tier{
+		{Tier: 1, Tags: []string{"fashion"}},
+		{Tier: 2, Tags: []string{"finance"}},
+		{Tier: 3, Tags: []string{"premium", "fashion"}},
+	}
+
+	overlaps, err := AnalyzeOverlaps(store, tiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 2 {
+		t.Fatalf("overlaps = %+v, want 2", overlaps)
+	}
+	// Most labels first
+	if o := overlaps[0]; joinInts(o.Tiers, ",") != "3,1" || o.Winner != 3 || o.Labels != 3 || strings.Join(o.Examples, ",") != "boots,hats,shoes" {
+		t.Errorf("first overlap = %+v", o)
+	}
+	if o := overlaps[1]; joinInts(o.Tiers, ",") != "3,2" || o.Labels != 1 {
+		t.Errorf("second overlap = %+v", o)
+	}
+
+	v := ValidateTiers(tiers, nil)
+	if !strings.Contains(strings.Join(v.Warnings, "\n"), `tag "fashion" is claimed by tiers 3, 1; tier 3 wins`) {
+		t.Errorf("warnings = %v", v.Warnings)
+	}
+	v.AddOverlaps(overlaps)
+	if len(v.Overlaps) != 2 || !strings.Contains(strings.Join(v.Warnings, "\n"), "3 label(s) match tiers 3, 1 and get tier 3") {
+		t.Errorf("validation = %+v", v)
+	}
+}
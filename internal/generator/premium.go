@@ -1,14 +1,25 @@
 package generator
 
 import (
-	"encoding/csv"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
+	"premium-list-maker/internal/atomicfile"
+	"premium-list-maker/internal/csvout"
 	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/fx"
+	"premium-list-maker/internal/metrics"
 	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagexpr"
 )
 
 // PremiumListEntry represents a single entry in the premium list output
@@ -21,31 +32,388 @@ type PremiumListEntry struct {
 	Currency string
 }
 
+// GenerateResult describes a generated premium list
+type GenerateResult struct {
+	Format     string        `json:"format"`
+	Entries    int           `json:"entries"`
+	TierCounts map[int]int   `json:"tier_counts"` // Entries per tier; tier 0 holds labels priced by an override only
+	Excluded   int           `json:"excluded"`    // Labels left out for carrying an excluded tag
+	Unmatched  int           `json:"unmatched"`   // Labels no tier or price override matched
+	Currency   string        `json:"currency,omitempty"`
+	Rates      *fx.Rates     `json:"-"` // Rates the prices were converted at, nil if not converted
+	SHA256     string        `json:"sha256"`
+	SizeBytes  int64         `json:"size_bytes"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+}
+
 // GeneratePremiumList generates a premium list CSV from tiers.json
-func GeneratePremiumList(db *db.DB, tiersPath, outputPath, format, tld string) error {
-	// Load tiers from JSON
-	tiers, err := loadTiers(tiersPath)
+// Labels carrying any of excludeTags (e.g. "registered") are left out of the list
+func GeneratePremiumList(db *db.DB, tiersPath, outputPath, format, tld string, excludeTags []string) (*GenerateResult, error) {
+	return GeneratePremiumListInCurrency(db, tiersPath, outputPath, format, tld, excludeTags, "", nil)
+}
+
+// GeneratePremiumListInCurrency is GeneratePremiumList with every price converted to currency using rates
+// With an empty currency the prices are written in the currencies of their tiers
+func GeneratePremiumListInCurrency(db *db.DB, tiersPath, outputPath, format, tld string, excludeTags []string, currency string, rates *fx.Rates) (*GenerateResult, error) {
+	return GeneratePremiumListWithOptions(db, tiersPath, outputPath, Options{
+		Format:      format,
+		TLD:         tld,
+		ExcludeTags: excludeTags,
+		Currency:    currency,
+		Rates:       rates,
+	})
+}
+
+// GeneratePremiumListWithOptions generates a premium list from tiers.json
+func GeneratePremiumListWithOptions(db *db.DB, tiersPath, outputPath string, opts Options) (*GenerateResult, error) {
+	tiers, err := LoadTiers(tiersPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tiers: %w", err)
+	}
+
+	return GenerateFromTiers(db, tiers, outputPath, opts)
+}
+
+// Options controls the premium list written by GenerateFromTiers and GenerateTo
+type Options struct {
+	Format      string       // default, cnic-new or xlsx
+	TLD         string       // Required for cnic-new
+	ExcludeTags []string     // Labels carrying any of these tags (e.g. "registered") are left out
+	Where       tagexpr.Expr // Only labels matching this filter expression are listed, nil for all
+	Currency    string       // Convert every price to this currency, empty to keep the tiers' currencies
+	Rates       *fx.Rates    // Exchange rates for Currency
+	Progress    progress.Func
+	Context     context.Context // Stops the generation when done; no output file is written
+
+	// PriceFormat controls how prices are written, nil for DefaultPriceFormat
+	PriceFormat *PriceFormat
+
+	// Reproducible marks a list that identical inputs write byte for byte: CSV lists are always written in
+	// label order, but workbooks carry their generation time, so it needs a CSV format
+	Reproducible bool
+	// Metadata lines are written as "# " comments before the CSV header, e.g. the tool version and
+	// tiers checksum; they should not vary between runs if the list is to stay reproducible
+	Metadata []string
+
+	// CRLF ends the lines of CSV lists with \r\n instead of \n, as some registry upload portals require
+	CRLF bool
+	// BOM starts CSV lists with a UTF-8 byte order mark, so Excel on Windows doesn't misread them
+	BOM bool
+	// Quoting controls how fields of CSV lists are quoted, the zero value quotes like encoding/csv
+	Quoting csvout.Quoting
+
+	// Verify checks the list after writing it: no label twice, one row per matched label (read back from
+	// the file by GenerateFromTiers), and the same matches in the database, see ErrDatabaseChanged
+	Verify bool
+
+	// written gets the number of labels written, for GenerateFromTiers to verify the file against
+	written *int
+}
+
+// GenerateFromTiers writes the premium list of already loaded tiers to outputPath
+func GenerateFromTiers(db db.Store, tiers []models.Tier, outputPath string, opts Options) (*GenerateResult, error) {
+	if err := checkFormat(opts); err != nil {
+		return nil, err
+	}
+
+	// Written under a temporary name, so a failed run never leaves a truncated list behind
+	file, err := atomicfile.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Abort()
+
+	var written int
+	if opts.Verify && opts.Format != "xlsx" {
+		opts.written = &written
+	}
+	result, err := GenerateTo(db, tiers, file, opts)
 	if err != nil {
-		return fmt.Errorf("failed to load tiers: %w", err)
+		return nil, err
+	}
+	if opts.written != nil {
+		if err := verifyOutput(file.Name(), opts.Format, written); err != nil {
+			return nil, err
+		}
+	}
+	if err := file.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GenerateTo writes the premium list of already loaded tiers to w, e.g. an HTTP response or a buffer
+func GenerateTo(db db.Store, tiers []models.Tier, w io.Writer, opts Options) (result *GenerateResult, err error) {
+	format, tld := opts.Format, opts.TLD
+	start := time.Now()
+	defer func() { metrics.ObserveGeneration(format, time.Since(start), err) }()
+
+	if err := checkFormat(opts); err != nil {
+		return nil, err
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// The checksum and size cover exactly the bytes written
+	hash := sha256.New()
+	counter := &countingWriter{}
+	w = ctxWriter{ctx, io.MultiWriter(w, hash, counter)}
+
+	opts.Progress.Report(progress.Update{Phase: progress.PhaseMatching})
+	result = &GenerateResult{Format: format, TierCounts: make(map[int]int)}
+	if opts.Currency != "" {
+		result.Currency = strings.ToUpper(opts.Currency)
+		result.Rates = opts.Rates
+	}
+	// Keep what the labels matched before conversion, to match them again after writing
+	var matched map[string]string
+	if opts.Verify {
+		matched = make(map[string]string)
+	}
+
+	prices := DefaultPriceFormat
+	if opts.PriceFormat != nil {
+		prices = *opts.PriceFormat
 	}
+	if opts.BOM {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, fmt.Errorf("failed to write BOM: %w", err)
+		}
+	}
+	if err := writeMetadata(w, opts.Metadata, opts.CRLF); err != nil {
+		return nil, fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	if format == "xlsx" {
+		// Workbooks group the entries by tier, so every label is matched before writing
+		entries, excluded, unmatched, err := matchLabels(db, tiers, opts.ExcludeTags, opts.Where)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("generation canceled: %w", err)
+		}
+		result.Entries, result.Excluded, result.Unmatched = len(entries), excluded, unmatched
+		for _, e := range entries {
+			result.TierCounts[e.Tier]++
+		}
+		if opts.Verify {
+			if matched, err = entryKeys(entries); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Currency != "" {
+			if err := ConvertEntries(entries, opts.Currency, opts.Rates); err != nil {
+				return nil, err
+			}
+		}
+		opts.Progress.Report(progress.Update{Phase: progress.PhaseWriting, Total: len(entries), MemoryMB: progress.MemoryMB()})
+
+		labelTags, err := db.GetAllLabelsWithTags()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get labels: %w", err)
+		}
+		if err := writeXLSX(entries, labelTags, w, tld, prices); err != nil {
+			return nil, fmt.Errorf("failed to write workbook: %w", err)
+		}
+	} else {
+		// CSV lists are written while the labels stream from the store, in label order, so memory
+		// doesn't grow with the database
+		lw := &csvListWriter{w: newCSVWriter(w, opts), format: format, tld: tld, prices: prices}
+		if err := lw.writeHeader(); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+		opts.Progress.Report(progress.Update{Phase: progress.PhaseWriting, MemoryMB: progress.MemoryMB()})
+
+		written := 0
+		result.Excluded, result.Unmatched, err = eachMatch(db, tiers, opts.ExcludeTags, opts.Where, func(e PremiumListEntry) error {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("generation canceled: %w", err)
+			}
+			if matched != nil {
+				if _, ok := matched[e.Label]; ok {
+					return fmt.Errorf("%w: label %s listed twice", ErrInvalidOutput, e.Label)
+				}
+				matched[e.Label] = entryKey(e)
+			}
+			result.Entries++
+			result.TierCounts[e.Tier]++
+			if opts.Currency != "" {
+				if err := convertEntry(&e, result.Currency, opts.Rates); err != nil {
+					return err
+				}
+			}
+			if format != "cnic-new" || e.PriceReg != nil || e.PriceRen != nil || e.PriceRes != nil {
+				written++
+			}
+			if result.Entries%progressEvery == 0 {
+				opts.Progress.Report(progress.Update{Phase: progress.PhaseWriting, Rows: result.Entries, MemoryMB: progress.MemoryMB()})
+			}
+			if err := lw.write(e); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := lw.flush(); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+		if opts.written != nil {
+			*opts.written = written
+		}
+	}
+
+	if opts.Verify {
+		if err := verifyUnchanged(db, tiers, opts.ExcludeTags, opts.Where, matched); err != nil {
+			return nil, err
+		}
+	}
+
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	result.SizeBytes = counter.n
+	result.Duration = time.Since(start)
+	result.DurationMS = result.Duration.Milliseconds()
+	opts.Progress.Report(progress.Update{Phase: progress.PhaseDone, Rows: result.Entries, Total: result.Entries})
+	return result, nil
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// ctxWriter fails writes once its context is done, stopping the list writers
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
 
-	// Validate method args if needed
-	if format == "cnic-new" && tld == "" {
+func (c ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, fmt.Errorf("generation canceled: %w", err)
+	}
+	return c.w.Write(p)
+}
+
+// checkFormat validates the format specific options before anything is written
+func checkFormat(opts Options) error {
+	if opts.Format == "cnic-new" && opts.TLD == "" {
 		return fmt.Errorf("tld is required for cnic-new format")
 	}
+	// Workbooks carry their generation time and have no place for comments
+	if opts.Format == "xlsx" && (opts.Reproducible || len(opts.Metadata) > 0) {
+		return fmt.Errorf("reproducible output and metadata need a CSV format, not xlsx")
+	}
+	if opts.Format == "xlsx" && (opts.CRLF || opts.BOM || !opts.Quoting.IsDefault()) {
+		return fmt.Errorf("line endings, a BOM and quoting apply to CSV formats, not xlsx")
+	}
+	if err := opts.Quoting.Validate(); err != nil {
+		return err
+	}
+	if opts.PriceFormat != nil {
+		if err := opts.PriceFormat.Validate(); err != nil {
+			return fmt.Errorf("invalid price format: %w", err)
+		}
+	}
+	return nil
+}
+
+// utf8BOM is the UTF-8 byte order mark written by Options.BOM
+const utf8BOM = "\ufeff"
+
+// skipBOM returns r without its UTF-8 byte order mark, if it starts with one
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(len(utf8BOM)); err == nil && string(b) == utf8BOM {
+		br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+// writeMetadata writes the metadata lines as comments
+func writeMetadata(w io.Writer, lines []string, crlf bool) error {
+	eol := "\n"
+	if crlf {
+		eol = "\r\n"
+	}
+	for _, line := range lines {
+		line = strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+		if _, err := fmt.Fprintf(w, "# %s%s", line, eol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MatchLabels assigns every label in the database to its best tier
+// Labels with a price override (e.g. from a partner feed) take the override's prices, and are listed
+// even when no tier matches them (as tier 0)
+// Labels carrying any of excludeTags are left out and counted in excluded
+func MatchLabels(db db.Store, tiers []models.Tier, excludeTags []string) (entries []PremiumListEntry, excluded int, err error) {
+	entries, excluded, _, err = matchLabels(db, tiers, excludeTags, nil)
+	return entries, excluded, err
+}
+
+// matchLabels is MatchLabels also counting the labels that match no tier or override
+// Labels not matching where (if not nil) are counted in excluded, like those carrying excludeTags
+func matchLabels(db db.Store, tiers []models.Tier, excludeTags []string, where tagexpr.Expr) (entries []PremiumListEntry, excluded, unmatched int, err error) {
+	entries = make([]PremiumListEntry, 0)
+	excluded, unmatched, err = eachMatch(db, tiers, excludeTags, where, func(e PremiumListEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return entries, excluded, unmatched, nil
+}
 
-	// Get all labels with their tags
-	labelsWithTags, err := db.GetAllLabelsWithTags()
+// eachMatch is matchLabels streaming the labels from the store: fn gets the entry of every listed label,
+// in label order, and an error of fn stops the matching
+func eachMatch(db db.Store, tiers []models.Tier, excludeTags []string, where tagexpr.Expr, fn func(PremiumListEntry) error) (excluded, unmatched int, err error) {
+	overrides, err := db.GetPriceOverrides()
 	if err != nil {
-		return fmt.Errorf("failed to get labels: %w", err)
+		return 0, 0, err
 	}
 
-	// Match labels to tiers
-	entries := make([]PremiumListEntry, 0)
-	for label, tags := range labelsWithTags {
+	excludeSet := make(map[string]bool)
+	for _, tag := range excludeTags {
+		excludeSet[tag] = true
+	}
+
+	err = db.EachLabelWithTags(func(label string, tags []string) error {
+		if (len(excludeSet) > 0 && hasMatchingTag(tags, excludeSet)) || (where != nil && !where.Match(label, tags)) {
+			excluded++
+			return nil
+		}
+
 		bestTier := findBestTier(tags, tiers)
-		if bestTier != nil {
-			entries = append(entries, PremiumListEntry{
+		override, hasOverride := overrides[label]
+		switch {
+		case hasOverride:
+			entry := PremiumListEntry{
+				Label:    label,
+				PriceReg: override.PriceReg,
+				PriceRen: override.PriceRen,
+				PriceRes: override.PriceRes,
+				Currency: override.Currency,
+			}
+			if bestTier != nil {
+				entry.Tier = bestTier.Tier
+			}
+			return fn(entry)
+		case bestTier != nil:
+			return fn(PremiumListEntry{
 				Label:    label,
 				Tier:     bestTier.Tier,
 				PriceReg: bestTier.PriceReg,
@@ -53,32 +421,64 @@ func GeneratePremiumList(db *db.DB, tiersPath, outputPath, format, tld string) e
 				PriceRes: bestTier.PriceRes,
 				Currency: bestTier.Currency,
 			})
+		default:
+			unmatched++
+			return nil
 		}
+	})
+	if err != nil {
+		return 0, 0, err
 	}
+	return excluded, unmatched, nil
+}
 
-	// Write to CSV based on format
-	if format == "cnic-new" {
-		if err := writeCNicNewCSV(entries, outputPath, tld); err != nil {
-			return fmt.Errorf("failed to write CSV: %w", err)
-		}
-	} else {
-		// Default format
-		if err := writeCSV(entries, outputPath); err != nil {
-			return fmt.Errorf("failed to write CSV: %w", err)
+// ConvertEntries converts the prices of the entries to currency in place
+// rates may be nil if every entry is already priced in currency
+func ConvertEntries(entries []PremiumListEntry, currency string, rates *fx.Rates) error {
+	currency = strings.ToUpper(currency)
+	for i := range entries {
+		if err := convertEntry(&entries[i], currency, rates); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	fmt.Printf("Generated premium list with %d entries (format: %s)\n", len(entries), format)
+// convertEntry converts the prices of an entry to currency, which must be upper case
+func convertEntry(entry *PremiumListEntry, currency string, rates *fx.Rates) error {
+	if strings.EqualFold(entry.Currency, currency) {
+		entry.Currency = currency
+		return nil
+	}
+	if rates == nil {
+		return fmt.Errorf("no exchange rates to convert %s prices to %s", entry.Currency, currency)
+	}
+	for _, price := range []**float64{&entry.PriceReg, &entry.PriceRen, &entry.PriceRes} {
+		if *price == nil {
+			continue
+		}
+		converted, err := rates.Convert(**price, entry.Currency, currency)
+		if err != nil {
+			return err
+		}
+		*price = &converted
+	}
+	entry.Currency = currency
 	return nil
 }
 
-// loadTiers loads tiers from a JSON file
-func loadTiers(path string) ([]models.Tier, error) {
+// LoadTiers loads tiers from a JSON file
+func LoadTiers(path string) ([]models.Tier, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tiers file: %w", err)
 	}
 
+	return ParseTiers(data)
+}
+
+// ParseTiers parses a tiers JSON document
+func ParseTiers(data []byte) ([]models.Tier, error) {
 	var tiers []models.Tier
 	if err := json.Unmarshal(data, &tiers); err != nil {
 		return nil, fmt.Errorf("failed to parse tiers JSON: %w", err)
@@ -123,108 +523,84 @@ func hasMatchingTag(tierTags []string, labelTagSet map[string]bool) bool {
 	return false
 }
 
-// writeCSV writes the premium list entries to a CSV file
-func writeCSV(entries []PremiumListEntry, path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer file.Close()
+// newCSVWriter returns a writer of CSV lists with the line endings and quoting of opts
+func newCSVWriter(w io.Writer, opts Options) *csvout.Writer {
+	writer := csvout.NewWriter(w, opts.Quoting)
+	writer.UseCRLF = opts.CRLF
+	return writer
+}
+
+// progressEvery is how many written entries a streamed list reports progress after
+const progressEvery = 100000
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// csvListWriter writes a CSV premium list one entry at a time
+type csvListWriter struct {
+	w      *csvout.Writer
+	format string // default or cnic-new
+	tld    string // For cnic-new
+	prices PriceFormat
+}
 
-	// Write header
+// writeHeader writes the header row of the format
+func (lw *csvListWriter) writeHeader() error {
 	header := []string{"Label", "Tier", "price_reg", "price_ren", "price_res", "currency"}
-	if err := writer.Write(header); err != nil {
+	if lw.format == "cnic-new" {
+		header = []string{"label", "suffix", "type", "currency", "amount"}
+	}
+	if err := lw.w.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
+	return nil
+}
 
-	// Write entries
-	for _, entry := range entries {
+// write writes the rows of an entry: one in the default format, one per price type in the new cnic format
+func (lw *csvListWriter) write(entry PremiumListEntry) error {
+	if lw.format != "cnic-new" {
 		record := []string{
 			entry.Label,
 			fmt.Sprintf("%d", entry.Tier),
-			floatPtrToString(entry.PriceReg),
-			floatPtrToString(entry.PriceRen),
-			floatPtrToString(entry.PriceRes),
+			lw.prices.formatPtr(entry.PriceReg),
+			lw.prices.formatPtr(entry.PriceRen),
+			lw.prices.formatPtr(entry.PriceRes),
 			entry.Currency,
 		}
-		if err := writer.Write(record); err != nil {
+		if err := lw.w.Write(record); err != nil {
 			return fmt.Errorf("failed to write record: %w", err)
 		}
+		return nil
 	}
 
-	return nil
-}
-
-// writeCNicNewCSV writes the premium list entries in the new cnic format
-func writeCNicNewCSV(entries []PremiumListEntry, path, tld string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	// label,suffix,type,currency,amount
-	header := []string{"label", "suffix", "type", "currency", "amount"}
-	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
-	}
-
-	// Write entries
-	for _, entry := range entries {
-		// Row 1: Registration
-		if entry.PriceReg != nil {
-			if err := writer.Write([]string{
-				entry.Label,
-				tld,
-				"Registration",
-				strings.ToUpper(entry.Currency),
-				fmt.Sprintf("%.2f", *entry.PriceReg),
-			}); err != nil {
-				return fmt.Errorf("failed to write registration record: %w", err)
-			}
-		}
-
-		// Row 2: Renewal
-		if entry.PriceRen != nil {
-			if err := writer.Write([]string{
-				entry.Label,
-				tld,
-				"Renewal",
-				strings.ToUpper(entry.Currency),
-				fmt.Sprintf("%.2f", *entry.PriceRen),
-			}); err != nil {
-				return fmt.Errorf("failed to write renewal record: %w", err)
-			}
+	for _, p := range []struct {
+		kind  string
+		price *float64
+	}{
+		{"Registration", entry.PriceReg},
+		{"Renewal", entry.PriceRen},
+		{"Restore", entry.PriceRes},
+	} {
+		if p.price == nil {
+			continue
 		}
-
-		// Row 3: Restore
-		if entry.PriceRes != nil {
-			if err := writer.Write([]string{
-				entry.Label,
-				tld,
-				"Restore",
-				strings.ToUpper(entry.Currency),
-				fmt.Sprintf("%.2f", *entry.PriceRes),
-			}); err != nil {
-				return fmt.Errorf("failed to write restore record: %w", err)
-			}
+		if err := lw.w.Write([]string{
+			entry.Label,
+			lw.tld,
+			p.kind,
+			strings.ToUpper(entry.Currency),
+			lw.prices.Format(*p.price),
+		}); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", strings.ToLower(p.kind), err)
 		}
 	}
-
 	return nil
 }
 
+// flush writes any buffered rows
+func (lw *csvListWriter) flush() error {
+	lw.w.Flush()
+	return lw.w.Error()
+}
+
 // floatPtrToString converts a float pointer to string, or empty string if nil
 func floatPtrToString(f *float64) string {
-	if f == nil {
-		return ""
-	}
-	return fmt.Sprintf("%.2f", *f)
+	return DefaultPriceFormat.formatPtr(f)
 }
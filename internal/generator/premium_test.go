@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/csvout"
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// memStore is an in-memory db.Store for generator tests
+type memStore struct {
+	labels    map[string][]string
+	overrides map[string]db.PriceOverride
+}
+
+func (s *memStore) BeginImport() (db.ImportTx, error) {
+	return nil, errors.New("memStore is read-only")
+}
+
+func (s *memStore) GetAllLabelsWithTags() (map[string][]string, error) {
+	return s.labels, nil
+}
+
+func (s *memStore) EachLabelWithTags(fn func(label string, tags []string) error) error {
+	return eachSorted(s.labels, fn)
+}
+
+// eachSorted calls fn for the labels in label order, like DB.EachLabelWithTags
+func eachSorted(labels map[string][]string, fn func(label string, tags []string) error) error {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+	for _, label := range names {
+		if err := fn(label, labels[label]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetPriceOverrides() (map[string]db.PriceOverride, error) {
+	return s.overrides, nil
+}
+
+func (s *memStore) ImportCheckpoint(string) (int, error) {
+	return 0, nil
+}
+
+func TestGenerateTo(t *testing.T) {
+	low, high, partner := 100.0, 500.0, 75.0
+	store := &memStore{
+		labels: map[string][]string{
+			"shoes": {"fashion", "premium"},
+			"hats":  {"fashion", "registered"},
+			"bags":  {"partner"},
+		},
+		overrides: map[string]db.PriceOverride{
+			"bags": {Label: "bags", Currency: "EUR", PriceReg: &partner},
+		},
+	}
+	tiers := []models.Tier{
+		{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &low},
+		{Tier: 2, Tags: []string{"premium"}, Currency: "USD", PriceReg: &high},
+	}
+
+	var buf bytes.Buffer
+	var phases []string
+	result, err := GenerateTo(store, tiers, &buf, Options{
+		Format:      "cnic-new",
+		TLD:         "shop",
+		ExcludeTags: []string{"registered"},
+		Progress:    func(u progress.Update) { phases = append(phases, u.Phase) },
+	})
+	if err != nil {
+		t.Fatalf("GenerateTo: %v", err)
+	}
+	if result.Entries != 2 || result.Excluded != 1 || result.TierCounts[2] != 1 || result.TierCounts[0] != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if result.SizeBytes != int64(buf.Len()) || len(result.SHA256) != 64 {
+		t.Errorf("size, checksum = %d, %q for %d bytes written", result.SizeBytes, result.SHA256, buf.Len())
+	}
+	if strings.Join(phases, ",") != "matching,writing,done" {
+		t.Errorf("phases = %v", phases)
+	}
+	for _, line := range []string{"shoes,shop,Registration,USD,500.00\n", "bags,shop,Registration,EUR,75.00\n"} {
+		if !bytes.Contains(buf.Bytes(), []byte(line)) {
+			t.Errorf("output lacks %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestGenerateFromTiers_Canceled(t *testing.T) {
+	price := 100.0
+	store := &memStore{labels: map[string][]string{"shoes": {"fashion"}}}
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path := filepath.Join(t.TempDir(), "premium.csv")
+	if _, err := GenerateFromTiers(store, tiers, path, Options{Format: "default", Context: ctx}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("canceled output was left behind: %v", err)
+	}
+}
+
+func TestGenerateTo_Reproducible(t *testing.T) {
+	price := 100.0
+	labels := make(map[string][]string)
+	for _, l := range []string{"shoes", "hats", "bags", "socks", "belts", "coats"} {
+		labels[l] = []string{"fashion"}
+	}
+	store := &memStore{labels: labels}
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+	opts := Options{Format: "default", Reproducible: true, Metadata: []string{"generated by test"}}
+
+	var first, second bytes.Buffer
+	if _, err := GenerateTo(store, tiers, &first, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateTo(store, tiers, &second, opts); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("outputs differ:\n%s\n%s", first.String(), second.String())
+	}
+	want := "# generated by test\nLabel,Tier,price_reg,price_ren,price_res,currency\nbags,1,100.00,,,USD\nbelts,1,100.00,,,USD\n"
+	if !strings.HasPrefix(first.String(), want) {
+		t.Errorf("output =\n%s\nwant prefix\n%s", first.String(), want)
+	}
+
+	if _, err := GenerateTo(store, tiers, &first, Options{Format: "xlsx", Reproducible: true}); err == nil {
+		t.Error("expected an error for a reproducible workbook")
+	}
+}
+
+func TestGenerateFromTiers_LineEndings(t *testing.T) {
+	price := 100.0
+	store := &memStore{labels: map[string][]string{"bags": {"fashion"}}}
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+
+	// Verified, so the list must read back despite the BOM before the metadata comment
+	out := filepath.Join(t.TempDir(), "list.csv")
+	opts := Options{Format: "cnic-new", TLD: "shop", CRLF: true, BOM: true, Metadata: []string{"generated by test"}, Verify: true}
+	if _, err := GenerateFromTiers(store, tiers, out, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeff# generated by test\r\nlabel,suffix,type,currency,amount\r\nbags,shop,Registration,USD,100.00\r\n"
+	if string(data) != want {
+		t.Errorf("output = %q, want %q", data, want)
+	}
+	entries, err := LoadPremiumList(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries["bags"] == nil {
+		t.Errorf("entries = %v, want bags", entries)
+	}
+
+	if _, err := GenerateTo(store, tiers, &bytes.Buffer{}, Options{Format: "xlsx", BOM: true}); err == nil {
+		t.Error("expected an error for a workbook with a BOM")
+	}
+}
+
+func TestGenerateFromTiers_Quoting(t *testing.T) {
+	price := 100.0
+	store := &memStore{labels: map[string][]string{"bags": {"fashion"}}}
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+
+	out := filepath.Join(t.TempDir(), "list.csv")
+	if _, err := GenerateFromTiers(store, tiers, out, Options{Quoting: csvout.Quoting{Mode: csvout.QuoteAll}, Verify: true}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"Label","Tier","price_reg","price_ren","price_res","currency"` + "\n" + `"bags","1","100.00","","","USD"` + "\n"
+	if string(data) != want {
+		t.Errorf("output = %q, want %q", data, want)
+	}
+
+	// Prices with a comma separator can't be written unquoted
+	comma := PriceFormat{Decimals: 2, DecimalSep: ","}
+	opts := Options{PriceFormat: &comma, Quoting: csvout.Quoting{Mode: csvout.QuoteNone}}
+	if _, err := GenerateTo(store, tiers, &bytes.Buffer{}, opts); !errors.Is(err, csvout.ErrNeedsQuoting) {
+		t.Errorf("err = %v, want ErrNeedsQuoting", err)
+	}
+}
+
+func TestGenerateTo_Where(t *testing.T) {
+	price := 100.0
+	store := &memStore{labels: map[string][]string{
+		"shoes": {"fashion", "len:5"},
+		"hats":  {"fashion", "len:4"},
+		"paris": {"city:paris", "len:5"},
+	}}
+	tiers := []models.Tier{{Tier: 1, Tags: []string{"fashion", "city:paris"}, Currency: "USD", PriceReg: &price}}
+
+	var buf bytes.Buffer
+	result, err := GenerateTo(store, tiers, &buf, Options{Format: "default", Where: tagexpr.MustParse("(fashion OR city:*) AND len >= 5")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Entries != 2 || result.Excluded != 1 || strings.Contains(buf.String(), "hats") {
+		t.Errorf("result = %+v, output:\n%s", result, buf.String())
+	}
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PriceFormat controls how prices are written, as some registry portals expect e.g. 1.000,00 or integer cents
+type PriceFormat struct {
+	Decimals     int    // Digits after the decimal separator, or the exponent of the minor unit
+	DecimalSep   string // Decimal separator, "." or ","
+	ThousandsSep string // Separator between groups of thousands, empty for none
+	MinorUnits   bool   // Write integers in minor units, e.g. 1000.00 as 100000 with 2 decimals
+}
+
+// DefaultPriceFormat writes 1234.50
+var DefaultPriceFormat = PriceFormat{Decimals: 2, DecimalSep: "."}
+
+// Validate checks that prices written in the format can be read back unambiguously
+func (f PriceFormat) Validate() error {
+	if f.Decimals < 0 || f.Decimals > 6 {
+		return fmt.Errorf("decimals must be between 0 and 6")
+	}
+	if f.MinorUnits {
+		return nil
+	}
+	if f.DecimalSep == "" {
+		return fmt.Errorf("decimal separator is required")
+	}
+	if f.ThousandsSep == f.DecimalSep {
+		return fmt.Errorf("thousands and decimal separators must differ")
+	}
+	return nil
+}
+
+// Format formats a price, never as a negative zero
+func (f PriceFormat) Format(p float64) string {
+	if f.MinorUnits {
+		minor := math.Round(p * math.Pow10(f.Decimals))
+		if minor == 0 {
+			minor = 0 // Drops the sign of negative zero
+		}
+		return strconv.FormatFloat(minor, 'f', 0, 64)
+	}
+	if math.Abs(p) < 0.5*math.Pow10(-f.Decimals) {
+		p = 0 // Rounds to zero; avoids -0.00
+	}
+	s := strconv.FormatFloat(p, 'f', f.Decimals, 64)
+	if f == DefaultPriceFormat {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if f.ThousandsSep != "" {
+		whole = groupThousands(whole, f.ThousandsSep)
+	}
+	if frac != "" {
+		return sign + whole + f.DecimalSep + frac
+	}
+	return sign + whole
+}
+
+// formatPtr formats an optional price, empty if unset
+func (f PriceFormat) formatPtr(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return f.Format(*p)
+}
+
+// excelValue returns the value of a price cell: the price, or an integer in minor units
+func (f PriceFormat) excelValue(p float64) float64 {
+	if f.MinorUnits {
+		return math.Round(p * math.Pow10(f.Decimals))
+	}
+	return p
+}
+
+// excelNumFmt returns the Excel number format of the prices
+// Excel shows its separators in the locale of the viewer, so only decimals and grouping apply
+func (f PriceFormat) excelNumFmt() string {
+	format := "0"
+	if f.ThousandsSep != "" {
+		format = "#,##0"
+	}
+	if !f.MinorUnits && f.Decimals > 0 {
+		format += "." + strings.Repeat("0", f.Decimals)
+	}
+	return format
+}
+
+// groupThousands inserts sep between groups of three digits
+func groupThousands(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	first := len(digits) % 3
+	if first > 0 {
+		b.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package generator
+
+import "testing"
+
+func TestPriceFormat(t *testing.T) {
+	tests := []struct {
+		format PriceFormat
+		price  float64
+		want   string
+	}{
+		{DefaultPriceFormat, 1234.5, "1234.50"},
+		{DefaultPriceFormat, -0.0001, "0.00"},
+		{PriceFormat{Decimals: 2, DecimalSep: ",", ThousandsSep: "."}, 1000, "1.000,00"},
+		{PriceFormat{Decimals: 2, DecimalSep: ",", ThousandsSep: "."}, 1234567.891, "1.234.567,89"},
+		{PriceFormat{Decimals: 0, DecimalSep: ".", ThousandsSep: ","}, 999.5, "1,000"},
+		{PriceFormat{Decimals: 3, DecimalSep: "."}, -12.5, "-12.500"},
+		{PriceFormat{Decimals: 2, MinorUnits: true}, 1000, "100000"},
+		{PriceFormat{Decimals: 2, MinorUnits: true}, 19.99, "1999"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.Format(tt.price); got != tt.want {
+			t.Errorf("%+v.Format(%v) = %q, want %q", tt.format, tt.price, got, tt.want)
+		}
+	}
+
+	if err := (PriceFormat{Decimals: 2, DecimalSep: ",", ThousandsSep: ","}).Validate(); err == nil {
+		t.Error("expected an error for equal separators")
+	}
+}
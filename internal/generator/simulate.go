@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+// Assumptions drive the projected revenue of a simulation
+type Assumptions struct {
+	SellThrough float64 `json:"sell_through"` // Share of the names registered in the first year
+	RenewalRate float64 `json:"renewal_rate"` // Share of the registered names renewed every following year
+	Years       int     `json:"years"`        // Years of revenue to project
+}
+
+// Validate checks that the assumptions are shares and a positive number of years
+func (a Assumptions) Validate() error {
+	if a.SellThrough < 0 || a.SellThrough > 1 || a.RenewalRate < 0 || a.RenewalRate > 1 {
+		return fmt.Errorf("sell-through and renewal rate must be between 0 and 1")
+	}
+	if a.Years < 1 {
+		return fmt.Errorf("years must be at least 1")
+	}
+	return nil
+}
+
+// TierSimulation is what a tier would capture in one currency
+type TierSimulation struct {
+	Tier     int     `json:"tier"` // 0 for labels priced by an override only
+	Currency string  `json:"currency"`
+	Labels   int     `json:"labels"`
+	Revenue  float64 `json:"revenue"` // Projected over the years of the assumptions
+}
+
+// Simulation is the outcome of a tiers configuration without writing a list
+type Simulation struct {
+	Assumptions Assumptions        `json:"assumptions"`
+	Tiers       []TierSimulation   `json:"tiers"` // Highest tier first
+	Overlaps    []TierOverlap      `json:"overlaps"`
+	Entries     int                `json:"entries"`
+	Excluded    int                `json:"excluded"`
+	Unmatched   int                `json:"unmatched"`
+	Revenue     map[string]float64 `json:"revenue"` // Total per currency
+}
+
+// Simulate reports how many labels each tier would capture and their projected revenue
+// opts.ExcludeTags, opts.Where, opts.Currency and opts.Rates apply as they would to the generated list
+func Simulate(store db.Store, tiers []models.Tier, opts Options, a Assumptions) (*Simulation, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	entries, excluded, unmatched, err := matchLabels(store, tiers, opts.ExcludeTags, opts.Where)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Currency != "" {
+		if err := ConvertEntries(entries, opts.Currency, opts.Rates); err != nil {
+			return nil, err
+		}
+	}
+	overlaps, err := AnalyzeOverlaps(store, tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{
+		Assumptions: a,
+		Tiers:       make([]TierSimulation, 0),
+		Overlaps:    overlaps,
+		Entries:     len(entries),
+		Excluded:    excluded,
+		Unmatched:   unmatched,
+		Revenue:     make(map[string]float64),
+	}
+	type key struct {
+		tier     int
+		currency string
+	}
+	byKey := make(map[key]*TierSimulation)
+	for _, e := range entries {
+		currency := strings.ToUpper(e.Currency)
+		k := key{e.Tier, currency}
+		ts, ok := byKey[k]
+		if !ok {
+			ts = &TierSimulation{Tier: e.Tier, Currency: currency}
+			byKey[k] = ts
+		}
+		revenue := a.expectedRevenue(e)
+		ts.Labels++
+		ts.Revenue += revenue
+		sim.Revenue[currency] += revenue
+	}
+
+	for _, ts := range byKey {
+		ts.Revenue = math.Round(ts.Revenue*100) / 100
+		sim.Tiers = append(sim.Tiers, *ts)
+	}
+	for currency, revenue := range sim.Revenue {
+		sim.Revenue[currency] = math.Round(revenue*100) / 100
+	}
+	sort.Slice(sim.Tiers, func(i, j int) bool {
+		if sim.Tiers[i].Tier != sim.Tiers[j].Tier {
+			return sim.Tiers[i].Tier > sim.Tiers[j].Tier
+		}
+		return sim.Tiers[i].Currency < sim.Tiers[j].Currency
+	})
+	return sim, nil
+}
+
+// expectedRevenue is the expected revenue of a name: the registration price if it sells in the
+// first year, then the renewal price for every year it is still renewed
+func (a Assumptions) expectedRevenue(e PremiumListEntry) float64 {
+	var revenue float64
+	if e.PriceReg != nil {
+		revenue += a.SellThrough * *e.PriceReg
+	}
+	if e.PriceRen != nil {
+		retained := a.SellThrough
+		for year := 2; year <= a.Years; year++ {
+			retained *= a.RenewalRate
+			revenue += retained * *e.PriceRen
+		}
+	}
+	return revenue
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"testing"
+
+	"premium-list-maker/internal/models"
+)
+
+func TestSimulate(t *testing.T) {
+	reg, ren, high := 100.0, 50.0, 1000.0
+	store := &memStore{labels: map[string][]string{
+		"shoes": {"fashion", "premium"},
+		"hats":  {"fashion"},
+		"bags":  {"fashion"},
+		"taken": {"fashion", "registered"},
+	}}
+	tiers := []models.Tier{
+		{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &reg, PriceRen: &ren},
+		{Tier: 2, Tags: []string{"premium"}, Currency: "USD", PriceReg: &high},
+	}
+
+	sim, err := Simulate(store, tiers, Options{ExcludeTags: []string{"registered"}}, Assumptions{SellThrough: 0.1, RenewalRate: 0.5, Years: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sim.Tiers) != 2 || sim.Entries != 3 || sim.Excluded != 1 {
+		t.Fatalf("simulation = %+v", sim)
+	}
+	// Tier 2: 0.1 * 1000 for shoes; tier 1: 2 * (0.1*100 + 0.05*50 + 0.025*50)
+	if tier := sim.Tiers[0]; tier.Tier != 2 || tier.Labels != 1 || tier.Revenue != 100 {
+		t.Errorf("tier 2 = %+v", tier)
+	}
+	if tier := sim.Tiers[1]; tier.Tier != 1 || tier.Labels != 2 || tier.Revenue != 27.5 {
+		t.Errorf("tier 1 = %+v", tier)
+	}
+	if sim.Revenue["USD"] != 127.5 {
+		t.Errorf("revenue = %v", sim.Revenue)
+	}
+	if len(sim.Overlaps) != 1 || sim.Overlaps[0].Labels != 1 {
+		t.Errorf("overlaps = %+v", sim.Overlaps)
+	}
+
+	if _, err := Simulate(store, tiers, Options{}, Assumptions{SellThrough: 2, Years: 1}); err == nil {
+		t.Error("expected an error for a sell-through above 1")
+	}
+}
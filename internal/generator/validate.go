@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"premium-list-maker/internal/models"
+)
+
+// TierValidation holds the problems found in a tiers configuration
+// Errors make the configuration unusable, warnings point at likely mistakes
+type TierValidation struct {
+	Valid    bool          `json:"valid"`
+	Errors   []string      `json:"errors"`
+	Warnings []string      `json:"warnings"`
+	Overlaps []TierOverlap `json:"overlaps,omitempty"` // Labels matching several tiers, if the database was analyzed
+}
+
+// ValidateTiers checks a tiers configuration for structural problems
+// If knownTags is not nil, tags that don't exist in the database are reported as warnings
+func ValidateTiers(tiers []models.Tier, knownTags map[string]bool) *TierValidation {
+	v := &TierValidation{
+		Errors:   make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+
+	if len(tiers) == 0 {
+		v.Errors = append(v.Errors, "no tiers defined")
+	}
+
+	seenTiers := make(map[int]bool)
+	tagTiers := make(map[string][]int)
+	var tagOrder []string
+	for i, tier := range tiers {
+		name := fmt.Sprintf("tier %d (entry %d)", tier.Tier, i+1)
+
+		if seenTiers[tier.Tier] {
+			v.Errors = append(v.Errors, fmt.Sprintf("%s: duplicate tier number", name))
+		}
+		seenTiers[tier.Tier] = true
+
+		if len(tier.Tags) == 0 {
+			v.Errors = append(v.Errors, fmt.Sprintf("%s: no tags", name))
+		}
+
+		prices := map[string]*float64{"price_reg": tier.PriceReg, "price_ren": tier.PriceRen, "price_res": tier.PriceRes}
+		hasPrice := false
+		for _, field := range []string{"price_reg", "price_ren", "price_res"} {
+			price := prices[field]
+			if price == nil {
+				continue
+			}
+			hasPrice = true
+			if *price < 0 {
+				v.Errors = append(v.Errors, fmt.Sprintf("%s: negative %s", name, field))
+			}
+		}
+
+		if !hasPrice {
+			v.Warnings = append(v.Warnings, fmt.Sprintf("%s: no prices set", name))
+		} else if tier.Currency == "" {
+			v.Errors = append(v.Errors, fmt.Sprintf("%s: currency is required when prices are set", name))
+		}
+
+		for _, tag := range tier.Tags {
+			claimed, ok := tagTiers[tag]
+			if !ok {
+				tagOrder = append(tagOrder, tag)
+			}
+			if len(claimed) == 0 || claimed[len(claimed)-1] != tier.Tier {
+				tagTiers[tag] = append(claimed, tier.Tier)
+			}
+		}
+
+		if knownTags != nil {
+			for _, tag := range tier.Tags {
+				if !knownTags[tag] {
+					v.Warnings = append(v.Warnings, fmt.Sprintf("%s: tag %q doesn't exist in the database", name, tag))
+				}
+			}
+		}
+	}
+
+	// The highest tier wins a label, so a tag in several tiers only prices at the highest
+	for _, tag := range tagOrder {
+		claimed := tagTiers[tag]
+		if len(claimed) < 2 {
+			continue
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(claimed)))
+		v.Warnings = append(v.Warnings, fmt.Sprintf("tag %q is claimed by tiers %s; tier %d wins", tag, joinInts(claimed, ", "), claimed[0]))
+	}
+
+	v.Valid = len(v.Errors) == 0
+	return v
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// ErrDatabaseChanged is returned by a verified generation when the labels, tags or price overrides
+// changed while the list was generated, e.g. by a concurrent import, so the list is out of date
+var ErrDatabaseChanged = errors.New("database changed during generation")
+
+// ErrInvalidOutput is returned by a verified generation when the written list breaks an invariant
+var ErrInvalidOutput = errors.New("generated list failed verification")
+
+// maxVerifyExamples caps the labels named in a verification error
+const maxVerifyExamples = 5
+
+// entryKey identifies what an entry was matched to, before any currency conversion
+func entryKey(e PremiumListEntry) string {
+	return fmt.Sprintf("%d|%s|%s|%s|%s", e.Tier, floatPtrToString(e.PriceReg), floatPtrToString(e.PriceRen), floatPtrToString(e.PriceRes), e.Currency)
+}
+
+// entryKeys maps the label of every entry to its entryKey, failing if a label appears twice
+func entryKeys(entries []PremiumListEntry) (map[string]string, error) {
+	keys := make(map[string]string, len(entries))
+	var dups []string
+	for _, e := range entries {
+		if _, ok := keys[e.Label]; ok {
+			dups = append(dups, e.Label)
+			continue
+		}
+		keys[e.Label] = entryKey(e)
+	}
+	if len(dups) > 0 {
+		return nil, fmt.Errorf("%w: %d label(s) listed twice: %s", ErrInvalidOutput, len(dups), examples(dups))
+	}
+	return keys, nil
+}
+
+// verifyUnchanged matches the labels again and compares them with the entries matched before writing
+// A label that was removed, lost or gained a tag that changes its tier, or got another override fails the check
+// The labels found again are deleted from matched, so what is left was removed
+func verifyUnchanged(store db.Store, tiers []models.Tier, excludeTags []string, where tagexpr.Expr, matched map[string]string) error {
+	var changed []string
+	_, _, err := eachMatch(store, tiers, excludeTags, where, func(e PremiumListEntry) error {
+		key, ok := matched[e.Label]
+		if !ok {
+			changed = append(changed, e.Label+" (added)")
+			return nil
+		}
+		delete(matched, e.Label)
+		if key != entryKey(e) {
+			changed = append(changed, e.Label+" (repriced)")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify list: %w", err)
+	}
+	for label := range matched {
+		changed = append(changed, label+" (removed)")
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return fmt.Errorf("%w: %d label(s) differ from the list, e.g. %s; generate again once the writes are done",
+			ErrDatabaseChanged, len(changed), examples(changed))
+	}
+	return nil
+}
+
+// verifyOutput reads a written CSV list back and checks that it has want labels and no label twice
+// cnic-new lists have a row per price type, so there a label and type must be unique instead
+func verifyOutput(path, format string, want int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to verify list: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(skipBOM(file))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.ReuseRecord = true
+	if _, err := reader.Read(); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to verify list: %w", err)
+	}
+
+	seen := make(map[string]bool, want)
+	labels := make(map[string]bool, want)
+	var dups []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to verify list: %w", err)
+		}
+		key := record[0]
+		if format == "cnic-new" && len(record) > 2 {
+			key += "\x00" + record[2]
+		}
+		if seen[key] {
+			dups = append(dups, record[0])
+		}
+		seen[key] = true
+		labels[record[0]] = true
+	}
+	if len(dups) > 0 {
+		return fmt.Errorf("%w: %d label(s) listed twice: %s", ErrInvalidOutput, len(dups), examples(dups))
+	}
+	if len(labels) != want {
+		return fmt.Errorf("%w: %d label(s) written, %d matched", ErrInvalidOutput, len(labels), want)
+	}
+	return nil
+}
+
+// examples lists the first few of items for an error message
+func examples(items []string) string {
+	if len(items) <= maxVerifyExamples {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxVerifyExamples], ", "), len(items)-maxVerifyExamples)
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+// changingStore is a memStore whose labels are replaced after the first read, like a concurrent import would
+type changingStore struct {
+	memStore
+	next  map[string][]string
+	reads int
+}
+
+func (s *changingStore) GetAllLabelsWithTags() (map[string][]string, error) {
+	s.reads++
+	if s.reads > 1 {
+		return s.next, nil
+	}
+	return s.labels, nil
+}
+
+func (s *changingStore) EachLabelWithTags(fn func(label string, tags []string) error) error {
+	labels, _ := s.GetAllLabelsWithTags()
+	return eachSorted(labels, fn)
+}
+
+func TestGenerateFromTiers_Verify(t *testing.T) {
+	low, high := 100.0, 500.0
+	tiers := []models.Tier{
+		{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &low},
+		{Tier: 2, Tags: []string{"premium"}, Currency: "USD", PriceReg: &high},
+	}
+	labels := map[string][]string{
+		"shoes": {"fashion", "premium"},
+		"hats":  {"fashion"},
+	}
+
+	for _, format := range []string{"default", "cnic-new"} {
+		out := filepath.Join(t.TempDir(), "list.csv")
+		store := &memStore{labels: labels}
+		result, err := GenerateFromTiers(store, tiers, out, Options{Format: format, TLD: "shop", Verify: true})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if result.Entries != 2 {
+			t.Errorf("%s: result = %+v", format, result)
+		}
+	}
+
+	// hats lost its tag and bags was added while the list was written
+	store := &changingStore{
+		memStore: memStore{labels: labels},
+		next: map[string][]string{
+			"shoes": {"fashion", "premium"},
+			"hats":  {},
+			"bags":  {"fashion"},
+		},
+	}
+	out := filepath.Join(t.TempDir(), "list.csv")
+	_, err := GenerateFromTiers(store, tiers, out, Options{Verify: true})
+	if !errors.Is(err, ErrDatabaseChanged) {
+		t.Fatalf("err = %v, want ErrDatabaseChanged", err)
+	}
+	if !strings.Contains(err.Error(), "2 label(s) differ") || !strings.Contains(err.Error(), "bags (added)") || !strings.Contains(err.Error(), "hats (removed)") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("list of a changed database was written: %v", err)
+	}
+
+	// A price override set during the generation reprices its label
+	price := 50.0
+	matched, err := entryKeys([]PremiumListEntry{{Label: "shoes", Tier: 2, PriceReg: &high, Currency: "USD"}, {Label: "hats", Tier: 1, PriceReg: &low, Currency: "USD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overridden := &memStore{
+		labels:    labels,
+		overrides: map[string]db.PriceOverride{"shoes": {Label: "shoes", Currency: "USD", PriceReg: &price}},
+	}
+	if err := verifyUnchanged(overridden, tiers, nil, nil, matched); !errors.Is(err, ErrDatabaseChanged) || !strings.Contains(err.Error(), "shoes (repriced)") {
+		t.Errorf("err = %v, want shoes repriced", err)
+	}
+}
+
+func TestVerifyOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.csv")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("# tiers: abc\nLabel,Tier,price_reg,price_ren,price_res,currency\nshoes,1,100.00,,,USD\nhats,1,100.00,,,USD\n")
+	if err := verifyOutput(path, "", 2); err != nil {
+		t.Errorf("valid list: %v", err)
+	}
+
+	write("Label,Tier,price_reg,price_ren,price_res,currency\nshoes,1,100.00,,,USD\nshoes,1,100.00,,,USD\n")
+	if err := verifyOutput(path, "", 2); !errors.Is(err, ErrInvalidOutput) || !strings.Contains(err.Error(), "listed twice: shoes") {
+		t.Errorf("duplicate label: err = %v", err)
+	}
+
+	write("Label,Tier,price_reg,price_ren,price_res,currency\nshoes,1,100.00,,,USD\n")
+	if err := verifyOutput(path, "", 2); !errors.Is(err, ErrInvalidOutput) || !strings.Contains(err.Error(), "1 label(s) written, 2 matched") {
+		t.Errorf("missing label: err = %v", err)
+	}
+
+	// A label has a row per price type in cnic-new lists
+	write("label,suffix,type,currency,amount\nshoes,shop,Registration,USD,100.00\nshoes,shop,Renewal,USD,100.00\nhats,shop,Registration,USD,100.00\n")
+	if err := verifyOutput(path, "cnic-new", 2); err != nil {
+		t.Errorf("valid cnic-new list: %v", err)
+	}
+}
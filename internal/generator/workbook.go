@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WorkbookSummarySheet is the name of the first sheet of an xlsx premium list
+const WorkbookSummarySheet = "Summary"
+
+// tierSheetName returns the sheet name of a tier; labels priced by an override without a tier go to "No tier"
+func tierSheetName(tier int) string {
+	if tier == 0 {
+		return "No tier"
+	}
+	return fmt.Sprintf("Tier %d", tier)
+}
+
+// writeXLSX writes the premium list as a workbook for approval: a summary sheet with one row per tier
+// and currency, then one sheet per tier (highest first) listing every label with its tags and prices
+func writeXLSX(entries []PremiumListEntry, labelTags map[string][]string, w io.Writer, tld string, prices PriceFormat) error {
+	byTier := make(map[int][]PremiumListEntry)
+	for _, e := range entries {
+		byTier[e.Tier] = append(byTier[e.Tier], e)
+	}
+	tierNums := make([]int, 0, len(byTier))
+	for tier := range byTier {
+		tierNums = append(tierNums, tier)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(tierNums)))
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}}})
+	if err != nil {
+		return err
+	}
+	numFmt := prices.excelNumFmt()
+	price, err := f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+	if err != nil {
+		return err
+	}
+	if err := f.SetSheetName("Sheet1", WorkbookSummarySheet); err != nil {
+		return err
+	}
+
+	// Summary: one row per tier and currency
+	summary, err := f.NewStreamWriter(WorkbookSummarySheet)
+	if err != nil {
+		return err
+	}
+	title := "Premium list"
+	if tld != "" {
+		title += " ." + strings.TrimPrefix(tld, ".")
+	}
+	summary.SetRow("A1", []interface{}{excelize.Cell{StyleID: header, Value: title}})
+	summary.SetRow("A2", []interface{}{"Generated", time.Now().UTC().Format("2006-01-02 15:04 MST")})
+	summary.SetRow("A3", []interface{}{"Names", len(entries)})
+	summary.SetRow("A5", headerCells(header, "Tier", "Names", "Currency", "Registration", "Renewal", "Restore"))
+	row := 6
+	for _, tier := range tierNums {
+		for _, group := range groupByCurrency(byTier[tier]) {
+			summary.SetRow(fmt.Sprintf("A%d", row), []interface{}{
+				tierSheetName(tier),
+				len(group),
+				group[0].Currency,
+				priceRange(group, prices, func(e PremiumListEntry) *float64 { return e.PriceReg }),
+				priceRange(group, prices, func(e PremiumListEntry) *float64 { return e.PriceRen }),
+				priceRange(group, prices, func(e PremiumListEntry) *float64 { return e.PriceRes }),
+			})
+			row++
+		}
+	}
+	summary.SetColWidth(1, 1, 14)
+	summary.SetColWidth(4, 6, 16)
+	if err := summary.Flush(); err != nil {
+		return err
+	}
+
+	// One sheet per tier, sorted by label
+	for _, tier := range tierNums {
+		name := tierSheetName(tier)
+		if _, err := f.NewSheet(name); err != nil {
+			return err
+		}
+		sw, err := f.NewStreamWriter(name)
+		if err != nil {
+			return err
+		}
+		sw.SetColWidth(1, 1, 24)
+		sw.SetColWidth(2, 2, 40)
+		sw.SetColWidth(3, 5, 14)
+		sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if err := sw.SetRow("A1", headerCells(header, "Label", "Tags", "Registration", "Renewal", "Restore", "Currency")); err != nil {
+			return err
+		}
+
+		tierEntries := byTier[tier]
+		sort.Slice(tierEntries, func(i, j int) bool { return tierEntries[i].Label < tierEntries[j].Label })
+		for i, e := range tierEntries {
+			tags := append([]string(nil), labelTags[e.Label]...)
+			sort.Strings(tags)
+			cells := []interface{}{e.Label, strings.Join(tags, ", "), priceCell(price, prices, e.PriceReg), priceCell(price, prices, e.PriceRen), priceCell(price, prices, e.PriceRes), e.Currency}
+			if err := sw.SetRow(fmt.Sprintf("A%d", i+2), cells); err != nil {
+				return err
+			}
+		}
+		if err := sw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	f.SetActiveSheet(0)
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to save workbook: %w", err)
+	}
+	return nil
+}
+
+func headerCells(style int, names ...string) []interface{} {
+	cells := make([]interface{}, len(names))
+	for i, name := range names {
+		cells[i] = excelize.Cell{StyleID: style, Value: name}
+	}
+	return cells
+}
+
+// priceCell returns a formatted price cell, or an empty cell if the price is unset
+func priceCell(style int, prices PriceFormat, p *float64) interface{} {
+	if p == nil {
+		return nil
+	}
+	return excelize.Cell{StyleID: style, Value: prices.excelValue(*p)}
+}
+
+// groupByCurrency splits the entries of a tier by currency, in currency order
+func groupByCurrency(entries []PremiumListEntry) [][]PremiumListEntry {
+	byCurrency := make(map[string][]PremiumListEntry)
+	var currencies []string
+	for _, e := range entries {
+		if _, ok := byCurrency[e.Currency]; !ok {
+			currencies = append(currencies, e.Currency)
+		}
+		byCurrency[e.Currency] = append(byCurrency[e.Currency], e)
+	}
+	sort.Strings(currencies)
+	groups := make([][]PremiumListEntry, len(currencies))
+	for i, c := range currencies {
+		groups[i] = byCurrency[c]
+	}
+	return groups
+}
+
+// priceRange describes the prices of a group: the price if all entries share it, "min – max" if price
+// overrides make them differ, empty if none is set
+func priceRange(entries []PremiumListEntry, prices PriceFormat, price func(PremiumListEntry) *float64) string {
+	var min, max *float64
+	for _, e := range entries {
+		p := price(e)
+		if p == nil {
+			continue
+		}
+		if min == nil || *p < *min {
+			min = p
+		}
+		if max == nil || *p > *max {
+			max = p
+		}
+	}
+	switch {
+	case min == nil:
+		return ""
+	case *min == *max:
+		return prices.Format(*min)
+	default:
+		return prices.Format(*min) + " – " + prices.Format(*max)
+	}
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	low, high := 100.0, 250.0
+	entries := []PremiumListEntry{
+		{Label: "shoes", Tier: 2, PriceReg: &high, Currency: "USD"},
+		{Label: "bags", Tier: 2, PriceReg: &low, Currency: "USD"},
+		{Label: "hats", Tier: 1, PriceReg: &low, Currency: "USD"},
+	}
+	tags := map[string][]string{"bags": {"retail", "4 letter"}}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(entries, tags, &buf, "shop", DefaultPriceFormat); err != nil {
+		t.Fatalf("writeXLSX: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if sheets := f.GetSheetList(); len(sheets) != 3 || sheets[0] != "Summary" || sheets[1] != "Tier 2" || sheets[2] != "Tier 1" {
+		t.Fatalf("sheets = %v", sheets)
+	}
+	rows, _ := f.GetRows("Summary")
+	if got := rows[5]; got[0] != "Tier 2" || got[1] != "2" || got[3] != "100.00 – 250.00" {
+		t.Errorf("summary row = %v", got)
+	}
+	rows, _ = f.GetRows("Tier 2")
+	if len(rows) != 3 || rows[1][0] != "bags" || rows[1][1] != "4 letter, retail" || rows[2][0] != "shoes" {
+		t.Errorf("tier 2 rows = %v", rows)
+	}
+}
@@ -1,15 +1,21 @@
 package importer
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
-	"runtime"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	dbpkg "premium-list-maker/internal/db"
+	"premium-list-maker/internal/metrics"
+	"premium-list-maker/internal/progress"
 	"premium-list-maker/internal/tagger"
 )
 
@@ -24,56 +30,101 @@ type ImportStats struct {
 	ExistingLabels int // Labels that already existed
 	Skipped        int
 	HeaderSkipped  bool
-	Errors         []string
+	Errors         []ImportError
 	StartTime      time.Time
 	MaxMemoryMB    uint64
+	Canceled       bool     // The import was canceled; the counts cover the committed labels only
+	SkippedSheets  []string // Workbook sheets that don't appear to contain labels
+	Resumed        int      // Lines skipped because an interrupted import of the file committed them
 }
 
 // ImportCSV imports labels from a CSV file into the database
-// The CSV should have labels in the first column
-// If autoTag is true, automatically adds length-based tags (len:N)
-// If filenameTag is not empty, adds that tag to all imported labels
+// The CSV should have labels in the first column, or the one WithLabelColumn selects; options select tagging, batching and validation
 // Returns ImportStats with detailed statistics
-// Uses optimized bulk inserts with pre-loaded data for maximum performance
-func ImportCSV(db *dbpkg.DB, csvPath string, autoTag bool, filenameTag string) (*ImportStats, error) {
-	stats := &ImportStats{
-		StartTime: time.Now(),
-		Errors:    make([]string, 0),
-	}
+// Uses optimized bulk inserts; existing labels are looked up per batch, so memory stays flat as the database grows
+func ImportCSV(db dbpkg.Store, csvPath string, opts ...ImportOption) (*ImportStats, error) {
+	return importFile(db, csvPath, "CSV", ImportCSVReader, opts)
+}
 
-	file, err := os.Open(csvPath)
+// ImportCSVReader is ImportCSV reading the CSV from r, e.g. an upload or an in-memory buffer
+func ImportCSVReader(db dbpkg.Store, r io.Reader, opts ...ImportOption) (*ImportStats, error) {
+	o, err := newImportOptions(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
+	// Refuse obvious non-CSV input before it turns into millions of parse errors
+	buffered := bufio.NewReaderSize(r, sniffSize)
+	sample, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if err := sniffCSV(sample); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(buffered)
 	// Allow variable number of fields per record
 	reader.FieldsPerRecord = -1
 	// Reuse record to reduce allocations
 	reader.ReuseRecord = true
 
+	return importRows(db, newCSVSource(reader, o), o)
+}
+
+// importRows imports the rows of an input format, validating, tagging and writing them in batches
+func importRows(db dbpkg.Store, src rowSource, o ImportOptions) (*ImportStats, error) {
+	validate, _ := validator(o.Validation)
+	autoTag, filenameTag, execTagger := o.AutoTag, o.Tag, o.ExecTagger
+
+	stats := &ImportStats{
+		StartTime: time.Now(),
+		Errors:    make([]ImportError, 0),
+	}
+
 	lineNum := 0
-	heartbeatInterval := 100000
-	batchSize := 10000       // Increased batch size for better performance
-	lastHeartbeatCount := 0  // Track last heartbeat to avoid duplicate messages
-	commitInterval := 100000 // Commit every 100K labels to reduce transaction size
+	commitInterval := o.CommitInterval
 
-	// Start single transaction for entire file
-	tx, err := db.BeginTransaction()
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	// Resume after the lines an interrupted import of the file committed
+	if o.checkpoint != "" {
+		resumed, err := db.ImportCheckpoint(o.checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		stats.Resumed = resumed
 	}
-	defer tx.Rollback()
 
-	// Pre-load all existing label IDs into memory
-	existingLabelMap, err := dbpkg.LoadAllLabelIDs(tx)
+	// Parse and tag on all cores while waiting for the transaction, which other imports may hold;
+	// the stages stop when the import returns
+	ctx := o.context()
+	pipelineCtx, stopPipeline := context.WithCancel(ctx)
+	defer stopPipeline()
+	chunks := make(chan rowChunk, o.Workers)
+	tagged := make(chan labelChunk, o.Workers)
+	go readChunks(pipelineCtx, src, o.BatchSize, stats.Resumed, chunks)
+	var workers sync.WaitGroup
+	for i := 0; i < o.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			tagChunks(pipelineCtx, chunks, tagged, validate, o)
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(tagged)
+	}()
+
+	// Start single transaction for entire file
+	tx, err := db.BeginImport()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load existing label IDs: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	// tx is replaced on every periodic commit, roll back the open one
+	defer func() { tx.Rollback() }()
 
 	// Pre-load all existing tag IDs into memory
-	existingTagMap, err := dbpkg.LoadAllTagIDs(tx)
+	existingTagMap, err := tx.TagIDs()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load existing tag IDs: %w", err)
 	}
@@ -87,7 +138,7 @@ func ImportCSV(db *dbpkg.DB, csvPath string, autoTag bool, filenameTag string) (
 				tagCache[lengthTag] = tagID
 			} else {
 				// Tag doesn't exist, create it
-				tagID, err := dbpkg.GetOrCreateTagTx(tx, lengthTag)
+				tagID, err := tx.GetOrCreateTag(lengthTag)
 				if err != nil {
 					return nil, fmt.Errorf("failed to create tag %s: %w", lengthTag, err)
 				}
@@ -104,7 +155,7 @@ func ImportCSV(db *dbpkg.DB, csvPath string, autoTag bool, filenameTag string) (
 			filenameTagID = tagID
 		} else {
 			// Tag doesn't exist, create it
-			tagID, err := dbpkg.GetOrCreateTagTx(tx, filenameTag)
+			tagID, err := tx.GetOrCreateTag(filenameTag)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create filename tag %s: %w", filenameTag, err)
 			}
@@ -113,209 +164,292 @@ func ImportCSV(db *dbpkg.DB, csvPath string, autoTag bool, filenameTag string) (
 		}
 	}
 
-	// Batch processing buffers
-	batch := make([]LabelData, 0, batchSize)
 	labelsProcessed := 0
 
-	// Process batch function - optimized to use pre-loaded maps and single transaction
-	processBatch := func() error {
+	// createdTags are the tags the current batch created, forgotten if its savepoint is rolled back
+	var createdTags []string
+
+	// lookupTagID resolves a tag ID from the cache, creating the tag if it doesn't exist yet
+	lookupTagID := func(tagName string) (int64, error) {
+		if tagID, ok := tagCache[tagName]; ok {
+			return tagID, nil
+		}
+		if tagID, exists := existingTagMap[tagName]; exists {
+			tagCache[tagName] = tagID
+			return tagID, nil
+		}
+		tagID, err := tx.GetOrCreateTag(tagName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create tag %s: %w", tagName, err)
+		}
+		tagCache[tagName] = tagID
+		existingTagMap[tagName] = tagID
+		createdTags = append(createdTags, tagName)
+		return tagID, nil
+	}
+
+	// committed holds the counts as of the last commit, reported if the import is canceled
+	committed := *stats
+
+	// canceled rolls back the open transaction and returns the stats as of the last commit
+	canceled := func() (*ImportStats, error) {
+		tx.Rollback()
+		stats.Imported, stats.NewLabels, stats.ExistingLabels = committed.Imported, committed.NewLabels, committed.ExistingLabels
+		stats.Canceled = true
+		return stats, fmt.Errorf("import canceled after %d labels: %w", stats.Imported, ctx.Err())
+	}
+
+	// Buffers of processBatch, reused across batches
+	var labelIDs []int64
+	var associations []TagAssociation
+	lengthTagIDs := make(map[int]int64)
+
+	// Write a validated and tagged chunk using the pre-loaded tags
+	processBatch := func(batch []LabelData, batchTags [][]string) error {
 		if len(batch) == 0 {
 			return nil
 		}
 
-		// Bulk insert labels using pre-loaded existingLabelMap
-		insertResult, err := db.BulkInsertLabels(tx, batch, existingLabelMap)
+		// Bulk insert labels; existing ones are looked up on the label index
+		insertResult, err := tx.InsertLabels(batch)
 		if err != nil {
 			return fmt.Errorf("failed to bulk insert labels: %w", err)
 		}
 
-		// Update existingLabelMap with newly inserted labels
-		for label, id := range insertResult.LabelMap {
-			existingLabelMap[label] = id
-		}
-
 		stats.NewLabels += insertResult.NewCount
 		stats.ExistingLabels += insertResult.ExistingCount
 		labelMap := insertResult.LabelMap
 
-		// Prepare tag associations using pre-loaded tag IDs
-		associations := make([]TagAssociation, 0, len(batch)*2) // Estimate: length tag + filename tag
+		// Ask the external tagger for tags for the whole batch at once
+		var externalTags [][]string
+		if execTagger != nil {
+			batchLabels := make([]string, len(batch))
+			for i, l := range batch {
+				batchLabels[i] = l.Label
+			}
+			externalTags, err = execTagger.TagBatch(batchLabels)
+			if err != nil {
+				return err
+			}
+		}
 
+		// Stage the batch to add the length and filename tags, which most labels get, with one statement each
+		labelIDs = labelIDs[:0]
 		for _, l := range batch {
+			if labelID, ok := labelMap[l.Label]; ok {
+				labelIDs = append(labelIDs, labelID)
+			}
+		}
+		if autoTag || filenameTag != "" {
+			if err := tx.StageLabels(labelIDs); err != nil {
+				return err
+			}
+		}
+		if autoTag {
+			clear(lengthTagIDs)
+			for _, l := range batch {
+				if _, ok := lengthTagIDs[l.Length]; ok {
+					continue
+				}
+				tagID, err := lookupTagID(tagger.GenerateLengthTag(l.Length))
+				if err != nil {
+					return err
+				}
+				lengthTagIDs[l.Length] = tagID
+			}
+			if err := tx.TagStagedByLength(lengthTagIDs); err != nil {
+				return err
+			}
+		}
+		if filenameTag != "" {
+			if err := tx.TagStaged(filenameTagID); err != nil {
+				return err
+			}
+		}
+
+		// Prepare the other tag associations using pre-loaded tag IDs
+		associations = associations[:0]
+
+		for i, l := range batch {
 			labelID, ok := labelMap[l.Label]
 			if !ok {
 				// Should not happen, but skip if it does
 				continue
 			}
 
-			// Add length tag if auto-tagging
-			if autoTag {
-				lengthTag := tagger.GenerateLengthTag(l.Length)
-				tagID, ok := tagCache[lengthTag]
-				if !ok {
-					// Tag not in cache - should have been pre-loaded, but handle gracefully
-					if tagIDFromMap, exists := existingTagMap[lengthTag]; exists {
-						tagID = tagIDFromMap
-						tagCache[lengthTag] = tagID
-					} else {
-						// Create tag if it doesn't exist (shouldn't happen for length 1-20)
-						tagID, err = dbpkg.GetOrCreateTagTx(tx, lengthTag)
-						if err != nil {
-							return fmt.Errorf("failed to create tag %s: %w", lengthTag, err)
-						}
-						tagCache[lengthTag] = tagID
-						existingTagMap[lengthTag] = tagID
-					}
+			// Add the tags of the tagging stage and those returned by the external tagger
+			tagNames := batchTags[i]
+			if externalTags != nil {
+				tagNames = append(tagNames, externalTags[i]...)
+			}
+			for _, tagName := range tagNames {
+				tagID, err := lookupTagID(tagName)
+				if err != nil {
+					return err
 				}
 				associations = append(associations, TagAssociation{
 					LabelID: labelID,
 					TagID:   tagID,
 				})
 			}
-
-			// Add filename tag if provided
-			if filenameTag != "" {
-				associations = append(associations, TagAssociation{
-					LabelID: labelID,
-					TagID:   filenameTagID,
-				})
-			}
 		}
 
 		// Bulk insert tag associations
 		if len(associations) > 0 {
-			if err := db.BulkAddTagsToLabels(tx, associations); err != nil {
+			if err := tx.AddTags(associations); err != nil {
 				return fmt.Errorf("failed to bulk add tags: %w", err)
 			}
 		}
 
 		labelsProcessed += len(batch)
 		stats.Imported += len(batch)
+		return nil
+	}
 
-		// Commit transaction periodically to reduce transaction size
+	// writeBatch writes a chunk in a savepoint, so a failed batch is recorded and skipped without losing the
+	// transaction; the labels and tags it created are rolled back and forgotten
+	writeBatch := func(batch []LabelData, batchTags [][]string) error {
+		newLabels, existingLabels := stats.NewLabels, stats.ExistingLabels
+		createdTags = createdTags[:0]
+		err := tx.Savepoint(func() error { return processBatch(batch, batchTags) })
+		if err != nil {
+			stats.NewLabels, stats.ExistingLabels = newLabels, existingLabels
+			for _, name := range createdTags {
+				delete(tagCache, name)
+				delete(existingTagMap, name)
+			}
+		}
+		return err
+	}
+
+	// commitIfDue commits the transaction periodically to reduce its size, recording how far the file is imported
+	commitIfDue := func() error {
 		if labelsProcessed >= commitInterval {
+			if o.checkpoint != "" {
+				if err := tx.SaveCheckpoint(o.checkpoint, o.checkpointFile, lineNum); err != nil {
+					return err
+				}
+			}
 			if err := tx.Commit(); err != nil {
 				return fmt.Errorf("failed to commit transaction: %w", err)
 			}
 			// Start new transaction
-			tx, err = db.BeginTransaction()
+			tx, err = db.BeginImport()
 			if err != nil {
 				return fmt.Errorf("failed to begin new transaction: %w", err)
 			}
 			labelsProcessed = 0
+			committed = *stats
 		}
-
-		batch = batch[:0] // Reset batch
 		return nil
 	}
 
-	// Read and process CSV
-	for {
-		record, err := reader.Read()
-		if err != nil {
-			// Check if we've reached EOF
-			if err == io.EOF {
-				// Process remaining batch
-				if err := processBatch(); err != nil {
-					errorMsg := fmt.Sprintf("batch processing error: %v", err)
-					stats.Errors = append(stats.Errors, errorMsg)
-				}
-				break
-			}
-			// For other errors, try to continue but log a warning
-			errorMsg := fmt.Sprintf("line %d: %v", lineNum+1, err)
-			stats.Errors = append(stats.Errors, errorMsg)
-			lineNum++
-			continue
-		}
-
-		lineNum++
-
-		if len(record) == 0 {
-			stats.Skipped++
-			continue
+	// Write the chunks in file order
+	for chunk := range inOrder(pipelineCtx, tagged) {
+		if ctx.Err() != nil {
+			return canceled()
 		}
-
-		label := strings.ToLower(strings.TrimSpace(record[0]))
-		if label == "" {
-			stats.Skipped++
-			continue
+		lineNum = chunk.lastLine
+		stats.Skipped += chunk.skipped
+		stats.HeaderSkipped = stats.HeaderSkipped || chunk.header
+		stats.Errors = append(stats.Errors, chunk.errors...)
+		if err := writeBatch(chunk.labels, chunk.tags); err != nil {
+			stats.Errors = append(stats.Errors, ImportError{Kind: ErrBatch, Err: err})
+			// Continue processing despite error
 		}
-
-		// Check if this looks like a header row
-		if !stats.HeaderSkipped && isHeaderRow(label) {
-			stats.HeaderSkipped = true
-			stats.Skipped++
-			continue
+		labelSlices.Put(&chunk.labels)
+		if err := commitIfDue(); err != nil {
+			return nil, err
 		}
 
-		// Validate label
-		if err := ValidateLabel(label); err != nil {
-			stats.Skipped++
-			// Log the error but continue
-			errorMsg := fmt.Sprintf("line %d: skipped invalid label '%s': %v", lineNum, label, err)
-			stats.Errors = append(stats.Errors, errorMsg)
-			continue
+		// Update max memory periodically
+		memMB := progress.MemoryMB()
+		if memMB > stats.MaxMemoryMB {
+			stats.MaxMemoryMB = memMB
 		}
+		o.Progress.Report(progress.Update{Phase: progress.PhaseImporting, Rows: lineNum, MemoryMB: memMB})
+	}
+	// The stages stop early only when canceled
+	if ctx.Err() != nil {
+		return canceled()
+	}
 
-		// Add to batch
-		batch = append(batch, LabelData{
-			Label:  label,
-			Length: len(label),
-		})
-
-		// Process batch when it reaches batchSize
-		if len(batch) >= batchSize {
-			if err := processBatch(); err != nil {
-				errorMsg := fmt.Sprintf("batch processing error: %v", err)
-				stats.Errors = append(stats.Errors, errorMsg)
-				// Continue processing despite error
-			}
-
-			// Heartbeat every 100K imports
-			if stats.Imported > 0 && stats.Imported >= lastHeartbeatCount+heartbeatInterval {
-				fmt.Printf("  [Heartbeat] Imported %d labels\n", stats.Imported)
-				lastHeartbeatCount = (stats.Imported / heartbeatInterval) * heartbeatInterval
-			}
-
-			// Update max memory periodically
-			if stats.Imported%10000 == 0 {
-				var m runtime.MemStats
-				runtime.ReadMemStats(&m)
-				memMB := m.Alloc / 1024 / 1024
-				if memMB > stats.MaxMemoryMB {
-					stats.MaxMemoryMB = memMB
-				}
+	// Commit final transaction, which completes the file for resuming
+	if labelsProcessed > 0 || o.checkpoint != "" {
+		if o.checkpoint != "" {
+			if err := tx.DeleteCheckpoint(o.checkpoint); err != nil {
+				return nil, err
 			}
 		}
-	}
-
-	// Commit final transaction
-	if labelsProcessed > 0 {
 		if err := tx.Commit(); err != nil {
 			return nil, fmt.Errorf("failed to commit final transaction: %w", err)
 		}
 	}
 
 	// Final memory check
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	memMB := m.Alloc / 1024 / 1024
+	memMB := progress.MemoryMB()
 	if memMB > stats.MaxMemoryMB {
 		stats.MaxMemoryMB = memMB
 	}
+	o.Progress.Report(progress.Update{Phase: progress.PhaseDone, Rows: lineNum, MemoryMB: memMB})
+
+	metrics.ObserveImport(stats.Imported, time.Since(stats.StartTime))
 
 	return stats, nil
 }
 
-// CountCSVLines counts the total number of lines in a CSV file
-func CountCSVLines(csvPath string) (int, error) {
+// estimateSampleSize is the number of bytes EstimateCSVLines reads
+const estimateSampleSize = 64 * 1024
+
+// EstimateCSVLines estimates the number of lines in a CSV file from its size and the line length of its start,
+// without reading the whole file; files smaller than the sample are counted exactly
+// The lines of compressed files can't be estimated from their size
+func EstimateCSVLines(csvPath string) (int, error) {
+	if IsCompressed(csvPath) {
+		return 0, fmt.Errorf("can't estimate the lines of compressed file %s", filepath.Base(csvPath))
+	}
 	file, err := os.Open(csvPath)
 	if err != nil {
 		return 0, err
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	sample := make([]byte, estimateSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	sample = sample[:n]
+	if n == 0 {
+		return 0, nil
+	}
+
+	lines := bytes.Count(sample, []byte{'\n'})
+	if int64(n) >= info.Size() {
+		if sample[n-1] != '\n' {
+			lines++
+		}
+		return lines, nil
+	}
+	if lines == 0 {
+		return 1, nil
+	}
+	return int(info.Size() * int64(lines) / int64(n)), nil
+}
+
+// CountCSVLines counts the total number of lines in a CSV file, decompressing it if needed
+// It reads the whole file; EstimateCSVLines is much cheaper for large files
+func CountCSVLines(csvPath string) (int, error) {
+	file, err := openInput(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
 	reader := csv.NewReader(file)
 	reader.FieldsPerRecord = -1
 	reader.ReuseRecord = true
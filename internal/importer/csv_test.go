@@ -0,0 +1,627 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	dbpkg "premium-list-maker/internal/db"
+	"premium-list-maker/internal/progress"
+)
+
+func TestImportCSVReader_Options(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	csv := "label\nshoes\nab--cd\nhats\n-bad\nbags\n"
+	var lines []int
+	stats, err := ImportCSVReader(db, strings.NewReader(csv),
+		WithTag("fashion"),
+		WithBatchSize(2),
+		WithValidation(ValidationLDH),
+		WithProgress(func(u progress.Update) { lines = append(lines, u.Rows) }),
+	)
+	if err != nil {
+		t.Fatalf("ImportCSVReader failed: %v", err)
+	}
+	// ab--cd is only accepted by the ldh profile
+	if stats.NewLabels != 4 || stats.Skipped != 2 || !stats.HeaderSkipped {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.Errors) != 1 || !errors.Is(stats.Errors[0], ErrInvalidLabel) || !errors.Is(stats.Errors[0], ErrInvalidLabelDash) || stats.Errors[0].Line != 5 {
+		t.Errorf("errors = %+v", stats.Errors)
+	}
+	if len(lines) < 2 || lines[len(lines)-1] != 6 {
+		t.Errorf("progress = %v", lines)
+	}
+
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := labels["ab--cd"]; len(tags) != 1 || tags[0] != "fashion" {
+		t.Errorf("ab--cd tags = %v", tags)
+	}
+}
+
+func TestImportCSVReader_Columns(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	csv := "id,vendor,domain,price,tags\n1,acme,Shoes,10,fashion| brand\n2,acme,bags,20,\n3,acme\n4,acme,hats,5,\"summer, 2024|x\x1fy\"\n"
+	stats, err := ImportCSVReader(db, strings.NewReader(csv),
+		WithLabelColumn(3),
+		WithTagsColumn(5),
+		WithTagSeparator("|"),
+	)
+	if err != nil {
+		t.Fatalf("ImportCSVReader failed: %v", err)
+	}
+	if stats.NewLabels != 3 || !stats.HeaderSkipped {
+		t.Errorf("stats = %+v", stats)
+	}
+	// The short row has no label column
+	if len(stats.Errors) != 1 || !errors.Is(stats.Errors[0], ErrParse) || stats.Errors[0].Line != 4 {
+		t.Errorf("errors = %+v", stats.Errors)
+	}
+
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := labels["shoes"]; strings.Join(tags, ",") != "fashion,brand" {
+		t.Errorf("shoes tags = %v", tags)
+	}
+	if tags, ok := labels["bags"]; !ok || len(tags) != 0 {
+		t.Errorf("bags tags = %v", tags)
+	}
+	// A comma stays in the tag name, the control character is dropped
+	if tags := labels["hats"]; strings.Join(tags, "|") != "summer, 2024|xy" {
+		t.Errorf("hats tags = %q", tags)
+	}
+
+	if _, err := ImportCSVReader(db, strings.NewReader(csv), WithLabelColumn(2), WithTagsColumn(2)); err == nil {
+		t.Error("label and tags in the same column were accepted")
+	}
+}
+
+func TestImportCSVReader_Concurrent(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Imports on their own transactions take turns at every commit and share the tags they create
+	const files, labels = 4, 500
+	errs := make(chan error, files)
+	for f := 0; f < files; f++ {
+		go func() {
+			var csv strings.Builder
+			for i := 0; i < labels; i++ {
+				fmt.Fprintf(&csv, "l%d-%d\nshared%d\n", f, i, i)
+			}
+			stats, err := ImportCSVReader(db, strings.NewReader(csv.String()),
+				WithAutoTag(), WithTag(fmt.Sprintf("file%d", f)), WithBatchSize(50), WithCommitInterval(100))
+			if err == nil && len(stats.Errors) > 0 {
+				err = stats.Errors[0]
+			}
+			errs <- err
+		}()
+	}
+	for f := 0; f < files; f++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+	}
+
+	all, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != files*labels+labels {
+		t.Errorf("%d labels, want %d", len(all), files*labels+labels)
+	}
+	if tags := all["shared7"]; len(tags) != files+1 {
+		t.Errorf("shared7 tags = %v, want its length tag and every file", tags)
+	}
+}
+
+func TestImportCSVReader_ExistingLabels(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := ImportCSVReader(db, strings.NewReader("shoes\nhats\n"), WithTag("first")); err != nil {
+		t.Fatal(err)
+	}
+	// Labels of earlier imports and earlier batches count as existing
+	stats, err := ImportCSVReader(db, strings.NewReader("hats\nbags\nshoes\nbags\n"), WithTag("second"), WithBatchSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.NewLabels != 1 || stats.ExistingLabels != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 3 || len(labels["shoes"]) != 2 || len(labels["bags"]) != 1 {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestImportCSVReader_AutoTag(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The length and file tags are added to the whole batch, the other tags per label
+	long := strings.Repeat("a", 25)
+	if _, err := ImportCSVReader(db, strings.NewReader("qxqxq\napp\n"+long+"\n"), WithAutoTag(), WithTag("fashion"), WithBatchSize(2)); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"qxqxq": "LLLLL,fashion,len:5",
+		"app":   "LLL,fashion,len:3,tld-word",
+		long:    "fashion,len:25",
+	}
+	for label, tags := range want {
+		got := append([]string(nil), labels[label]...)
+		sort.Strings(got)
+		if strings.Join(got, ",") != tags {
+			t.Errorf("%s tags = %v, want %s", label, got, tags)
+		}
+	}
+}
+
+func TestImportCSVReader_Workers(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var csv strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&csv, "label%d\n", i)
+	}
+	stats, err := ImportCSVReader(db, strings.NewReader(csv.String()),
+		WithBatchSize(7),
+		WithWorkers(4),
+		WithRankThresholds([]int{10}),
+	)
+	if err != nil {
+		t.Fatalf("ImportCSVReader failed: %v", err)
+	}
+	if stats.NewLabels != 1000 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Rank tags follow the position in the file, whichever worker tagged the batch
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked := 0
+	for label, tags := range labels {
+		if len(tags) > 0 {
+			ranked++
+			if n, _ := strconv.Atoi(strings.TrimPrefix(label, "label")); n >= 10 {
+				t.Errorf("%s tags = %v", label, tags)
+			}
+		}
+	}
+	if ranked != 10 {
+		t.Errorf("%d ranked labels, want 10", ranked)
+	}
+}
+
+func TestImportCSVReader_InvalidOptions(t *testing.T) {
+	if _, err := ImportCSVReader(nil, strings.NewReader(""), WithValidation("lenient")); err == nil {
+		t.Error("expected an error for an unknown validation profile")
+	}
+}
+
+func TestImportCSVReader_Canceled(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Cancel after the first batch, which is committed on its own
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stats, err := ImportCSVReader(db, strings.NewReader("shoes\nhats\nbags\ncaps\nbelts\n"),
+		WithBatchSize(2),
+		WithCommitInterval(2),
+		WithContext(ctx),
+		WithProgress(func(progress.Update) { cancel() }),
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if !stats.Canceled || stats.Imported != 2 || stats.NewLabels != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 {
+		t.Errorf("labels = %v, want the first batch only", labels)
+	}
+}
+
+// failingStore is a store whose import transactions fail the failAt-th AddTags call, after the batch wrote its labels
+type failingStore struct {
+	*dbpkg.DB
+	failAt int
+	calls  int
+}
+
+func (s *failingStore) BeginImport() (dbpkg.ImportTx, error) {
+	tx, err := s.DB.BeginImport()
+	if err != nil {
+		return nil, err
+	}
+	return &failingTx{ImportTx: tx, store: s}, nil
+}
+
+type failingTx struct {
+	dbpkg.ImportTx
+	store *failingStore
+}
+
+func (tx *failingTx) AddTags(associations []dbpkg.TagAssociation) error {
+	tx.store.calls++
+	if tx.store.calls == tx.store.failAt {
+		return errors.New("disk full")
+	}
+	return tx.ImportTx.AddTags(associations)
+}
+
+func TestImportCSVReader_BatchError(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The second batch creates the fresh tag and fails; the third needs the tag again
+	csv := "shoes,old\nhats,old\nbags,fresh\ncaps,fresh\nbelts,fresh\nboots,old\n"
+	stats, err := ImportCSVReader(&failingStore{DB: db, failAt: 2}, strings.NewReader(csv),
+		WithTagsColumn(2),
+		WithBatchSize(2),
+		WithWorkers(1),
+	)
+	if err != nil {
+		t.Fatalf("ImportCSVReader failed: %v", err)
+	}
+	if stats.Imported != 4 || stats.NewLabels != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.Errors) != 1 || !errors.Is(stats.Errors[0], ErrBatch) {
+		t.Errorf("errors = %+v", stats.Errors)
+	}
+
+	// The failed batch is rolled back to its savepoint, the others are committed
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 4 {
+		t.Errorf("labels = %v, want all but bags and caps", labels)
+	}
+	if _, ok := labels["bags"]; ok {
+		t.Error("the failed batch's bags was kept")
+	}
+	if tags := labels["belts"]; len(tags) != 1 || tags[0] != "fresh" {
+		t.Errorf("belts tags = %v", tags)
+	}
+}
+
+func TestImportCSV_Resume(t *testing.T) {
+	dir := t.TempDir()
+	db, err := dbpkg.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	csvPath := filepath.Join(dir, "ranked.csv")
+	if err := os.WriteFile(csvPath, []byte("label\nshoes\nhats\nbags\ncaps\nbelts\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancel after the first commit, which records the line it covers
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stats, err := ImportCSV(db, csvPath,
+		WithResume(),
+		WithRankThresholds([]int{3}),
+		WithBatchSize(2),
+		WithCommitInterval(2),
+		WithContext(ctx),
+		WithProgress(func(progress.Update) { cancel() }),
+	)
+	if !errors.Is(err, context.Canceled) || stats.Imported != 2 {
+		t.Fatalf("canceled import = %+v, %v", stats, err)
+	}
+
+	// The second run skips the committed lines but keeps the rank positions
+	var rows []int
+	stats, err = ImportCSV(db, csvPath,
+		WithResume(),
+		WithRankThresholds([]int{3}),
+		WithProgress(func(u progress.Update) { rows = append(rows, u.Rows) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Resumed != 3 || stats.Imported != 3 || stats.NewLabels != 3 {
+		t.Errorf("resumed import = %+v", stats)
+	}
+	if len(rows) == 0 || rows[len(rows)-1] != 6 {
+		t.Errorf("progress = %v, want it to end at line 6", rows)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 5 || len(labels["bags"]) != 1 || len(labels["caps"]) != 0 {
+		t.Errorf("labels = %v, want all, with bags at rank 3", labels)
+	}
+
+	// The completed import forgets its checkpoint
+	if line, err := db.ImportCheckpoint(strings.Repeat("0", 64)); err != nil || line != 0 {
+		t.Errorf("checkpoint of an unknown file = %d, %v", line, err)
+	}
+	stats, err = ImportCSV(db, csvPath, WithResume(), WithRankThresholds([]int{3}))
+	if err != nil || stats.Resumed != 0 || stats.ExistingLabels != 5 {
+		t.Errorf("import after completion = %+v, %v", stats, err)
+	}
+}
+
+func TestSetCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "ranked.csv")
+	if err := os.WriteFile(csvPath, []byte(strings.Repeat("shoes\n", 50000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	key := func(path string, opts ...ImportOption) string {
+		o, err := newImportOptions(append(opts, WithResume()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := setCheckpoint(&o, path); err != nil {
+			t.Fatal(err)
+		}
+		return o.checkpoint
+	}
+	base := key(csvPath)
+
+	// Options that change the rows of the file change the key
+	for name, opt := range map[string]ImportOption{
+		"label column":    WithLabelColumn(2),
+		"tags column":     WithTagsColumn(2),
+		"tag separator":   WithTagSeparator("|"),
+		"rank thresholds": WithRankThresholds([]int{10}),
+		"tag":             WithTag("ranked"),
+	} {
+		if key(csvPath, opt) == base {
+			t.Errorf("key ignores the %s", name)
+		}
+	}
+
+	// Renaming the file keeps the key, changing its end doesn't
+	moved := filepath.Join(dir, "moved.csv")
+	if err := os.Rename(csvPath, moved); err != nil {
+		t.Fatal(err)
+	}
+	if key(moved) != base {
+		t.Error("renaming the file changed the key")
+	}
+	info, err := os.Stat(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(moved, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("hats\n"), info.Size()-6); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.Chtimes(moved, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if key(moved) == base {
+		t.Error("changing the end of the file kept the key")
+	}
+}
+
+func TestImportCSVReader_UndoImport(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	first, err := db.StartImport([]string{"first.csv"}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportCSVReader(db.InImport(first), strings.NewReader("shoes\nhats\ncaps\n"), WithAutoTag(), WithTag("first"), WithBatchSize(2)); err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.StartImport([]string{"second.csv"}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportCSVReader(db.InImport(second), strings.NewReader("hats\nbags\n"), WithAutoTag(), WithTag("second")); err != nil {
+		t.Fatal(err)
+	}
+	counts := dbpkg.ImportCounts{FilesProcessed: 1, LabelsProcessed: 2, NewLabels: 1, ExistingLabels: 1}
+	if err := db.FinishImport(second, dbpkg.ImportCompleted, counts, []string{"second.csv line 3: bad", "second.csv line 4: bad"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := db.ListImports(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != second || sessions[0].ImportCounts != counts || sessions[0].ErrorCount != 2 || sessions[1].Status != dbpkg.ImportRunning {
+		t.Errorf("sessions = %+v", sessions)
+	}
+	if errs, err := db.ImportErrors(second); err != nil || len(errs) != 2 || errs[1] != "second.csv line 4: bad" {
+		t.Errorf("errors = %v, %v", errs, err)
+	}
+
+	// Tagged outside of an import, so caps is no longer the first import's alone
+	if _, err := db.TagLabels([]string{"caps"}, "hand-picked"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := db.UndoImport(first, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Labels != 1 || result.LabelsKept != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	labels, err := db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The length and content tags of hats were added by both imports, so they stay
+	want := map[string]string{
+		"hats": "LLLL,len:4,second",
+		"caps": "hand-picked",
+		"bags": "LLLL,len:4,second",
+	}
+	if len(labels) != len(want) {
+		t.Errorf("labels = %v", labels)
+	}
+	for label, tags := range want {
+		got := append([]string(nil), labels[label]...)
+		sort.Strings(got)
+		if strings.Join(got, ",") != tags {
+			t.Errorf("%s tags = %v, want %s", label, got, tags)
+		}
+	}
+
+	session, err := db.GetImport(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Status != dbpkg.ImportUndone || session.UndoneAt == nil || len(session.Files) != 1 {
+		t.Errorf("session = %+v", session)
+	}
+
+	// Undoing the second import too leaves what neither import created alone
+	if _, err := db.UndoImport(second, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	labels, err = db.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || len(labels["caps"]) != 1 || len(labels["hats"]) != 2 {
+		t.Errorf("labels = %v, want caps and hats with its shared tags", labels)
+	}
+	tags, err := db.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 {
+		t.Errorf("tags = %+v, want LLLL, len:4 and hand-picked", tags)
+	}
+}
+
+func TestImportCSV_NotCSV(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for name, content := range map[string]string{
+		"workbook":  "PK\x03\x04\x14\x00\x06\x00",
+		"gzip":      "\x1f\x8b\x08\x00",
+		"utf-16":    "\xff\xfes\x00h\x00",
+		"nul":       "shoes\nha\x00ts\n",
+		"control":   strings.Repeat("\x01\x02\x03ab", 100),
+		"long line": strings.Repeat("a", 100<<10),
+	} {
+		if _, err := ImportCSVReader(db, strings.NewReader(content)); !errors.Is(err, ErrNotCSV) {
+			t.Errorf("%s: err = %v, want ErrNotCSV", name, err)
+		}
+	}
+
+	// Text in other encodings is still imported
+	stats, err := ImportCSVReader(db, strings.NewReader("shoes\r\nhats,caf\xe9\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Imported != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	path := filepath.Join(t.TempDir(), "labels.csv")
+	if err := os.WriteFile(path, []byte("bags\nbelts\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportCSV(db, path, WithMaxFileSize(5)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+	if _, err := ImportCSV(db, path, WithMaxFileSize(-1)); err != nil {
+		t.Errorf("no limit: %v", err)
+	}
+}
+
+func TestEstimateCSVLines(t *testing.T) {
+	dir := t.TempDir()
+
+	// Small files are counted exactly, with or without a final newline
+	small := filepath.Join(dir, "small.csv")
+	if err := os.WriteFile(small, []byte("label\nshoes\nhats"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := EstimateCSVLines(small); err != nil || n != 3 {
+		t.Errorf("EstimateCSVLines(small) = %d, %v, want 3", n, err)
+	}
+
+	// Large files are extrapolated from the sample; equal lines give the exact count
+	large := filepath.Join(dir, "large.csv")
+	if err := os.WriteFile(large, bytes.Repeat([]byte("label12\n"), 100000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := EstimateCSVLines(large); err != nil || n != 100000 {
+		t.Errorf("EstimateCSVLines(large) = %d, %v, want 100000", n, err)
+	}
+}
@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ParseDroppedDomains reads a dropped or expiring domains feed and calls fn for every label in tld
+// The feed is a list of domain names, one per line, optionally as the first column of a CSV or
+// tab-separated file; header and # comment lines are skipped, as are names in other TLDs
+func ParseDroppedDomains(r io.Reader, tld string, fn func(label string) error) error {
+	suffix := "." + strings.Trim(strings.ToLower(tld), ".")
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, ",;\t "); i >= 0 {
+			name = line[:i]
+		}
+		name = strings.Trim(name, `"`)
+		if isHeaderRow(name) {
+			continue
+		}
+
+		name = NormalizeLabel(strings.TrimSuffix(name, "."))
+		label, ok := strings.CutSuffix(name, suffix)
+		if !ok || label == "" || strings.Contains(label, ".") {
+			continue
+		}
+		if err := fn(label); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
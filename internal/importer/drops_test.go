@@ -0,0 +1,25 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDroppedDomains(t *testing.T) {
+	feed := "domain,drop_date\n# dropped today\nShoes.shop,2025-03-01\n\"hats.shop.\",2025-03-01\nshoes.com,2025-03-01\nwww.app.shop,2025-03-01\nmünchen.shop\tpending\n\n"
+
+	var labels []string
+	err := ParseDroppedDomains(strings.NewReader(feed), ".SHOP", func(label string) error {
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseDroppedDomains failed: %v", err)
+	}
+
+	want := []string{"shoes", "hats", "xn--mnchen-3ya"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("got labels %v, want %v", labels, want)
+	}
+}
@@ -0,0 +1,62 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Kinds of import errors; every ImportError wraps the one of its kind, so callers can test with errors.Is
+var (
+	ErrInvalidLabel = errors.New("invalid label")
+	ErrParse        = errors.New("parse error")
+	ErrBatch        = errors.New("batch failed")
+)
+
+// ImportError records a row or batch an import couldn't store
+type ImportError struct {
+	File  string // File name, empty for imports from a reader
+	Line  int    // Line of the row, 0 for batch errors
+	Label string // Offending label, if any
+	Kind  error  // ErrInvalidLabel, ErrParse or ErrBatch
+	Err   error  // Underlying error, e.g. ErrInvalidLabelDash
+}
+
+// Error formats the record as in the import error report
+func (e ImportError) Error() string {
+	prefix := ""
+	if e.File != "" {
+		prefix = e.File + " "
+	}
+	switch {
+	case e.Kind == ErrInvalidLabel:
+		return fmt.Sprintf("%sline %d: skipped invalid label '%s': %v", prefix, e.Line, e.Label, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%sline %d: %v", prefix, e.Line, e.Err)
+	default:
+		return fmt.Sprintf("%sbatch processing error: %v", prefix, e.Err)
+	}
+}
+
+// Unwrap returns the kind and the underlying error
+func (e ImportError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// errorKinds maps kinds to their names in JSON
+var errorKinds = map[error]string{
+	ErrInvalidLabel: "invalid_label",
+	ErrParse:        "parse",
+	ErrBatch:        "batch",
+}
+
+// MarshalJSON encodes the record with its kind as a name, e.g. {"line": 3, "label": "-bad", "kind": "invalid_label", ...}
+func (e ImportError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File    string `json:"file,omitempty"`
+		Line    int    `json:"line,omitempty"`
+		Label   string `json:"label,omitempty"`
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}{e.File, e.Line, e.Label, errorKinds[e.Kind], e.Err.Error()})
+}
@@ -0,0 +1,415 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+// FeedLayout describes the columns of a third-party premium feed by the header names they go by
+// Header names are matched case-insensitively, with underscores and hyphens treated as spaces
+type FeedLayout struct {
+	Name         string
+	Label        []string // Label or full domain name
+	Registration []string
+	Renewal      []string
+	Restore      []string
+	Currency     []string
+	Category     []string // Categories become tags; several may be separated by ; or |
+}
+
+// FeedLayouts are the known feed layouts, in the order they are tried when detecting the layout of a feed
+var FeedLayouts = []FeedLayout{
+	{
+		Name:         "generic",
+		Label:        []string{"label", "sld"},
+		Registration: []string{"price", "price reg", "registration"},
+		Renewal:      []string{"price ren", "renewal"},
+		Restore:      []string{"price res", "restore"},
+		Currency:     []string{"currency"},
+		Category:     []string{"category", "categories", "tags"},
+	},
+	{
+		// Aftermarket marketplace exports: full domain names with a buy-now price
+		Name:         "aftermarket",
+		Label:        []string{"domain", "domain name", "name"},
+		Registration: []string{"buy now price", "bin price", "asking price", "price"},
+		Currency:     []string{"currency", "price currency"},
+		Category:     []string{"category", "categories"},
+	},
+	{
+		// Registry partner price sheets: create/renew/restore prices per premium class
+		Name:         "registry-partner",
+		Label:        []string{"label", "domain", "sld"},
+		Registration: []string{"create", "create price", "registration price"},
+		Renewal:      []string{"renew", "renew price", "renewal price"},
+		Restore:      []string{"restore", "restore price"},
+		Currency:     []string{"currency"},
+		Category:     []string{"class", "premium class", "category"},
+	},
+}
+
+// FeedRecord is a row of a premium feed mapped to a label
+type FeedRecord struct {
+	Line     int
+	Label    string
+	Tags     []string
+	Currency string // Empty if the feed has no currency column and the price carries no currency code
+	PriceReg *float64
+	PriceRen *float64
+	PriceRes *float64
+}
+
+// FindFeedLayout returns the named layout, or nil if there is none
+func FindFeedLayout(name string) *FeedLayout {
+	for i := range FeedLayouts {
+		if FeedLayouts[i].Name == name {
+			return &FeedLayouts[i]
+		}
+	}
+	return nil
+}
+
+// feedColumns are the column indexes of a feed, -1 if the feed lacks the column
+type feedColumns struct {
+	label, reg, ren, res, currency, category int
+}
+
+// columns maps the layout onto a header row
+// ok is false if the header has no label column or no price column of the layout
+func (l *FeedLayout) columns(header []string) (cols feedColumns, ok bool) {
+	find := headerFinder(header)
+	cols = feedColumns{
+		label:    find(l.Label),
+		reg:      find(l.Registration),
+		ren:      find(l.Renewal),
+		res:      find(l.Restore),
+		currency: find(l.Currency),
+		category: find(l.Category),
+	}
+	ok = cols.label >= 0 && (cols.reg >= 0 || cols.ren >= 0 || cols.res >= 0)
+	return cols, ok
+}
+
+// headerFinder returns a function looking up the index of the first of several header names in header, -1 if none is present
+func headerFinder(header []string) func(names []string) int {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = normalizeFeedHeader(name)
+		if _, seen := index[name]; !seen {
+			index[name] = i
+		}
+	}
+	return func(names []string) int {
+		for _, name := range names {
+			if i, found := index[name]; found {
+				return i
+			}
+		}
+		return -1
+	}
+}
+
+// ParseFeed reads a premium feed CSV with a header row and calls fn for every row
+// layout is the name of one of FeedLayouts, or "auto" (or empty) to pick the first layout matching the header
+// Full domain names are reduced to their first label; rows that can't be parsed are passed to onError and skipped
+func ParseFeed(r io.Reader, layout string, fn func(FeedRecord) error, onError func(line int, err error)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("feed is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read feed header: %w", err)
+	}
+	var cols feedColumns
+	found := false
+	if layout == "" || layout == "auto" {
+		for i := range FeedLayouts {
+			if cols, found = FeedLayouts[i].columns(header); found {
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unrecognized feed header %q: expected a label/domain column and a price column", strings.Join(header, ","))
+		}
+	} else {
+		l := FindFeedLayout(layout)
+		if l == nil {
+			return fmt.Errorf("unknown feed layout %q", layout)
+		}
+		if cols, found = l.columns(header); !found {
+			return fmt.Errorf("feed header %q doesn't match the %s layout", strings.Join(header, ","), layout)
+		}
+	}
+
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			onError(line, err)
+			continue
+		}
+
+		record, err := parseFeedRow(row, cols)
+		if err != nil {
+			onError(line, err)
+			continue
+		}
+		if record == nil {
+			continue
+		}
+		record.Line = line
+		if err := fn(*record); err != nil {
+			return err
+		}
+	}
+}
+
+// parseFeedRow maps a row onto a record, returning nil for blank rows
+func parseFeedRow(row []string, cols feedColumns) (*FeedRecord, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	name := field(cols.label)
+	if name == "" {
+		return nil, nil
+	}
+	label, err := domainLabel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &FeedRecord{Label: label, Currency: strings.ToUpper(field(cols.currency))}
+	prices := []struct {
+		col   int
+		price **float64
+	}{{cols.reg, &record.PriceReg}, {cols.ren, &record.PriceRen}, {cols.res, &record.PriceRes}}
+	for _, p := range prices {
+		value, currency, err := parseFeedPrice(field(p.col))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*p.price = value
+		if record.Currency == "" {
+			record.Currency = currency
+		}
+	}
+	if record.PriceReg == nil && record.PriceRen == nil && record.PriceRes == nil {
+		return nil, fmt.Errorf("%s: no price", name)
+	}
+
+	for _, category := range strings.FieldsFunc(field(cols.category), func(r rune) bool { return r == ';' || r == '|' }) {
+		if tag := strings.ToLower(strings.TrimSpace(category)); tag != "" {
+			record.Tags = append(record.Tags, tag)
+		}
+	}
+	return record, nil
+}
+
+// domainLabel returns the validated label of a label or full domain name, as aftermarket data lists them
+func domainLabel(name string) (string, error) {
+	label := NormalizeLabel(strings.TrimSuffix(name, "."))
+	if i := strings.IndexByte(label, '.'); i >= 0 {
+		label = label[:i]
+	}
+	if err := ValidateLabel(label); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return label, nil
+}
+
+// parseFeedPrice parses prices as feeds write them, e.g. "1250", "$1,250.00" or "1250 EUR"
+// It returns nil for an empty price and the currency code if the price carries one
+func parseFeedPrice(s string) (*float64, string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, "", nil
+	}
+
+	currency := ""
+	if fields := strings.Fields(s); len(fields) == 2 {
+		switch {
+		case isCurrencyCode(fields[0]):
+			currency, s = fields[0], fields[1]
+		case isCurrencyCode(fields[1]):
+			currency, s = fields[1], fields[0]
+		}
+	}
+	s = strings.TrimLeft(s, "$€£")
+	s = strings.ReplaceAll(s, ",", "")
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return nil, "", fmt.Errorf("invalid price %q", s)
+	}
+	return &value, strings.ToUpper(currency), nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeFeedHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(strings.NewReplacer("_", " ", "-", " ").Replace(name)), " ")
+}
+
+// FeedImportOptions controls how a feed is imported
+type FeedImportOptions struct {
+	Layout          string   // Layout name, or "auto"
+	Source          string   // Name of the feed; labels are tagged feed:<source> and overrides record it
+	DefaultCurrency string   // Currency of prices that don't name one
+	Tags            []string // Extra tags for every label of the feed
+	NoPrices        bool     // Only import labels and tags, keeping the tier prices
+	Replace         bool     // Drop the source's earlier price overrides, for feeds that are full snapshots
+}
+
+// FeedImportStats summarizes a feed import
+type FeedImportStats struct {
+	Rows           int
+	NewLabels      int
+	ExistingLabels int
+	PriceOverrides int
+	Replaced       int // Earlier price overrides of the source that were dropped
+	Errors         []string
+}
+
+// ImportFeed imports a third-party premium feed in one transaction: its labels, its categories
+// and source as tags, and its prices as price overrides
+// A label listed twice in the feed takes its last row
+func ImportFeed(db *dbpkg.DB, path string, opts FeedImportOptions) (*FeedImportStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feed: %w", err)
+	}
+	defer file.Close()
+
+	stats := &FeedImportStats{}
+	records := make(map[string]FeedRecord)
+	var order []string
+	err = ParseFeed(file, opts.Layout, func(record FeedRecord) error {
+		stats.Rows++
+		if record.Currency == "" {
+			record.Currency = opts.DefaultCurrency
+		}
+		if record.Currency == "" && !opts.NoPrices {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("line %d: %s: no currency (use --currency)", record.Line, record.Label))
+			return nil
+		}
+		if _, seen := records[record.Label]; !seen {
+			order = append(order, record.Label)
+		}
+		records[record.Label] = record
+		return nil
+	}, func(line int, err error) {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("line %d: %v", line, err))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if opts.Replace {
+		if stats.Replaced, err = dbpkg.DeletePriceOverridesTx(tx, opts.Source); err != nil {
+			return nil, err
+		}
+	}
+
+	labels := make([]LabelData, len(order))
+	for i, label := range order {
+		labels[i] = LabelData{Label: label, Length: len(label)}
+	}
+	inserted, err := db.BulkInsertLabels(tx, labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk insert labels: %w", err)
+	}
+	stats.NewLabels = inserted.NewCount
+	stats.ExistingLabels = inserted.ExistingCount
+
+	tagIDs := make(map[string]int64)
+	tagID := func(name string) (int64, error) {
+		if id, ok := tagIDs[name]; ok {
+			return id, nil
+		}
+		id, err := dbpkg.GetOrCreateTagTx(tx, name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create tag %s: %w", name, err)
+		}
+		tagIDs[name] = id
+		return id, nil
+	}
+	commonTags := append([]string(nil), opts.Tags...)
+	if opts.Source != "" {
+		commonTags = append(commonTags, "feed:"+opts.Source)
+	}
+
+	var associations []TagAssociation
+	var overrides []dbpkg.PriceOverride
+	for _, label := range order {
+		record := records[label]
+		labelID := inserted.LabelMap[label]
+		for _, name := range append(commonTags, record.Tags...) {
+			id, err := tagID(name)
+			if err != nil {
+				return nil, err
+			}
+			associations = append(associations, TagAssociation{LabelID: labelID, TagID: id})
+		}
+		if !opts.NoPrices {
+			overrides = append(overrides, dbpkg.PriceOverride{
+				Label:    label,
+				Currency: record.Currency,
+				PriceReg: record.PriceReg,
+				PriceRen: record.PriceRen,
+				PriceRes: record.PriceRes,
+				Source:   opts.Source,
+			})
+		}
+	}
+
+	if err := db.BulkAddTagsToLabels(tx, associations); err != nil {
+		return nil, fmt.Errorf("failed to bulk add tags: %w", err)
+	}
+	if err := dbpkg.SetPriceOverridesTx(tx, overrides, inserted.LabelMap); err != nil {
+		return nil, err
+	}
+	stats.PriceOverrides = len(overrides)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return stats, nil
+}
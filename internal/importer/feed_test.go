@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+func TestParseFeed_Aftermarket(t *testing.T) {
+	feed := `Domain Name,Buy_Now_Price,Category
+Shoes.shop,"$1,250.00",Fashion; Retail
+ab--name.shop,100,
+cars.shop,2000 EUR,
+empty.shop,,
+`
+
+	var records []FeedRecord
+	var errs []int
+	err := ParseFeed(strings.NewReader(feed), "auto", func(r FeedRecord) error {
+		records = append(records, r)
+		return nil
+	}, func(line int, err error) { errs = append(errs, line) })
+	if err != nil {
+		t.Fatalf("ParseFeed failed: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %+v", len(records), records)
+	}
+	shoes := records[0]
+	if shoes.Label != "shoes" || *shoes.PriceReg != 1250 || shoes.PriceRen != nil || shoes.Currency != "" {
+		t.Errorf("shoes = %+v", shoes)
+	}
+	if strings.Join(shoes.Tags, ",") != "fashion,retail" {
+		t.Errorf("shoes tags = %v", shoes.Tags)
+	}
+	if records[1].Label != "cars" || records[1].Currency != "EUR" || *records[1].PriceReg != 2000 {
+		t.Errorf("cars = %+v", records[1])
+	}
+	if len(errs) != 2 || errs[0] != 3 || errs[1] != 5 {
+		t.Errorf("error lines = %v, want [3 5]", errs)
+	}
+}
+
+func TestParseFeed_UnknownHeader(t *testing.T) {
+	err := ParseFeed(strings.NewReader("foo,bar\nx,1\n"), "auto", func(FeedRecord) error { return nil }, func(int, error) {})
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized header")
+	}
+}
+
+func TestImportFeed(t *testing.T) {
+	dir := t.TempDir()
+	db, err := dbpkg.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	feed := filepath.Join(dir, "partner.csv")
+	os.WriteFile(feed, []byte("label,create,renew,restore,currency,class\nshoes,500,250,,usd,gold\nhats,90,90,40,,silver\nshoes,600,300,,usd,gold\n"), 0644)
+
+	stats, err := ImportFeed(db, feed, FeedImportOptions{Layout: "registry-partner", Source: "acme", DefaultCurrency: "EUR"})
+	if err != nil {
+		t.Fatalf("ImportFeed failed: %v", err)
+	}
+	if stats.Rows != 3 || stats.NewLabels != 2 || stats.PriceOverrides != 2 || len(stats.Errors) != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	overrides, err := db.GetPriceOverrides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shoes := overrides["shoes"]
+	if shoes.Currency != "USD" || *shoes.PriceReg != 600 || *shoes.PriceRen != 300 || shoes.PriceRes != nil || shoes.Source != "acme" {
+		t.Errorf("shoes override = %+v", shoes)
+	}
+	if overrides["hats"].Currency != "EUR" {
+		t.Errorf("hats override = %+v", overrides["hats"])
+	}
+
+	label, err := db.GetLabel("shoes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(label.Tags, ",") != "feed:acme,gold" && strings.Join(label.Tags, ",") != "gold,feed:acme" {
+		t.Errorf("shoes tags = %v", label.Tags)
+	}
+}
@@ -0,0 +1,199 @@
+package importer
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbpkg "premium-list-maker/internal/db"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// formats maps the extensions of the files ImportFile imports to their import function
+var formats = map[string]func(dbpkg.Store, string, ...ImportOption) (*ImportStats, error){
+	".csv":   ImportCSV,
+	".json":  ImportJSON,
+	".jsonl": ImportJSON,
+	".xlsx":  ImportXLSX,
+}
+
+// decompressors maps the extensions of compressed files to a reader decompressing them
+// A compressed file is imported in the format of the extension before, e.g. labels.csv.gz as CSV
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	},
+}
+
+// IsCompressed reports whether a file is decompressed while it is read, by its extension (.gz or .zst)
+func IsCompressed(path string) bool {
+	_, ok := decompressors[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// formatExt returns the lowercased extension of the format of a file, e.g. .csv for labels.CSV.gz
+func formatExt(path string) string {
+	if IsCompressed(path) {
+		path = path[:len(path)-len(filepath.Ext(path))]
+	}
+	return strings.ToLower(filepath.Ext(path))
+}
+
+// IsImportFile reports whether ImportFile imports the file, by its extension
+// The lock files Excel leaves next to open workbooks (~$name.xlsx) are not imported, nor compressed workbooks
+func IsImportFile(path string) bool {
+	ext := formatExt(path)
+	_, ok := formats[ext]
+	return ok && !strings.HasPrefix(filepath.Base(path), "~$") && !(ext == ".xlsx" && IsCompressed(path))
+}
+
+// FileTag returns the tag of the labels of a file: its name without directory and import (and compression) extension
+func FileTag(path string) string {
+	name := filepath.Base(path)
+	if IsImportFile(name) {
+		name = name[:len(name)-len(filepath.Ext(name))]
+		if IsCompressed(filepath.Base(path)) {
+			name = name[:len(name)-len(filepath.Ext(name))]
+		}
+	}
+	return name
+}
+
+// ImportFile imports a file in the format of its extension: CSV (.csv), JSON (.json, .jsonl) or Excel (.xlsx)
+// CSV and JSON files compressed with gzip (.gz) or zstd (.zst) are decompressed while they are read
+func ImportFile(db dbpkg.Store, path string, opts ...ImportOption) (*ImportStats, error) {
+	importer, ok := formats[formatExt(path)]
+	if !ok || !IsImportFile(path) {
+		return nil, fmt.Errorf("unsupported file type %q (must be .csv, .json, .jsonl or .xlsx, or .gz or .zst compressed CSV or JSON)", filepath.Base(path))
+	}
+	return importer(db, path, opts...)
+}
+
+// openInput opens a file for reading, decompressing it if its extension is .gz or .zst
+func openInput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	decompress, ok := decompressors[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return file, nil
+	}
+	r, err := decompress(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", filepath.Base(path), err)
+	}
+	return decompressedFile{r, file}, nil
+}
+
+// decompressedFile closes the decompressor and then the file
+type decompressedFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (f decompressedFile) Close() error {
+	return errors.Join(f.ReadCloser.Close(), f.file.Close())
+}
+
+// importFile opens (and decompresses) a file, checks the MaxFileSize option and imports it with read
+// what names the format in errors, e.g. CSV
+func importFile(db dbpkg.Store, path, what string, read func(dbpkg.Store, io.Reader, ...ImportOption) (*ImportStats, error), opts []ImportOption) (*ImportStats, error) {
+	o, err := newImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file: %w", what, err)
+	}
+	defer file.Close()
+
+	// Compressed files are limited by their compressed size
+	if err := checkFileSize(path, o); err != nil {
+		return nil, err
+	}
+	if err := setCheckpoint(&o, path); err != nil {
+		return nil, err
+	}
+
+	stats, err := read(db, file, WithOptions(o))
+	if stats != nil {
+		name := filepath.Base(path)
+		for i := range stats.Errors {
+			stats.Errors[i].File = name
+		}
+	}
+	return stats, err
+}
+
+// checkpointSample is the bytes of the start and of the end of a file that its checkpoint key hashes
+const checkpointSample = 64 << 10
+
+// setCheckpoint makes a Resume import of path record its progress under a key of the file and of the options
+// that decide its rows, so that renaming the file doesn't lose it, and changing the file or the options starts over
+// The key hashes the size, the modification time and the start and end of the file rather than all of it, which
+// would read a large file twice
+func setCheckpoint(o *ImportOptions, path string) error {
+	if !o.Resume {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d %d\n", info.Size(), info.ModTime().UnixNano())
+	if _, err := io.CopyN(hash, file, checkpointSample); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if size := info.Size(); size > checkpointSample {
+		tail := max(checkpointSample, size-checkpointSample)
+		if _, err := io.Copy(hash, io.NewSectionReader(file, tail, size-tail)); err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+		}
+	}
+	profanity := ""
+	if o.ProfanityTagger != nil {
+		profanity = o.ProfanityTagger.Tag
+	}
+	fmt.Fprintf(hash, "\n%d %d %q %v %q %t %q %q", o.LabelColumn, o.TagsColumn, o.TagSeparator, o.RankThresholds,
+		o.Tag, o.AutoTag, profanity, o.Validation)
+
+	o.checkpoint, o.checkpointFile = hex.EncodeToString(hash.Sum(nil)), path
+	return nil
+}
+
+// checkFileSize returns ErrTooLarge if the file is larger than the MaxFileSize option
+func checkFileSize(path string, o ImportOptions) error {
+	if o.MaxFileSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	if info.Size() > o.MaxFileSize {
+		return fmt.Errorf("%w: %.1f GB, the limit is %.1f GB", ErrTooLarge, float64(info.Size())/(1<<30), float64(o.MaxFileSize)/(1<<30))
+	}
+	return nil
+}
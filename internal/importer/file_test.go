@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbpkg "premium-list-maker/internal/db"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestImportFile_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	db, err := dbpkg.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gzPath := filepath.Join(dir, "letters.csv.gz")
+	f, err := os.Create(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("label\nabc\nxyz\n"))
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	zstPath := filepath.Join(dir, "feed.jsonl.zst")
+	f, err = os.Create(zstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw.Write([]byte(`{"label": "shoes", "tags": ["fashion"]}` + "\n\"bags\"\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for path, want := range map[string]int{gzPath: 2, zstPath: 2} {
+		if !IsImportFile(path) {
+			t.Errorf("IsImportFile(%q) = false", path)
+		}
+		stats, err := ImportFile(db, path, WithTag(FileTag(path)))
+		if err != nil {
+			t.Fatalf("ImportFile(%q): %v", path, err)
+		}
+		if stats.Imported != want {
+			t.Errorf("%s: imported %d, want %d", filepath.Base(path), stats.Imported, want)
+		}
+	}
+
+	labels, err := db.ListLabels(dbpkg.LabelFilter{Tags: []string{"fashion", "feed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].Label != "shoes" {
+		t.Errorf("labels tagged fashion and feed = %v, want shoes", labels)
+	}
+
+	lines, err := CountCSVLines(gzPath)
+	if err != nil || lines != 3 {
+		t.Errorf("CountCSVLines = %d, %v, want 3", lines, err)
+	}
+	if _, err := EstimateCSVLines(gzPath); err == nil {
+		t.Error("EstimateCSVLines of a compressed file succeeded")
+	}
+}
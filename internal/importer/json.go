@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+// jsonRecord is a label of a JSON import, e.g. {"label": "example", "tags": ["brand", "top 5k"]}
+// A record may also be just the label as a string
+type jsonRecord struct {
+	Label *string  `json:"label"`
+	Tags  []string `json:"tags"`
+}
+
+// ImportJSON imports labels from a JSON or JSONL file into the database, like ImportCSV
+// A .json file holds an array of records, a .jsonl file one record per line; see ImportJSONReader
+func ImportJSON(db dbpkg.Store, jsonPath string, opts ...ImportOption) (*ImportStats, error) {
+	return importFile(db, jsonPath, "JSON", ImportJSONReader, opts)
+}
+
+// ImportJSONReader is ImportJSON reading from r
+// Input starting with [ is an array of records, other input has one record per line (JSONL)
+// Records are objects with a label and optional tags, which are added to the label, or label strings;
+// a record that can't be parsed is recorded as an error, except inside an array, where it ends the import
+func ImportJSONReader(db dbpkg.Store, r io.Reader, opts ...ImportOption) (*ImportStats, error) {
+	o, err := newImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse obvious non-JSON input before it turns into millions of parse errors
+	buffered := bufio.NewReaderSize(r, sniffSize)
+	sample, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+	if err := sniffJSON(sample); err != nil {
+		return nil, err
+	}
+
+	// Skip a byte order mark, which the decoder doesn't
+	if bytes.HasPrefix(sample, []byte("\xef\xbb\xbf")) {
+		buffered.Discard(3)
+		sample = sample[3:]
+	}
+
+	src := &jsonSource{reader: buffered}
+	if trimmed := bytes.TrimLeft(sample, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		src.array = json.NewDecoder(buffered)
+		if _, err := src.array.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read JSON: %w", err)
+		}
+	}
+	return importRows(db, src, o)
+}
+
+// jsonSource reads the records of a JSON array, or of JSONL lines if array is nil
+// The line of an array record is its position in the array
+type jsonSource struct {
+	reader *bufio.Reader
+	array  *json.Decoder
+	line   int
+}
+
+func (s *jsonSource) next() (sourceRow, error) {
+	if s.array != nil {
+		if !s.array.More() {
+			return sourceRow{}, io.EOF
+		}
+		var raw json.RawMessage
+		if err := s.array.Decode(&raw); err != nil {
+			return sourceRow{}, fmt.Errorf("invalid JSON array: %w", err)
+		}
+		s.line++
+		return s.row(raw), nil
+	}
+
+	data, err := s.reader.ReadBytes('\n')
+	if len(data) == 0 && err != nil {
+		return sourceRow{}, err
+	}
+	if err != nil && err != io.EOF {
+		return sourceRow{}, err
+	}
+	s.line++
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return sourceRow{line: s.line}, nil
+	}
+	return s.row(data), nil
+}
+
+// row parses a record
+func (s *jsonSource) row(data []byte) sourceRow {
+	var record jsonRecord
+	if len(data) > 0 && data[0] == '"' {
+		record.Label = new(string)
+		if err := json.Unmarshal(data, record.Label); err != nil {
+			return sourceRow{line: s.line, err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+	} else if err := json.Unmarshal(data, &record); err != nil {
+		return sourceRow{line: s.line, err: fmt.Errorf("invalid JSON record: %w", err)}
+	}
+	if record.Label == nil {
+		return sourceRow{line: s.line, err: fmt.Errorf("record has no label")}
+	}
+
+	row := sourceRow{line: s.line, label: strings.ToLower(strings.TrimSpace(*record.Label))}
+	for _, tag := range record.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			row.tags = append(row.tags, tag)
+		}
+	}
+	return row
+}
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+func TestImportJSONReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		labels string // label:tags, ordered by label
+		errors int
+	}{
+		{
+			name: "jsonl",
+			input: `{"label": "Shoes", "tags": ["fashion", " brand "]}
+"bags"
+
+{"label": "hats", "tags": [], "source": "feed"}
+{"label": "broken"
+{"tags": ["fashion"]}
+`,
+			labels: "bags:acquired,hats:acquired,shoes:acquired;brand;fashion",
+			errors: 2,
+		},
+		{
+			name:   "array",
+			input:  "\xef\xbb\xbf[\n  {\"label\": \"shoes\", \"tags\": [\"fashion\"]},\n  \"bags\"\n]\n",
+			labels: "bags:acquired,shoes:acquired;fashion",
+		},
+		{
+			name:   "broken array",
+			input:  `[{"label": "shoes"}, {"label": ]`,
+			labels: "shoes:acquired",
+			errors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			stats, err := ImportJSONReader(db, strings.NewReader(tt.input), WithTag("acquired"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stats.Errors) != tt.errors {
+				t.Errorf("errors = %v, want %d", stats.Errors, tt.errors)
+			}
+			for _, e := range stats.Errors {
+				if !errors.Is(e, ErrParse) {
+					t.Errorf("error %v is not a parse error", e)
+				}
+			}
+
+			labels, err := db.ListLabels(dbpkg.LabelFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, l := range labels {
+				tags := append([]string(nil), l.Tags...)
+				sort.Strings(tags)
+				got = append(got, l.Label+":"+strings.Join(tags, ";"))
+			}
+			if strings.Join(got, ",") != tt.labels {
+				t.Errorf("labels = %v, want %s", got, tt.labels)
+			}
+		})
+	}
+}
+
+func TestImportJSONReader_NotJSON(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for name, content := range map[string]string{
+		"csv":  "label,tags\nshoes,fashion\n",
+		"gzip": "\x1f\x8b\x08\x00",
+	} {
+		if _, err := ImportJSONReader(db, strings.NewReader(content)); !errors.Is(err, ErrNotJSON) {
+			t.Errorf("%s: err = %v, want ErrNotJSON", name, err)
+		}
+	}
+}
+
+func TestFileTag(t *testing.T) {
+	for path, want := range map[string]string{
+		"lists/Cities 250k+.csv": "Cities 250k+",
+		"feed.JSONL":             "feed",
+		"acquired.json":          "acquired",
+		"notes.txt":              "notes.txt",
+		"dump.csv.gz":            "dump",
+		"feed.JSONL.zst":         "feed",
+		"book.xlsx.gz":           "book.xlsx.gz",
+	} {
+		if got := FileTag(path); got != want {
+			t.Errorf("FileTag(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagger"
+)
+
+// Defaults of a CSV import
+const (
+	DefaultBatchSize      = 10000   // Labels inserted per batch
+	DefaultCommitInterval = 100000  // Labels per transaction
+	DefaultMaxFileSize    = 4 << 30 // Bytes of the largest file ImportCSV takes
+	DefaultTagSeparator   = ";"     // Separates the tags of a tags column, as export writes them
+)
+
+// Validation profiles decide which labels an import accepts
+const (
+	ValidationStrict = "strict" // Valid LDH labels and IDNs (ValidateLabel)
+	ValidationLDH    = "ldh"    // Letters, digits and inner hyphens, without the IDN rules, e.g. for legacy lists
+)
+
+// ImportOptions controls a CSV import
+// The zero value imports labels without tags, in batches of DefaultBatchSize, validated strictly
+type ImportOptions struct {
+	AutoTag         bool                   // Add length tags (len:N) and content tags (e.g. tld-word)
+	Tag             string                 // Tag added to every imported label, typically the file name
+	ExecTagger      *tagger.ExecTagger     // External tagger sent every batch; its tags are added
+	RankThresholds  []int                  // Add rank:topN tags by the label's position in the file
+	ProfanityTagger *tagger.WordListTagger // Labels containing listed words get its tag
+	BatchSize       int                    // Rows parsed and inserted per batch (default DefaultBatchSize)
+	CommitInterval  int                    // Labels per transaction (default DefaultCommitInterval)
+	Validation      string                 // ValidationStrict (default) or ValidationLDH
+	Progress        progress.Func          // Gets the lines read after every batch and once at the end
+	Context         context.Context        // Cancels the import between batches (default: never)
+	Workers         int                    // Goroutines validating and tagging batches (default: one per CPU)
+	MaxFileSize     int64                  // ImportCSV refuses larger files (default DefaultMaxFileSize, negative for no limit)
+	LabelColumn     int                    // 1-based CSV column of the labels (default 1)
+	TagsColumn      int                    // 1-based CSV column of tags added to the label, 0 for none
+	TagSeparator    string                 // Separates the tags of TagsColumn (default DefaultTagSeparator)
+	Resume          bool                   // Resume an interrupted import of the same file after the last line it committed
+
+	// checkpoint is the key of the file and options of a Resume import, under which every commit records its last line
+	checkpoint     string
+	checkpointFile string
+}
+
+// ImportOption sets an import option
+type ImportOption func(*ImportOptions)
+
+// WithAutoTag adds length and content tags to the imported labels
+func WithAutoTag() ImportOption {
+	return func(o *ImportOptions) { o.AutoTag = true }
+}
+
+// WithTag adds tag to every imported label
+func WithTag(tag string) ImportOption {
+	return func(o *ImportOptions) { o.Tag = tag }
+}
+
+// WithExecTagger sends every batch of labels to an external tagger
+func WithExecTagger(t *tagger.ExecTagger) ImportOption {
+	return func(o *ImportOptions) { o.ExecTagger = t }
+}
+
+// WithRankThresholds adds rank:topN tags by position in the file
+func WithRankThresholds(thresholds []int) ImportOption {
+	return func(o *ImportOptions) { o.RankThresholds = thresholds }
+}
+
+// WithProfanityTagger tags labels containing words of a word list
+func WithProfanityTagger(t *tagger.WordListTagger) ImportOption {
+	return func(o *ImportOptions) { o.ProfanityTagger = t }
+}
+
+// WithBatchSize sets the number of labels inserted per batch
+func WithBatchSize(n int) ImportOption {
+	return func(o *ImportOptions) { o.BatchSize = n }
+}
+
+// WithCommitInterval sets the number of labels per transaction
+func WithCommitInterval(n int) ImportOption {
+	return func(o *ImportOptions) { o.CommitInterval = n }
+}
+
+// WithValidation selects the validation profile
+func WithValidation(profile string) ImportOption {
+	return func(o *ImportOptions) { o.Validation = profile }
+}
+
+// WithProgress sets the progress function
+func WithProgress(f progress.Func) ImportOption {
+	return func(o *ImportOptions) { o.Progress = f }
+}
+
+// WithWorkers sets the number of goroutines validating and tagging batches
+func WithWorkers(n int) ImportOption {
+	return func(o *ImportOptions) { o.Workers = n }
+}
+
+// WithContext stops the import when ctx is done, rolling back the labels since the last commit
+func WithContext(ctx context.Context) ImportOption {
+	return func(o *ImportOptions) { o.Context = ctx }
+}
+
+// WithMaxFileSize sets the size of the largest file ImportCSV takes, negative for no limit
+func WithMaxFileSize(n int64) ImportOption {
+	return func(o *ImportOptions) { o.MaxFileSize = n }
+}
+
+// WithLabelColumn reads the labels of CSV files from a 1-based column instead of the first
+func WithLabelColumn(n int) ImportOption {
+	return func(o *ImportOptions) { o.LabelColumn = n }
+}
+
+// WithTagsColumn adds the tags in a 1-based column of CSV files to their labels
+func WithTagsColumn(n int) ImportOption {
+	return func(o *ImportOptions) { o.TagsColumn = n }
+}
+
+// WithTagSeparator sets the separator of the tags of the tags column
+func WithTagSeparator(sep string) ImportOption {
+	return func(o *ImportOptions) { o.TagSeparator = sep }
+}
+
+// WithResume resumes an interrupted import of the same file with the same options after the last line it committed,
+// and records the progress of this one so that it can be resumed in turn; it applies to imports of files only
+func WithResume() ImportOption {
+	return func(o *ImportOptions) { o.Resume = true }
+}
+
+// WithOptions replaces all options, for callers that build an ImportOptions up front
+func WithOptions(opts ImportOptions) ImportOption {
+	return func(o *ImportOptions) { *o = opts }
+}
+
+// newImportOptions applies opts over the defaults and checks the result
+func newImportOptions(opts []ImportOption) (ImportOptions, error) {
+	var o ImportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.BatchSize == 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.CommitInterval == 0 {
+		o.CommitInterval = DefaultCommitInterval
+	}
+	if o.Validation == "" {
+		o.Validation = ValidationStrict
+	}
+	if o.Workers == 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.MaxFileSize == 0 {
+		o.MaxFileSize = DefaultMaxFileSize
+	}
+	if o.LabelColumn == 0 {
+		o.LabelColumn = 1
+	}
+	if o.TagSeparator == "" {
+		o.TagSeparator = DefaultTagSeparator
+	}
+	if o.BatchSize < 0 || o.CommitInterval < 0 || o.Workers < 0 {
+		return o, fmt.Errorf("batch size, commit interval and workers must be positive")
+	}
+	if o.LabelColumn < 0 || o.TagsColumn < 0 {
+		return o, fmt.Errorf("label and tags columns must be positive")
+	}
+	if o.TagsColumn == o.LabelColumn {
+		return o, fmt.Errorf("label and tags can't be in the same column %d", o.LabelColumn)
+	}
+	if _, err := validator(o.Validation); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// context returns the context of the import
+func (o ImportOptions) context() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+// validator returns the label validation function of a profile
+func validator(profile string) (func(string) error, error) {
+	switch profile {
+	case ValidationStrict:
+		return ValidateLabel, nil
+	case ValidationLDH:
+		return validateLDH, nil
+	default:
+		return nil, fmt.Errorf("unknown validation profile %q (use %s or %s)", profile, ValidationStrict, ValidationLDH)
+	}
+}
+
+// validateLDH checks the length and characters of a label and that it doesn't start or end with a hyphen
+func validateLDH(label string) error {
+	if len(label) > LabelMaxLen || len(label) < LabelMinLen {
+		return ErrInvalidLabelLength
+	}
+	if !validLabelChars.MatchString(label) {
+		return ErrLabelContainsInvalidCharacter
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return ErrInvalidLabelDash
+	}
+	return nil
+}
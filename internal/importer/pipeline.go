@@ -0,0 +1,279 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"premium-list-maker/internal/tagger"
+)
+
+// The import is a pipeline: one goroutine parses the input (CSV, JSON, ...) into chunks, workers validate and tag
+// the chunks on all cores, and the importer writes them to the database in file order on a single goroutine
+
+// rawRow is a candidate label read from the input
+type rawRow struct {
+	line     int // Line in the file
+	position int // Position among the data rows, for rank tags
+	label    string
+	tags     []string // Tags the input gives the label
+}
+
+// sourceRow is a row of an input format
+type sourceRow struct {
+	line   int    // Line (or record) in the file
+	label  string // Lowercased and trimmed, empty for rows without a label
+	tags   []string
+	header bool  // The row is a header, not a label
+	err    error // The row couldn't be parsed; the input continues with the next row
+}
+
+// rowSource reads the rows of an input format for the parser stage
+type rowSource interface {
+	// next returns the next row; io.EOF at the end, any other error ends the input early
+	next() (sourceRow, error)
+}
+
+// csvSource reads the labels in a column of a CSV file, and optionally their tags in another, skipping a header row
+type csvSource struct {
+	reader        *csv.Reader
+	labelColumn   int // 0-based
+	tagsColumn    int // 0-based, negative for none
+	tagSeparator  string
+	line          int
+	headerSkipped bool
+}
+
+// newCSVSource reads the columns the options select
+func newCSVSource(reader *csv.Reader, o ImportOptions) *csvSource {
+	return &csvSource{
+		reader:       reader,
+		labelColumn:  o.LabelColumn - 1,
+		tagsColumn:   o.TagsColumn - 1,
+		tagSeparator: o.TagSeparator,
+	}
+}
+
+func (s *csvSource) next() (sourceRow, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		return sourceRow{}, err
+	}
+	s.line++
+	if err != nil {
+		// For other errors, try to continue but log a warning
+		return sourceRow{line: s.line, err: err}, nil
+	}
+	if len(record) == 0 {
+		return sourceRow{line: s.line}, nil
+	}
+	if s.labelColumn >= len(record) {
+		return sourceRow{line: s.line, err: fmt.Errorf("row has %d column(s), the label is in column %d", len(record), s.labelColumn+1)}, nil
+	}
+	label := strings.ToLower(strings.TrimSpace(record[s.labelColumn]))
+
+	// Check if this looks like a header row
+	if label != "" && !s.headerSkipped && isHeaderRow(label) {
+		s.headerSkipped = true
+		return sourceRow{line: s.line, header: true}, nil
+	}
+	row := sourceRow{line: s.line, label: label}
+	if s.tagsColumn >= 0 && s.tagsColumn < len(record) {
+		for _, tag := range strings.Split(record[s.tagsColumn], s.tagSeparator) {
+			// Control characters are dropped, the database separates the tags it lists for a label with one
+			if tag = strings.TrimSpace(strings.Map(dropControl, tag)); tag != "" {
+				row.tags = append(row.tags, tag)
+			}
+		}
+	}
+	return row, nil
+}
+
+// dropControl maps control characters to nothing, for strings.Map
+func dropControl(r rune) rune {
+	if unicode.IsControl(r) {
+		return -1
+	}
+	return r
+}
+
+// rowChunk is a batch of rows from the parser stage
+type rowChunk struct {
+	seq      int
+	rows     []rawRow
+	skipped  int  // Empty and header rows
+	header   bool // The header row was in this chunk
+	errors   []ImportError
+	lastLine int
+}
+
+// labelChunk is a chunk after validation and tagging, ready to be written
+type labelChunk struct {
+	seq      int
+	labels   []LabelData
+	tags     [][]string // Names of the tags of each label, except the length, file and external tags
+	skipped  int
+	header   bool
+	errors   []ImportError
+	lastLine int
+}
+
+// rowSlices and labelSlices recycle the batch-sized slices of chunks once a chunk is done with,
+// so that large imports don't allocate a new slice per batch
+var (
+	rowSlices   sync.Pool
+	labelSlices sync.Pool
+)
+
+// getRows returns an empty row slice with room for size rows
+func getRows(size int) []rawRow {
+	if rows, ok := rowSlices.Get().(*[]rawRow); ok && cap(*rows) >= size {
+		return (*rows)[:0]
+	}
+	return make([]rawRow, 0, size)
+}
+
+// getLabels returns an empty label slice with room for size labels
+func getLabels(size int) []LabelData {
+	if labels, ok := labelSlices.Get().(*[]LabelData); ok && cap(*labels) >= size {
+		return (*labels)[:0]
+	}
+	return make([]LabelData, 0, size)
+}
+
+// readChunks is the parser stage: it reads the input into chunks of up to size candidate labels
+// Empty rows and the header row are counted but not passed on, and the lines up to after are skipped
+func readChunks(ctx context.Context, src rowSource, size, after int, out chan<- rowChunk) {
+	defer close(out)
+
+	lineNum, position, seq := 0, 0, 0
+	chunk := rowChunk{rows: getRows(size)}
+	send := func() bool {
+		chunk.seq, chunk.lastLine = seq, lineNum
+		seq++
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+			return false
+		}
+		chunk = rowChunk{rows: getRows(size)}
+		return true
+	}
+
+	for {
+		row, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			chunk.errors = append(chunk.errors, ImportError{Line: lineNum + 1, Kind: ErrParse, Err: err})
+			break
+		}
+		lineNum = row.line
+
+		// The lines up to after were imported by an earlier import; they only count for the positions of ranked lists
+		if row.line <= after {
+			if row.err == nil && !row.header && row.label != "" {
+				position++
+			}
+			continue
+		}
+
+		switch {
+		case row.err != nil:
+			chunk.errors = append(chunk.errors, ImportError{Line: row.line, Kind: ErrParse, Err: row.err})
+			continue
+		case row.header:
+			chunk.header = true
+			chunk.skipped++
+			continue
+		case row.label == "":
+			chunk.skipped++
+			continue
+		}
+
+		// Invalid labels still occupy their position in a ranked list
+		position++
+		chunk.rows = append(chunk.rows, rawRow{line: row.line, position: position, label: row.label, tags: row.tags})
+		if len(chunk.rows) >= size && !send() {
+			return
+		}
+	}
+	send()
+}
+
+// tagChunks is the validation and tagging stage, run by several workers at once
+func tagChunks(ctx context.Context, in <-chan rowChunk, out chan<- labelChunk, validate func(string) error, o ImportOptions) {
+	for chunk := range in {
+		tagged := labelChunk{
+			seq:      chunk.seq,
+			labels:   getLabels(len(chunk.rows)),
+			tags:     make([][]string, 0, len(chunk.rows)),
+			skipped:  chunk.skipped,
+			header:   chunk.header,
+			errors:   chunk.errors,
+			lastLine: chunk.lastLine,
+		}
+		for _, row := range chunk.rows {
+			if err := validate(row.label); err != nil {
+				tagged.skipped++
+				tagged.errors = append(tagged.errors, ImportError{Line: row.line, Label: row.label, Kind: ErrInvalidLabel, Err: err})
+				continue
+			}
+			tagged.labels = append(tagged.labels, LabelData{Label: row.label, Length: len(row.label)})
+			tagged.tags = append(tagged.tags, labelTags(row, o))
+		}
+		rowSlices.Put(&chunk.rows)
+
+		select {
+		case out <- tagged:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// labelTags returns the names of the tags the options give a label
+// The length tag is left to the writer, which adds it to the whole batch at once
+func labelTags(row rawRow, o ImportOptions) []string {
+	var tags []string
+	if o.AutoTag {
+		tags = append(tags, tagger.GenerateAutoTags(row.label)...)
+	}
+	if o.ProfanityTagger != nil && o.ProfanityTagger.Match(row.label) {
+		tags = append(tags, o.ProfanityTagger.Tag)
+	}
+	tags = append(tags, row.tags...)
+	return append(tags, tagger.GenerateRankTags(row.position, o.RankThresholds)...)
+}
+
+// inOrder passes on the chunks of the workers in file order
+func inOrder(ctx context.Context, in <-chan labelChunk) <-chan labelChunk {
+	out := make(chan labelChunk)
+	go func() {
+		defer close(out)
+		pending := make(map[int]labelChunk)
+		next := 0
+		for chunk := range in {
+			pending[chunk.seq] = chunk
+			for {
+				c, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+// Header names of the columns of a sales data file, matched like feed headers
+var (
+	salesLabelColumns    = []string{"label", "domain", "domain name", "name"}
+	salesPriceColumns    = []string{"price", "sale price", "sold price", "amount"}
+	salesDateColumns     = []string{"date", "sale date", "sold on", "sold at", "sold date"}
+	salesCurrencyColumns = []string{"currency", "price currency"}
+)
+
+// salesDateLayouts are the date formats accepted in sales data
+var salesDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2006/01/02", "2006-01"}
+
+// salesColumns are the column indexes of sales data, -1 if the data lacks the column
+type salesColumns struct {
+	label, price, date, currency int
+}
+
+// ParseSales reads historic sales data (a CSV with label or domain, price and date columns and a header row)
+// and calls fn for every sale; a currency column or code in the price is optional, defaultCurrency is used without one
+// Rows that can't be parsed are passed to onError and skipped
+func ParseSales(r io.Reader, defaultCurrency string, fn func(dbpkg.Sale) error, onError func(line int, err error)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("sales data is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read sales header: %w", err)
+	}
+	find := headerFinder(header)
+	cols := salesColumns{
+		label:    find(salesLabelColumns),
+		price:    find(salesPriceColumns),
+		date:     find(salesDateColumns),
+		currency: find(salesCurrencyColumns),
+	}
+	if cols.label < 0 || cols.price < 0 || cols.date < 0 {
+		return fmt.Errorf("unrecognized sales header %q: expected label/domain, price and date columns", strings.Join(header, ","))
+	}
+
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			onError(line, err)
+			continue
+		}
+
+		sale, err := parseSaleRow(row, cols, defaultCurrency)
+		if err != nil {
+			onError(line, err)
+			continue
+		}
+		if sale == nil {
+			continue
+		}
+		if err := fn(*sale); err != nil {
+			return err
+		}
+	}
+}
+
+// parseSaleRow maps a row onto a sale, returning nil for blank rows
+func parseSaleRow(row []string, cols salesColumns, defaultCurrency string) (*dbpkg.Sale, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	name := field(cols.label)
+	if name == "" {
+		return nil, nil
+	}
+	label, err := domainLabel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	price, currency, err := parseFeedPrice(field(cols.price))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if price == nil {
+		return nil, fmt.Errorf("%s: no price", name)
+	}
+	if c := strings.ToUpper(field(cols.currency)); c != "" {
+		currency = c
+	}
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	if currency == "" {
+		return nil, fmt.Errorf("%s: no currency (use --currency)", name)
+	}
+
+	date := field(cols.date)
+	for _, layout := range salesDateLayouts {
+		if soldOn, err := time.Parse(layout, date); err == nil {
+			return &dbpkg.Sale{Label: label, Price: *price, Currency: currency, SoldOn: soldOn}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: invalid date %q", name, date)
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrNotCSV is returned for input that obviously isn't a CSV list, e.g. binary content or absurdly long lines;
+// the import refuses it before the first row instead of recording a parse error for every line
+var ErrNotCSV = errors.New("not a CSV file")
+
+// ErrNotJSON is returned for .json and .jsonl input that obviously isn't JSON
+var ErrNotJSON = errors.New("not a JSON file")
+
+// ErrNotZone is returned for zone file input that obviously isn't a text zone file
+var ErrNotZone = errors.New("not a zone file")
+
+// ErrTooLarge is returned by ImportCSV for files larger than the MaxFileSize option
+var ErrTooLarge = errors.New("file too large")
+
+const (
+	sniffSize     = 1 << 20  // Bytes at the start of the input checked before importing
+	maxLineLength = 64 << 10 // A line this long is no label list
+)
+
+// signatures are the leading bytes of formats that get mistaken for CSV files
+var signatures = []struct {
+	magic string
+	what  string
+}{
+	{"PK\x03\x04", "a zip archive or Excel workbook (split workbooks with split-xlsx)"},
+	{"\x1f\x8b", "gzip-compressed (name it .gz to import it)"},
+	{"\x28\xb5\x2f\xfd", "zstd-compressed (name it .zst to import it)"},
+	{"%PDF", "a PDF document"},
+	{"\xd0\xcf\x11\xe0", "a legacy Office document"},
+	{"SQLite format 3\x00", "an SQLite database"},
+	{"\xff\xfe", "UTF-16 text (save it as UTF-8)"},
+	{"\xfe\xff", "UTF-16 text (save it as UTF-8)"},
+}
+
+// sniffCSV checks the start of the input for content no CSV label list has
+func sniffCSV(sample []byte) error {
+	if err := sniffBinary(sample, ErrNotCSV); err != nil {
+		return err
+	}
+
+	line := 1
+	for len(sample) > 0 {
+		n := bytes.IndexByte(sample, '\n')
+		if n < 0 {
+			n = len(sample)
+		}
+		if n > maxLineLength {
+			return fmt.Errorf("%w: line %d is longer than %d KB", ErrNotCSV, line, maxLineLength>>10)
+		}
+		sample = sample[min(n+1, len(sample)):]
+		line++
+	}
+	return nil
+}
+
+// sniffJSON checks the start of the input for content no JSON label list has
+func sniffJSON(sample []byte) error {
+	if err := sniffBinary(sample, ErrNotJSON); err != nil {
+		return err
+	}
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(sample, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) > 0 && !bytes.ContainsAny(trimmed[:1], `[{"`) {
+		return fmt.Errorf("%w: it starts with %q, not an array, object or string", ErrNotJSON, trimmed[:1])
+	}
+	return nil
+}
+
+// sniffBinary checks the start of the input for known binary formats and binary content, returning kind if found
+func sniffBinary(sample []byte, kind error) error {
+	for _, s := range signatures {
+		if bytes.HasPrefix(sample, []byte(s.magic)) {
+			return fmt.Errorf("%w: the file is %s", kind, s.what)
+		}
+	}
+	if i := bytes.IndexByte(sample, 0); i >= 0 {
+		return fmt.Errorf("%w: binary content (NUL byte at offset %d)", kind, i)
+	}
+	control := 0
+	for _, b := range sample {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			control++
+		}
+	}
+	if control*10 > len(sample) {
+		return fmt.Errorf("%w: binary content (%d%% control characters)", kind, control*100/len(sample))
+	}
+	return nil
+}
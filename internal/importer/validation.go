@@ -66,3 +66,27 @@ func ValidateLabel(label string) error {
 
 	return nil
 }
+
+// NormalizeLabel lowercases and trims a label and converts U-labels to their A-label (xn--) form
+// Labels that can't be converted are returned lowercased so they still compare consistently
+func NormalizeLabel(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if isASCII(label) {
+		return label
+	}
+	aLabel, err := idna.Lookup.ToASCII(label)
+	if err != nil {
+		return label
+	}
+	return aLabel
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
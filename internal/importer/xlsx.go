@@ -1,9 +1,9 @@
 package importer
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -12,6 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"premium-list-maker/internal/atomicfile"
+	"premium-list-maker/internal/csvout"
+	dbpkg "premium-list-maker/internal/db"
+
 	"github.com/xuri/excelize/v2"
 )
 
@@ -21,11 +25,22 @@ type TierConfig struct {
 	Tags []string `json:"tags"`
 }
 
+// SplitOptions controls how SplitXLSX splits a workbook
+type SplitOptions struct {
+	Format    string         // "andy" further splits sheets by their "Tier Level" column
+	TiersName string         // File name of the tiers JSON written in andy format, tiers-<date>.json if empty
+	Quoting   csvout.Quoting // Quoting of the CSV files
+}
+
 // SplitXLSX splits an Excel file into CSV files, one per sheet
 // Only processes sheets where the first column appears to contain domain labels
-// If format is "andy", it further splits sheets by "Tier Level" column
 // Returns a summary of processed and skipped sheets
-func SplitXLSX(xlsxPath, outputDir, format string) error {
+func SplitXLSX(xlsxPath, outputDir string, opts SplitOptions) error {
+	if err := opts.Quoting.Validate(); err != nil {
+		return err
+	}
+	format := opts.Format
+
 	// Open Excel file
 	f, err := excelize.OpenFile(xlsxPath)
 	if err != nil {
@@ -82,7 +97,7 @@ func SplitXLSX(xlsxPath, outputDir, format string) error {
 			if tierColIdx == -1 {
 				fmt.Printf("Warning: 'Tier Level' column not found in sheet '%s', using default split\n", sheetName)
 			} else {
-				tiersInSheet, err := splitSheetByTier(rows, sheetName, outputDir, tierColIdx)
+				tiersInSheet, err := splitSheetByTier(rows, sheetName, outputDir, tierColIdx, opts.Quoting)
 				if err != nil {
 					fmt.Printf("Warning: failed to split sheet '%s' by tier: %v\n", sheetName, err)
 					skipped = append(skipped, fmt.Sprintf("%s (split error)", sheetName))
@@ -106,7 +121,7 @@ func SplitXLSX(xlsxPath, outputDir, format string) error {
 		outputPath := filepath.Join(outputDir, outputFile)
 
 		// Write sheet to CSV
-		if err := writeSheetToCSV(rows, outputPath); err != nil {
+		if err := writeSheetToCSV(rows, outputPath, opts.Quoting); err != nil {
 			fmt.Printf("Warning: failed to write sheet '%s' to CSV: %v\n", sheetName, err)
 			skipped = append(skipped, fmt.Sprintf("%s (write error)", sheetName))
 			continue
@@ -118,7 +133,7 @@ func SplitXLSX(xlsxPath, outputDir, format string) error {
 
 	// Generate tiers JSON if in "andy" format and tiers were found
 	if format == "andy" && len(foundTiers) > 0 {
-		if err := generateTiersJSON(foundTiers, outputDir); err != nil {
+		if err := generateTiersJSON(foundTiers, outputDir, opts.TiersName); err != nil {
 			fmt.Printf("Warning: failed to generate tiers JSON: %v\n", err)
 		} else {
 			fmt.Printf("Generated tiers JSON file in %s\n", outputDir)
@@ -146,7 +161,7 @@ func SplitXLSX(xlsxPath, outputDir, format string) error {
 
 // splitSheetByTier splits rows into multiple CSVs based on tier column
 // Returns map of tier numbers to filenames created
-func splitSheetByTier(rows [][]string, sheetName, outputDir string, tierColIdx int) (map[int]string, error) {
+func splitSheetByTier(rows [][]string, sheetName, outputDir string, tierColIdx int, quoting csvout.Quoting) (map[int]string, error) {
 	if len(rows) == 0 {
 		return nil, nil
 	}
@@ -202,7 +217,7 @@ func splitSheetByTier(rows [][]string, sheetName, outputDir string, tierColIdx i
 
 		// Create CSV with header
 		allRows := append([][]string{header}, tierRows...)
-		if err := writeSheetToCSV(allRows, outputPath); err != nil {
+		if err := writeSheetToCSV(allRows, outputPath, quoting); err != nil {
 			return nil, err
 		}
 		fmt.Printf("  -> Created %s (%d rows)\n", outputFilename, len(tierRows))
@@ -213,8 +228,8 @@ func splitSheetByTier(rows [][]string, sheetName, outputDir string, tierColIdx i
 
 }
 
-// generateTiersJSON generates a tiers-<date>.json file with found tiers
-func generateTiersJSON(foundTiers map[int][]string, outputDir string) error {
+// generateTiersJSON generates a tiers JSON file named filename (tiers-<date>.json if empty) with found tiers
+func generateTiersJSON(foundTiers map[int][]string, outputDir, filename string) error {
 	var tierConfigs []TierConfig
 	for tier, tags := range foundTiers {
 		// Dedup tags just in case
@@ -240,14 +255,16 @@ func generateTiersJSON(foundTiers map[int][]string, outputDir string) error {
 	})
 
 	// JSON filename with date and time
-	filename := fmt.Sprintf("tiers-%s.json", time.Now().Format("20060102-150405"))
+	if filename == "" {
+		filename = fmt.Sprintf("tiers-%s.json", time.Now().Format("20060102-150405"))
+	}
 	outputPath := filepath.Join(outputDir, filename)
 
-	file, err := os.Create(outputPath)
+	file, err := atomicfile.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create tiers JSON file: %w", err)
 	}
-	defer file.Close()
+	defer file.Abort()
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "    ")
@@ -255,7 +272,7 @@ func generateTiersJSON(foundTiers map[int][]string, outputDir string) error {
 		return fmt.Errorf("failed to encode tiers JSON: %w", err)
 	}
 
-	return nil
+	return file.Commit()
 }
 
 // isValidLabelSheet checks if the sheet appears to have domain labels in the first column
@@ -318,15 +335,14 @@ func sanitizeSheetName(name string) string {
 }
 
 // writeSheetToCSV writes a sheet's rows to a CSV file
-func writeSheetToCSV(rows [][]string, outputPath string) error {
-	file, err := os.Create(outputPath)
+func writeSheetToCSV(rows [][]string, outputPath string, quoting csvout.Quoting) error {
+	file, err := atomicfile.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create CSV file: %w", err)
 	}
-	defer file.Close()
+	defer file.Abort()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer := csvout.NewWriter(file, quoting)
 
 	for _, row := range rows {
 		// Ensure row has at least one column
@@ -337,6 +353,175 @@ func writeSheetToCSV(rows [][]string, outputPath string) error {
 			return fmt.Errorf("failed to write row: %w", err)
 		}
 	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+
+	return file.Commit()
+}
+
+// ImportXLSX imports the labels of an Excel workbook into the database, like ImportCSV
+// Every sheet whose first column appears to contain domain labels is imported with the tag <tag>:<sheet>,
+// where tag is the Tag option or, without one, the file name; the workbook itself gets no tag
+// Errors name the sheet in their File and count lines per sheet
+func ImportXLSX(db dbpkg.Store, xlsxPath string, opts ...ImportOption) (*ImportStats, error) {
+	o, err := newImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFileSize(xlsxPath, o); err != nil {
+		return nil, err
+	}
+	if err := setCheckpoint(&o, xlsxPath); err != nil {
+		return nil, err
+	}
+
+	f, err := excelize.OpenFile(xlsxPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+
+	prefix := o.Tag
+	if prefix == "" {
+		prefix = strings.TrimSuffix(filepath.Base(xlsxPath), filepath.Ext(xlsxPath))
+	}
+	src := &xlsxSource{file: f, sheets: f.GetSheetList(), prefix: prefix}
+	defer src.close()
+	o.Tag = ""
+
+	stats, err := importRows(db, src, o)
+	if stats != nil {
+		stats.SkippedSheets = src.skipped
+		name := filepath.Base(xlsxPath)
+		for i := range stats.Errors {
+			stats.Errors[i].File = name
+			if e := &stats.Errors[i]; e.Line > 0 {
+				sheet, ok := src.sheetOf(e.Line)
+				if !ok {
+					continue
+				}
+				e.File = fmt.Sprintf("%s [%s]", name, sheet.name)
+				e.Line -= sheet.firstLine - 1
+			}
+		}
+	}
+	return stats, err
+}
+
+// xlsxSheet is a sheet of a workbook import, whose rows are numbered on from those of the sheets before it
+type xlsxSheet struct {
+	name      string
+	firstLine int
+}
+
+// xlsxSource reads the labels in the first column of the label sheets of a workbook, streaming the rows
+type xlsxSource struct {
+	file    *excelize.File
+	sheets  []string // Sheets still to read
+	prefix  string
+	skipped []string // Sheets that don't appear to contain labels
+
+	rows     *excelize.Rows
+	buffered [][]string // Rows read ahead to check the sheet
+	tags     []string
+	header   bool // The header row of the current sheet was skipped
+	line     int
+	read     []xlsxSheet
+}
+
+func (s *xlsxSource) next() (sourceRow, error) {
+	for {
+		if s.rows == nil {
+			if len(s.sheets) == 0 {
+				return sourceRow{}, io.EOF
+			}
+			if err := s.openSheet(); err != nil {
+				return sourceRow{}, err
+			}
+			continue
+		}
+
+		var record []string
+		if len(s.buffered) > 0 {
+			record, s.buffered = s.buffered[0], s.buffered[1:]
+		} else if s.rows.Next() {
+			var err error
+			if record, err = s.rows.Columns(); err != nil {
+				s.line++
+				return sourceRow{line: s.line, err: err}, nil
+			}
+		} else {
+			err := s.rows.Error()
+			s.rows.Close()
+			s.rows = nil
+			if err != nil {
+				return sourceRow{}, fmt.Errorf("failed to read sheet '%s': %w", s.read[len(s.read)-1].name, err)
+			}
+			continue
+		}
+
+		s.line++
+		if len(record) == 0 {
+			return sourceRow{line: s.line}, nil
+		}
+		label := strings.ToLower(strings.TrimSpace(record[0]))
+		if label != "" && !s.header && isHeaderRow(label) {
+			s.header = true
+			return sourceRow{line: s.line, header: true}, nil
+		}
+		return sourceRow{line: s.line, label: label, tags: s.tags}, nil
+	}
+}
+
+// openSheet starts reading the next sheet, or skips it if its first rows don't look like labels
+func (s *xlsxSource) openSheet() error {
+	name := s.sheets[0]
+	s.sheets = s.sheets[1:]
+	rows, err := s.file.Rows(name)
+	if err != nil {
+		return fmt.Errorf("failed to read sheet '%s': %w", name, err)
+	}
+
+	// isValidLabelSheet checks up to 10 rows after a header
+	var first [][]string
+	for len(first) < 11 && rows.Next() {
+		record, err := rows.Columns()
+		if err != nil {
+			break
+		}
+		first = append(first, record)
+	}
+	if !isValidLabelSheet(first) {
+		rows.Close()
+		s.skipped = append(s.skipped, name)
+		return nil
+	}
 
+	s.rows, s.buffered, s.header = rows, first, false
+	s.tags = []string{s.prefix + ":" + name}
+	s.read = append(s.read, xlsxSheet{name: name, firstLine: s.line + 1})
 	return nil
 }
+
+// sheetOf returns the sheet of a line, false if no sheet was opened before the error
+func (s *xlsxSource) sheetOf(line int) (xlsxSheet, bool) {
+	if len(s.read) == 0 {
+		return xlsxSheet{}, false
+	}
+	sheet := s.read[0]
+	for _, next := range s.read[1:] {
+		if next.firstLine > line {
+			break
+		}
+		sheet = next
+	}
+	return sheet, true
+}
+
+func (s *xlsxSource) close() {
+	if s.rows != nil {
+		s.rows.Close()
+	}
+}
@@ -1,11 +1,14 @@
 package importer
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	dbpkg "premium-list-maker/internal/db"
+
 	"github.com/xuri/excelize/v2"
 )
 
@@ -66,7 +69,7 @@ func TestSplitXLSX_AndyFormat(t *testing.T) {
 	outDir := filepath.Join(tmpDir, "output")
 
 	// Run SplitXLSX with "andy" format
-	if err := SplitXLSX(xlsxPath, outDir, "andy"); err != nil {
+	if err := SplitXLSX(xlsxPath, outDir, SplitOptions{Format: "andy"}); err != nil {
 		t.Fatalf("SplitXLSX failed: %v", err)
 	}
 
@@ -131,7 +134,7 @@ func TestSplitXLSX_AndyFormat_LowestOne(t *testing.T) {
 	}
 
 	outDir := filepath.Join(tmpDir, "output")
-	if err := SplitXLSX(xlsxPath, outDir, "andy"); err != nil {
+	if err := SplitXLSX(xlsxPath, outDir, SplitOptions{Format: "andy"}); err != nil {
 		t.Fatalf("SplitXLSX failed: %v", err)
 	}
 
@@ -181,3 +184,73 @@ func checkFileNotContains(t *testing.T, path, content string) {
 		t.Errorf("file %s expected NOT to contain %q, but it does", path, content)
 	}
 }
+
+func TestImportXLSX(t *testing.T) {
+	dir := t.TempDir()
+	xlsxPath := filepath.Join(dir, "Premium Names.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"Label", "Notes"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"Shoes", "fashion"})
+	f.SetSheetRow("Sheet1", "A3", &[]interface{}{"-bad"})
+	f.SetSheetRow("Sheet1", "A4", &[]interface{}{"bags"})
+	f.NewSheet("Cities, Towns")
+	f.SetSheetRow("Cities, Towns", "A1", &[]interface{}{"paris"})
+	f.SetSheetRow("Cities, Towns", "A2", &[]interface{}{"-nope"})
+	f.NewSheet("Notes")
+	f.SetSheetRow("Notes", "A1", &[]interface{}{"Remember to check these!"})
+	if err := f.SaveAs(xlsxPath); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	db, err := dbpkg.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stats, err := ImportFile(db, xlsxPath, WithTag(FileTag(xlsxPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Imported != 3 || !stats.HeaderSkipped {
+		t.Errorf("imported %d (header skipped: %t), want 3 after the header", stats.Imported, stats.HeaderSkipped)
+	}
+	if strings.Join(stats.SkippedSheets, ",") != "Notes" {
+		t.Errorf("skipped sheets = %v, want Notes", stats.SkippedSheets)
+	}
+	var errs []string
+	for _, e := range stats.Errors {
+		errs = append(errs, fmt.Sprintf("%s line %d", e.File, e.Line))
+	}
+	if strings.Join(errs, ",") != "Premium Names.xlsx [Sheet1] line 3,Premium Names.xlsx [Cities, Towns] line 2" {
+		t.Errorf("errors at %v, want Sheet1 line 3 and Cities line 2", errs)
+	}
+
+	labels, err := db.ListLabels(dbpkg.LabelFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range labels {
+		got = append(got, l.Label+":"+strings.Join(l.Tags, ";"))
+	}
+	if strings.Join(got, ",") != "bags:Premium Names:Sheet1,paris:Premium Names:Cities, Towns,shoes:Premium Names:Sheet1" {
+		t.Errorf("labels = %v, want tags <file>:<sheet>", got)
+	}
+}
+
+func TestXLSXSource_SheetOf(t *testing.T) {
+	// Errors can come before any sheet was opened, e.g. a workbook that fails to stream
+	src := &xlsxSource{}
+	if _, ok := src.sheetOf(1); ok {
+		t.Error("sheetOf found a sheet before any was opened")
+	}
+
+	src.read = []xlsxSheet{{name: "Sheet1", firstLine: 1}, {name: "Cities", firstLine: 4}}
+	for line, want := range map[int]string{1: "Sheet1", 3: "Sheet1", 4: "Cities", 9: "Cities"} {
+		if sheet, ok := src.sheetOf(line); !ok || sheet.name != want {
+			t.Errorf("sheetOf(%d) = %q, %t, want %q", line, sheet.name, ok, want)
+		}
+	}
+}
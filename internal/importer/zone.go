@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+// ParseZoneSLDs reads a DNS master zone file and calls fn for every label
+// directly under the zone origin (e.g. "example" for "www.example.com." in zone "com.")
+// If origin is empty, the first $ORIGIN directive or SOA owner is used as the zone origin
+// Labels are lowercased and passed once per record, so callers must dedupe if needed
+func ParseZoneSLDs(r io.Reader, origin string, fn func(label string) error) error {
+	z := newZoneReader(r, origin)
+	for {
+		label, _, err := z.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(label); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportZone imports the labels under the origin of a DNS master zone file into the database, like ImportCSV,
+// e.g. with WithTag("in-zone") to tag the labels that are delegated
+// If origin is empty, the first $ORIGIN directive or SOA owner is used as the zone origin
+func ImportZone(db dbpkg.Store, zonePath, origin string, opts ...ImportOption) (*ImportStats, error) {
+	return importFile(db, zonePath, "zone", func(db dbpkg.Store, r io.Reader, opts ...ImportOption) (*ImportStats, error) {
+		return ImportZoneReader(db, r, origin, opts...)
+	}, opts)
+}
+
+// ImportZoneReader is ImportZone reading the zone file from r
+func ImportZoneReader(db dbpkg.Store, r io.Reader, origin string, opts ...ImportOption) (*ImportStats, error) {
+	o, err := newImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse binary input, e.g. a compressed zone file
+	buffered := bufio.NewReaderSize(r, sniffSize)
+	sample, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read zone file: %w", err)
+	}
+	if err := sniffBinary(sample, ErrNotZone); err != nil {
+		return nil, err
+	}
+
+	return importRows(db, &zoneSource{zone: newZoneReader(buffered, origin)}, o)
+}
+
+// zoneSource reads the labels of a zone file, once per run of records of the same label
+type zoneSource struct {
+	zone *zoneReader
+	last string
+}
+
+func (s *zoneSource) next() (sourceRow, error) {
+	for {
+		label, line, err := s.zone.next()
+		if err != nil {
+			return sourceRow{}, err
+		}
+		label = NormalizeLabel(label)
+		if label == s.last {
+			continue
+		}
+		s.last = label
+		return sourceRow{line: line, label: label}, nil
+	}
+}
+
+// zoneReader reads the labels under the origin of a master zone file one at a time, see ParseZoneSLDs
+type zoneReader struct {
+	scanner       *bufio.Scanner
+	zoneOrigin    string
+	currentOrigin string
+	lastOwner     string
+	parenDepth    int
+	line          int
+}
+
+func newZoneReader(r io.Reader, origin string) *zoneReader {
+	scanner := bufio.NewScanner(r)
+	// Zone files can have long TXT/DNSSEC records
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	zoneOrigin := normalizeZoneName(origin)
+	return &zoneReader{scanner: scanner, zoneOrigin: zoneOrigin, currentOrigin: zoneOrigin}
+}
+
+// next returns the next label and its line; io.EOF at the end of the file
+func (z *zoneReader) next() (string, int, error) {
+	for z.scanner.Scan() {
+		z.line++
+		raw := stripZoneComment(z.scanner.Text())
+
+		// Continuation lines of a multi-line record carry no owner
+		continuation := z.parenDepth > 0
+		z.parenDepth += strings.Count(raw, "(") - strings.Count(raw, ")")
+		if z.parenDepth < 0 {
+			z.parenDepth = 0
+		}
+		if continuation || strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(raw))
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Directives
+		if strings.HasPrefix(fields[0], "$") {
+			switch strings.ToUpper(fields[0]) {
+			case "$ORIGIN":
+				if len(fields) < 2 {
+					return "", z.line, fmt.Errorf("line %d: $ORIGIN without a name", z.line)
+				}
+				z.currentOrigin = normalizeZoneName(fields[1])
+				if z.zoneOrigin == "" {
+					z.zoneOrigin = z.currentOrigin
+				}
+			}
+			continue
+		}
+
+		// Lines starting with whitespace reuse the previous owner
+		if raw[0] == ' ' || raw[0] == '\t' {
+			continue
+		}
+
+		owner := fields[0]
+		switch {
+		case owner == "@":
+			owner = z.currentOrigin
+		case strings.HasSuffix(owner, "."):
+			owner = normalizeZoneName(owner)
+		case z.currentOrigin != "":
+			owner = strings.ToLower(owner) + "." + z.currentOrigin
+		default:
+			owner = strings.ToLower(owner)
+		}
+
+		// Without an explicit origin, the SOA owner defines the zone
+		if z.zoneOrigin == "" && isSOARecord(fields[1:]) {
+			z.zoneOrigin = owner
+			if z.currentOrigin == "" {
+				z.currentOrigin = owner
+			}
+		}
+
+		if owner == z.lastOwner || z.zoneOrigin == "" || owner == z.zoneOrigin {
+			continue
+		}
+		z.lastOwner = owner
+
+		if !strings.HasSuffix(owner, "."+z.zoneOrigin) {
+			// Out-of-zone glue or records for another origin
+			continue
+		}
+
+		rest := strings.TrimSuffix(owner, "."+z.zoneOrigin)
+		label := rest[strings.LastIndex(rest, ".")+1:]
+		if label == "" || label == "*" {
+			continue
+		}
+		return label, z.line, nil
+	}
+
+	if err := z.scanner.Err(); err != nil {
+		return "", z.line, fmt.Errorf("failed to read zone file: %w", err)
+	}
+	return "", z.line, io.EOF
+}
+
+// normalizeZoneName lowercases a domain name and removes the trailing dot
+func normalizeZoneName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// stripZoneComment removes a trailing ; comment that is not inside a quoted string
+func stripZoneComment(line string) string {
+	inQuotes := false
+	for i, c := range line {
+		switch c {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// isSOARecord checks whether the fields after the owner (TTL, class, type) describe an SOA record
+func isSOARecord(fields []string) bool {
+	for i := 0; i < len(fields) && i < 3; i++ {
+		if strings.EqualFold(fields[i], "SOA") {
+			return true
+		}
+	}
+	return false
+}
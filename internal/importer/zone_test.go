@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	dbpkg "premium-list-maker/internal/db"
+)
+
+func TestParseZoneSLDs(t *testing.T) {
+	zone := `$ORIGIN shop.
+$TTL 3600
+@ IN SOA ns1.nic.shop. hostmaster.nic.shop. (
+   2024010101 ; serial
+   3600 900 604800 86400 )
+  IN NS ns1.nic.shop.
+app 86400 IN NS ns1.app.shop.
+app 86400 IN NS ns2.app.shop.
+ns1.app IN A 192.0.2.1
+Hello.shop. IN NS ns.example.com. ; absolute owner
+ns.example.com. IN A 192.0.2.2
+* IN TXT "wildcard; not a label"
+`
+
+	var labels []string
+	err := ParseZoneSLDs(strings.NewReader(zone), "", func(label string) error {
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseZoneSLDs failed: %v", err)
+	}
+
+	want := []string{"app", "app", "hello"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("got labels %v, want %v", labels, want)
+	}
+}
+
+func TestParseZoneSLDs_SOAOrigin(t *testing.T) {
+	// No $ORIGIN: the SOA owner defines the zone
+	zone := `example. 3600 IN SOA ns1.example. hostmaster.example. 1 3600 900 604800 86400
+foo.example. IN NS ns1.foo.example.
+bar.baz.example. IN A 192.0.2.1
+`
+
+	var labels []string
+	err := ParseZoneSLDs(strings.NewReader(zone), "", func(label string) error {
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseZoneSLDs failed: %v", err)
+	}
+
+	want := []string{"foo", "baz"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("got labels %v, want %v", labels, want)
+	}
+}
+
+func TestImportZoneReader(t *testing.T) {
+	db, err := dbpkg.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	zone := `$ORIGIN shop.
+@ IN SOA ns1.nic.shop. hostmaster.nic.shop. 1 3600 900 604800 86400
+app IN NS ns1.app.shop.
+app IN NS ns2.app.shop.
+ns1.app IN A 192.0.2.1
+Hello IN NS ns.example.com.
+bad_name IN NS ns.example.com.
+`
+	stats, err := ImportZoneReader(db, strings.NewReader(zone), "", WithTag("in-zone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Imported != 2 || len(stats.Errors) != 1 || stats.Errors[0].Line != 7 {
+		t.Errorf("imported %d with errors %v, want app and hello and an error on line 7", stats.Imported, stats.Errors)
+	}
+	labels, err := db.ListLabels(dbpkg.LabelFilter{Tags: []string{"in-zone"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || labels[0].Label != "app" || labels[1].Label != "hello" {
+		t.Errorf("in-zone labels = %v, want app and hello", labels)
+	}
+
+	if _, err := ImportZoneReader(db, strings.NewReader("\x1f\x8b\x08\x00"), ""); !errors.Is(err, ErrNotZone) {
+		t.Errorf("compressed input: err = %v, want ErrNotZone", err)
+	}
+}
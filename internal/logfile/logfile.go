@@ -0,0 +1,107 @@
+// Package logfile appends run logs to a file that is rotated by size, so unattended runs under
+// cron keep a bounded history: when the file would grow past its limit it is renamed to
+// <path>.1, older files shift to <path>.2 and so on, and the oldest is removed
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Writer appends to a log file, rotating it by size
+type Writer struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxFiles int // Rotated files kept next to the current one
+	file     *os.File
+	size     int64
+}
+
+// Open opens the log file at path for appending
+// A write that would grow the file past maxBytes rotates it first; maxFiles rotated files are kept
+func Open(path string, maxBytes int64, maxFiles int) (*Writer, error) {
+	if maxBytes <= 0 || maxFiles < 0 {
+		return nil, fmt.Errorf("log size must be positive and the number of rotated logs not negative")
+	}
+	w := &Writer{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the current file, continuing at its end
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first if p would not fit
+// Callers should write whole lines, so that lines are never split across files
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files up by one, renames the current file to <path>.1 and starts a new one
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	w.file = nil
+
+	if w.maxFiles == 0 {
+		os.Remove(w.path)
+	} else {
+		os.Remove(w.rotated(w.maxFiles))
+		for i := w.maxFiles - 1; i >= 1; i-- {
+			os.Rename(w.rotated(i), w.rotated(i+1)) // Gaps are fine
+		}
+		if err := os.Rename(w.path, w.rotated(1)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	return w.open()
+}
+
+// rotated returns the path of the nth rotated file
+func (w *Writer) rotated(n int) string {
+	return w.path + "." + strconv.Itoa(n)
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,61 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	w, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every line fills a file, so each write after the first rotates
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range want {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("oldest log was kept: %v", err)
+	}
+}
+
+func TestWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	for _, line := range []string{"one\n", "two\n"} {
+		w, err := Open(path, 1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(line))
+		w.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("log = %q, want both runs", data)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"premium-list-maker/internal/db"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbCollector reports database gauges, queried on every scrape
+type dbCollector struct {
+	db     *db.DB
+	labels *prometheus.Desc
+	tags   *prometheus.Desc
+	size   *prometheus.Desc
+}
+
+// RegisterDB registers gauges for the label count, tag count and size of the database
+// When serving several databases, tld tells them apart as a "tld" label (empty = no label)
+func RegisterDB(database *db.DB, tld string) error {
+	var constLabels prometheus.Labels
+	if tld != "" {
+		constLabels = prometheus.Labels{"tld": tld}
+	}
+	return Registry.Register(&dbCollector{
+		db:     database,
+		labels: prometheus.NewDesc(Namespace+"_labels", "Labels in the database.", nil, constLabels),
+		tags:   prometheus.NewDesc(Namespace+"_tags", "Tags in the database.", nil, constLabels),
+		size:   prometheus.NewDesc(Namespace+"_db_size_bytes", "Size of the SQLite database.", nil, constLabels),
+	})
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.labels
+	ch <- c.tags
+	ch <- c.size
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	if count, err := c.db.CountLabels(db.LabelFilter{}); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.labels, prometheus.GaugeValue, float64(count))
+	} else {
+		ch <- prometheus.NewInvalidMetric(c.labels, err)
+	}
+	if count, err := c.db.CountTags(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.tags, prometheus.GaugeValue, float64(count))
+	} else {
+		ch <- prometheus.NewInvalidMetric(c.tags, err)
+	}
+	if size, err := c.db.Size(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(size))
+	} else {
+		ch <- prometheus.NewInvalidMetric(c.size, err)
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace prefixes every metric name
+const Namespace = "premium_list"
+
+// Registry holds all metrics exposed by the serve command
+var Registry = prometheus.NewRegistry()
+
+var (
+	importedLabels = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "imported_labels_total",
+		Help:      "Labels processed by CSV imports.",
+	})
+	importDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "import_duration_seconds",
+		Help:      "Duration of CSV file imports.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	})
+	generations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "generations_total",
+		Help:      "Premium list generations by format and result.",
+	}, []string{"format", "result"})
+	generationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "generation_duration_seconds",
+		Help:      "Duration of premium list generations.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"format"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		importedLabels,
+		importDuration,
+		generations,
+		generationDuration,
+	)
+}
+
+// ObserveImport records a finished CSV import of the given number of labels
+func ObserveImport(labels int, duration time.Duration) {
+	importedLabels.Add(float64(labels))
+	importDuration.Observe(duration.Seconds())
+}
+
+// ObserveGeneration records a finished premium list generation
+func ObserveGeneration(format string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	generations.WithLabelValues(format, result).Inc()
+	generationDuration.WithLabelValues(format).Observe(duration.Seconds())
+}
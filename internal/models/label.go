@@ -2,9 +2,15 @@ package models
 
 // Label represents a domain label in the database
 type Label struct {
-	ID     int64
-	Label  string
-	Length int
-	Tags   []string
+	ID     int64    `json:"id"`
+	Label  string   `json:"label"`
+	Length int      `json:"length"`
+	Tags   []string `json:"tags"`
 }
 
+// TagInfo represents a tag with the number of labels carrying it
+type TagInfo struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	LabelCount int    `json:"label_count"`
+}
@@ -0,0 +1,93 @@
+// Package naming expands the templates auto-named files are named by, e.g. {name}-{date:2006-01-02}-{tld},
+// so generated artifacts sort and match archive conventions
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultDateLayout is the layout of {date} without one
+const DefaultDateLayout = "20060102-150405"
+
+// Placeholders are the keys a template may use:
+//
+//	name   what the file is, e.g. sanitized, catch-list or tiers
+//	date   when it was made, {date:<Go time layout>} for another layout than DefaultDateLayout
+//	tld    the TLD it is about, if any
+//	input  the input file it was made from, without its extension, if any
+var Placeholders = []string{"name", "date", "tld", "input"}
+
+var placeholder = regexp.MustCompile(`\{([a-z]+)(?::([^}]*))?\}`)
+
+// Validate checks that template only uses known placeholders and names a file, not a path
+// It must contain {name}, as files of different kinds would get the same name otherwise
+func Validate(template string) error {
+	if !strings.Contains(template, "{name}") {
+		return fmt.Errorf("name template %q must contain {name}", template)
+	}
+	_, err := Expand(template, time.Now(), map[string]string{"name": "name"})
+	return err
+}
+
+// Expand fills in the placeholders of template, with t for {date} and vars for the others
+// A placeholder without a value is left out together with the separator (-, _ or .) before it,
+// so {name}-{tld} gives just the name for files without a TLD
+func Expand(template string, t time.Time, vars map[string]string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholder.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(template[last:m[0]])
+		last = m[1]
+
+		key := template[m[2]:m[3]]
+		var value string
+		switch {
+		case key == "date":
+			layout := DefaultDateLayout
+			if m[4] >= 0 {
+				layout = template[m[4]:m[5]]
+			}
+			value = t.Format(layout)
+		case !known(key):
+			return "", fmt.Errorf("unknown placeholder {%s} in name template %q (known: %s)", key, template, strings.Join(Placeholders, ", "))
+		case m[4] >= 0:
+			return "", fmt.Errorf("only {date} takes a layout in name template %q", template)
+		default:
+			value = vars[key]
+		}
+
+		// Values never add directories
+		value = strings.NewReplacer("/", "-", `\`, "-").Replace(value)
+		if value == "" {
+			if s := b.String(); s != "" && strings.ContainsRune("-_.", rune(s[len(s)-1])) {
+				b.Reset()
+				b.WriteString(s[:len(s)-1])
+			}
+			continue
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(template[last:])
+
+	name := strings.TrimLeft(b.String(), "-_.")
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("name template %q must give a file name, not a path", template)
+	}
+	if name == "" {
+		return "", fmt.Errorf("name template %q gives an empty file name", template)
+	}
+	return name, nil
+}
+
+// known reports whether key is one of the Placeholders
+func known(key string) bool {
+	for _, p := range Placeholders {
+		if p == key {
+			return true
+		}
+	}
+	return false
+}
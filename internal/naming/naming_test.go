@@ -0,0 +1,62 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	at := time.Date(2025, 3, 1, 14, 5, 9, 0, time.UTC)
+	vars := map[string]string{"name": "sold-premiums", "tld": "shop", "input": "zone"}
+
+	tests := []struct {
+		template string
+		vars     map[string]string
+		want     string
+	}{
+		{"{name}-{date:2006-01-02}-{tld}", vars, "sold-premiums-2025-03-01-shop"},
+		{"{name}-{date}", vars, "sold-premiums-20250301-140509"},
+		{"{date:2006-01-02}_{input}_{name}", vars, "2025-03-01_zone_sold-premiums"},
+		// Placeholders without a value drop their separator
+		{"{name}-{date:2006-01-02}-{tld}", map[string]string{"name": "tiers"}, "tiers-2025-03-01"},
+		{"{tld}-{name}", map[string]string{"name": "tiers"}, "tiers"},
+		// Values can't add directories
+		{"{name}-{input}", map[string]string{"name": "catch-list", "input": "a/b"}, "catch-list-a-b"},
+		{"{date:2006/01}-{name}", vars, "2025-03-sold-premiums"},
+		{"premiums", vars, "premiums"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.template, at, tt.vars)
+		if err != nil {
+			t.Errorf("Expand(%q): %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	for template, want := range map[string]string{
+		"{name}-{serial}": "unknown placeholder {serial}",
+		"{name:upper}":    "only {date} takes a layout",
+		"archive/{name}":  "not a path",
+		"{tld}":           "empty file name",
+	} {
+		if _, err := Expand(template, at, map[string]string{"name": "tiers"}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expand(%q): err = %v, want %q", template, err, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("{name}-{date:2006-01-02}-{tld}"); err != nil {
+		t.Error(err)
+	}
+	if err := Validate("{date}-{tld}"); err == nil || !strings.Contains(err.Error(), "must contain {name}") {
+		t.Errorf("err = %v, want missing {name}", err)
+	}
+	if err := Validate("{name}-{serial}"); err == nil {
+		t.Error("expected an error for an unknown placeholder")
+	}
+}
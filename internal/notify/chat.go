@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/webhook"
+)
+
+// Chat providers
+const (
+	ChatSlack = "slack"
+	ChatTeams = "teams"
+)
+
+// ChatConfig configures a Slack or Teams incoming webhook
+type ChatConfig struct {
+	Provider string   `json:"provider"` // slack or teams
+	URL      string   `json:"url"`
+	Events   []string `json:"events"` // Events to post (default: all)
+}
+
+// ChatNotifier posts event summaries to Slack or Teams channels
+// A nil ChatNotifier is valid and posts nothing
+type ChatNotifier struct {
+	configs []ChatConfig
+	client  *http.Client
+}
+
+// NewChatNotifier validates the configurations and creates a notifier, or returns nil if there are none
+func NewChatNotifier(configs []ChatConfig) (*ChatNotifier, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	for _, c := range configs {
+		if c.Provider != ChatSlack && c.Provider != ChatTeams {
+			return nil, fmt.Errorf("unknown chat provider %q (expected slack or teams)", c.Provider)
+		}
+		if c.URL == "" {
+			return nil, fmt.Errorf("%s notification has no url", c.Provider)
+		}
+	}
+	return &ChatNotifier{configs: configs, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Notify posts the event to every channel that wants it
+// All channels are attempted; the first error is returned
+func (n *ChatNotifier) Notify(event webhook.Event) error {
+	if n == nil {
+		return nil
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	var firstErr error
+	for _, c := range n.configs {
+		if !wantsEvent(c.Events, event.Event) {
+			continue
+		}
+		var payload any
+		if c.Provider == ChatTeams {
+			payload = teamsMessage(event)
+		} else {
+			payload = slackMessage(event)
+		}
+		if err := n.post(c.URL, payload); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s notification failed: %w", c.Provider, err)
+		}
+	}
+	return firstErr
+}
+
+// post sends a JSON payload to an incoming webhook
+func (n *ChatNotifier) post(url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// fact is a name/value line of a summary
+type fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// summaryTitle returns a one-line title for an event, e.g. "generate.completed: premium-shop.csv"
+func summaryTitle(event webhook.Event) string {
+	title := event.Event
+	if event.OutputPath != "" {
+		title += ": " + filepath.Base(event.OutputPath)
+	}
+	return title
+}
+
+// summaryFacts lists the stats of an event (counts, duration, error count) followed by the output checksum
+func summaryFacts(event webhook.Event) []fact {
+	var facts []fact
+	if event.Stats != nil {
+		// Stats are one of the webhook stats structs; flatten their JSON form so every event type is covered
+		var stats map[string]any
+		data, _ := json.Marshal(event.Stats)
+		json.Unmarshal(data, &stats)
+
+		keys := make([]string, 0, len(stats))
+		for k := range stats {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			value := fmt.Sprint(stats[k])
+			if k == "duration_ms" {
+				if ms, ok := stats[k].(float64); ok {
+					k, value = "duration", (time.Duration(ms) * time.Millisecond).Round(time.Millisecond).String()
+				}
+			}
+			facts = append(facts, fact{Name: strings.ReplaceAll(k, "_", " "), Value: value})
+		}
+	}
+	if event.SHA256 != "" {
+		facts = append(facts, fact{Name: "sha256", Value: event.SHA256})
+	}
+	if event.ErrorReport != "" {
+		facts = append(facts, fact{Name: "error report", Value: event.ErrorReport})
+	}
+	return facts
+}
+
+// slackMessage formats an event for a Slack incoming webhook
+func slackMessage(event webhook.Event) map[string]any {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*%s*\n", summaryTitle(event))
+	for _, f := range summaryFacts(event) {
+		fmt.Fprintf(&text, "• %s: `%s`\n", f.Name, f.Value)
+	}
+	return map[string]any{"text": strings.TrimSuffix(text.String(), "\n")}
+}
+
+// teamsMessage formats an event as a MessageCard for a Teams incoming webhook
+func teamsMessage(event webhook.Event) map[string]any {
+	title := summaryTitle(event)
+	return map[string]any{
+		"@type":    "MessageCard",
+		"@context": "http://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"sections": []any{map[string]any{"facts": summaryFacts(event)}},
+	}
+}
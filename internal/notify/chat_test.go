@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/webhook"
+)
+
+func TestChatNotifier(t *testing.T) {
+	received := make(map[string]map[string]any)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		received[r.URL.Path] = payload
+	}))
+	defer ts.Close()
+
+	notifier, err := NewChatNotifier([]ChatConfig{
+		{Provider: ChatSlack, URL: ts.URL + "/slack"},
+		{Provider: ChatTeams, URL: ts.URL + "/teams"},
+		{Provider: ChatSlack, URL: ts.URL + "/imports", Events: []string{webhook.EventImportCompleted}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notifier.Notify(webhook.Event{
+		Event:      webhook.EventGenerateCompleted,
+		OutputPath: "out/premium-shop.csv",
+		SHA256:     "abc123",
+		Stats:      webhook.GenerateStats{Format: "default", SizeBytes: 130, DurationMS: 1500},
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if _, ok := received["/imports"]; ok {
+		t.Error("posted a generate event to an imports-only channel")
+	}
+	text, _ := received["/slack"]["text"].(string)
+	for _, want := range []string{"*generate.completed: premium-shop.csv*", "duration: `1.5s`", "size bytes: `130`", "sha256: `abc123`"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("slack text is missing %q:\n%s", want, text)
+		}
+	}
+	if received["/teams"]["@type"] != "MessageCard" || received["/teams"]["title"] != "generate.completed: premium-shop.csv" {
+		t.Errorf("teams payload = %v", received["/teams"])
+	}
+}
@@ -0,0 +1,275 @@
+// Package notify sends run summaries to people, complementing the machine-oriented webhooks
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/webhook"
+)
+
+// DefaultMaxAttachmentMB is the size above which files are linked instead of attached
+const DefaultMaxAttachmentMB = 10
+
+// EmailConfig configures SMTP notifications
+type EmailConfig struct {
+	Host            string   `json:"host"`
+	Port            int      `json:"port"` // Default 587, or 465 with tls "implicit"
+	Username        string   `json:"username"`
+	Password        string   `json:"password"` // Default $PREMIUM_LIST_SMTP_PASSWORD
+	TLS             string   `json:"tls"`      // starttls (default), implicit or none
+	From            string   `json:"from"`
+	To              []string `json:"to"`
+	Events          []string `json:"events"`            // Events to send (default: all)
+	Attach          bool     `json:"attach"`            // Attach the output and error report instead of linking them
+	MaxAttachmentMB int      `json:"max_attachment_mb"` // Larger files are linked even with attach (default 10)
+	LinkBaseURL     string   `json:"link_base_url"`     // Files are linked as <link_base_url>/<file name>; default: their local path
+}
+
+// Emailer sends event summaries by email
+// A nil Emailer is valid and sends nothing
+type Emailer struct {
+	config EmailConfig
+}
+
+// NewEmailer validates the configuration and creates an emailer, or returns nil if config is nil
+func NewEmailer(config *EmailConfig) (*Emailer, error) {
+	if config == nil {
+		return nil, nil
+	}
+	c := *config
+	if c.Host == "" || c.From == "" || len(c.To) == 0 {
+		return nil, fmt.Errorf("email notifications need host, from and to")
+	}
+	switch c.TLS {
+	case "":
+		c.TLS = "starttls"
+	case "starttls", "implicit", "none":
+	default:
+		return nil, fmt.Errorf("unknown email tls mode %q (expected starttls, implicit or none)", c.TLS)
+	}
+	if c.Port == 0 {
+		c.Port = 587
+		if c.TLS == "implicit" {
+			c.Port = 465
+		}
+	}
+	if c.Password == "" {
+		c.Password = os.Getenv("PREMIUM_LIST_SMTP_PASSWORD")
+	}
+	if c.MaxAttachmentMB <= 0 {
+		c.MaxAttachmentMB = DefaultMaxAttachmentMB
+	}
+	return &Emailer{config: c}, nil
+}
+
+// Notify emails a summary of the event, unless the event is filtered out
+func (e *Emailer) Notify(event webhook.Event) error {
+	if e == nil || !wantsEvent(e.config.Events, event.Event) {
+		return nil
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	msg, err := e.message(event)
+	if err != nil {
+		return err
+	}
+	if err := e.send(msg); err != nil {
+		return fmt.Errorf("failed to send email notification: %w", err)
+	}
+	return nil
+}
+
+// message builds the MIME message for an event, with the output and error report attached or linked
+func (e *Emailer) message(event webhook.Event) ([]byte, error) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Event:        %s\n", event.Event)
+	fmt.Fprintf(&body, "Time:         %s\n", event.Timestamp.Format(time.RFC1123))
+	if event.Database != "" {
+		fmt.Fprintf(&body, "Database:     %s\n", event.Database)
+	}
+	if event.SHA256 != "" {
+		fmt.Fprintf(&body, "SHA-256:      %s\n", event.SHA256)
+	}
+
+	var attachments []string
+	for _, f := range []struct{ name, path string }{{"Output", event.OutputPath}, {"Error report", event.ErrorReport}} {
+		if f.path == "" {
+			continue
+		}
+		if e.config.Attach && e.fitsAttachment(f.path) {
+			attachments = append(attachments, f.path)
+			fmt.Fprintf(&body, "%-13s %s (attached)\n", f.name+":", filepath.Base(f.path))
+		} else {
+			fmt.Fprintf(&body, "%-13s %s\n", f.name+":", e.link(f.path))
+		}
+	}
+
+	if event.Stats != nil {
+		stats, err := json.MarshalIndent(event.Stats, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&body, "\nSummary:\n%s\n", stats)
+	}
+
+	var buf bytes.Buffer
+	subject := "[premium-list-maker] " + event.Event
+	if event.OutputPath != "" {
+		subject += ": " + filepath.Base(event.OutputPath)
+	}
+	fmt.Fprintf(&buf, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", event.Timestamp.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(strings.ReplaceAll(body.String(), "\n", "\r\n")))
+
+	for _, path := range attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(path))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(path)})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64Lines(part, data)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fitsAttachment reports whether a file is small enough to attach
+func (e *Emailer) fitsAttachment(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() <= int64(e.config.MaxAttachmentMB)<<20
+}
+
+// link returns where a file can be found: below the link base URL if configured, otherwise its local path
+func (e *Emailer) link(path string) string {
+	if e.config.LinkBaseURL != "" {
+		return strings.TrimSuffix(e.config.LinkBaseURL, "/") + "/" + filepath.Base(path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// send delivers the message over SMTP
+func (e *Emailer) send(msg []byte) error {
+	c := e.config
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	tlsConfig := &tls.Config{ServerName: c.Host}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if c.TLS == "implicit" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Minute))
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.TLS == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.From); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// writeBase64Lines writes data base64-encoded in lines of 76 characters, as MIME requires
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+// wantsEvent reports whether event is in events, where an empty list means all events
+func wantsEvent(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
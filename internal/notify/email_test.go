@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/webhook"
+)
+
+func TestEmailMessage(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "premium-shop.csv")
+	report := filepath.Join(dir, "import_errors.txt")
+	os.WriteFile(output, []byte("Label,Tier\nshoes,1\n"), 0644)
+	os.WriteFile(report, []byte("- bad label\n"), 0644)
+
+	emailer, err := NewEmailer(&EmailConfig{Host: "smtp.example", From: "pipeline@example", To: []string{"pricing@example"}, Attach: true, MaxAttachmentMB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := webhook.Event{
+		Event:       webhook.EventGenerateCompleted,
+		Timestamp:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		OutputPath:  output,
+		ErrorReport: report,
+		SHA256:      "abc123",
+		Stats:       webhook.GenerateStats{Format: "default", SizeBytes: 20},
+	}
+	msg, err := emailer.message(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Subject: [premium-list-maker] generate.completed: premium-shop.csv",
+		"Content-Type: multipart/mixed",
+		"SHA-256:      abc123",
+		`filename=premium-shop.csv`,
+		`filename=import_errors.txt`,
+		`"size_bytes": 20`,
+	} {
+		if !strings.Contains(string(msg), want) {
+			t.Errorf("message is missing %q:\n%s", want, msg)
+		}
+	}
+
+	// Without attach, files are linked below the base URL
+	emailer.config.Attach = false
+	emailer.config.LinkBaseURL = "https://files.example/lists/"
+	msg, _ = emailer.message(event)
+	if !strings.Contains(string(msg), "https://files.example/lists/premium-shop.csv") || strings.Contains(string(msg), "multipart") {
+		t.Errorf("expected a linked output in a plain message:\n%s", msg)
+	}
+}
@@ -0,0 +1,184 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagger"
+)
+
+// SuggestionColumns is the header of the suggestions CSV
+var SuggestionColumns = []string{"label", "current_tier", "current_price", "suggested_tier", "suggested_price", "currency", "comparables", "low", "high", "basis"}
+
+// Suggestion is a proposed price for a label, backed by comparable sales
+type Suggestion struct {
+	Label         string
+	CurrentTier   int      // 0 if the label has no tier
+	CurrentPrice  *float64 // Current registration price, nil if unknown
+	SuggestedTier int      // Tier priced closest to the suggested price, 0 without tiers
+	Price         float64  // Median price of the comparable sales
+	Low, High     float64  // Range of the comparable sales
+	Currency      string
+	Comparables   int
+	Basis         string // "label" for earlier sales of the label itself, or the comparable group, e.g. "length 4, LLLL"
+}
+
+// Options controls which sales back a suggestion
+type Options struct {
+	Currency       string        // Only sales in this currency are compared
+	MinComparables int           // Labels with fewer comparable sales get no suggestion
+	Tiers          []models.Tier // Tiers to propose for the suggested prices, may be nil
+}
+
+// Suggest proposes prices for labels from comparable sales
+// A label that sold before (in any TLD) is priced on its own sales; other labels on the sales of labels
+// with the same length and shape (letters, digits, hyphens). Labels without enough sales are left out
+func Suggest(labels []string, sales []db.Sale, opts Options) []Suggestion {
+	byLabel := make(map[string][]float64)
+	byGroup := make(map[string][]float64)
+	for _, s := range sales {
+		if s.Currency != opts.Currency {
+			continue
+		}
+		byLabel[s.Label] = append(byLabel[s.Label], s.Price)
+		group := comparableGroup(s.Label)
+		byGroup[group] = append(byGroup[group], s.Price)
+	}
+
+	minComparables := opts.MinComparables
+	if minComparables < 1 {
+		minComparables = 1
+	}
+
+	var suggestions []Suggestion
+	for _, label := range labels {
+		prices, basis := byLabel[label], "label"
+		if len(prices) == 0 {
+			basis = comparableGroup(label)
+			prices = byGroup[basis]
+			if len(prices) < minComparables {
+				continue
+			}
+		}
+
+		sorted := append([]float64(nil), prices...)
+		sort.Float64s(sorted)
+		price := median(sorted)
+		suggestions = append(suggestions, Suggestion{
+			Label:         label,
+			SuggestedTier: closestTier(price, opts.Currency, opts.Tiers),
+			Price:         price,
+			Low:           sorted[0],
+			High:          sorted[len(sorted)-1],
+			Currency:      opts.Currency,
+			Comparables:   len(sorted),
+			Basis:         basis,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Label < suggestions[j].Label })
+	return suggestions
+}
+
+// comparableGroup groups labels that sell alike: their length and shape (e.g. "length 3, LNL"),
+// or for labels too long for a shape whether they contain digits or hyphens
+func comparableGroup(label string) string {
+	if shape := tagger.GenerateShapeTag(label); shape != "" {
+		return fmt.Sprintf("length %d, %s", len(label), shape)
+	}
+
+	kind := "letters"
+	switch {
+	case strings.HasPrefix(label, "xn--"):
+		kind = "idn"
+	case strings.Contains(label, "-"):
+		kind = "hyphenated"
+	case strings.ContainsAny(label, "0123456789"):
+		kind = "with digits"
+	}
+	return fmt.Sprintf("length %d, %s", len(label), kind)
+}
+
+// closestTier returns the tier in currency whose registration price is closest to price, 0 if none
+// On a tie the cheaper tier wins
+func closestTier(price float64, currency string, tiers []models.Tier) int {
+	best, bestDiff := 0, -1.0
+	var bestPrice float64
+	for _, t := range tiers {
+		if t.Currency != currency || t.PriceReg == nil {
+			continue
+		}
+		diff := *t.PriceReg - price
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff || (diff == bestDiff && *t.PriceReg < bestPrice) {
+			best, bestDiff, bestPrice = t.Tier, diff, *t.PriceReg
+		}
+	}
+	return best
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// WriteSuggestions writes the suggestions as a CSV for review
+func WriteSuggestions(path string, suggestions []Suggestion) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create suggestions file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(SuggestionColumns); err != nil {
+		return err
+	}
+	for _, s := range suggestions {
+		row := []string{
+			s.Label,
+			formatTier(s.CurrentTier),
+			"",
+			formatTier(s.SuggestedTier),
+			formatPrice(s.Price),
+			s.Currency,
+			strconv.Itoa(s.Comparables),
+			formatPrice(s.Low),
+			formatPrice(s.High),
+			s.Basis,
+		}
+		if s.CurrentPrice != nil {
+			row[2] = formatPrice(*s.CurrentPrice)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func formatTier(tier int) string {
+	if tier == 0 {
+		return ""
+	}
+	return strconv.Itoa(tier)
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
@@ -0,0 +1,60 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+func TestSuggest(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	sales := []db.Sale{
+		{Label: "shoes", Price: 5000, Currency: "USD", SoldOn: day},
+		{Label: "abc", Price: 900, Currency: "USD", SoldOn: day},
+		{Label: "xyz", Price: 1200, Currency: "USD", SoldOn: day},
+		{Label: "qrs", Price: 3000, Currency: "USD", SoldOn: day},
+		{Label: "def", Price: 99999, Currency: "EUR", SoldOn: day},
+		{Label: "a1b", Price: 400, Currency: "USD", SoldOn: day},
+	}
+	reg100, reg1000, reg5000 := 100.0, 1000.0, 5000.0
+	tiers := []models.Tier{
+		{Tier: 1, Currency: "USD", PriceReg: &reg100},
+		{Tier: 2, Currency: "USD", PriceReg: &reg1000},
+		{Tier: 3, Currency: "USD", PriceReg: &reg5000},
+		{Tier: 9, Currency: "EUR", PriceReg: &reg1000},
+	}
+
+	got := Suggest([]string{"shoes", "def", "k9z", "zzz"}, sales, Options{Currency: "USD", MinComparables: 3, Tiers: tiers})
+	if len(got) != 3 {
+		t.Fatalf("got %d suggestions, want 3: %+v", len(got), got)
+	}
+
+	// def and zzz are compared with the three-letter sales in USD
+	def := got[0]
+	if def.Label != "def" || def.Price != 1200 || def.Low != 900 || def.High != 3000 || def.Comparables != 3 || def.Basis != "length 3, LLL" || def.SuggestedTier != 2 {
+		t.Errorf("def = %+v", def)
+	}
+	if got[1].Label != "shoes" || got[1].Basis != "label" || got[1].Price != 5000 || got[1].SuggestedTier != 3 {
+		t.Errorf("shoes = %+v", got[1])
+	}
+	if got[2].Label != "zzz" {
+		t.Errorf("got %+v, want zzz last (k9z has too few comparables)", got[2])
+	}
+}
+
+func TestComparableGroup(t *testing.T) {
+	tests := map[string]string{
+		"ab1":           "length 3, LLN",
+		"longername":    "length 10, letters",
+		"long-name":     "length 9, hyphenated",
+		"name2024":      "length 8, with digits",
+		"xn--bcher-kva": "length 13, idn",
+	}
+	for label, want := range tests {
+		if got := comparableGroup(label); got != want {
+			t.Errorf("comparableGroup(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Bar layout
+const (
+	barWidth    = 30                     // Characters of the bar itself
+	barInterval = 100 * time.Millisecond // Least time between redraws
+)
+
+// Bar draws the progress of an operation on a terminal as one line redrawn in place: the phase, a bar with the
+// percent done when the total is known, the rows, the rate and the time left
+// Its Report method is the Func to pass to the operation
+type Bar struct {
+	w     io.Writer
+	total int
+	now   func() time.Time
+
+	phase     string
+	start     time.Time // First update of the phase, which the rate is measured from
+	startRows int
+	drawn     time.Time
+	width     int // Of the line drawn last, which the next one overwrites; 0 if no line is open
+}
+
+// NewBar returns a bar drawn on w; total is the rows of updates without a total of their own, e.g. the estimated
+// lines of a file, 0 if unknown
+func NewBar(w io.Writer, total int) *Bar {
+	return &Bar{w: w, total: total, now: time.Now}
+}
+
+// Report redraws the bar, at most every barInterval; PhaseDone draws it a last time and ends its line
+func (b *Bar) Report(u Update) {
+	now := b.now()
+	done := u.Phase == PhaseDone
+	if !done && u.Phase != b.phase {
+		b.phase, b.start, b.startRows = u.Phase, now, u.Rows
+	} else if !done && now.Sub(b.drawn) < barInterval {
+		return
+	}
+	b.drawn = now
+
+	line := b.format(u, now, done)
+	fmt.Fprintf(b.w, "\r%s%s", line, strings.Repeat(" ", max(0, b.width-len(line))))
+	b.width = len(line)
+	if done {
+		b.End()
+	}
+}
+
+// End ends the line of the bar, for operations that stop without reporting PhaseDone, e.g. when canceled
+func (b *Bar) End() {
+	if b.width > 0 {
+		fmt.Fprintln(b.w)
+		b.width = 0
+	}
+}
+
+// format returns the line of an update
+func (b *Bar) format(u Update, now time.Time, done bool) string {
+	phase := b.phase
+	if phase == "" {
+		phase = u.Phase
+	}
+	total := u.Total
+	if total == 0 {
+		total = b.total
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "  %-10s", phase)
+	if total > 0 {
+		// Estimated totals can fall short, so only the end is 100%
+		fraction := min(float64(u.Rows)/float64(total), 0.99)
+		if done {
+			fraction = 1
+		}
+		filled := int(fraction * barWidth)
+		fmt.Fprintf(&s, " [%s%s] %3d%%", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), int(fraction*100))
+	}
+	fmt.Fprintf(&s, " %d rows", u.Rows)
+
+	elapsed := now.Sub(b.start)
+	if elapsed > 0 && u.Rows > b.startRows {
+		rate := float64(u.Rows-b.startRows) / elapsed.Seconds()
+		fmt.Fprintf(&s, ", %.0f rows/s", rate)
+		if !done && total > u.Rows {
+			eta := time.Duration(float64(total-u.Rows) / rate * float64(time.Second))
+			fmt.Fprintf(&s, ", ETA %s", eta.Round(time.Second))
+		}
+	}
+	if u.MemoryMB > 0 {
+		fmt.Fprintf(&s, ", %d MB", u.MemoryMB)
+	}
+	return s.String()
+}
@@ -0,0 +1,38 @@
+package progress
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBar(t *testing.T) {
+	var out strings.Builder
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	bar := NewBar(&out, 1000)
+	bar.now = func() time.Time { return clock }
+
+	bar.Report(Update{Phase: PhaseImporting, Rows: 0})
+	clock = clock.Add(2 * time.Second)
+	bar.Report(Update{Phase: PhaseImporting, Rows: 250})
+	// Redraws closer together than barInterval are left out
+	clock = clock.Add(time.Millisecond)
+	bar.Report(Update{Phase: PhaseImporting, Rows: 260})
+
+	lines := strings.Split(out.String(), "\r")
+	if len(lines) != 3 {
+		t.Fatalf("drew %q, want 2 redraws", out.String())
+	}
+	want := "  importing  [=======                       ]  25% 250 rows, 125 rows/s, ETA 6s"
+	if lines[2] != want {
+		t.Errorf("bar = %q, want %q", lines[2], want)
+	}
+
+	// The end is drawn as 100% even if the total was an estimate, and ends the line
+	clock = clock.Add(time.Second)
+	bar.Report(Update{Phase: PhaseDone, Rows: 900})
+	last := out.String()[strings.LastIndex(out.String(), "\r"):]
+	if !strings.HasSuffix(last, "\n") || strings.TrimRight(last, " \n") != "\r  importing  [==============================] 100% 900 rows, 300 rows/s" {
+		t.Errorf("last line = %q", last)
+	}
+}
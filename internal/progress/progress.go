@@ -0,0 +1,62 @@
+// Package progress carries the progress of long-running operations (imports, generation, deduplication)
+// to whoever renders it: the CLI prints it, the server streams it to job subscribers
+package progress
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// Phases of the operations
+const (
+	PhaseImporting     = "importing"
+	PhaseMatching      = "matching"
+	PhaseWriting       = "writing"
+	PhaseDeduplicating = "deduplicating"
+	PhaseDone          = "done"
+)
+
+// Update is a progress report
+type Update struct {
+	Phase    string
+	Rows     int    // Rows read or written so far in the phase
+	Total    int    // Rows of the phase, 0 if unknown
+	MemoryMB uint64 // Heap in use, 0 if not measured
+}
+
+// Func receives progress updates; operations call it from their own goroutine
+type Func func(Update)
+
+// Report calls f if it is set
+func (f Func) Report(u Update) {
+	if f != nil {
+		f(u)
+	}
+}
+
+// MemoryMB returns the heap in use in MB
+func MemoryMB() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Alloc / 1024 / 1024
+}
+
+// Printer returns a Func that prints a heartbeat line to w every time another `every` rows are done
+func Printer(w io.Writer, every int) Func {
+	next := every
+	return func(u Update) {
+		if u.Rows < next {
+			return
+		}
+		fmt.Fprintf(w, "  [Heartbeat] %s: %d rows", u.Phase, u.Rows)
+		if u.Total > 0 {
+			fmt.Fprintf(w, " of %d", u.Total)
+		}
+		if u.MemoryMB > 0 {
+			fmt.Fprintf(w, ", %d MB", u.MemoryMB)
+		}
+		fmt.Fprintln(w)
+		next = (u.Rows/every + 1) * every
+	}
+}
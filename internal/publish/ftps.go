@@ -0,0 +1,201 @@
+package publish
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpTimeout bounds every FTP control command and the data transfer setup
+const ftpTimeout = 30 * time.Second
+
+// uploadFTPS uploads r to remotePath over FTP with explicit TLS (AUTH TLS) on the control and data connections
+func uploadFTPS(ctx context.Context, u *url.URL, remotePath string, r io.Reader, opts Options) error {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "21")
+	}
+	tlsConfig := &tls.Config{
+		ServerName: u.Hostname(),
+		// Servers commonly require the data connection to resume the control connection's TLS session
+		ClientSessionCache: tls.NewLRUClientSessionCache(4),
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", opts.CAFile)
+		}
+	}
+
+	dialer := net.Dialer{Timeout: ftpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c := &ftpConn{text: textproto.NewConn(conn), conn: conn}
+	if _, err := c.expect(220); err != nil {
+		return err
+	}
+	if _, err := c.cmd(234, "AUTH TLS"); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	c.text, c.conn = textproto.NewConn(tlsConn), tlsConn
+
+	user := u.User.Username()
+	if user == "" {
+		user = "anonymous"
+	}
+	code, err := c.cmd(0, "USER %s", user)
+	if err != nil {
+		return err
+	}
+	if code == 331 {
+		if _, err := c.cmd(230, "PASS %s", password(u, opts)); err != nil {
+			return err
+		}
+	} else if code != 230 {
+		return fmt.Errorf("login failed: unexpected reply %d", code)
+	}
+
+	for _, setup := range []struct {
+		code int
+		cmd  string
+	}{{200, "PBSZ 0"}, {200, "PROT P"}, {200, "TYPE I"}} {
+		if _, err := c.cmd(setup.code, "%s", setup.cmd); err != nil {
+			return err
+		}
+	}
+
+	part := partName(remotePath)
+	if err := c.store(ctx, part, r, u.Hostname(), tlsConfig); err != nil {
+		c.cmd(0, "DELE %s", part)
+		return err
+	}
+	// Some servers refuse to rename over an existing file
+	c.cmd(0, "DELE %s", remotePath)
+	if _, err := c.cmd(350, "RNFR %s", part); err != nil {
+		return err
+	}
+	if _, err := c.cmd(250, "RNTO %s", remotePath); err != nil {
+		return err
+	}
+	c.cmd(0, "QUIT")
+	return nil
+}
+
+// ftpConn is a minimal FTP control connection
+type ftpConn struct {
+	text *textproto.Conn
+	conn net.Conn
+}
+
+// cmd sends a command and reads the reply; a non-zero expectCode makes other replies an error
+func (c *ftpConn) cmd(expectCode int, format string, args ...interface{}) (int, error) {
+	c.conn.SetDeadline(time.Now().Add(ftpTimeout))
+	if _, err := c.text.Cmd(format, args...); err != nil {
+		return 0, err
+	}
+	return c.expect(expectCode)
+}
+
+// expect reads a reply; a non-zero expectCode makes other replies an error
+func (c *ftpConn) expect(expectCode int) (int, error) {
+	c.conn.SetDeadline(time.Now().Add(ftpTimeout))
+	code, msg, err := c.text.ReadResponse(0)
+	if err != nil && code == 0 {
+		return 0, err
+	}
+	if expectCode != 0 && code != expectCode {
+		return code, fmt.Errorf("FTP server replied %d %s", code, msg)
+	}
+	return code, nil
+}
+
+// store uploads r to path over a passive, TLS-protected data connection
+func (c *ftpConn) store(ctx context.Context, path string, r io.Reader, host string, tlsConfig *tls.Config) error {
+	port, err := c.passivePort()
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: ftpTimeout}
+	dataConn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("failed to open data connection: %w", err)
+	}
+	defer dataConn.Close()
+
+	c.conn.SetDeadline(time.Now().Add(ftpTimeout))
+	if _, err := c.text.Cmd("STOR %s", path); err != nil {
+		return err
+	}
+	code, err := c.expect(0)
+	if err != nil {
+		return err
+	}
+	if code != 125 && code != 150 {
+		return fmt.Errorf("FTP server refused upload: reply %d", code)
+	}
+
+	tlsData := tls.Client(dataConn, tlsConfig)
+	if _, err := io.Copy(tlsData, r); err != nil {
+		return fmt.Errorf("failed to send file: %w", err)
+	}
+	if err := tlsData.Close(); err != nil {
+		return fmt.Errorf("failed to close data connection: %w", err)
+	}
+
+	// The transfer itself may take longer than a command, so wait without the usual deadline
+	c.conn.SetDeadline(time.Time{})
+	code, msg, err := c.text.ReadResponse(0)
+	if err != nil && code == 0 {
+		return err
+	}
+	if code != 226 && code != 250 {
+		return fmt.Errorf("upload failed: FTP server replied %d %s", code, msg)
+	}
+	return nil
+}
+
+// passivePort enters extended passive mode and returns the data port
+// The host of the control connection is used, as EPSV doesn't return one
+func (c *ftpConn) passivePort() (int, error) {
+	c.conn.SetDeadline(time.Now().Add(ftpTimeout))
+	if _, err := c.text.Cmd("EPSV"); err != nil {
+		return 0, err
+	}
+	code, msg, err := c.text.ReadResponse(229)
+	if err != nil {
+		return 0, fmt.Errorf("FTP server refused EPSV: %d %s", code, msg)
+	}
+
+	// 229 Entering Extended Passive Mode (|||6446|)
+	start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+	if start < 0 || end <= start+4 {
+		return 0, fmt.Errorf("malformed EPSV reply: %s", msg)
+	}
+	port, err := strconv.Atoi(msg[start+4 : end])
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("malformed EPSV reply: %s", msg)
+	}
+	return port, nil
+}
@@ -0,0 +1,236 @@
+package publish
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFTPSServer is an in-process FTP server with explicit TLS that keeps files in memory
+type fakeFTPSServer struct {
+	addr   string
+	caFile string // PEM file of the server's self-signed certificate
+
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func newFakeFTPSServer(t *testing.T, password string, files map[string]string) *fakeFTPSServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ftp.test"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	if files == nil {
+		files = make(map[string]string)
+	}
+	s := &fakeFTPSServer{addr: listener.Addr().String(), caFile: caFile, files: files}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, tlsConfig, password)
+		}
+	}()
+	return s
+}
+
+// serveConn answers the commands the uploader sends on a control connection
+func (s *fakeFTPSServer) serveConn(conn net.Conn, tlsConfig *tls.Config, password string) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	reply := func(format string, args ...interface{}) {
+		text.PrintfLine(format, args...)
+	}
+	reply("220 fake FTP server ready")
+
+	var data net.Listener
+	defer func() {
+		if data != nil {
+			data.Close()
+		}
+	}()
+	var renameFrom string
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch cmd {
+		case "AUTH":
+			reply("234 AUTH TLS ok")
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, text = tlsConn, textproto.NewConn(tlsConn)
+		case "USER":
+			reply("331 password required")
+		case "PASS":
+			if arg != password {
+				reply("530 login incorrect")
+				continue
+			}
+			reply("230 logged in")
+		case "PBSZ", "PROT", "TYPE":
+			reply("200 ok")
+		case "EPSV":
+			if data, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				reply("425 can't open data connection")
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "STOR":
+			dataConn, err := data.Accept()
+			if err != nil {
+				reply("425 can't open data connection")
+				continue
+			}
+			reply("150 ok to send data")
+			body, err := io.ReadAll(tls.Server(dataConn, tlsConfig))
+			dataConn.Close()
+			if err != nil {
+				reply("426 transfer aborted")
+				continue
+			}
+			if strings.HasPrefix(arg, "/full/") {
+				reply("452 disk full")
+				continue
+			}
+			s.mu.Lock()
+			s.files[arg] = string(body)
+			s.mu.Unlock()
+			reply("226 transfer complete")
+		case "DELE":
+			s.mu.Lock()
+			_, ok := s.files[arg]
+			delete(s.files, arg)
+			s.mu.Unlock()
+			if !ok {
+				reply("550 no such file")
+				continue
+			}
+			reply("250 deleted")
+		case "RNFR":
+			renameFrom = arg
+			reply("350 ready for RNTO")
+		case "RNTO":
+			s.mu.Lock()
+			s.files[arg] = s.files[renameFrom]
+			delete(s.files, renameFrom)
+			s.mu.Unlock()
+			reply("250 renamed")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 %s not implemented", cmd)
+		}
+	}
+}
+
+// stored returns a copy of the stored files
+func (s *fakeFTPSServer) stored() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make(map[string]string, len(s.files))
+	for name, data := range s.files {
+		files[name] = data
+	}
+	return files
+}
+
+func TestUploadFTPS(t *testing.T) {
+	s := newFakeFTPSServer(t, "secret", map[string]string{"/premium/shop.csv": "old list"})
+	local := filepath.Join(t.TempDir(), "premium-shop.csv")
+	if err := os.WriteFile(local, []byte("Label,Tier\nshoes,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	uploaded, err := Upload(context.Background(), local, "ftps://pricing@"+s.addr+"/premium/shop.csv", Options{Password: "secret", CAFile: s.caFile})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if want := "ftps://pricing@" + s.addr + "/premium/shop.csv"; uploaded != want {
+		t.Errorf("uploaded = %s, want %s", uploaded, want)
+	}
+	if files := s.stored(); len(files) != 1 || files["/premium/shop.csv"] != "Label,Tier\nshoes,1\n" {
+		t.Errorf("files = %v, want the old list replaced", files)
+	}
+}
+
+func TestUploadFTPS_Failures(t *testing.T) {
+	s := newFakeFTPSServer(t, "secret", nil)
+	local := filepath.Join(t.TempDir(), "premium-shop.csv")
+	if err := os.WriteFile(local, []byte("Label,Tier\nshoes,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Password: "secret", CAFile: s.caFile}
+
+	tests := []struct {
+		name, uri string
+		opts      Options
+		want      string
+	}{
+		{"wrong password", "ftps://pricing:wrong@" + s.addr + "/premium.csv", opts, "530"},
+		{"untrusted certificate", "ftps://pricing@" + s.addr + "/premium.csv", Options{Password: "secret"}, "TLS handshake failed"},
+		{"refused upload", "ftps://pricing@" + s.addr + "/full/premium.csv", opts, "452"},
+	}
+	for _, tt := range tests {
+		_, err := Upload(context.Background(), local, tt.uri, tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.want)
+		}
+	}
+	if files := s.stored(); len(files) != 0 {
+		t.Errorf("files after failed uploads = %v", files)
+	}
+
+	if _, err := Upload(context.Background(), local, "ftps://pricing@"+s.addr+"/premium.csv", Options{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("upload with a missing CA file succeeded")
+	}
+}
@@ -0,0 +1,131 @@
+// Package publish uploads generated premium lists to the places registry operators pick them up from
+package publish
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Options holds credentials and settings shared by all upload destinations
+type Options struct {
+	Password       string // Used when the URI has no password (SFTP, FTPS)
+	KeyFile        string // SSH private key for SFTP (default: SSH agent, then ~/.ssh/id_ed25519 and id_rsa)
+	KnownHostsFile string // SSH known_hosts file for SFTP host key checks (default: ~/.ssh/known_hosts)
+	CAFile         string // PEM certificates of the CAs trusted for FTPS servers (default: the system roots)
+
+	GoogleCredentialsFile string // Google service account key for Google Sheets
+
+	S3Region   string // AWS region of the bucket (default us-east-1); credentials come from the AWS_* environment variables
+	S3Endpoint string // S3-compatible endpoint (e.g. MinIO), addressed path-style; empty for AWS
+}
+
+// Upload copies the local file to the destination URI
+// Supported schemes are sftp://user@host[:port]/path and ftps://user@host[:port]/path (explicit TLS)
+// A path ending in "/" is a directory and the file keeps its local name
+// Files are uploaded under a temporary name and renamed when complete, so pickup jobs never see partial files
+// gsheets://<spreadsheet-id>/<tab> replaces the tab's contents with the CSV file (tab defaults to the file name)
+// s3://bucket/key uploads the object followed by a manifest with its SHA-256
+// Returns the URI of the uploaded file, without its password
+func Upload(ctx context.Context, localPath, destination string, opts Options) (string, error) {
+	uploaded, err := UploadFiles(ctx, []string{localPath}, destination, opts)
+	if err != nil {
+		return "", err
+	}
+	return uploaded[0], nil
+}
+
+// UploadFiles copies several local files to the destination URI, which must then be a directory (ending in "/")
+// For s3:// a single manifest listing all files is written after them
+// Returns the URIs of the uploaded files, without passwords
+func UploadFiles(ctx context.Context, localPaths []string, destination string, opts Options) ([]string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload URI %q: %w", destination, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid upload URI %q: missing host", destination)
+	}
+	if len(localPaths) > 1 && u.Scheme != "gsheets" && !strings.HasSuffix(u.Path, "/") {
+		return nil, fmt.Errorf("invalid upload URI %q: uploading several files needs a directory ending in /", destination)
+	}
+
+	remotePaths := make([]string, len(localPaths))
+	for i, localPath := range localPaths {
+		remotePath := u.Path
+		if u.Scheme == "gsheets" {
+			// The path is the tab name, not a directory
+			remotePath = strings.Trim(remotePath, "/")
+			if remotePath == "" || len(localPaths) > 1 {
+				remotePath = strings.TrimSuffix(filepath.Base(localPath), filepath.Ext(localPath))
+			}
+		} else if remotePath == "" || strings.HasSuffix(remotePath, "/") {
+			remotePath = path.Join(remotePath, filepath.Base(localPath))
+		}
+		remotePaths[i] = remotePath
+	}
+
+	if u.Scheme == "s3" {
+		keys := make([]string, len(remotePaths))
+		for i, remotePath := range remotePaths {
+			keys[i] = strings.TrimPrefix(remotePath, "/")
+		}
+		if err := uploadS3(ctx, u, localPaths, keys, s3ManifestKey(u.Path, keys), opts); err != nil {
+			return nil, fmt.Errorf("failed to upload to %s: %w", u.Redacted(), err)
+		}
+	} else {
+		for i, localPath := range localPaths {
+			if err := uploadOne(ctx, u, localPath, remotePaths[i], opts); err != nil {
+				return nil, fmt.Errorf("failed to upload to %s: %w", u.Redacted(), err)
+			}
+		}
+	}
+
+	uploaded := make([]string, len(remotePaths))
+	for i, remotePath := range remotePaths {
+		file := *u
+		file.Path = remotePath
+		if u.Scheme == "gsheets" {
+			file.Path = "/" + remotePath
+		}
+		uploaded[i] = file.Redacted()
+	}
+	return uploaded, nil
+}
+
+// uploadOne uploads a single file to a non-S3 destination
+func uploadOne(ctx context.Context, u *url.URL, localPath, remotePath string, opts Options) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	switch u.Scheme {
+	case "sftp":
+		return uploadSFTP(ctx, u, remotePath, file, opts)
+	case "ftps":
+		return uploadFTPS(ctx, u, remotePath, file, opts)
+	case "gsheets":
+		return uploadSheets(ctx, u, remotePath, file, opts)
+	default:
+		return fmt.Errorf("unsupported upload scheme %q (expected sftp, ftps, gsheets or s3)", u.Scheme)
+	}
+}
+
+// password returns the password of the URI, falling back to the configured one
+func password(u *url.URL, opts Options) string {
+	if p, ok := u.User.Password(); ok {
+		return p
+	}
+	return opts.Password
+}
+
+// partName is the temporary name a file is uploaded under before it is renamed into place
+func partName(remotePath string) string {
+	return remotePath + ".part"
+}
@@ -0,0 +1,174 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/generator"
+)
+
+// Price types, as named in the cnic-new output format
+const (
+	PriceRegistration = "registration"
+	PriceRenewal      = "renewal"
+	PriceRestore      = "restore"
+)
+
+// RegistryPrice is a single premium price to set at a registry backend
+type RegistryPrice struct {
+	Label    string
+	Domain   string // label.tld
+	Type     string // registration, renewal or restore
+	Currency string
+	Amount   float64
+}
+
+// RegistryPublisher submits premium prices to a registry backend API
+type RegistryPublisher interface {
+	SetPrice(ctx context.Context, price RegistryPrice) error
+}
+
+// PriceError records a price the registry rejected
+type PriceError struct {
+	Label string
+	Type  string
+	Err   error
+}
+
+// PushResult summarizes a price push
+type PushResult struct {
+	Submitted int
+	Failed    int
+	Errors    []PriceError
+}
+
+// RegistryPrices expands premium list entries into one price per label and price type, sorted by label
+func RegistryPrices(entries map[string]*generator.PremiumListEntry, tld string) []RegistryPrice {
+	tld = strings.Trim(strings.ToLower(tld), ".")
+	labels := make([]string, 0, len(entries))
+	for label := range entries {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var prices []RegistryPrice
+	for _, label := range labels {
+		entry := entries[label]
+		for _, p := range []struct {
+			typ    string
+			amount *float64
+		}{{PriceRegistration, entry.PriceReg}, {PriceRenewal, entry.PriceRen}, {PriceRestore, entry.PriceRes}} {
+			if p.amount == nil {
+				continue
+			}
+			prices = append(prices, RegistryPrice{
+				Label:    label,
+				Domain:   label + "." + tld,
+				Type:     p.typ,
+				Currency: strings.ToUpper(entry.Currency),
+				Amount:   *p.amount,
+			})
+		}
+	}
+	return prices
+}
+
+// PushPrices submits every price, continuing past rejected ones so all failures are reported at once
+// progress (if not nil) is called after every price with the number submitted so far
+func PushPrices(ctx context.Context, publisher RegistryPublisher, prices []RegistryPrice, progress func(done int)) (*PushResult, error) {
+	result := &PushResult{}
+	for i, price := range prices {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := publisher.SetPrice(ctx, price); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, PriceError{Label: price.Label, Type: price.Type, Err: err})
+		} else {
+			result.Submitted++
+		}
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+	return result, nil
+}
+
+// CentralNicPublisher sets premium prices through the CentralNic registry API
+// Each price is a POST of {"domain", "type", "currency", "amount"} to <base URL>/premium-prices,
+// authenticated with the API key as a bearer token
+type CentralNicPublisher struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+// NewCentralNicPublisher creates a CentralNic publisher
+func NewCentralNicPublisher(baseURL, apiKey string) *CentralNicPublisher {
+	return &CentralNicPublisher{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		APIKey:  apiKey,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// centralNicPrice is the request body of a price update
+type centralNicPrice struct {
+	Domain   string `json:"domain"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+// SetPrice submits a single price
+func (p *CentralNicPublisher) SetPrice(ctx context.Context, price RegistryPrice) error {
+	body, err := json.Marshal(centralNicPrice{
+		Domain:   price.Domain,
+		Type:     price.Type,
+		Currency: price.Currency,
+		Amount:   strconv.FormatFloat(price.Amount, 'f', 2, 64),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/premium-prices", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// Surface the registry's reason, e.g. "domain is registered" or "currency not supported"
+	var apiErr struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &apiErr) == nil {
+		if apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+		}
+		if apiErr.Error != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Error)
+		}
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
@@ -0,0 +1,55 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"premium-list-maker/internal/generator"
+)
+
+func TestPushPricesToCentralNic(t *testing.T) {
+	var received []centralNicPrice
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var price centralNicPrice
+		json.NewDecoder(r.Body).Decode(&price)
+		received = append(received, price)
+		if price.Domain == "taken.shop" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "domain is registered"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	reg, ren := 100.0, 50.0
+	entries := map[string]*generator.PremiumListEntry{
+		"shoes": {Label: "shoes", PriceReg: &reg, PriceRen: &ren, Currency: "usd"},
+		"taken": {Label: "taken", PriceReg: &reg, Currency: "usd"},
+	}
+	prices := RegistryPrices(entries, ".shop")
+	if len(prices) != 3 {
+		t.Fatalf("got %d prices, want 3", len(prices))
+	}
+
+	result, err := PushPrices(context.Background(), NewCentralNicPublisher(ts.URL+"/", "secret"), prices, nil)
+	if err != nil {
+		t.Fatalf("PushPrices: %v", err)
+	}
+	if result.Submitted != 2 || result.Failed != 1 {
+		t.Errorf("submitted %d, failed %d; want 2 and 1", result.Submitted, result.Failed)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Label != "taken" || result.Errors[0].Err.Error() != "422 Unprocessable Entity: domain is registered" {
+		t.Errorf("errors = %+v", result.Errors)
+	}
+	if received[0] != (centralNicPrice{Domain: "shoes.shop", Type: "registration", Currency: "USD", Amount: "100.00"}) {
+		t.Errorf("first request = %+v", received[0])
+	}
+}
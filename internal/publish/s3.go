@@ -0,0 +1,261 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Manifest is written next to the objects of an S3 upload, so consumers can verify what they pull
+type S3Manifest struct {
+	Bucket      string           `json:"bucket"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Files       []S3ManifestFile `json:"files"`
+}
+
+// S3ManifestFile is an uploaded object as listed in the manifest
+type S3ManifestFile struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size_bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// s3Client puts objects into a bucket, signing requests with AWS Signature Version 4
+type s3Client struct {
+	bucket       string
+	region       string
+	endpoint     string // Custom endpoint (path-style), empty for AWS
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+// newS3Client creates a client for the bucket from the options and the standard AWS environment variables
+func newS3Client(bucket string, opts Options) (*s3Client, error) {
+	c := &s3Client{
+		bucket:       bucket,
+		region:       opts.S3Region,
+		endpoint:     strings.TrimSuffix(opts.S3Endpoint, "/"),
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: 10 * time.Minute},
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for s3:// uploads")
+	}
+	return c, nil
+}
+
+// objectURL returns the URL of an object: virtual-hosted style on AWS, path style on custom endpoints (e.g. MinIO)
+func (c *s3Client) objectURL(key string) string {
+	escaped := s3EscapePath(key)
+	if c.endpoint != "" {
+		return c.endpoint + "/" + c.bucket + "/" + escaped
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.bucket, c.region, escaped)
+}
+
+// putFile uploads a local file to key and returns its manifest entry
+// S3 verifies the upload against the SHA-256 sent along, so a corrupted transfer fails instead of landing in the bucket
+func (c *s3Client) putFile(ctx context.Context, key, localPath string) (*S3ManifestFile, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sum := hash.Sum(nil)
+
+	if err := c.put(ctx, key, file, size, sum, contentType(key)); err != nil {
+		return nil, err
+	}
+	return &S3ManifestFile{Key: key, Size: size, SHA256: hex.EncodeToString(sum)}, nil
+}
+
+// putBytes uploads data to key
+func (c *s3Client) putBytes(ctx context.Context, key string, data []byte, contentType string) error {
+	sum := sha256.Sum256(data)
+	return c.put(ctx, key, bytes.NewReader(data), int64(len(data)), sum[:], contentType)
+}
+
+// put sends a signed PutObject request
+func (c *s3Client) put(ctx context.Context, key string, body io.Reader, size int64, sum []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Checksum-Sha256", base64.StdEncoding.EncodeToString(sum))
+	c.sign(req, hex.EncodeToString(sum), time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// S3 errors are XML; the code and message are enough to act on
+	var s3Err struct {
+		Code    string
+		Message string
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("%s: %s: %s", resp.Status, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
+
+// sign adds the AWS Signature Version 4 headers to req
+func (c *s3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	// Sign the host and every x-amz-* and content-type header
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + c.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), day)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// uploadS3 uploads the files to the bucket, followed by a manifest with their checksums
+// keys are the object keys of the files; the manifest is written as manifestKey
+func uploadS3(ctx context.Context, u *url.URL, localPaths, keys []string, manifestKey string, opts Options) error {
+	client, err := newS3Client(u.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	manifest := S3Manifest{Bucket: u.Host, GeneratedAt: time.Now().UTC()}
+	for i, localPath := range localPaths {
+		file, err := client.putFile(ctx, keys[i], localPath)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", keys[i], err)
+		}
+		manifest.Files = append(manifest.Files, *file)
+	}
+
+	// The manifest goes last, so a consumer that sees it can rely on every object being in place
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := client.putBytes(ctx, manifestKey, append(data, '\n'), "application/json"); err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	return nil
+}
+
+// s3ManifestKey returns the manifest key for an upload: manifest.json under a prefix, or <key>.manifest.json for a single named object
+func s3ManifestKey(destinationPath string, keys []string) string {
+	if strings.HasSuffix(destinationPath, "/") || destinationPath == "" {
+		return strings.TrimPrefix(destinationPath, "/") + "manifest.json"
+	}
+	return keys[0] + ".manifest.json"
+}
+
+// s3EscapePath escapes an object key for the URL path as Signature Version 4 expects:
+// everything but unreserved characters and the slashes between segments is percent-encoded
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// contentType guesses the content type of a generated artifact from its extension
+func contentType(key string) string {
+	switch {
+	case strings.HasSuffix(key, ".csv"):
+		return "text/csv"
+	case strings.HasSuffix(key, ".json"):
+		return "application/json"
+	case strings.HasSuffix(key, ".xlsx"):
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
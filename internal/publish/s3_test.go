@@ -0,0 +1,64 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFilesToS3(t *testing.T) {
+	objects := make(map[string][]byte)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>SignatureDoesNotMatch</Code><Message>bad</Message></Error>`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		objects[r.URL.Path] = body
+	}))
+	defer ts.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	dir := t.TempDir()
+	premium := filepath.Join(dir, "premium-shop.csv")
+	reserved := filepath.Join(dir, "reserved shop.csv")
+	os.WriteFile(premium, []byte("Label,Tier\nshoes,1\n"), 0644)
+	os.WriteFile(reserved, []byte("domain\nnic.shop\n"), 0644)
+
+	opts := Options{S3Region: "eu-west-1", S3Endpoint: ts.URL}
+	uploaded, err := UploadFiles(context.Background(), []string{premium, reserved}, "s3://lists/shop/", opts)
+	if err != nil {
+		t.Fatalf("UploadFiles: %v", err)
+	}
+	if uploaded[0] != "s3://lists/shop/premium-shop.csv" {
+		t.Errorf("uploaded = %v", uploaded)
+	}
+	if string(objects["/lists/shop/premium-shop.csv"]) != "Label,Tier\nshoes,1\n" {
+		t.Errorf("objects = %v", objects)
+	}
+
+	var manifest S3Manifest
+	if err := json.Unmarshal(objects["/lists/shop/manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[1].Key != "shop/reserved shop.csv" || len(manifest.Files[0].SHA256) != 64 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	// A single named object gets its own manifest
+	if _, err := Upload(context.Background(), premium, "s3://lists/latest.csv", opts); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if _, ok := objects["/lists/latest.csv.manifest.json"]; !ok {
+		t.Errorf("missing manifest of a single object: %v", objects)
+	}
+}
@@ -0,0 +1,359 @@
+package publish
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTP (version 3) packet types and open flags used by the uploader
+const (
+	sshFxpInit     = 1
+	sshFxpVersion  = 2
+	sshFxpOpen     = 3
+	sshFxpClose    = 4
+	sshFxpWrite    = 6
+	sshFxpRemove   = 13
+	sshFxpRename   = 18
+	sshFxpStatus   = 101
+	sshFxpHandle   = 102
+	sshFxpExtended = 200
+
+	sshFxfWrite = 0x02
+	sshFxfCreat = 0x08
+	sshFxfTrunc = 0x10
+
+	sshFxOK = 0
+
+	// sftpChunkSize is the payload of a single write request, the maximum every server must accept
+	sftpChunkSize = 32 * 1024
+
+	posixRenameExtension = "posix-rename@openssh.com"
+)
+
+// uploadSFTP uploads r to remotePath over SFTP
+func uploadSFTP(ctx context.Context, u *url.URL, remotePath string, r io.Reader, opts Options) error {
+	config, closeAgent, err := sshClientConfig(u, opts)
+	if err != nil {
+		return err
+	}
+	defer closeAgent()
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("failed to start sftp subsystem: %w", err)
+	}
+
+	sc := &sftpConn{w: stdin, r: bufio.NewReader(stdout)}
+	if err := sc.init(); err != nil {
+		return err
+	}
+
+	part := partName(remotePath)
+	if err := sc.writeFile(part, r); err != nil {
+		sc.remove(part)
+		return err
+	}
+	return sc.rename(part, remotePath)
+}
+
+// sshClientConfig builds the SSH client config: known_hosts checking and key/agent/password auth
+// The returned function closes the agent connection
+func sshClientConfig(u *url.URL, opts Options) (*ssh.ClientConfig, func(), error) {
+	home, _ := os.UserHomeDir()
+
+	knownHostsFile := opts.KnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load known hosts (add the host with ssh-keyscan): %w", err)
+	}
+
+	closeAgent := func() {}
+	var signers []ssh.Signer
+	keyFiles := []string{opts.KeyFile}
+	if opts.KeyFile == "" {
+		if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+			if conn, err := net.Dial("unix", sock); err == nil {
+				closeAgent = func() { conn.Close() }
+				if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+					signers = append(signers, agentSigners...)
+				}
+			}
+		}
+		keyFiles = []string{filepath.Join(home, ".ssh", "id_ed25519"), filepath.Join(home, ".ssh", "id_rsa")}
+	}
+	for _, keyFile := range keyFiles {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			if opts.KeyFile != "" {
+				closeAgent()
+				return nil, nil, fmt.Errorf("failed to read SSH key: %w", err)
+			}
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			closeAgent()
+			return nil, nil, fmt.Errorf("failed to parse SSH key %s: %w", keyFile, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	var auth []ssh.AuthMethod
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	if pw := password(u, opts); pw != "" {
+		auth = append(auth, ssh.Password(pw))
+	}
+	if len(auth) == 0 {
+		closeAgent()
+		return nil, nil, errors.New("no SSH credentials (use a key, an SSH agent or a password)")
+	}
+
+	user := u.User.Username()
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, closeAgent, nil
+}
+
+// sftpConn is a minimal SFTP v3 client with just enough requests to upload a file
+// Requests are sent one at a time, so every response belongs to the last request
+type sftpConn struct {
+	w          io.Writer
+	r          *bufio.Reader
+	id         uint32
+	extensions map[string]bool
+}
+
+// init negotiates protocol version 3 and records the server's extensions
+func (c *sftpConn) init() error {
+	if err := c.send(sshFxpInit, nil, uint32(3)); err != nil {
+		return err
+	}
+	typ, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != sshFxpVersion || len(payload) < 4 {
+		return fmt.Errorf("unexpected SFTP init response %d", typ)
+	}
+	c.extensions = make(map[string]bool)
+	rest := payload[4:]
+	for len(rest) > 0 {
+		name, next, ok := readString(rest)
+		if !ok {
+			break
+		}
+		_, next, ok = readString(next)
+		if !ok {
+			break
+		}
+		c.extensions[string(name)] = true
+		rest = next
+	}
+	return nil
+}
+
+// writeFile creates (or truncates) path and writes the contents of r to it
+func (c *sftpConn) writeFile(path string, r io.Reader) error {
+	c.id++
+	if err := c.send(sshFxpOpen, []uint32{c.id}, path, uint32(sshFxfWrite|sshFxfCreat|sshFxfTrunc), uint32(0)); err != nil {
+		return err
+	}
+	typ, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ == sshFxpStatus {
+		return statusError("open "+path, payload)
+	}
+	if typ != sshFxpHandle || len(payload) < 4 {
+		return fmt.Errorf("unexpected SFTP open response %d", typ)
+	}
+	handle, _, ok := readString(payload[4:])
+	if !ok {
+		return fmt.Errorf("malformed SFTP handle")
+	}
+
+	buf := make([]byte, sftpChunkSize)
+	var offset uint64
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			c.id++
+			if err := c.send(sshFxpWrite, []uint32{c.id}, string(handle), offset, string(buf[:n])); err != nil {
+				return err
+			}
+			if err := c.expectOK("write " + path); err != nil {
+				return err
+			}
+			offset += uint64(n)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	c.id++
+	if err := c.send(sshFxpClose, []uint32{c.id}, string(handle)); err != nil {
+		return err
+	}
+	return c.expectOK("close " + path)
+}
+
+// rename moves from to to, replacing an existing file
+func (c *sftpConn) rename(from, to string) error {
+	c.id++
+	if c.extensions[posixRenameExtension] {
+		if err := c.send(sshFxpExtended, []uint32{c.id}, posixRenameExtension, from, to); err != nil {
+			return err
+		}
+		return c.expectOK("rename " + from)
+	}
+
+	// Plain v3 rename fails if the target exists
+	c.remove(to)
+	c.id++
+	if err := c.send(sshFxpRename, []uint32{c.id}, from, to); err != nil {
+		return err
+	}
+	return c.expectOK("rename " + from)
+}
+
+// remove deletes a file, ignoring errors
+func (c *sftpConn) remove(path string) {
+	c.id++
+	if c.send(sshFxpRemove, []uint32{c.id}, path) == nil {
+		c.expectOK("remove " + path)
+	}
+}
+
+// expectOK reads a status response and returns an error unless it is SSH_FX_OK
+func (c *sftpConn) expectOK(op string) error {
+	typ, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != sshFxpStatus {
+		return fmt.Errorf("%s: unexpected SFTP response %d", op, typ)
+	}
+	return statusError(op, payload)
+}
+
+// send writes a packet; fields are uint32, uint64 or string values, ids are written first
+func (c *sftpConn) send(typ byte, ids []uint32, fields ...interface{}) error {
+	payload := []byte{typ}
+	for _, id := range ids {
+		payload = binary.BigEndian.AppendUint32(payload, id)
+	}
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint32:
+			payload = binary.BigEndian.AppendUint32(payload, v)
+		case uint64:
+			payload = binary.BigEndian.AppendUint64(payload, v)
+		case string:
+			payload = binary.BigEndian.AppendUint32(payload, uint32(len(v)))
+			payload = append(payload, v...)
+		}
+	}
+
+	packet := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), uint32(len(payload)))
+	_, err := c.w.Write(append(packet, payload...))
+	return err
+}
+
+// recv reads a packet, returning its type and payload
+func (c *sftpConn) recv() (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("failed to read SFTP response: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.r, packet); err != nil {
+		return 0, nil, fmt.Errorf("failed to read SFTP response: %w", err)
+	}
+	return packet[0], packet[1:], nil
+}
+
+// statusError decodes a status payload (id, code, message), returning nil for SSH_FX_OK
+func statusError(op string, payload []byte) error {
+	if len(payload) < 8 {
+		return fmt.Errorf("%s: malformed SFTP status", op)
+	}
+	code := binary.BigEndian.Uint32(payload[4:8])
+	if code == sshFxOK {
+		return nil
+	}
+	msg, _, _ := readString(payload[8:])
+	return fmt.Errorf("%s: %s (SFTP status %d)", op, msg, code)
+}
+
+// readString reads a length-prefixed string, returning the rest of the buffer
+func readString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
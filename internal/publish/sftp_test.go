@@ -0,0 +1,257 @@
+package publish
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeSFTPServer is an in-process SSH server with an SFTP subsystem that keeps files in memory
+// Writes to files below /full/ fail
+type fakeSFTPServer struct {
+	addr        string
+	hostKey     ssh.Signer
+	posixRename bool // Announce posix-rename@openssh.com
+
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newFakeSFTPServer(t *testing.T, password string, posixRename bool, files map[string][]byte) *fakeSFTPServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+			if conn.User() != "pricing" || string(pw) != password {
+				return nil, ssh.ErrNoAuth
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	if files == nil {
+		files = make(map[string][]byte)
+	}
+	s := &fakeSFTPServer{addr: listener.Addr().String(), hostKey: hostKey, posixRename: posixRename, files: files}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, config)
+		}
+	}()
+	return s
+}
+
+// knownHosts writes a known_hosts file with the server's host key
+func (s *fakeSFTPServer) knownHosts(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, s.hostKey.PublicKey())
+	if err := os.WriteFile(path, []byte(line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (s *fakeSFTPServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && bytes.Equal(req.Payload[4:], []byte("sftp"))
+				req.Reply(ok, nil)
+				if ok {
+					go func() {
+						defer channel.Close()
+						s.serveSFTP(&sftpConn{w: channel, r: bufio.NewReader(channel)})
+					}()
+				}
+			}
+		}()
+	}
+}
+
+// serveSFTP answers the requests the uploader sends, using handles that are the file paths
+func (s *fakeSFTPServer) serveSFTP(c *sftpConn) {
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			return
+		}
+		if typ == sshFxpInit {
+			if s.posixRename {
+				c.send(sshFxpVersion, nil, uint32(3), posixRenameExtension, "1")
+			} else {
+				c.send(sshFxpVersion, nil, uint32(3))
+			}
+			continue
+		}
+
+		id, rest := binary.BigEndian.Uint32(payload), payload[4:]
+		str := func() string {
+			b, next, _ := readString(rest)
+			rest = next
+			return string(b)
+		}
+		status := func(code uint32, msg string) {
+			c.send(sshFxpStatus, []uint32{id}, code, msg, "")
+		}
+
+		s.mu.Lock()
+		switch typ {
+		case sshFxpOpen:
+			path := str()
+			s.files[path] = nil
+			c.send(sshFxpHandle, []uint32{id}, path)
+		case sshFxpWrite:
+			path := str()
+			offset := binary.BigEndian.Uint64(rest)
+			rest = rest[8:]
+			data := str()
+			if strings.HasPrefix(path, "/full/") {
+				status(4, "disk full")
+				break
+			}
+			s.files[path] = append(s.files[path][:offset], data...)
+			status(sshFxOK, "")
+		case sshFxpClose:
+			status(sshFxOK, "")
+		case sshFxpRemove:
+			path := str()
+			if _, ok := s.files[path]; !ok {
+				status(2, "no such file")
+				break
+			}
+			delete(s.files, path)
+			status(sshFxOK, "")
+		case sshFxpRename, sshFxpExtended:
+			if typ == sshFxpExtended && str() != posixRenameExtension {
+				status(8, "unsupported")
+				break
+			}
+			from, to := str(), str()
+			if _, exists := s.files[to]; exists && typ == sshFxpRename {
+				status(4, "file exists")
+				break
+			}
+			s.files[to] = s.files[from]
+			delete(s.files, from)
+			status(sshFxOK, "")
+		default:
+			status(8, "unsupported")
+		}
+		s.mu.Unlock()
+	}
+}
+
+// stored returns a copy of the stored files
+func (s *fakeSFTPServer) stored() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make(map[string]string, len(s.files))
+	for name, data := range s.files {
+		files[name] = string(data)
+	}
+	return files
+}
+
+func TestUploadSFTP(t *testing.T) {
+	// Only the password of the URI authenticates, not the agent or keys of the user running the tests
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	// Bigger than a write request, so it is sent in chunks
+	content := strings.Repeat("shoes,1\n", sftpChunkSize/4)
+	local := filepath.Join(t.TempDir(), "premium-shop.csv")
+	if err := os.WriteFile(local, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, posixRename := range []bool{true, false} {
+		s := newFakeSFTPServer(t, "secret", posixRename, map[string][]byte{"/incoming/premium-shop.csv": []byte("old list")})
+		opts := Options{KnownHostsFile: s.knownHosts(t)}
+
+		uploaded, err := Upload(context.Background(), local, "sftp://pricing:secret@"+s.addr+"/incoming/", opts)
+		if err != nil {
+			t.Fatalf("posix-rename %v: Upload: %v", posixRename, err)
+		}
+		if want := "sftp://pricing:xxxxx@" + s.addr + "/incoming/premium-shop.csv"; uploaded != want {
+			t.Errorf("posix-rename %v: uploaded = %s, want %s", posixRename, uploaded, want)
+		}
+		if files := s.stored(); len(files) != 1 || files["/incoming/premium-shop.csv"] != content {
+			t.Errorf("posix-rename %v: %d files, want only the old list replaced", posixRename, len(files))
+		}
+	}
+}
+
+func TestUploadSFTP_Failures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+	local := filepath.Join(t.TempDir(), "premium-shop.csv")
+	if err := os.WriteFile(local, []byte("shoes,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := newFakeSFTPServer(t, "secret", true, nil)
+	opts := Options{KnownHostsFile: s.knownHosts(t)}
+
+	if _, err := Upload(context.Background(), local, "sftp://pricing:wrong@"+s.addr+"/premium.csv", opts); err == nil {
+		t.Error("upload with a wrong password succeeded")
+	}
+
+	unknown := filepath.Join(t.TempDir(), "known_hosts")
+	os.WriteFile(unknown, nil, 0644)
+	_, err := Upload(context.Background(), local, "sftp://pricing:secret@"+s.addr+"/premium.csv", Options{KnownHostsFile: unknown})
+	if err == nil || !strings.Contains(err.Error(), "knownhosts") {
+		t.Errorf("upload to an unknown host: err = %v, want a host key error", err)
+	}
+
+	// A failed write removes the partial file
+	_, err = Upload(context.Background(), local, "sftp://pricing:secret@"+s.addr+"/full/premium.csv", opts)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("upload with failing writes: err = %v", err)
+	}
+	if files := s.stored(); len(files) != 0 {
+		t.Errorf("files after a failed upload = %v", files)
+	}
+}
@@ -0,0 +1,191 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2/jwt"
+)
+
+// SheetsScope is the OAuth scope needed to write spreadsheets
+const SheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// sheetsBatchRows is the number of rows written per request, keeping requests well below the API's size limit
+const sheetsBatchRows = 10000
+
+// SheetsPublisher writes rows into a tab of a Google Sheet through the Sheets API v4
+type SheetsPublisher struct {
+	BaseURL string       // API base URL, https://sheets.googleapis.com by default
+	Client  *http.Client // Client authorized for SheetsScope
+}
+
+// NewSheetsPublisher creates a publisher authenticated as the service account in credentialsFile
+// The spreadsheet must be shared with the service account's email address
+func NewSheetsPublisher(ctx context.Context, credentialsFile string) (*SheetsPublisher, error) {
+	if credentialsFile == "" {
+		return nil, fmt.Errorf("Google service account credentials are required for gsheets:// uploads")
+	}
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var key struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse Google credentials: %w", err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("%s is not a Google service account key", credentialsFile)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	config := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{SheetsScope},
+		TokenURL:     key.TokenURI,
+	}
+	return &SheetsPublisher{BaseURL: "https://sheets.googleapis.com", Client: config.Client(ctx)}, nil
+}
+
+// WriteTab replaces the contents of a tab with rows, creating the tab if it doesn't exist
+func (p *SheetsPublisher) WriteTab(ctx context.Context, spreadsheetID, tab string, rows [][]string) error {
+	exists, err := p.hasTab(ctx, spreadsheetID, tab)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		addSheet := map[string]any{
+			"requests": []any{map[string]any{"addSheet": map[string]any{"properties": map[string]any{"title": tab}}}},
+		}
+		if err := p.call(ctx, http.MethodPost, "/v4/spreadsheets/"+url.PathEscape(spreadsheetID)+":batchUpdate", addSheet, nil); err != nil {
+			return fmt.Errorf("failed to add tab %q: %w", tab, err)
+		}
+	} else {
+		clearPath := "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(sheetRange(tab, "")) + ":clear"
+		if err := p.call(ctx, http.MethodPost, clearPath, map[string]any{}, nil); err != nil {
+			return fmt.Errorf("failed to clear tab %q: %w", tab, err)
+		}
+	}
+
+	for start := 0; start < len(rows); start += sheetsBatchRows {
+		end := min(start+sheetsBatchRows, len(rows))
+		cell := fmt.Sprintf("A%d", start+1)
+		body := map[string]any{
+			"range":          sheetRange(tab, cell),
+			"majorDimension": "ROWS",
+			"values":         rows[start:end],
+		}
+		// RAW keeps labels like "0001" or "true" exactly as written instead of parsing them
+		updatePath := "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(sheetRange(tab, cell)) + "?valueInputOption=RAW"
+		if err := p.call(ctx, http.MethodPut, updatePath, body, nil); err != nil {
+			return fmt.Errorf("failed to write rows %d-%d: %w", start+1, end, err)
+		}
+	}
+	return nil
+}
+
+// hasTab reports whether the spreadsheet has a tab with the given title
+func (p *SheetsPublisher) hasTab(ctx context.Context, spreadsheetID, tab string) (bool, error) {
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := p.call(ctx, http.MethodGet, "/v4/spreadsheets/"+url.PathEscape(spreadsheetID)+"?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return false, fmt.Errorf("failed to open spreadsheet: %w", err)
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == tab {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// call sends a JSON request to the API and decodes the JSON response into out (if not nil)
+func (p *SheetsPublisher) call(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// sheetRange returns an A1 range on a tab, quoting the tab name, e.g. 'Premium List'!A1
+func sheetRange(tab, cell string) string {
+	r := "'" + strings.ReplaceAll(tab, "'", "''") + "'"
+	if cell != "" {
+		r += "!" + cell
+	}
+	return r
+}
+
+// uploadSheets writes the CSV in r to the tab of the spreadsheet named by the URI host
+func uploadSheets(ctx context.Context, u *url.URL, tab string, r io.Reader, opts Options) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff") // Written by generate --bom
+	}
+
+	publisher, err := NewSheetsPublisher(ctx, opts.GoogleCredentialsFile)
+	if err != nil {
+		return err
+	}
+	return publisher.WriteTab(ctx, u.Host, tab, rows)
+}
@@ -0,0 +1,57 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSheetsWriteTab(t *testing.T) {
+	var calls []string
+	var written [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"sheets": [{"properties": {"title": "Sheet1"}}]}`))
+		case r.Method == http.MethodPut:
+			if r.URL.Query().Get("valueInputOption") != "RAW" {
+				t.Errorf("valueInputOption = %q", r.URL.Query().Get("valueInputOption"))
+			}
+			var body struct {
+				Values [][]string `json:"values"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			written = append(written, body.Values...)
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	publisher := &SheetsPublisher{BaseURL: ts.URL, Client: ts.Client()}
+	rows := [][]string{{"Label", "Tier"}, {"0001", "3"}}
+	if err := publisher.WriteTab(context.Background(), "sheet-id", "Premium List", rows); err != nil {
+		t.Fatalf("WriteTab: %v", err)
+	}
+
+	want := []string{
+		"GET /v4/spreadsheets/sheet-id",
+		"POST /v4/spreadsheets/sheet-id:batchUpdate",
+		"PUT /v4/spreadsheets/sheet-id/values/'Premium List'!A1",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %q, want %q", i, calls[i], want[i])
+		}
+	}
+	if len(written) != 2 || written[1][0] != "0001" {
+		t.Errorf("written = %v", written)
+	}
+}
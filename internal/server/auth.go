@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// Role is the access level granted to a caller
+type Role int
+
+// Roles, in increasing order of access
+const (
+	RoleNone Role = iota
+	RoleRead
+	RoleWrite
+)
+
+// ParseRole parses "read" or "write"
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "read", "ro", "read-only":
+		return RoleRead, nil
+	case "write", "rw", "read-write":
+		return RoleWrite, nil
+	default:
+		return RoleNone, fmt.Errorf("invalid role %q (expected read or write)", s)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return "read"
+	case RoleWrite:
+		return "write"
+	default:
+		return "none"
+	}
+}
+
+// OIDCConfig configures bearer token validation against an OIDC provider
+type OIDCConfig struct {
+	Issuer    string // Issuer URL, used for discovery
+	Audience  string // Expected audience (client ID), required
+	RoleClaim string // Claim holding the caller's roles (string or list), defaults to "roles"
+	WriteRole string // Role value granting write access; all other valid tokens are read-only
+}
+
+// Authenticator resolves credentials on incoming requests to a role
+// A nil Authenticator disables authentication and grants write access to everyone
+type Authenticator struct {
+	apiKeys   map[[sha256.Size]byte]Role
+	verifier  *oidc.IDTokenVerifier
+	roleClaim string
+	writeRole string
+}
+
+// NewAuthenticator creates an authenticator from API keys and an optional OIDC provider
+// API keys are given as "key:role" pairs
+func NewAuthenticator(ctx context.Context, apiKeys []string, oidcConfig *OIDCConfig) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: make(map[[sha256.Size]byte]Role)}
+
+	for _, entry := range apiKeys {
+		key, roleStr, ok := strings.Cut(entry, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid API key %q (expected key:role)", redactKey(entry))
+		}
+		role, err := ParseRole(roleStr)
+		if err != nil {
+			return nil, err
+		}
+		a.apiKeys[sha256.Sum256([]byte(key))] = role
+	}
+
+	if oidcConfig != nil && oidcConfig.Issuer != "" {
+		// Without an audience, tokens the issuer signs for any of its clients would be accepted
+		if oidcConfig.Audience == "" {
+			return nil, fmt.Errorf("an OIDC audience is required with issuer %s", oidcConfig.Issuer)
+		}
+		provider, err := oidc.NewProvider(ctx, oidcConfig.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+		}
+		a.verifier = provider.Verifier(&oidc.Config{ClientID: oidcConfig.Audience})
+		a.roleClaim = oidcConfig.RoleClaim
+		if a.roleClaim == "" {
+			a.roleClaim = "roles"
+		}
+		a.writeRole = oidcConfig.WriteRole
+	}
+
+	return a, nil
+}
+
+// LoadAPIKeysFile reads "key role" pairs, one per line; blank lines and # comments are ignored
+func LoadAPIKeysFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API keys file: %w", err)
+	}
+	defer file.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"key role\"", path, lineNum)
+		}
+		keys = append(keys, fields[0]+":"+fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	return keys, nil
+}
+
+// Authenticate returns the role granted by a credential (API key or OIDC token)
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) Role {
+	if credential == "" {
+		return RoleNone
+	}
+	if role, ok := a.apiKeys[sha256.Sum256([]byte(credential))]; ok {
+		return role
+	}
+	if a.verifier == nil {
+		return RoleNone
+	}
+
+	token, err := a.verifier.Verify(ctx, credential)
+	if err != nil {
+		return RoleNone
+	}
+	if a.writeRole != "" && a.hasRoleClaim(token, a.writeRole) {
+		return RoleWrite
+	}
+	return RoleRead
+}
+
+// hasRoleClaim reports whether the token's role claim contains role
+func (a *Authenticator) hasRoleClaim(token *oidc.IDToken, role string) bool {
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return false
+	}
+	switch v := claims[a.roleClaim].(type) {
+	case string:
+		return v == role
+	case []interface{}:
+		for _, r := range v {
+			if s, ok := r.(string); ok && s == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// credentialFromRequest extracts the API key or bearer token from a request
+// The access_token query parameter is accepted for event streams, since browsers' EventSource can't set headers
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token := bearerToken(r.Header.Get("Authorization")); token != "" {
+		return token
+	}
+	if strings.HasSuffix(r.URL.Path, "/events") {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" value
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// requiredRole returns the role needed for an HTTP request
+// Reads are GET/HEAD plus the side-effect free POSTs for validation, evaluation and generation
+func requiredRole(r *http.Request) Role {
+	path := r.URL.Path
+	if path == "/" || path == "/healthz" || path == "/readyz" || path == "/api/openapi.yaml" || strings.HasPrefix(path, "/ui/") {
+		return RoleNone
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return RoleRead
+	}
+	if r.Method == http.MethodPost && (path == "/api/tiers/validate" || path == "/api/tiers/evaluate" || path == "/api/generate") {
+		return RoleRead
+	}
+	return RoleWrite
+}
+
+// authorize checks the request against the authenticator, writing an error response on failure
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	return authorizeRequest(s.auth, w, r)
+}
+
+// authorizeRequest checks the request against auth (nil allows everything), writing an error response on failure
+func authorizeRequest(auth *Authenticator, w http.ResponseWriter, r *http.Request) bool {
+	if auth == nil {
+		return true
+	}
+	required := requiredRole(r)
+	if required == RoleNone {
+		return true
+	}
+
+	role := auth.Authenticate(r.Context(), credentialFromRequest(r))
+	if role == RoleNone {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="premium-list-maker"`)
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+	if role < required {
+		writeError(w, http.StatusForbidden, "write access required")
+		return false
+	}
+	return true
+}
+
+// redactKey hides the key part of a "key:role" entry for error messages
+func redactKey(entry string) string {
+	if len(entry) <= 4 {
+		return "****"
+	}
+	return entry[:4] + "****"
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/db"
+)
+
+func TestAuthorization(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	auth, err := NewAuthenticator(context.Background(), []string{"reader:read", "writer:write"}, nil)
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	srv, err := New(database, Options{Auth: auth, JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		want   int
+	}{
+		{"no key", http.MethodGet, "/api/tags", "", http.StatusUnauthorized},
+		{"wrong key", http.MethodGet, "/api/tags", "nope", http.StatusUnauthorized},
+		{"read key can read", http.MethodGet, "/api/tags", "reader", http.StatusOK},
+		{"read key can validate", http.MethodPost, "/api/tiers/validate", "reader", http.StatusOK},
+		{"read key cannot write", http.MethodDelete, "/api/tags/x", "reader", http.StatusForbidden},
+		{"write key can write", http.MethodDelete, "/api/tags/x", "writer", http.StatusNotFound},
+		{"spec is public", http.MethodGet, "/api/openapi.yaml", "", http.StatusOK},
+		{"ui is public", http.MethodGet, "/ui/", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.method == http.MethodPost {
+				req = httptest.NewRequest(tt.method, tt.path, strings.NewReader("[]"))
+			}
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator_RequiresAudience(t *testing.T) {
+	_, err := NewAuthenticator(context.Background(), nil, &OIDCConfig{Issuer: "https://login.example.com/realms/registry"})
+	if err == nil || !strings.Contains(err.Error(), "audience") {
+		t.Errorf("err = %v, want the missing audience refused", err)
+	}
+}
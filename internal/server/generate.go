@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagexpr"
+	"premium-list-maker/internal/webhook"
+)
+
+// generateRequest is the body for generating a premium list
+type generateRequest struct {
+	Tiers       []models.Tier `json:"tiers"`
+	Format      string        `json:"format"`
+	TLD         string        `json:"tld"`
+	ExcludeTags []string      `json:"exclude_tags"`
+	Where       string        `json:"where"` // Filter expression, see package tagexpr
+}
+
+// maxEvaluateLabels caps the labels of one tier evaluation request
+const maxEvaluateLabels = 1000
+
+// evaluateRequest is the body for evaluating tiers against labels
+type evaluateRequest struct {
+	Tiers       []models.Tier `json:"tiers"`
+	Labels      []string      `json:"labels"`
+	ExcludeTags []string      `json:"exclude_tags"`
+	Where       string        `json:"where"`
+}
+
+// evaluateResponse holds the decision trail of every evaluated label in the database
+type evaluateResponse struct {
+	Results  []*generator.Explanation `json:"results"`
+	NotFound []string                 `json:"not_found"`
+}
+
+// handleEvaluateTiers evaluates tiers against labels in the database, returning how generate would list and price each
+func (s *Server) handleEvaluateTiers(w http.ResponseWriter, r *http.Request) {
+	var req evaluateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Labels) == 0 || len(req.Labels) > maxEvaluateLabels {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("labels must list 1 to %d labels", maxEvaluateLabels))
+		return
+	}
+	if validation := generator.ValidateTiers(req.Tiers, nil); !validation.Valid {
+		writeJSON(w, http.StatusBadRequest, validation)
+		return
+	}
+	opts := generator.Options{ExcludeTags: req.ExcludeTags}
+	if req.Where != "" {
+		where, err := tagexpr.Parse(req.Where)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opts.Where = where
+	}
+
+	resp := evaluateResponse{Results: make([]*generator.Explanation, 0, len(req.Labels)), NotFound: make([]string, 0)}
+	for _, label := range req.Labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		l, err := s.db.GetLabel(label)
+		if errors.Is(err, db.ErrNotFound) {
+			resp.NotFound = append(resp.NotFound, label)
+			continue
+		}
+		if err != nil {
+			writeDBError(w, err)
+			return
+		}
+		override, err := s.db.GetPriceOverride(label)
+		if errors.Is(err, db.ErrNotFound) {
+			override = nil
+		} else if err != nil {
+			writeDBError(w, err)
+			return
+		}
+		// Explain only fails on the posted tiers, e.g. prices it can't convert
+		explanation, err := generator.Explain(l.Label, l.Tags, override, req.Tiers, opts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp.Results = append(resp.Results, explanation)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleValidateTiers validates a tiers configuration posted as the JSON body
+func (s *Server) handleValidateTiers(w http.ResponseWriter, r *http.Request) {
+	var tiers []models.Tier
+	if !decodeJSON(w, r, &tiers) {
+		return
+	}
+
+	knownTags, err := s.knownTags()
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	validation, err := s.validateTiers(tiers, knownTags)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, validation)
+}
+
+// validateTiers validates a tiers configuration and reports the labels in the database matching several tiers
+func (s *Server) validateTiers(tiers []models.Tier, knownTags map[string]bool) (*generator.TierValidation, error) {
+	validation := generator.ValidateTiers(tiers, knownTags)
+	if !validation.Valid {
+		return validation, nil
+	}
+	overlaps, err := generator.AnalyzeOverlaps(s.db, tiers)
+	if err != nil {
+		return nil, err
+	}
+	validation.AddOverlaps(overlaps)
+	return validation, nil
+}
+
+// handleGenerate generates a premium list and returns it as a CSV download
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req generateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Format == "" {
+		req.Format = "default"
+	}
+
+	if !s.checkGenerateRequest(w, req) {
+		return
+	}
+
+	// The generation stops if the client goes away
+	outputPath, cleanup, err := s.generateToFile(r.Context(), req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer cleanup()
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("premium-list-%s%s", time.Now().Format("20060102-150405"), filepath.Ext(outputPath))
+	w.Header().Set("Content-Type", listContentType(outputPath))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	io.Copy(w, file)
+}
+
+// checkGenerateRequest validates a generate request up front, writing an error response on failure
+func (s *Server) checkGenerateRequest(w http.ResponseWriter, req generateRequest) bool {
+	if validation := generator.ValidateTiers(req.Tiers, nil); !validation.Valid {
+		writeJSON(w, http.StatusBadRequest, validation)
+		return false
+	}
+	if req.Format == "cnic-new" && req.TLD == "" {
+		writeError(w, http.StatusBadRequest, "tld is required for cnic-new format")
+		return false
+	}
+	if req.Where != "" {
+		if _, err := tagexpr.Parse(req.Where); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+// listFileName returns the file name of a generated premium list in format
+func listFileName(format string) string {
+	if format == "xlsx" {
+		return "premium-list.xlsx"
+	}
+	return "premium-list.csv"
+}
+
+// listContentType returns the content type of a generated premium list by its file name
+func listContentType(name string) string {
+	if strings.HasSuffix(name, ".xlsx") {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// generateToFile generates a premium list into a temp file, until ctx is canceled
+// The returned cleanup function removes the temp dir
+func (s *Server) generateToFile(ctx context.Context, req generateRequest) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "premium-list-generate")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	outputPath := filepath.Join(tmpDir, listFileName(req.Format))
+	if _, err := s.generate(ctx, req, outputPath, nil); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return outputPath, cleanup, nil
+}
+
+// generate generates a premium list into outputPath and fires the generate webhook
+// report (if not nil) gets the generator's progress; canceling ctx stops the generation without output
+func (s *Server) generate(ctx context.Context, req generateRequest, outputPath string, report progress.Func) (*generator.GenerateResult, error) {
+	opts := generator.Options{Format: req.Format, TLD: req.TLD, ExcludeTags: req.ExcludeTags, Progress: report, Verify: true, Context: ctx}
+	if req.Where != "" {
+		where, err := tagexpr.Parse(req.Where)
+		if err != nil {
+			return nil, err
+		}
+		opts.Where = where
+	}
+	result, err := generator.GenerateFromTiers(s.db, req.Tiers, outputPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifyGenerated(result, req)
+	}
+	return result, nil
+}
+
+// notifyGenerated fires the generate.completed webhook in the background
+// The output is a temp file, so only its checksum and size are reported
+func (s *Server) notifyGenerated(result *generator.GenerateResult, req generateRequest) {
+	event := webhook.Event{
+		Event:  webhook.EventGenerateCompleted,
+		SHA256: result.SHA256,
+		Stats: webhook.GenerateStats{
+			Format:     req.Format,
+			TLD:        req.TLD,
+			SizeBytes:  result.SizeBytes,
+			DurationMS: result.DurationMS,
+		},
+	}
+	go func() {
+		if err := s.notifier.Notify(event); err != nil {
+			log.Printf("webhook: %v", err)
+		}
+	}()
+}
+
+// knownTags returns the set of tag names in the database
+func (s *Server) knownTags() (map[string]bool, error) {
+	tags, err := s.db.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		known[t.Name] = true
+	}
+	return known, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+func TestEvaluateTiers(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv, err := New(database, Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	for _, body := range []string{`{"label": "shoes", "tags": ["fashion"]}`, `{"label": "hats", "tags": ["fashion", "registered"]}`} {
+		resp, err := http.Post(ts.URL+"/api/labels", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create label = %d", resp.StatusCode)
+		}
+	}
+
+	const tiers = `"tiers": [{"tier": 1, "tags": ["fashion"], "currency": "USD", "price_reg": 100}]`
+	resp, err := http.Post(ts.URL+"/api/tiers/evaluate", "application/json",
+		strings.NewReader(`{`+tiers+`, "labels": ["shoes", "Hats", "bags"], "exclude_tags": ["registered"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result evaluateResponse
+	decodeBody(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || len(result.Results) != 2 || len(result.NotFound) != 1 || result.NotFound[0] != "bags" {
+		t.Fatalf("evaluate = %d %+v", resp.StatusCode, result)
+	}
+	if shoes := result.Results[0]; !shoes.Listed || shoes.Tier != 1 || *shoes.Entry.PriceReg != 100 {
+		t.Errorf("shoes = %+v", shoes)
+	}
+	if hats := result.Results[1]; hats.Listed || len(hats.ExcludedBy) != 1 {
+		t.Errorf("hats = %+v", hats)
+	}
+
+	invalidTiers := []string{
+		`{"tiers": [], "labels": ["shoes"]}`,
+		`{"tiers": [{"tier": 1, "tags": ["fashion"], "currency": "USD", "price_reg": -5}], "labels": ["shoes"]}`,
+		`{"tiers": [{"tier": 1, "tags": [], "currency": "USD", "price_reg": 100}], "labels": ["shoes"]}`,
+	}
+	for _, body := range append([]string{`{` + tiers + `, "labels": []}`, `{` + tiers + `, "labels": ["shoes"], "where": "len <"}`}, invalidTiers...) {
+		resp, err := http.Post(ts.URL+"/api/tiers/evaluate", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestGenerate_Canceled(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv, err := New(database, Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// A client that went away cancels the request context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	price := 100.0
+	req := generateRequest{Format: "default", Tiers: []models.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}}
+	if _, _, err := srv.generateToFile(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
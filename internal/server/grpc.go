@@ -0,0 +1,318 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	pb "premium-list-maker/api/premiumlist/v1"
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// generateChunkSize is the size of the CSV chunks streamed by Generate
+const generateChunkSize = 64 * 1024
+
+// grpcService implements the PremiumListService gRPC API on top of the REST server's database
+type grpcService struct {
+	pb.UnimplementedPremiumListServiceServer
+	s *Server
+}
+
+// NewGRPCServer creates a gRPC server exposing the same operations as the REST API
+// Calls are authorized with the same authenticator and roles as HTTP requests
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	if s.auth != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(s.unaryAuthInterceptor),
+			grpc.ChainStreamInterceptor(s.streamAuthInterceptor),
+		)
+	}
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterPremiumListServiceServer(grpcServer, &grpcService{s: s})
+	return grpcServer
+}
+
+// grpcWriteMethods are the RPCs that modify the database and require write access
+var grpcWriteMethods = map[string]bool{
+	pb.PremiumListService_CreateLabel_FullMethodName:    true,
+	pb.PremiumListService_DeleteLabel_FullMethodName:    true,
+	pb.PremiumListService_AddLabelTags_FullMethodName:   true,
+	pb.PremiumListService_RemoveLabelTag_FullMethodName: true,
+	pb.PremiumListService_CreateTag_FullMethodName:      true,
+	pb.PremiumListService_RenameTag_FullMethodName:      true,
+	pb.PremiumListService_DeleteTag_FullMethodName:      true,
+}
+
+// authorizeGRPC checks the "authorization" (Bearer) or "x-api-key" metadata of a call
+func (s *Server) authorizeGRPC(ctx context.Context, fullMethod string) error {
+	var credential string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get("x-api-key"); len(keys) > 0 {
+			credential = keys[0]
+		} else if auth := md.Get("authorization"); len(auth) > 0 {
+			credential = bearerToken(auth[0])
+		}
+	}
+
+	required := RoleRead
+	if grpcWriteMethods[fullMethod] {
+		required = RoleWrite
+	}
+
+	role := s.auth.Authenticate(ctx, credential)
+	if role == RoleNone {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if role < required {
+		return status.Error(codes.PermissionDenied, "write access required")
+	}
+	return nil
+}
+
+func (s *Server) unaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorizeGRPC(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorizeGRPC(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// grpcError maps database errors to gRPC status errors
+func grpcError(err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (g *grpcService) ListLabels(ctx context.Context, req *pb.ListLabelsRequest) (*pb.ListLabelsResponse, error) {
+	filter := db.LabelFilter{
+		Tags:      req.Tags,
+		MinLength: int(req.MinLength),
+		MaxLength: int(req.MaxLength),
+		Prefix:    strings.ToLower(req.Prefix),
+		Contains:  strings.ToLower(req.Contains),
+		Limit:     int(req.Limit),
+		Offset:    int(req.Offset),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	labels, err := g.s.db.ListLabels(filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	total, err := g.s.db.CountLabels(filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &pb.ListLabelsResponse{Total: int64(total)}
+	for i := range labels {
+		resp.Labels = append(resp.Labels, toPBLabel(&labels[i]))
+	}
+	return resp, nil
+}
+
+func (g *grpcService) GetLabel(ctx context.Context, req *pb.GetLabelRequest) (*pb.Label, error) {
+	return g.getLabel(req.Label)
+}
+
+func (g *grpcService) CreateLabel(ctx context.Context, req *pb.CreateLabelRequest) (*pb.Label, error) {
+	label := strings.ToLower(strings.TrimSpace(req.Label))
+	if err := importer.ValidateLabel(label); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	labelID, err := g.s.db.InsertLabel(label, len(label))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := g.s.addTags(labelID, req.Tags); err != nil {
+		return nil, grpcError(err)
+	}
+	return g.getLabel(label)
+}
+
+func (g *grpcService) DeleteLabel(ctx context.Context, req *pb.DeleteLabelRequest) (*pb.DeleteLabelResponse, error) {
+	if err := g.s.db.DeleteLabel(req.Label); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteLabelResponse{}, nil
+}
+
+func (g *grpcService) AddLabelTags(ctx context.Context, req *pb.AddLabelTagsRequest) (*pb.Label, error) {
+	labelID, err := g.s.db.GetLabelID(req.Label)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	if err := g.s.addTags(labelID, req.Tags); err != nil {
+		return nil, grpcError(err)
+	}
+	return g.getLabel(req.Label)
+}
+
+func (g *grpcService) RemoveLabelTag(ctx context.Context, req *pb.RemoveLabelTagRequest) (*pb.RemoveLabelTagResponse, error) {
+	if err := g.s.db.RemoveTagFromLabel(req.Label, req.Tag); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.RemoveLabelTagResponse{}, nil
+}
+
+func (g *grpcService) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	tags, err := g.s.db.ListTags()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &pb.ListTagsResponse{}
+	for _, t := range tags {
+		resp.Tags = append(resp.Tags, &pb.Tag{Id: t.ID, Name: t.Name, LabelCount: int64(t.LabelCount)})
+	}
+	return resp, nil
+}
+
+func (g *grpcService) CreateTag(ctx context.Context, req *pb.CreateTagRequest) (*pb.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "tag name is required")
+	}
+
+	id, err := g.s.db.GetOrCreateTag(name)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Tag{Id: id, Name: name}, nil
+}
+
+func (g *grpcService) RenameTag(ctx context.Context, req *pb.RenameTagRequest) (*pb.Tag, error) {
+	newName := strings.TrimSpace(req.NewName)
+	if newName == "" {
+		return nil, status.Error(codes.InvalidArgument, "new tag name is required")
+	}
+
+	if _, err := g.s.db.RenameTag(req.Name, newName); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Tag{Name: newName}, nil
+}
+
+func (g *grpcService) DeleteTag(ctx context.Context, req *pb.DeleteTagRequest) (*pb.DeleteTagResponse, error) {
+	if err := g.s.db.DeleteTag(req.Name); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteTagResponse{}, nil
+}
+
+func (g *grpcService) ValidateTiers(ctx context.Context, req *pb.ValidateTiersRequest) (*pb.ValidateTiersResponse, error) {
+	knownTags, err := g.s.knownTags()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	validation, err := g.s.validateTiers(fromPBTiers(req.Tiers), knownTags)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.ValidateTiersResponse{
+		Valid:    validation.Valid,
+		Errors:   validation.Errors,
+		Warnings: validation.Warnings,
+	}, nil
+}
+
+func (g *grpcService) Generate(req *pb.GenerateRequest, stream pb.PremiumListService_GenerateServer) error {
+	genReq := generateRequest{
+		Tiers:       fromPBTiers(req.Tiers),
+		Format:      req.Format,
+		TLD:         req.Tld,
+		ExcludeTags: req.ExcludeTags,
+	}
+	if genReq.Format == "" {
+		genReq.Format = "default"
+	}
+
+	if validation := generator.ValidateTiers(genReq.Tiers, nil); !validation.Valid {
+		return status.Error(codes.InvalidArgument, strings.Join(validation.Errors, "; "))
+	}
+
+	outputPath, cleanup, err := g.s.generateToFile(stream.Context(), genReq)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer cleanup()
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer file.Close()
+
+	buf := make([]byte, generateChunkSize)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&pb.GenerateChunk{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+}
+
+// getLabel loads a label and converts it to its protobuf form
+func (g *grpcService) getLabel(label string) (*pb.Label, error) {
+	l, err := g.s.db.GetLabel(label)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBLabel(l), nil
+}
+
+// toPBLabel converts a label model to its protobuf form
+func toPBLabel(l *models.Label) *pb.Label {
+	return &pb.Label{
+		Id:     l.ID,
+		Label:  l.Label,
+		Length: int32(l.Length),
+		Tags:   l.Tags,
+	}
+}
+
+// fromPBTiers converts protobuf tiers to tier models
+func fromPBTiers(tiers []*pb.Tier) []models.Tier {
+	result := make([]models.Tier, 0, len(tiers))
+	for _, t := range tiers {
+		result = append(result, models.Tier{
+			Tier:     int(t.Tier),
+			Tags:     t.Tags,
+			Currency: t.Currency,
+			PriceReg: t.PriceReg,
+			PriceRen: t.PriceRen,
+			PriceRes: t.PriceRes,
+		})
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	pb "premium-list-maker/api/premiumlist/v1"
+	"premium-list-maker/internal/db"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCAuthorization(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	auth, err := NewAuthenticator(context.Background(), []string{"reader:read", "writer:write"}, nil)
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	srv, err := New(database, Options{Auth: auth, JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := srv.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewPremiumListServiceClient(conn)
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+	}
+
+	// A write key can write
+	label, err := client.CreateLabel(withKey("writer"), &pb.CreateLabelRequest{Label: "Shoes", Tags: []string{"fashion"}})
+	if err != nil {
+		t.Fatalf("CreateLabel with a write key: %v", err)
+	}
+	if label.Label != "shoes" || len(label.Tags) != 1 || label.Tags[0] != "fashion" {
+		t.Errorf("created label = %v", label)
+	}
+
+	// A read key can read, also as a bearer token
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer reader")
+	labels, err := client.ListLabels(ctx, &pb.ListLabelsRequest{Tags: []string{"fashion"}})
+	if err != nil {
+		t.Fatalf("ListLabels with a read key: %v", err)
+	}
+	if labels.Total != 1 || len(labels.Labels) != 1 || labels.Labels[0].Label != "shoes" {
+		t.Errorf("labels = %v", labels)
+	}
+
+	// A read key can't write, and a missing or unknown key can't do anything
+	_, err = client.CreateLabel(withKey("reader"), &pb.CreateLabelRequest{Label: "hats"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CreateLabel with a read key: err = %v, want PermissionDenied", err)
+	}
+	_, err = client.ListLabels(context.Background(), &pb.ListLabelsRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListLabels without a key: err = %v, want Unauthenticated", err)
+	}
+	_, err = client.ListLabels(withKey("nope"), &pb.ListLabelsRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListLabels with an unknown key: err = %v, want Unauthenticated", err)
+	}
+
+	if n, err := database.CountLabels(db.LabelFilter{}); err != nil || n != 1 {
+		t.Errorf("CountLabels = %d, %v; want only shoes", n, err)
+	}
+}
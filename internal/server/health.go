@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds the database check of the readiness probe
+const readinessTimeout = 2 * time.Second
+
+// handleHealthz is the liveness probe: the process is up and serving
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz is the readiness probe: the database is accessible and the server isn't shutting down
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := s.db.Ping(ctx); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "database unavailable", "error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// Shutdown marks the server as not ready, stops accepting jobs and waits for the running job
+// If ctx ends first, the running job is canceled and waited for, so it's safe to close the database afterwards
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+	return s.jobs.shutdown(ctx)
+}
+
+// Drain makes the readiness probe fail so load balancers stop sending traffic
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
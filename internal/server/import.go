@@ -0,0 +1,419 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagger"
+	"premium-list-maker/internal/webhook"
+)
+
+// maxImportErrors caps the per-file error messages returned by the import endpoint
+const maxImportErrors = 100
+
+// fetchTimeout bounds the download of a URI to import, so a stalled server can't hold up the request
+const fetchTimeout = 10 * time.Minute
+
+// fetchClient downloads the URIs to import
+var fetchClient = &http.Client{Timeout: fetchTimeout}
+
+// importURIsRequest is the JSON body for importing remote files
+type importURIsRequest struct {
+	URIs         []string `json:"uris"`
+	RankTags     []int    `json:"rank_tags"`
+	TagProfanity bool     `json:"tag_profanity"`
+}
+
+// importOptions are the tagging options shared by all files of an import request
+type importOptions struct {
+	rankThresholds []int
+	profanity      *tagger.WordListTagger
+}
+
+// fileImportResult holds the stats of one imported file
+type fileImportResult struct {
+	File           string                 `json:"file"`
+	Tag            string                 `json:"tag"`
+	Imported       int                    `json:"imported"`
+	NewLabels      int                    `json:"new_labels"`
+	ExistingLabels int                    `json:"existing_labels"`
+	Skipped        int                    `json:"skipped"`
+	HeaderSkipped  bool                   `json:"header_skipped"`
+	ErrorCount     int                    `json:"error_count"`
+	Errors         []importer.ImportError `json:"errors,omitempty"`
+	DurationMS     int64                  `json:"duration_ms"`
+	Failed         string                 `json:"failed,omitempty"`
+}
+
+// importResponse is the response of the import endpoint
+type importResponse struct {
+	Files           []fileImportResult `json:"files"`
+	LabelsProcessed int                `json:"labels_processed"`
+	NewLabels       int                `json:"new_labels"`
+	ExistingLabels  int                `json:"existing_labels"`
+	LabelsSkipped   int                `json:"labels_skipped"`
+	DurationMS      int64              `json:"duration_ms"`
+	ImportID        int64              `json:"import_id,omitempty"` // Import session, for undo-import
+}
+
+// handleImport imports labels like the import command, from one of:
+//   - a raw CSV body (text/csv), named by the "name" query parameter
+//   - a multipart upload with one or more CSV files
+//   - a JSON body with http(s) URIs to fetch, e.g. presigned object storage URLs
+//
+// As with the CLI, each file's name (without .csv) is added as a tag
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	tmpDir, err := os.MkdirTemp("", "premium-list-import")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files, opts, ok := stageImport(w, r, tmpDir)
+	if !ok {
+		return
+	}
+
+	resp := s.runImport(r.Context(), tmpDir, files, opts, nil)
+	resp.DurationMS = time.Since(start).Milliseconds()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// stageImport saves the files of an import request into dir, writing an error response on failure
+func stageImport(w http.ResponseWriter, r *http.Request, dir string) ([]string, importOptions, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	opts, err := importOptionsFromQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, opts, false
+	}
+
+	var files []string
+	switch mediaType {
+	case "application/json":
+		var req importURIsRequest
+		if !decodeJSON(w, r, &req) {
+			return nil, opts, false
+		}
+		if len(req.URIs) == 0 {
+			writeError(w, http.StatusBadRequest, "uris is required")
+			return nil, opts, false
+		}
+		opts.rankThresholds = append(opts.rankThresholds, req.RankTags...)
+		if req.TagProfanity && opts.profanity == nil {
+			if opts.profanity, err = tagger.NewProfanityTagger(""); err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return nil, opts, false
+			}
+		}
+		for _, uri := range req.URIs {
+			name, err := fetchURI(r.Context(), uri, dir)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return nil, opts, false
+			}
+			files = append(files, name)
+		}
+
+	case "multipart/form-data":
+		reader, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return nil, opts, false
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid multipart body: "+err.Error())
+				return nil, opts, false
+			}
+			if part.FileName() == "" {
+				continue
+			}
+			name, err := stageFile(dir, part.FileName(), part)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return nil, opts, false
+			}
+			files = append(files, name)
+		}
+
+	default:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			writeError(w, http.StatusBadRequest, "name query parameter is required for a raw CSV upload")
+			return nil, opts, false
+		}
+		staged, err := stageFile(dir, name, r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return nil, opts, false
+		}
+		files = append(files, staged)
+	}
+
+	if len(files) == 0 {
+		writeError(w, http.StatusBadRequest, "no files to import")
+		return nil, opts, false
+	}
+	return files, opts, true
+}
+
+// runImport imports staged files one request at a time and fires the import webhook
+// The importer bulk loads in one transaction per file, so concurrent imports would contend for the database
+// When ctx is done, e.g. the client went away, the file being imported is rolled back to its last commit
+func (s *Server) runImport(ctx context.Context, dir string, files []string, opts importOptions, progress *progressTracker) importResponse {
+	start := time.Now()
+	s.importMu.Lock()
+	resp := s.importFiles(ctx, dir, files, opts, progress)
+	s.importMu.Unlock()
+	resp.DurationMS = time.Since(start).Milliseconds()
+
+	if s.notifier != nil {
+		s.notifyImported(resp)
+	}
+	return resp
+}
+
+// importFiles imports staged files and collects their stats
+// If tracker is not nil, rows read across all files are reported to it
+func (s *Server) importFiles(ctx context.Context, dir string, files []string, opts importOptions, tracker *progressTracker) importResponse {
+	resp := importResponse{Files: make([]fileImportResult, 0, len(files))}
+
+	// Estimated line counts give the progress total; files that can't be estimated contribute 0
+	lineCounts := make([]int, len(files))
+	rowsTotal := 0
+	if tracker != nil {
+		for i, name := range files {
+			if n, err := importer.EstimateCSVLines(filepath.Join(dir, name)); err == nil {
+				lineCounts[i] = n
+				rowsTotal += n
+			}
+		}
+	}
+	rowsDone := 0
+
+	// Record the import as a session, so it can be undone; importing without one beats not importing
+	var store db.Store = s.db
+	if id, err := s.db.StartImport(files, time.Now()); err != nil {
+		log.Printf("import: %v", err)
+	} else {
+		resp.ImportID = id
+		store = s.db.InImport(id)
+	}
+	var errs []string
+
+	for i, name := range files {
+		fileStart := time.Now()
+		tag := strings.TrimSuffix(strings.TrimSuffix(name, ".csv"), ".CSV")
+		result := fileImportResult{File: name, Tag: tag}
+
+		var reportRows progress.Func
+		fileRows := 0
+		if tracker != nil {
+			reportRows = func(u progress.Update) {
+				fileRows = u.Rows
+				tracker.update(jobProgress{
+					Phase:      "importing",
+					File:       name,
+					FilesDone:  i,
+					FilesTotal: len(files),
+					Rows:       rowsDone + u.Rows,
+					// The total is estimated, so it grows if the rows pass it
+					RowsTotal: max(rowsTotal, rowsDone+u.Rows),
+				})
+			}
+			reportRows(progress.Update{})
+		}
+
+		stats, err := importer.ImportCSV(store, filepath.Join(dir, name),
+			importer.WithAutoTag(),
+			importer.WithTag(tag),
+			importer.WithRankThresholds(opts.rankThresholds),
+			importer.WithProfanityTagger(opts.profanity),
+			importer.WithProgress(reportRows),
+			importer.WithContext(ctx),
+		)
+		// Replace the estimate of the file by the lines actually read
+		rowsTotal += fileRows - lineCounts[i]
+		rowsDone += fileRows
+		if err != nil {
+			result.Failed = err.Error()
+			resp.Files = append(resp.Files, result)
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, importErr := range stats.Errors {
+			errs = append(errs, importErr.Error())
+		}
+
+		result.Imported = stats.Imported
+		result.NewLabels = stats.NewLabels
+		result.ExistingLabels = stats.ExistingLabels
+		result.Skipped = stats.Skipped
+		result.HeaderSkipped = stats.HeaderSkipped
+		result.ErrorCount = len(stats.Errors)
+		result.Errors = stats.Errors
+		if len(result.Errors) > maxImportErrors {
+			result.Errors = result.Errors[:maxImportErrors]
+		}
+		result.DurationMS = time.Since(fileStart).Milliseconds()
+
+		resp.LabelsProcessed += stats.Imported
+		resp.NewLabels += stats.NewLabels
+		resp.ExistingLabels += stats.ExistingLabels
+		resp.LabelsSkipped += stats.Skipped
+		resp.Files = append(resp.Files, result)
+	}
+
+	if resp.ImportID != 0 {
+		status := db.ImportCompleted
+		if ctx.Err() != nil {
+			status = db.ImportCanceled
+		}
+		counts := db.ImportCounts{
+			LabelsProcessed: resp.LabelsProcessed,
+			NewLabels:       resp.NewLabels,
+			ExistingLabels:  resp.ExistingLabels,
+			LabelsSkipped:   resp.LabelsSkipped,
+		}
+		for _, f := range resp.Files {
+			if f.Failed != "" {
+				counts.FilesSkipped++
+			} else {
+				counts.FilesProcessed++
+			}
+		}
+		if err := s.db.FinishImport(resp.ImportID, status, counts, errs, time.Now()); err != nil {
+			log.Printf("import: %v", err)
+		}
+	}
+	return resp
+}
+
+// notifyImported fires the import.completed webhook in the background
+func (s *Server) notifyImported(resp importResponse) {
+	stats := webhook.ImportStats{
+		Files:           len(resp.Files),
+		LabelsProcessed: resp.LabelsProcessed,
+		NewLabels:       resp.NewLabels,
+		ExistingLabels:  resp.ExistingLabels,
+		LabelsSkipped:   resp.LabelsSkipped,
+		DurationMS:      resp.DurationMS,
+	}
+	for _, f := range resp.Files {
+		if f.Failed != "" {
+			stats.FilesSkipped++
+		}
+		stats.Errors += f.ErrorCount
+	}
+
+	event := webhook.Event{Event: webhook.EventImportCompleted, Stats: stats}
+	go func() {
+		if err := s.notifier.Notify(event); err != nil {
+			log.Printf("webhook: %v", err)
+		}
+	}()
+}
+
+// importOptionsFromQuery parses the rank_tags and tag_profanity query parameters
+func importOptionsFromQuery(r *http.Request) (importOptions, error) {
+	var opts importOptions
+	query := r.URL.Query()
+
+	if value := query.Get("rank_tags"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("invalid rank_tags value %q", field)
+			}
+			opts.rankThresholds = append(opts.rankThresholds, n)
+		}
+	}
+
+	if value := query.Get("tag_profanity"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid tag_profanity value %q", value)
+		}
+		if enabled {
+			if opts.profanity, err = tagger.NewProfanityTagger(""); err != nil {
+				return opts, err
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// stageFile copies r into dir under the base name of name, returning the staged name
+func stageFile(dir, name string, r io.Reader) (string, error) {
+	name = filepath.Base(filepath.Clean("/" + name))
+	if name == "/" || name == "." {
+		return "", fmt.Errorf("invalid file name")
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".csv") {
+		name += ".csv"
+	}
+	if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+		return "", fmt.Errorf("duplicate file name %s", name)
+	}
+
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// fetchURI downloads an http(s) URI into dir, named after the last path segment
+// The download stops when ctx is done, e.g. the client went away, or after fetchTimeout
+func fetchURI(ctx context.Context, uri, dir string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported URI scheme %q (use http(s), e.g. a presigned object storage URL)", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI %q: %w", u.Redacted(), err)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch %s: %s", u.Redacted(), resp.Status)
+	}
+
+	return stageFile(dir, path.Base(u.Path), resp.Body)
+}
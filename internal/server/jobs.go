@@ -0,0 +1,447 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/progress"
+)
+
+// Job types
+const (
+	JobTypeImport   = "import"
+	JobTypeGenerate = "generate"
+)
+
+// jobQueueSize is the number of jobs that can wait for the worker
+const jobQueueSize = 100
+
+// jobListLimit is the number of jobs returned by the job list endpoint
+const jobListLimit = 100
+
+// jobExpiryInterval is how often finished jobs are checked against the job retention
+const jobExpiryInterval = time.Hour
+
+// Causes a running job is canceled for, recorded as its error
+var (
+	errJobCanceled = errors.New("canceled")
+	errShutdown    = errors.New("interrupted by server shutdown")
+)
+
+// jobTask does the work of a job in dir, reporting its progress to the tracker, until ctx is canceled
+// It returns the job result and an optional artifact file name
+type jobTask func(ctx context.Context, dir string, progress *progressTracker) (result interface{}, artifact string, err error)
+
+// jobRunner runs jobs one at a time in the background
+// Job state is kept in the jobs table, the pending work in memory
+type jobRunner struct {
+	s         *Server
+	dir       string
+	retention time.Duration // Age at which finished jobs are deleted with their files, 0 keeps them
+	queue     chan string
+
+	mu       sync.Mutex
+	tasks    map[string]jobTask
+	trackers map[string]*progressTracker
+	running  string                  // ID of the running job, "" for none
+	cancel   context.CancelCauseFunc // Cancels the running job, see errJobCanceled and errShutdown
+	stopping bool
+	stopped  chan struct{}
+}
+
+// newJobRunner starts the job worker, and deletes finished jobs older than retention every jobExpiryInterval
+// Jobs left unfinished by a previous server process are marked as failed
+func newJobRunner(s *Server, dir string, retention time.Duration) (*jobRunner, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "premium-list-jobs")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	if n, err := s.db.FailUnfinishedJobs("interrupted by server restart", time.Now()); err != nil {
+		return nil, err
+	} else if n > 0 {
+		log.Printf("marked %d unfinished job(s) as failed", n)
+	}
+
+	jr := &jobRunner{
+		s:         s,
+		dir:       dir,
+		retention: retention,
+		queue:     make(chan string, jobQueueSize),
+		tasks:     make(map[string]jobTask),
+		trackers:  make(map[string]*progressTracker),
+		stopped:   make(chan struct{}),
+	}
+	if retention > 0 {
+		if err := jr.expire(time.Now()); err != nil {
+			return nil, err
+		}
+		go jr.expireEvery(jobExpiryInterval)
+	}
+	go jr.work()
+	return jr, nil
+}
+
+// expire deletes the jobs that finished more than the retention before now, with their directories
+func (jr *jobRunner) expire(now time.Time) error {
+	ids, err := jr.s.db.DeleteFinishedJobs(now.Add(-jr.retention))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := os.RemoveAll(jr.jobDir(id)); err != nil {
+			log.Printf("job %s: %v", id, err)
+		}
+	}
+	if len(ids) > 0 {
+		log.Printf("deleted %d expired job(s)", len(ids))
+	}
+	return nil
+}
+
+// expireEvery expires jobs at every interval until the runner is shut down
+func (jr *jobRunner) expireEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-jr.stopped:
+			return
+		case now := <-ticker.C:
+			if err := jr.expire(now); err != nil {
+				log.Printf("job expiry: %v", err)
+			}
+		}
+	}
+}
+
+// jobDir returns the working directory of a job
+func (jr *jobRunner) jobDir(id string) string {
+	return filepath.Join(jr.dir, id)
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// prepare creates a job ID and its working directory, for staging input before submit
+func (jr *jobRunner) prepare() (string, string, error) {
+	id := newJobID()
+	dir := jr.jobDir(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return id, dir, nil
+}
+
+// submit records a queued job and hands its task to the worker
+func (jr *jobRunner) submit(id, jobType string, params interface{}, task jobTask) (*models.Job, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	job := &models.Job{
+		ID:        id,
+		Type:      jobType,
+		Status:    models.JobQueued,
+		Params:    paramsJSON,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := jr.s.db.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	reject := func(reason string) (*models.Job, error) {
+		jr.s.db.FinishJob(id, models.JobFailed, nil, reason, "", time.Now())
+		return nil, errors.New(reason)
+	}
+	if jr.stopping {
+		return reject("server is shutting down")
+	}
+
+	select {
+	case jr.queue <- id:
+		jr.tasks[id] = task
+		jr.trackers[id] = newProgressTracker()
+	default:
+		return reject("job queue is full")
+	}
+	return job, nil
+}
+
+// shutdown stops accepting jobs and waits for the running job to finish
+// Queued jobs are canceled. If ctx ends first, the running job is canceled and waited for, so it stops at its
+// next batch and rolls back its open transaction before the database is closed; ctx's error is returned
+func (jr *jobRunner) shutdown(ctx context.Context) error {
+	jr.mu.Lock()
+	if !jr.stopping {
+		jr.stopping = true
+		close(jr.queue)
+	}
+	jr.mu.Unlock()
+
+	select {
+	case <-jr.stopped:
+		return nil
+	case <-ctx.Done():
+	}
+	jr.mu.Lock()
+	if jr.cancel != nil {
+		jr.cancel(errShutdown)
+	}
+	jr.mu.Unlock()
+	<-jr.stopped
+	return ctx.Err()
+}
+
+// take removes and returns the pending task of a job, ending its progress stream
+func (jr *jobRunner) take(id string) (jobTask, *progressTracker) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	task := jr.tasks[id]
+	delete(jr.tasks, id)
+	tracker := jr.trackers[id]
+	delete(jr.trackers, id)
+	return task, tracker
+}
+
+// tracker returns the progress tracker of a queued or running job (nil otherwise)
+func (jr *jobRunner) tracker(id string) *progressTracker {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	return jr.trackers[id]
+}
+
+// cancelRunning cancels the context of a job if it is running
+// It returns false if the job isn't running
+func (jr *jobRunner) cancelRunning(id string) bool {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.running != id || jr.cancel == nil {
+		return false
+	}
+	jr.cancel(errJobCanceled)
+	return true
+}
+
+// discard drops a job that will never run
+func (jr *jobRunner) discard(id string) {
+	if _, tracker := jr.take(id); tracker != nil {
+		tracker.finish()
+	}
+}
+
+// work runs queued jobs until the runner is shut down
+func (jr *jobRunner) work() {
+	defer close(jr.stopped)
+	for id := range jr.queue {
+		jr.mu.Lock()
+		stopping := jr.stopping
+		jr.mu.Unlock()
+
+		if stopping {
+			if _, err := jr.s.db.CancelQueuedJob(id, time.Now()); err != nil {
+				log.Printf("job %s: %v", id, err)
+			}
+			jr.discard(id)
+			os.RemoveAll(jr.jobDir(id))
+			continue
+		}
+		jr.run(id)
+	}
+}
+
+// run executes a single job and records its outcome
+// A job whose context is canceled while it runs is recorded as canceled
+func (jr *jobRunner) run(id string) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	jr.mu.Lock()
+	task, tracker := jr.tasks[id], jr.trackers[id]
+	jr.running, jr.cancel = id, cancel
+	jr.mu.Unlock()
+	defer func() {
+		jr.mu.Lock()
+		jr.running, jr.cancel = "", nil
+		jr.mu.Unlock()
+		cancel(nil)
+	}()
+	dir := jr.jobDir(id)
+
+	started, err := jr.s.db.StartJob(id, time.Now())
+	if err != nil || !started || task == nil {
+		// Canceled while queued
+		jr.discard(id)
+		os.RemoveAll(dir)
+		return
+	}
+
+	result, artifact, taskErr := task(ctx, dir, tracker)
+
+	status, errMsg := models.JobSucceeded, ""
+	switch {
+	case ctx.Err() != nil:
+		status, errMsg, artifact = models.JobCanceled, context.Cause(ctx).Error(), ""
+	case taskErr != nil:
+		status, errMsg = models.JobFailed, taskErr.Error()
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil || result == nil {
+		resultJSON = nil
+	}
+	if err := jr.s.db.FinishJob(id, status, resultJSON, errMsg, artifact, time.Now()); err != nil {
+		log.Printf("job %s: %v", id, err)
+	}
+	jr.discard(id)
+	if artifact == "" {
+		os.RemoveAll(dir)
+	}
+}
+
+// handleListJobs lists the most recent jobs
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.db.ListJobs(jobListLimit)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleGetJob returns the status and result of a job
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.db.GetJob(r.PathValue("id"))
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleCancelJob cancels a queued job, or stops a running one
+// A running job stops at its next batch and is recorded as canceled once it has; an import keeps the batches it
+// committed, so the response is 202 with the job still running
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	canceled, err := s.db.CancelQueuedJob(id, time.Now())
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	code := http.StatusOK
+	switch {
+	case canceled:
+		s.jobs.discard(id)
+	case s.jobs.cancelRunning(id):
+		code = http.StatusAccepted
+	default:
+		writeError(w, http.StatusConflict, "only queued and running jobs can be canceled")
+		return
+	}
+
+	job, err := s.db.GetJob(id)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, code, job)
+}
+
+// handleJobArtifact downloads the artifact (generated premium list) of a finished job
+func (s *Server) handleJobArtifact(w http.ResponseWriter, r *http.Request) {
+	job, err := s.db.GetJob(r.PathValue("id"))
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	if job.Artifact == "" {
+		writeError(w, http.StatusNotFound, "job has no artifact")
+		return
+	}
+
+	w.Header().Set("Content-Type", listContentType(job.Artifact))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.Artifact))
+	http.ServeFile(w, r, filepath.Join(s.jobs.jobDir(job.ID), job.Artifact))
+}
+
+// handleSubmitImportJob stages an import request (same input as /api/import) and runs it as a job
+func (s *Server) handleSubmitImportJob(w http.ResponseWriter, r *http.Request) {
+	id, dir, err := s.jobs.prepare()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	files, opts, ok := stageImport(w, r, dir)
+	if !ok {
+		os.RemoveAll(dir)
+		return
+	}
+
+	params := map[string]interface{}{"files": files, "rank_tags": opts.rankThresholds, "tag_profanity": opts.profanity != nil}
+	job, err := s.jobs.submit(id, JobTypeImport, params, func(ctx context.Context, dir string, progress *progressTracker) (interface{}, string, error) {
+		return s.runImport(ctx, dir, files, opts, progress), "", nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleSubmitGenerateJob runs a generation (same body as /api/generate) as a job
+// The premium list is kept as the job artifact
+func (s *Server) handleSubmitGenerateJob(w http.ResponseWriter, r *http.Request) {
+	var req generateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Format == "" {
+		req.Format = "default"
+	}
+	if !s.checkGenerateRequest(w, req) {
+		return
+	}
+
+	id, _, err := s.jobs.prepare()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	job, err := s.jobs.submit(id, JobTypeGenerate, req, func(ctx context.Context, dir string, tracker *progressTracker) (interface{}, string, error) {
+		tracker.update(jobProgress{Phase: "generating"})
+		artifact := listFileName(req.Format)
+		outputPath := filepath.Join(dir, artifact)
+		report := func(u progress.Update) {
+			tracker.update(jobProgress{Phase: "generating", Rows: u.Rows, RowsTotal: u.Total})
+		}
+		result, err := s.generate(ctx, req, outputPath, report)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, artifact, nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
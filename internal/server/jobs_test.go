@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/models"
+)
+
+func TestImportJobEvents(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv, err := New(database, Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/jobs/import?name=words.csv", "text/csv", strings.NewReader("label\nfoo\nbar\n"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	var job models.Job
+	decodeBody(t, resp, &job)
+	if resp.StatusCode != http.StatusAccepted || job.ID == "" {
+		t.Fatalf("submit = %d %+v", resp.StatusCode, job)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err = client.Get(ts.URL + "/api/jobs/" + job.ID + "/events")
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	stream := string(body)
+	if !strings.Contains(stream, "event: done") || !strings.Contains(stream, `"status":"succeeded"`) {
+		t.Errorf("event stream missing successful done event:\n%s", stream)
+	}
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv, err := New(database, Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// The task runs until its context is canceled, like an import between batches
+	started := make(chan struct{})
+	id, _, err := srv.jobs.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.jobs.submit(id, JobTypeImport, nil, func(ctx context.Context, dir string, progress *progressTracker) (interface{}, string, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+
+	cancel := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/api/jobs/"+id, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		return resp
+	}
+	resp := cancel()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("cancel running job = %d", resp.StatusCode)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := database.GetJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == models.JobCanceled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job status = %s, want canceled", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp = cancel()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("cancel finished job = %d", resp.StatusCode)
+	}
+}
+
+func TestShutdown_CancelsSlowJob(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv, err := New(database, Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The job writes in an open import transaction and doesn't finish on its own
+	started := make(chan struct{})
+	returned := make(chan struct{})
+	id, _, err := srv.jobs.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.jobs.submit(id, JobTypeImport, nil, func(ctx context.Context, dir string, progress *progressTracker) (interface{}, string, error) {
+		defer close(returned)
+		tx, err := database.BeginImport()
+		if err != nil {
+			return nil, "", err
+		}
+		defer tx.Rollback()
+		if _, err := tx.InsertLabels([]db.LabelData{{Label: "shoes", Length: 5}}); err != nil {
+			return nil, "", err
+		}
+		close(started)
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case <-returned:
+	default:
+		t.Fatal("Shutdown returned before the job stopped")
+	}
+
+	job, err := database.GetJob(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobCanceled || job.Error != errShutdown.Error() {
+		t.Errorf("job = %s %q", job.Status, job.Error)
+	}
+	labels, err := database.GetAllLabelsWithTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 0 {
+		t.Errorf("labels = %v, want the job's transaction rolled back", labels)
+	}
+}
+
+func TestJobExpiry(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	// A job that expired while the server was down is deleted at startup
+	jobDir := t.TempDir()
+	old := &models.Job{ID: "0ld0ld0ld0ld0ld0", Type: JobTypeGenerate, Status: models.JobQueued, CreatedAt: time.Now().Add(-3 * time.Hour)}
+	if err := database.CreateJob(old); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.FinishJob(old.ID, models.JobSucceeded, nil, "", "premium.csv", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(jobDir, old.ID), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, old.ID, "premium.csv"), []byte("shoes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := New(database, Options{JobDir: jobDir, JobRetention: time.Hour})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+	if _, err := database.GetJob(old.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expired job: err = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(jobDir, old.ID)); !os.IsNotExist(err) {
+		t.Errorf("expired job directory: err = %v, want it deleted", err)
+	}
+
+	// A new job is kept until it is older than the retention
+	id, _, err := srv.jobs.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.jobs.submit(id, JobTypeGenerate, nil, func(ctx context.Context, dir string, progress *progressTracker) (interface{}, string, error) {
+		return nil, "premium.csv", os.WriteFile(filepath.Join(dir, "premium.csv"), []byte("shoes\n"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := database.GetJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == models.JobSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job status = %s %q, want succeeded", job.Status, job.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := srv.jobs.expire(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(jobDir, id, "premium.csv")); err != nil {
+		t.Errorf("artifact of a recent job: %v", err)
+	}
+	if err := srv.jobs.expire(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.GetJob(id); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expired job: err = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(jobDir, id)); !os.IsNotExist(err) {
+		t.Errorf("expired job directory: err = %v, want it deleted", err)
+	}
+}
+
+func decodeBody(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// DefaultPageSize is the number of labels returned when no limit is given
+const DefaultPageSize = 100
+
+// labelListResponse is the paginated response for label searches
+type labelListResponse struct {
+	Labels []models.Label `json:"labels"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// createLabelRequest is the body for creating a label
+type createLabelRequest struct {
+	Label string   `json:"label"`
+	Tags  []string `json:"tags"`
+}
+
+// tagsRequest is the body for adding tags to a label
+type tagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// handleListLabels searches labels
+// Query parameters: tag (repeatable, all must match), min_length, max_length, prefix, contains, limit, offset
+func (s *Server) handleListLabels(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLabelFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	labels, err := s.db.ListLabels(filter)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	total, err := s.db.CountLabels(filter)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, labelListResponse{
+		Labels: labels,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+}
+
+// parseLabelFilter builds a label filter from query parameters
+func parseLabelFilter(r *http.Request) (db.LabelFilter, error) {
+	query := r.URL.Query()
+	filter := db.LabelFilter{
+		Tags:     query["tag"],
+		Prefix:   strings.ToLower(query.Get("prefix")),
+		Contains: strings.ToLower(query.Get("contains")),
+	}
+
+	var err error
+	if q := query.Get("q"); q != "" {
+		if filter.Where, err = tagexpr.Parse(q); err != nil {
+			return filter, err
+		}
+	}
+	if filter.MinLength, err = queryInt(r, "min_length", 0); err != nil {
+		return filter, err
+	}
+	if filter.MaxLength, err = queryInt(r, "max_length", 0); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = queryInt(r, "limit", DefaultPageSize); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = queryInt(r, "offset", 0); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// handleCreateLabel creates a label (validated like imported labels) with optional tags
+func (s *Server) handleCreateLabel(w http.ResponseWriter, r *http.Request) {
+	var req createLabelRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	label := strings.ToLower(strings.TrimSpace(req.Label))
+	if err := importer.ValidateLabel(label); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	labelID, err := s.db.InsertLabel(label, len(label))
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	if err := s.addTags(labelID, req.Tags); err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	s.writeLabel(w, http.StatusCreated, label)
+}
+
+// handleGetLabel returns a single label with its tags
+func (s *Server) handleGetLabel(w http.ResponseWriter, r *http.Request) {
+	s.writeLabel(w, http.StatusOK, r.PathValue("label"))
+}
+
+// handleDeleteLabel deletes a label
+func (s *Server) handleDeleteLabel(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.DeleteLabel(r.PathValue("label")); err != nil {
+		writeDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAddLabelTags adds tags to an existing label
+func (s *Server) handleAddLabelTags(w http.ResponseWriter, r *http.Request) {
+	var req tagsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	label := r.PathValue("label")
+	labelID, err := s.db.GetLabelID(label)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err := s.addTags(labelID, req.Tags); err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	s.writeLabel(w, http.StatusOK, label)
+}
+
+// handleRemoveLabelTag removes a single tag from a label
+func (s *Server) handleRemoveLabelTag(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.RemoveTagFromLabel(r.PathValue("label"), r.PathValue("tag")); err != nil {
+		writeDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addTags adds tags to a label, creating tags that don't exist
+func (s *Server) addTags(labelID int64, tags []string) error {
+	for _, tagName := range tags {
+		tagName = strings.TrimSpace(tagName)
+		if tagName == "" {
+			continue
+		}
+		tagID, err := s.db.GetOrCreateTag(tagName)
+		if err != nil {
+			return err
+		}
+		if err := s.db.AddTagToLabel(labelID, tagID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLabel loads a label and writes it as the response
+func (s *Server) writeLabel(w http.ResponseWriter, status int, label string) {
+	l, err := s.db.GetLabel(label)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, status, l)
+}
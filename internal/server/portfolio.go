@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// tldPattern restricts TLD/profile names so they are safe in URL paths and job directory names
+var tldPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// DatabaseConfig maps a TLD (or profile name) to its database file
+type DatabaseConfig struct {
+	TLD  string `json:"tld"`
+	Path string `json:"path"`
+}
+
+// PortfolioConfig is the databases file used to serve several databases from one process
+type PortfolioConfig struct {
+	Default   string           `json:"default"` // TLD also served under the unprefixed /api/ routes (optional)
+	Databases []DatabaseConfig `json:"databases"`
+}
+
+// LoadPortfolioConfig reads a databases file
+// Relative database paths are resolved against the directory of the file
+func LoadPortfolioConfig(path string) (*PortfolioConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read databases file: %w", err)
+	}
+
+	var config PortfolioConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse databases file: %w", err)
+	}
+	if len(config.Databases) == 0 {
+		return nil, fmt.Errorf("databases file lists no databases")
+	}
+
+	seen := make(map[string]bool)
+	for i := range config.Databases {
+		entry := &config.Databases[i]
+		entry.TLD = strings.Trim(strings.ToLower(strings.TrimSpace(entry.TLD)), ".")
+		if !tldPattern.MatchString(entry.TLD) {
+			return nil, fmt.Errorf("databases file entry %d: invalid tld %q", i+1, entry.TLD)
+		}
+		if seen[entry.TLD] {
+			return nil, fmt.Errorf("databases file entry %d: duplicate tld %q", i+1, entry.TLD)
+		}
+		seen[entry.TLD] = true
+		if entry.Path == "" {
+			return nil, fmt.Errorf("databases file entry %d (%s): path is required", i+1, entry.TLD)
+		}
+		if !filepath.IsAbs(entry.Path) {
+			entry.Path = filepath.Join(filepath.Dir(path), entry.Path)
+		}
+	}
+
+	config.Default = strings.Trim(strings.ToLower(strings.TrimSpace(config.Default)), ".")
+	if config.Default != "" && !seen[config.Default] {
+		return nil, fmt.Errorf("default tld %q is not listed in the databases file", config.Default)
+	}
+	return &config, nil
+}
+
+// Portfolio serves one Server per TLD from a single process
+// Per-TLD endpoints live under /api/tlds/{tld}/..., with the same paths as a single-database server
+// below /api/. Probes, metrics, the spec and the web UI are served once for all databases
+type Portfolio struct {
+	servers    map[string]*Server
+	defaultTLD string
+	auth       *Authenticator
+	mux        *http.ServeMux
+}
+
+// NewPortfolio creates a server for every database, keyed by TLD
+// Each TLD gets its own job worker and a job directory below opts.JobDir
+func NewPortfolio(databases map[string]*db.DB, defaultTLD string, opts Options) (*Portfolio, error) {
+	if opts.JobDir == "" {
+		opts.JobDir = filepath.Join(os.TempDir(), "premium-list-jobs")
+	}
+
+	p := &Portfolio{
+		servers:    make(map[string]*Server, len(databases)),
+		defaultTLD: defaultTLD,
+		auth:       opts.Auth,
+		mux:        http.NewServeMux(),
+	}
+	for tld, database := range databases {
+		tldOpts := opts
+		tldOpts.JobDir = filepath.Join(opts.JobDir, tld)
+		srv, err := New(database, tldOpts)
+		if err != nil {
+			p.Shutdown(context.Background())
+			return nil, fmt.Errorf("%s: %w", tld, err)
+		}
+		p.servers[tld] = srv
+	}
+
+	p.routes()
+	return p, nil
+}
+
+// routes registers the endpoints shared by all databases
+func (p *Portfolio) routes() {
+	p.mux.HandleFunc("GET /api/tlds", p.handleListTLDs)
+	p.mux.HandleFunc("GET /healthz", handleHealthz)
+	p.mux.HandleFunc("GET /readyz", p.handleReadyz)
+	p.mux.HandleFunc("GET /api/openapi.yaml", handleOpenAPISpec)
+	p.mux.HandleFunc("GET /{$}", handleIndex)
+	p.mux.Handle("GET /ui/", uiHandler())
+	p.mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+}
+
+// Server returns the server of a TLD (nil if it isn't configured)
+func (p *Portfolio) Server(tld string) *Server {
+	return p.servers[tld]
+}
+
+// Default returns the server of the default TLD (nil if there is none)
+func (p *Portfolio) Default() *Server {
+	return p.servers[p.defaultTLD]
+}
+
+// TLDs returns the configured TLDs in sorted order
+func (p *Portfolio) TLDs() []string {
+	tlds := make([]string, 0, len(p.servers))
+	for tld := range p.servers {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	return tlds
+}
+
+// ServeHTTP implements http.Handler, routing /api/tlds/{tld}/... to the TLD's server
+func (p *Portfolio) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	p.route(rec, r)
+	log.Printf("%s %s %d %v", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+}
+
+// route dispatches a request to a TLD's server or to the shared endpoints
+func (p *Portfolio) route(w http.ResponseWriter, r *http.Request) {
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/api/tlds/"); ok {
+		tld, path, _ := strings.Cut(rest, "/")
+		srv := p.servers[tld]
+		if srv == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("unknown tld %q", tld))
+			return
+		}
+		// Rewrite to the single-database path, so routing and authorization are the same
+		scoped := r.Clone(r.Context())
+		scoped.URL.Path = "/api/" + path
+		scoped.URL.RawPath = ""
+		srv.serve(w, scoped)
+		return
+	}
+
+	if _, pattern := p.mux.Handler(r); pattern != "" {
+		if authorizeRequest(p.auth, w, r) {
+			p.mux.ServeHTTP(w, r)
+		}
+		return
+	}
+
+	if srv := p.Default(); srv != nil && strings.HasPrefix(r.URL.Path, "/api/") {
+		srv.serve(w, r)
+		return
+	}
+	writeError(w, http.StatusNotFound, "not found (use /api/tlds/{tld}/...)")
+}
+
+// tldInfo describes a configured TLD in the /api/tlds response
+type tldInfo struct {
+	TLD     string `json:"tld"`
+	Default bool   `json:"default"`
+}
+
+// handleListTLDs lists the configured TLDs
+func (p *Portfolio) handleListTLDs(w http.ResponseWriter, r *http.Request) {
+	tlds := make([]tldInfo, 0, len(p.servers))
+	for _, tld := range p.TLDs() {
+		tlds = append(tlds, tldInfo{TLD: tld, Default: tld == p.defaultTLD})
+	}
+	writeJSON(w, http.StatusOK, tlds)
+}
+
+// handleReadyz is the readiness probe: every database is accessible and the server isn't shutting down
+func (p *Portfolio) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	for _, tld := range p.TLDs() {
+		srv := p.servers[tld]
+		if srv.draining.Load() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+			return
+		}
+		if err := srv.db.Ping(ctx); err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "database unavailable", "tld": tld, "error": err.Error()})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// Drain makes the readiness probe fail so load balancers stop sending traffic
+func (p *Portfolio) Drain() {
+	for _, srv := range p.servers {
+		srv.Drain()
+	}
+}
+
+// Shutdown stops every TLD's job worker, waiting for running jobs within ctx and canceling them after it
+func (p *Portfolio) Shutdown(ctx context.Context) error {
+	var errs []error
+	for tld, srv := range p.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tld, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"premium-list-maker/internal/db"
+)
+
+func TestPortfolioRoutesByTLD(t *testing.T) {
+	dir := t.TempDir()
+	databases := make(map[string]*db.DB)
+	for _, tld := range []string{"shop", "store"} {
+		database, err := db.New(filepath.Join(dir, tld+".db"))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer database.Close()
+		databases[tld] = database
+	}
+
+	p, err := NewPortfolio(databases, "shop", Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewPortfolio: %v", err)
+	}
+
+	do := func(method, path, body string) int {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if code := do(http.MethodPost, "/api/tlds/store/labels", `{"label": "example"}`); code != http.StatusCreated {
+		t.Fatalf("create label = %d, want 201", code)
+	}
+	if code := do(http.MethodGet, "/api/tlds/store/labels/example", ""); code != http.StatusOK {
+		t.Errorf("get label in store = %d, want 200", code)
+	}
+	if code := do(http.MethodGet, "/api/tlds/shop/labels/example", ""); code != http.StatusNotFound {
+		t.Errorf("get label in shop = %d, want 404", code)
+	}
+	// Unprefixed routes go to the default TLD
+	if code := do(http.MethodGet, "/api/labels/example", ""); code != http.StatusNotFound {
+		t.Errorf("get label in default = %d, want 404", code)
+	}
+	if code := do(http.MethodGet, "/api/tlds/market/labels", ""); code != http.StatusNotFound {
+		t.Errorf("unknown tld = %d, want 404", code)
+	}
+	if code := do(http.MethodGet, "/readyz", ""); code != http.StatusOK {
+		t.Errorf("/readyz = %d, want 200", code)
+	}
+}
+
+func TestLoadPortfolioConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "databases.json")
+	config := `{"default": "Shop", "databases": [{"tld": ".Shop", "path": "shop.db"}, {"tld": "store", "path": "/data/store.db"}]}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadPortfolioConfig(path)
+	if err != nil {
+		t.Fatalf("LoadPortfolioConfig: %v", err)
+	}
+	if got.Default != "shop" || got.Databases[0].TLD != "shop" {
+		t.Errorf("TLDs not normalized: %+v", got)
+	}
+	if want := filepath.Join(dir, "shop.db"); got.Databases[0].Path != want {
+		t.Errorf("relative path = %q, want %q", got.Databases[0].Path, want)
+	}
+	if got.Databases[1].Path != "/data/store.db" {
+		t.Errorf("absolute path = %q", got.Databases[1].Path)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"databases": [{"tld": "shop", "path": "a.db"}, {"tld": "shop", "path": "b.db"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPortfolioConfig(path); err == nil {
+		t.Error("duplicate tld accepted")
+	}
+}
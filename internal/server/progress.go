@@ -0,0 +1,157 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// progressHeartbeat is how often an unchanged progress event is repeated to keep SSE connections alive
+const progressHeartbeat = 15 * time.Second
+
+// jobProgress is the progress of a running job, sent as SSE "progress" events
+type jobProgress struct {
+	Phase      string  `json:"phase"` // queued, importing, generating
+	File       string  `json:"file,omitempty"`
+	FilesDone  int     `json:"files_done"`
+	FilesTotal int     `json:"files_total"`
+	Rows       int     `json:"rows"`       // Rows read so far (imports)
+	RowsTotal  int     `json:"rows_total"` // Total rows of all files (imports), 0 if unknown
+	Percent    float64 `json:"percent"`
+	ElapsedMS  int64   `json:"elapsed_ms"`
+	ETASeconds float64 `json:"eta_seconds,omitempty"`
+}
+
+// progressTracker holds the latest progress of a job and wakes up subscribers on changes
+type progressTracker struct {
+	mu      sync.Mutex
+	current jobProgress
+	started time.Time
+	subs    map[chan struct{}]struct{}
+	done    chan struct{}
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{
+		current: jobProgress{Phase: "queued"},
+		subs:    make(map[chan struct{}]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// update replaces the progress, filling in elapsed time, percent and ETA
+func (t *progressTracker) update(p jobProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.started.IsZero() {
+		t.started = time.Now()
+	}
+	elapsed := time.Since(t.started)
+	p.ElapsedMS = elapsed.Milliseconds()
+	if p.RowsTotal > 0 && p.Rows > 0 {
+		p.Percent = 100 * float64(p.Rows) / float64(p.RowsTotal)
+		if p.Percent > 100 {
+			p.Percent = 100
+		}
+		p.ETASeconds = elapsed.Seconds() * float64(p.RowsTotal-p.Rows) / float64(p.Rows)
+		if p.ETASeconds < 0 {
+			p.ETASeconds = 0
+		}
+	}
+	t.current = p
+
+	for ch := range t.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// Subscriber hasn't read the previous wakeup yet; it will read the latest progress
+		}
+	}
+}
+
+// get returns the latest progress
+func (t *progressTracker) get() jobProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+// subscribe returns a channel that receives a value whenever the progress changes
+func (t *progressTracker) subscribe() (chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	t.mu.Lock()
+	t.subs[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.subs, ch)
+		t.mu.Unlock()
+	}
+}
+
+// finish marks the job as done, ending all event streams
+func (t *progressTracker) finish() {
+	close(t.done)
+}
+
+// handleJobEvents streams job progress as Server-Sent Events
+// "progress" events carry a jobProgress, the final "done" event carries the finished job
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, err := s.db.GetJob(id)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+	sendDone := func() {
+		if job, err := s.db.GetJob(id); err == nil {
+			send("done", job)
+		}
+	}
+
+	tracker := s.jobs.tracker(id)
+	if job.Done() || tracker == nil {
+		sendDone()
+		return
+	}
+
+	updates, unsubscribe := tracker.subscribe()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(progressHeartbeat)
+	defer heartbeat.Stop()
+
+	send("progress", tracker.get())
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-tracker.done:
+			sendDone()
+			return
+		case <-updates:
+			send("progress", tracker.get())
+		case <-heartbeat.C:
+			send("progress", tracker.get())
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"premium-list-maker/api"
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/metrics"
+	"premium-list-maker/internal/webhook"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server exposes the label database over a REST API
+type Server struct {
+	db       *db.DB
+	mux      *http.ServeMux
+	notifier *webhook.Notifier
+	auth     *Authenticator
+	importMu sync.Mutex
+	jobs     *jobRunner
+	draining atomic.Bool
+}
+
+// Options configures optional server features
+type Options struct {
+	Notifier *webhook.Notifier // Fired after imports and generations (nil disables webhooks)
+	Auth     *Authenticator    // Checks credentials on every request (nil disables authentication)
+	JobDir   string            // Working directory for jobs and their artifacts (defaults to a temp dir)
+
+	// JobRetention is how long finished jobs, their uploaded input and artifacts are kept (0 keeps them forever)
+	JobRetention time.Duration
+}
+
+// New creates a server backed by the given database and starts its job worker
+func New(database *db.DB, opts Options) (*Server, error) {
+	s := &Server{
+		db:       database,
+		mux:      http.NewServeMux(),
+		notifier: opts.Notifier,
+		auth:     opts.Auth,
+	}
+
+	jobs, err := newJobRunner(s, opts.JobDir, opts.JobRetention)
+	if err != nil {
+		return nil, err
+	}
+	s.jobs = jobs
+
+	s.routes()
+	return s, nil
+}
+
+// routes registers all API endpoints
+func (s *Server) routes() {
+	// Labels
+	s.mux.HandleFunc("GET /api/labels", s.handleListLabels)
+	s.mux.HandleFunc("POST /api/labels", s.handleCreateLabel)
+	s.mux.HandleFunc("GET /api/labels/{label}", s.handleGetLabel)
+	s.mux.HandleFunc("DELETE /api/labels/{label}", s.handleDeleteLabel)
+	s.mux.HandleFunc("POST /api/labels/{label}/tags", s.handleAddLabelTags)
+	s.mux.HandleFunc("DELETE /api/labels/{label}/tags/{tag}", s.handleRemoveLabelTag)
+
+	// Tags
+	s.mux.HandleFunc("GET /api/tags", s.handleListTags)
+	s.mux.HandleFunc("POST /api/tags", s.handleCreateTag)
+	s.mux.HandleFunc("PUT /api/tags/{tag}", s.handleRenameTag)
+	s.mux.HandleFunc("DELETE /api/tags/{tag}", s.handleDeleteTag)
+
+	// Import
+	s.mux.HandleFunc("POST /api/import", s.handleImport)
+
+	// Background jobs
+	s.mux.HandleFunc("GET /api/jobs", s.handleListJobs)
+	s.mux.HandleFunc("POST /api/jobs/import", s.handleSubmitImportJob)
+	s.mux.HandleFunc("POST /api/jobs/generate", s.handleSubmitGenerateJob)
+	s.mux.HandleFunc("GET /api/jobs/{id}", s.handleGetJob)
+	s.mux.HandleFunc("GET /api/jobs/{id}/artifact", s.handleJobArtifact)
+	s.mux.HandleFunc("GET /api/jobs/{id}/events", s.handleJobEvents)
+	s.mux.HandleFunc("DELETE /api/jobs/{id}", s.handleCancelJob)
+
+	// Tiers and generation
+	s.mux.HandleFunc("POST /api/tiers/validate", s.handleValidateTiers)
+	s.mux.HandleFunc("POST /api/tiers/evaluate", s.handleEvaluateTiers)
+	s.mux.HandleFunc("POST /api/generate", s.handleGenerate)
+
+	// Health probes
+	s.mux.HandleFunc("GET /healthz", handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+
+	// API specification
+	s.mux.HandleFunc("GET /api/openapi.yaml", handleOpenAPISpec)
+
+	// Web UI
+	s.mux.HandleFunc("GET /{$}", handleIndex)
+	s.mux.Handle("GET /ui/", uiHandler())
+
+	// Prometheus metrics
+	s.mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+}
+
+// handleOpenAPISpec serves the OpenAPI spec of this API
+func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(api.OpenAPISpec)
+}
+
+// ServeHTTP implements http.Handler with request logging
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.serve(rec, r)
+	log.Printf("%s %s %d %v", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+}
+
+// serve authorizes and routes a request without logging it
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if s.authorize(w, r) {
+		s.mux.ServeHTTP(w, r)
+	}
+}
+
+// statusRecorder captures the response status for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streaming handlers (SSE) flush through the recorder
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// errorResponse is the JSON body returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeDBError maps database errors to HTTP responses
+func writeDBError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Printf("database error: %v", err)
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// decodeJSON decodes a JSON request body into v, writing an error response on failure
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// queryInt parses an integer query parameter, returning def if it is missing
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid " + name + " parameter")
+	}
+	return n, nil
+}
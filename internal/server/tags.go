@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// tagRequest is the body for creating or renaming a tag
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+// handleListTags lists all tags with their label counts
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.db.ListTags()
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// handleCreateTag creates a tag if it doesn't exist
+func (s *Server) handleCreateTag(w http.ResponseWriter, r *http.Request) {
+	var req tagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "tag name is required")
+		return
+	}
+
+	id, err := s.db.GetOrCreateTag(name)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "name": name})
+}
+
+// handleRenameTag renames a tag, merging it into the target tag if that name is taken
+func (s *Server) handleRenameTag(w http.ResponseWriter, r *http.Request) {
+	var req tagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "tag name is required")
+		return
+	}
+
+	merged, err := s.db.RenameTag(r.PathValue("tag"), name)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "merged": merged})
+}
+
+// handleDeleteTag deletes a tag and its label associations
+func (s *Server) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.DeleteTag(r.PathValue("tag")); err != nil {
+		writeDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
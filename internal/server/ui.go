@@ -0,0 +1,26 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// uiFiles holds the embedded web UI
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the web UI assets under /ui/
+func uiHandler() http.Handler {
+	sub, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/ui/", http.FileServerFS(sub))
+}
+
+// handleIndex redirects the root to the web UI
+func handleIndex(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/ui/", http.StatusFound)
+}
@@ -0,0 +1,316 @@
+// Minimal curation UI on top of the REST API
+(function () {
+  'use strict';
+
+  const pageSize = 50;
+  let offset = 0;
+
+  const $ = (sel) => document.querySelector(sel);
+  const splitList = (value) => value.split(',').map((s) => s.trim()).filter(Boolean);
+
+  function toast(message) {
+    const el = $('#toast');
+    el.textContent = message;
+    el.style.display = 'block';
+    clearTimeout(toast.timer);
+    toast.timer = setTimeout(() => { el.style.display = 'none'; }, 4000);
+  }
+
+  // The API key is kept in local storage and sent as a bearer token
+  const apiKey = $('#api-key');
+  apiKey.value = localStorage.getItem('apiKey') || '';
+  apiKey.addEventListener('change', () => {
+    localStorage.setItem('apiKey', apiKey.value);
+    loadLabels();
+  });
+
+  async function api(method, path, body) {
+    const opts = { method, headers: {} };
+    if (apiKey.value) opts.headers['Authorization'] = 'Bearer ' + apiKey.value;
+    if (body !== undefined) {
+      opts.headers['Content-Type'] = 'application/json';
+      opts.body = JSON.stringify(body);
+    }
+    const resp = await fetch(path, opts);
+    if (!resp.ok) {
+      let message = resp.statusText;
+      try {
+        const data = await resp.json();
+        message = data.error || (data.errors || []).join('; ') || message;
+      } catch (e) { /* not JSON */ }
+      if (resp.status === 401) message = 'Enter a valid API key (top right)';
+      throw new Error(message);
+    }
+    return resp;
+  }
+
+  function el(tag, attrs, ...children) {
+    const node = document.createElement(tag);
+    Object.entries(attrs || {}).forEach(([k, v]) => {
+      if (k.startsWith('on')) node.addEventListener(k.slice(2), v);
+      else node.setAttribute(k, v);
+    });
+    children.forEach((c) => node.append(c));
+    return node;
+  }
+
+  // Tabs
+  document.querySelectorAll('.tab').forEach((tab) => {
+    tab.addEventListener('click', () => {
+      document.querySelectorAll('.tab, .panel').forEach((n) => n.classList.remove('active'));
+      tab.classList.add('active');
+      $('#' + tab.dataset.tab).classList.add('active');
+      if (tab.dataset.tab === 'tags') loadTags();
+    });
+  });
+
+  // Background jobs: submit, then follow progress events until the job is done
+  function formatDuration(seconds) {
+    seconds = Math.round(seconds);
+    return seconds >= 60 ? `${Math.floor(seconds / 60)}m ${seconds % 60}s` : `${seconds}s`;
+  }
+
+  function followJob(job, box) {
+    const bar = box.querySelector('progress');
+    const text = box.querySelector('.progress-text');
+    box.hidden = false;
+    bar.removeAttribute('value');
+    text.textContent = 'Queued';
+
+    return new Promise((resolve, reject) => {
+      let url = `/api/jobs/${job.id}/events`;
+      if (apiKey.value) url += '?access_token=' + encodeURIComponent(apiKey.value);
+      const events = new EventSource(url);
+
+      events.addEventListener('progress', (e) => {
+        const p = JSON.parse(e.data);
+        if (p.rows_total > 0) {
+          bar.value = p.percent;
+          let msg = `${p.file} (${p.files_done + 1}/${p.files_total}): ${p.rows.toLocaleString()} of ${p.rows_total.toLocaleString()} rows`;
+          if (p.eta_seconds) msg += `, about ${formatDuration(p.eta_seconds)} left`;
+          text.textContent = msg;
+        } else {
+          bar.removeAttribute('value');
+          text.textContent = `${p.phase[0].toUpperCase()}${p.phase.slice(1)}... ${formatDuration(p.elapsed_ms / 1000)}`;
+        }
+      });
+      events.addEventListener('done', (e) => {
+        events.close();
+        const done = JSON.parse(e.data);
+        bar.value = 100;
+        text.textContent = done.status === 'succeeded' ? 'Done' : `${done.status}: ${done.error || ''}`;
+        if (done.status === 'succeeded') resolve(done);
+        else reject(new Error(done.error || done.status));
+      });
+      events.onerror = () => {
+        events.close();
+        reject(new Error('Lost connection to progress stream'));
+      };
+    });
+  }
+
+  // Labels
+  async function loadLabels() {
+    const form = new FormData($('#label-search'));
+    const params = new URLSearchParams();
+    ['prefix', 'contains', 'min_length', 'max_length'].forEach((name) => {
+      if (form.get(name)) params.set(name, form.get(name));
+    });
+    splitList(form.get('tag') || '').forEach((tag) => params.append('tag', tag));
+    params.set('limit', pageSize);
+    params.set('offset', offset);
+
+    try {
+      const data = await (await api('GET', '/api/labels?' + params)).json();
+      const rows = $('#label-rows');
+      rows.replaceChildren(...(data.labels || []).map(labelRow));
+      const end = Math.min(offset + pageSize, data.total);
+      $('#label-summary').textContent = data.total ? `Showing ${offset + 1}-${end} of ${data.total} labels` : 'No labels found';
+      $('#prev-page').disabled = offset === 0;
+      $('#next-page').disabled = end >= data.total;
+    } catch (e) {
+      toast(e.message);
+    }
+  }
+
+  function labelRow(l) {
+    const path = '/api/labels/' + encodeURIComponent(l.label);
+    const tags = el('td');
+    (l.tags || []).forEach((tag) => {
+      tags.append(el('span', { class: 'tag' }, tag,
+        el('button', { title: 'Remove tag', onclick: () => act('DELETE', path + '/tags/' + encodeURIComponent(tag)) }, '×')));
+    });
+    const input = el('input', { class: 'tag-input', placeholder: '+ tag' });
+    input.addEventListener('keydown', (e) => {
+      if (e.key === 'Enter' && input.value.trim()) act('POST', path + '/tags', { tags: splitList(input.value) });
+    });
+    tags.append(input);
+
+    return el('tr', {},
+      el('td', {}, l.label),
+      el('td', {}, String(l.length)),
+      tags,
+      el('td', {}, el('button', { onclick: () => confirm(`Delete ${l.label}?`) && act('DELETE', path) }, 'Delete')));
+  }
+
+  async function act(method, path, body) {
+    try {
+      await api(method, path, body);
+      loadLabels();
+    } catch (e) {
+      toast(e.message);
+    }
+  }
+
+  $('#label-search').addEventListener('submit', (e) => { e.preventDefault(); offset = 0; loadLabels(); });
+  $('#prev-page').addEventListener('click', () => { offset = Math.max(0, offset - pageSize); loadLabels(); });
+  $('#next-page').addEventListener('click', () => { offset += pageSize; loadLabels(); });
+  $('#label-create').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const form = e.target;
+    try {
+      await api('POST', '/api/labels', { label: form.label.value, tags: splitList(form.tags.value) });
+      form.reset();
+      loadLabels();
+    } catch (err) {
+      toast(err.message);
+    }
+  });
+
+  $('#label-import').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const form = e.target;
+    const body = new FormData();
+    Array.from(form.files.files).forEach((file) => body.append('files', file, file.name));
+    try {
+      const opts = { method: 'POST', body, headers: {} };
+      if (apiKey.value) opts.headers['Authorization'] = 'Bearer ' + apiKey.value;
+      const resp = await fetch('/api/jobs/import', opts);
+      if (!resp.ok) throw new Error((await resp.json()).error || resp.statusText);
+      const done = await followJob(await resp.json(), $('#import-progress'));
+      const r = done.result || {};
+      $('#import-progress .progress-text').textContent =
+        `Imported ${r.labels_processed} labels (${r.new_labels} new, ${r.labels_skipped} skipped)`;
+      form.reset();
+      loadLabels();
+    } catch (err) {
+      toast(err.message);
+    }
+  });
+
+  // Tags
+  async function loadTags() {
+    try {
+      const tags = await (await api('GET', '/api/tags')).json();
+      $('#tag-rows').replaceChildren(...(tags || []).map((t) => el('tr', {},
+        el('td', {}, t.name),
+        el('td', {}, String(t.label_count)),
+        el('td', {},
+          el('button', { onclick: () => renameTag(t.name) }, 'Rename'), ' ',
+          el('button', { onclick: () => confirm(`Delete tag ${t.name} from all labels?`) && tagAction('DELETE', t.name) }, 'Delete')))));
+    } catch (e) {
+      toast(e.message);
+    }
+  }
+
+  function renameTag(name) {
+    const newName = prompt('Rename tag', name);
+    if (newName && newName !== name) tagAction('PUT', name, { name: newName });
+  }
+
+  async function tagAction(method, name, body) {
+    try {
+      await api(method, '/api/tags/' + encodeURIComponent(name), body);
+      loadTags();
+    } catch (e) {
+      toast(e.message);
+    }
+  }
+
+  $('#tag-create').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    try {
+      await api('POST', '/api/tags', { name: e.target.name.value });
+      e.target.reset();
+      loadTags();
+    } catch (err) {
+      toast(err.message);
+    }
+  });
+
+  // Tiers and generation
+  function readTiers() {
+    try {
+      return JSON.parse($('#tiers-json').value);
+    } catch (e) {
+      toast('Invalid JSON: ' + e.message);
+      return null;
+    }
+  }
+
+  function generateRequest() {
+    const tiers = readTiers();
+    if (!tiers) return null;
+    return {
+      tiers,
+      format: $('#format').value,
+      tld: $('#tld').value.trim(),
+      exclude_tags: splitList($('#exclude-tags').value),
+    };
+  }
+
+  $('#validate').addEventListener('click', async () => {
+    const tiers = readTiers();
+    if (!tiers) return;
+    try {
+      const v = await (await api('POST', '/api/tiers/validate', tiers)).json();
+      const items = [];
+      (v.errors || []).forEach((m) => items.push(el('li', { class: 'error' }, m)));
+      (v.warnings || []).forEach((m) => items.push(el('li', { class: 'warning' }, m)));
+      if (v.valid && items.length === 0) items.push(el('li', { class: 'ok' }, 'Tiers are valid'));
+      $('#tier-messages').replaceChildren(...items);
+    } catch (e) {
+      toast(e.message);
+    }
+  });
+
+  async function generate() {
+    const req = generateRequest();
+    if (!req) return null;
+    try {
+      return await (await api('POST', '/api/generate', req)).text();
+    } catch (e) {
+      toast(e.message);
+      return null;
+    }
+  }
+
+  $('#preview').addEventListener('click', async () => {
+    const csv = await generate();
+    if (csv === null) return;
+    const lines = csv.trim().split('\n');
+    const rows = lines.slice(0, 101).map((line, i) =>
+      el('tr', {}, ...line.split(',').map((cell) => el(i === 0 ? 'th' : 'td', {}, cell))));
+    $('#preview-table').replaceChildren(...rows);
+    const count = Math.max(0, lines.length - 1);
+    $('#preview-summary').textContent = count > 100 ? `Showing first 100 of ${count} entries` : `${count} entries`;
+  });
+
+  // Generate as a background job so large lists show progress instead of a hanging request
+  $('#generate').addEventListener('click', async () => {
+    const req = generateRequest();
+    if (!req) return;
+    try {
+      const job = await (await api('POST', '/api/jobs/generate', req)).json();
+      await followJob(job, $('#generate-progress'));
+      const blob = await (await api('GET', `/api/jobs/${job.id}/artifact`)).blob();
+      const link = el('a', { href: URL.createObjectURL(blob), download: 'premium-list.csv' });
+      link.click();
+      URL.revokeObjectURL(link.href);
+    } catch (e) {
+      toast(e.message);
+    }
+  });
+
+  loadLabels();
+})();
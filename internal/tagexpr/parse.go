@@ -0,0 +1,230 @@
+package tagexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokQuoted
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokCompare
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// Parse parses a filter expression; see the package documentation for the syntax
+func Parse(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return e, nil
+}
+
+// MustParse is like Parse but panics on an error, for expressions in code and tests
+func MustParse(s string) Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case strings.HasPrefix(s[i:], "&&"):
+			tokens = append(tokens, token{tokAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, token{tokOr, "||", i})
+			i += 2
+		case strings.HasPrefix(s[i:], "!="), strings.HasPrefix(s[i:], "<="), strings.HasPrefix(s[i:], ">="), strings.HasPrefix(s[i:], "=="):
+			tokens = append(tokens, token{tokCompare, s[i : i+2], i})
+			i += 2
+		case c == '<' || c == '>' || c == '=':
+			tokens = append(tokens, token{tokCompare, s[i : i+1], i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{tokNot, "!", i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated quote at position %d", i+1)
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted tag at position %d: %w", i+1, err)
+			}
+			tokens = append(tokens, token{tokQuoted, text, i})
+			i = end + 1
+		case c == '&' || c == '|':
+			return nil, fmt.Errorf("unexpected %q at position %d (use %c%c)", c, i+1, c, c)
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t\n\r()\"!<>=&|", rune(s[end])) {
+				end++
+			}
+			word := s[i:end]
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{tokAnd, word, i})
+			case "or":
+				tokens = append(tokens, token{tokOr, word, i})
+			case "not":
+				tokens = append(tokens, token{tokNot, word, i})
+			default:
+				tokens = append(tokens, token{tokWord, word, i})
+			}
+			i = end
+		}
+	}
+	return append(tokens, token{tokEOF, "end of expression", len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid filter expression at position %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokWord, tokQuoted, tokNot, tokLParen:
+			// Juxtaposition is AND
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokRParen {
+			return nil, p.errorf(c, "expected ) but found %q", c.text)
+		}
+		return e, nil
+	case tokQuoted:
+		return Tag{Pattern: t.text}, nil
+	case tokWord:
+		word := strings.ToLower(t.text)
+		if (word == "len" || word == "length") && p.peek().kind == tokCompare {
+			op := p.next().text
+			if op == "==" {
+				op = "="
+			}
+			n := p.next()
+			value, err := strconv.Atoi(n.text)
+			if n.kind != tokWord || err != nil || value < 0 {
+				return nil, p.errorf(n, "expected a length after %s %s but found %q", t.text, op, n.text)
+			}
+			return Length{Op: op, N: value}, nil
+		}
+		return Tag{Pattern: t.text}, nil
+	case tokCompare:
+		return nil, p.errorf(t, "%q must follow len", t.text)
+	default:
+		return nil, p.errorf(t, "expected a tag, len or ( but found %q", t.text)
+	}
+}
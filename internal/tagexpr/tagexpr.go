@@ -0,0 +1,145 @@
+// Package tagexpr parses the filter expressions every command and API endpoint selects labels with,
+// e.g. `brand AND NOT registered AND len <= 5` or `(city:* OR country:*) !adult`
+//
+// Syntax:
+//
+//	a AND b, a && b, a b   both (juxtaposition is AND)
+//	a OR b, a || b         either
+//	NOT a, !a              not
+//	( ... )                grouping
+//	len < 5                label length; also <=, >, >=, =, == and !=, and length for len
+//	city:*, ?letter        tags, * matching any run of characters and ? a single one
+//	"len"                  a quoted tag, for tags that read as keywords or contain spaces
+//
+// Keywords are case-insensitive; tags are matched as stored
+package tagexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed filter expression
+type Expr interface {
+	// Match reports whether a label with the given tags satisfies the expression
+	Match(label string, tags []string) bool
+	String() string
+}
+
+// And matches labels matching both Left and Right
+type And struct{ Left, Right Expr }
+
+// Or matches labels matching Left, Right or both
+type Or struct{ Left, Right Expr }
+
+// Not matches labels not matching X
+type Not struct{ X Expr }
+
+// Tag matches labels carrying a tag matching Pattern
+type Tag struct{ Pattern string }
+
+// Length matches labels whose length compares to N by Op (<, <=, >, >=, = or !=)
+type Length struct {
+	Op string
+	N  int
+}
+
+func (e And) Match(label string, tags []string) bool {
+	return e.Left.Match(label, tags) && e.Right.Match(label, tags)
+}
+
+func (e Or) Match(label string, tags []string) bool {
+	return e.Left.Match(label, tags) || e.Right.Match(label, tags)
+}
+
+func (e Not) Match(label string, tags []string) bool { return !e.X.Match(label, tags) }
+
+func (e Tag) Match(_ string, tags []string) bool {
+	for _, tag := range tags {
+		if e.Matches(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether tag matches the pattern
+func (e Tag) Matches(tag string) bool { return glob(e.Pattern, tag) }
+
+// IsWildcard reports whether the pattern has wildcards, i.e. can match more than one tag
+func (e Tag) IsWildcard() bool { return strings.ContainsAny(e.Pattern, "*?") }
+
+func (e Length) Match(label string, _ []string) bool {
+	n := len(label)
+	switch e.Op {
+	case "<":
+		return n < e.N
+	case "<=":
+		return n <= e.N
+	case ">":
+		return n > e.N
+	case ">=":
+		return n >= e.N
+	case "!=":
+		return n != e.N
+	default:
+		return n == e.N
+	}
+}
+
+func (e And) String() string    { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e Or) String() string     { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e Not) String() string    { return "NOT " + e.X.String() }
+func (e Length) String() string { return fmt.Sprintf("len %s %d", e.Op, e.N) }
+
+func (e Tag) String() string {
+	if e.Pattern == "" || strings.ContainsAny(e.Pattern, " \t\"()!<>=&|") || isKeyword(e.Pattern) {
+		return strconv.Quote(e.Pattern)
+	}
+	return e.Pattern
+}
+
+// glob matches s against pattern, where * matches any run of characters and ? a single one
+func glob(pattern, s string) bool {
+	px, sx := 0, 0
+	nextPx, nextSx := -1, -1
+	p, str := []rune(pattern), []rune(s)
+	for px < len(p) || sx < len(str) {
+		if px < len(p) {
+			switch p[px] {
+			case '*':
+				// Try matching nothing first; backtrack to match one more character
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(str) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(str) && str[sx] == p[px] {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if nextSx > 0 && nextSx <= len(str) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func isKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "len", "length":
+		return true
+	}
+	return false
+}
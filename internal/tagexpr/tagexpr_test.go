@@ -0,0 +1,104 @@
+package tagexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"brand", "brand"},
+		{"brand AND NOT registered", "(brand AND NOT registered)"},
+		{"brand && !registered", "(brand AND NOT registered)"},
+		{"brand !registered len<=5", "((brand AND NOT registered) AND len <= 5)"},
+		{"a OR b AND c", "(a OR (b AND c))"},
+		{"(a or b) and c", "((a OR b) AND c)"},
+		{"city:* || ?letter", "(city:* OR ?letter)"},
+		{"length == 3", "len = 3"},
+		{"len != 4", "len != 4"},
+		{`"len" "two words"`, `("len" AND "two words")`},
+		{"len:5", "len:5"},
+		{"len", `"len"`},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+		// The string form parses back to the same expression
+		if again, err := Parse(e.String()); err != nil || again.String() != e.String() {
+			t.Errorf("Parse(%q) doesn't round-trip: %v %v", e.String(), again, err)
+		}
+	}
+
+	for expr, want := range map[string]string{
+		"":             "empty filter expression",
+		"brand AND":    "expected a tag",
+		"(brand":       "expected )",
+		"brand)":       `unexpected ")"`,
+		"len < five":   "expected a length",
+		"< 5":          "must follow len",
+		"brand & city": "use &&",
+		`"brand`:       "unterminated quote",
+	} {
+		if _, err := Parse(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q): err = %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tags := []string{"brand", "city:paris", "len:5"}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"brand", true},
+		{"NOT brand", false},
+		{"brand registered", false},
+		{"brand OR registered", true},
+		{"city:*", true},
+		{"city:?aris", true},
+		{"city:*s", true},
+		{"country:*", false},
+		{"*:paris", true},
+		{"len = 5", true},
+		{"len < 5", false},
+		{"len >= 5 AND len <= 6", true},
+		{"(registered OR city:*) AND !adult", true},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.expr).Match("paris", tags); got != tt.want {
+			t.Errorf("%q matches = %t, want %t", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"a*", "a", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbbd", false},
+		{"*b*", "abc", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"é?", "éa", true},
+		{"a*b*c", "aXbYbZc", true},
+	}
+	for _, tt := range tests {
+		if got := glob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("glob(%q, %q) = %t, want %t", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -2,9 +2,45 @@ package tagger
 
 import "fmt"
 
+// lengthTags holds the length tags of all valid label lengths, so that imports don't format one per label
+var lengthTags = func() []string {
+	tags := make([]string, 64)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("len:%d", i)
+	}
+	return tags
+}()
+
 // GenerateLengthTag generates a length-based tag for a label
 // Returns a tag in the format "len:N" where N is the label length
 func GenerateLengthTag(length int) string {
+	if length >= 0 && length < len(lengthTags) {
+		return lengthTags[length]
+	}
 	return fmt.Sprintf("len:%d", length)
 }
 
+// GenerateAutoTags generates the content-based tags for a label
+// The length tag is handled separately since it is pre-created for every import
+func GenerateAutoTags(label string) []string {
+	var tags []string
+	if shape := GenerateShapeTag(label); shape != "" {
+		tags = append(tags, shape)
+	}
+	if IsTLD(label) {
+		tags = append(tags, TLDWordTag)
+	}
+	return tags
+}
+
+// GenerateRankTags generates positional tags for a label from a ranked source file
+// Returns a "rank:topN" tag for every threshold N that the 1-based position falls within
+func GenerateRankTags(position int, thresholds []int) []string {
+	var tags []string
+	for _, threshold := range thresholds {
+		if position <= threshold {
+			tags = append(tags, fmt.Sprintf("rank:top%d", threshold))
+		}
+	}
+	return tags
+}
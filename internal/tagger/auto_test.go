@@ -0,0 +1,77 @@
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateShapeTag(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"ab", "LL"},
+		{"abc", "LLL"},
+		{"007", "NNN"},
+		{"a1b", "LNL"},
+		{"1a", "NL"},
+		{"abcd", "LLLL"},
+		{"abcde", "LLLLL"},
+		{"abcdef", ""}, // too long
+		{"a-b", ""},    // hyphen has no shape
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := GenerateShapeTag(tt.label); got != tt.want {
+			t.Errorf("GenerateShapeTag(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestIsTLD(t *testing.T) {
+	for _, label := range []string{"app", "shop", "xyz", "com", "XYZ"} {
+		if !IsTLD(label) {
+			t.Errorf("IsTLD(%q) = false, want true", label)
+		}
+	}
+	for _, label := range []string{"notatld", "premium-list", ""} {
+		if IsTLD(label) {
+			t.Errorf("IsTLD(%q) = true, want false", label)
+		}
+	}
+}
+
+func TestGenerateAutoTags(t *testing.T) {
+	tests := []struct {
+		label string
+		want  []string
+	}{
+		{"app", []string{"LLL", TLDWordTag}},
+		{"a1b", []string{"LNL"}},
+		{"premium", nil},
+	}
+
+	for _, tt := range tests {
+		if got := GenerateAutoTags(tt.label); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GenerateAutoTags(%q) = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRankTags(t *testing.T) {
+	thresholds := []int{1000, 10000}
+
+	if got := GenerateRankTags(500, thresholds); !reflect.DeepEqual(got, []string{"rank:top1000", "rank:top10000"}) {
+		t.Errorf("GenerateRankTags(500) = %v", got)
+	}
+	if got := GenerateRankTags(1000, thresholds); !reflect.DeepEqual(got, []string{"rank:top1000", "rank:top10000"}) {
+		t.Errorf("GenerateRankTags(1000) = %v", got)
+	}
+	if got := GenerateRankTags(5000, thresholds); !reflect.DeepEqual(got, []string{"rank:top10000"}) {
+		t.Errorf("GenerateRankTags(5000) = %v", got)
+	}
+	if got := GenerateRankTags(20000, thresholds); got != nil {
+		t.Errorf("GenerateRankTags(20000) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,114 @@
+package tagger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ExecTagger streams labels to an external program and reads back tags
+// Protocol: one label per line on the program's stdin, and for every label
+// exactly one line on its stdout containing comma-separated tags (empty line = no tags)
+type ExecTagger struct {
+	command string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+	mu      sync.Mutex // Batches of concurrent imports are sent one at a time
+}
+
+// NewExecTagger starts the external tagger program
+// The command string is split on whitespace, so arguments may be passed along with the path
+func NewExecTagger(command string) (*ExecTagger, error) {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("exec tagger command is empty")
+	}
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exec tagger stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exec tagger stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start exec tagger %s: %w", parts[0], err)
+	}
+
+	return &ExecTagger{
+		command: command,
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  bufio.NewReader(stdout),
+	}, nil
+}
+
+// TagBatch sends a batch of labels to the external program and returns the tags for each label
+// The result has the same length and order as labels; it is safe for concurrent use
+func (t *ExecTagger) TagBatch(labels []string) ([][]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Write in a separate goroutine so a program that answers while still
+	// reading input can't deadlock on a full pipe buffer
+	writeErr := make(chan error, 1)
+	go func() {
+		w := bufio.NewWriter(t.stdin)
+		for _, label := range labels {
+			if _, err := w.WriteString(label + "\n"); err != nil {
+				writeErr <- err
+				return
+			}
+		}
+		writeErr <- w.Flush()
+	}()
+
+	results := make([][]string, len(labels))
+	for i := range labels {
+		line, err := t.stdout.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("exec tagger %q stopped responding after %d of %d labels: %w", t.command, i, len(labels), err)
+		}
+		results[i] = parseTagLine(line)
+	}
+
+	if err := <-writeErr; err != nil {
+		return nil, fmt.Errorf("failed to write labels to exec tagger: %w", err)
+	}
+
+	return results, nil
+}
+
+// Close closes the program's stdin and waits for it to exit
+func (t *ExecTagger) Close() error {
+	t.stdin.Close()
+	if err := t.cmd.Wait(); err != nil {
+		return fmt.Errorf("exec tagger %q exited with error: %w", t.command, err)
+	}
+	return nil
+}
+
+// parseTagLine splits a comma-separated line of tags, dropping empty entries
+func parseTagLine(line string) []string {
+	var tags []string
+	for _, tag := range strings.Split(strings.TrimRight(line, "\r\n"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,114 @@
+package tagger
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProfanityTag is the tag applied to labels containing profanity or adult terms
+const ProfanityTag = "profanity"
+
+// defaultProfanityWords is the built-in word list used when no custom list is given
+//
+//go:embed profanity-words.txt
+var defaultProfanityWords string
+
+// WordListTagger tags labels that contain any word from a list
+// A word list has three kinds of entries, one per line:
+//   - term: matched anywhere in the label, e.g. porn in freeporn
+//   - =term: matched only as the whole label or one of its hyphen-separated parts, e.g. cum in cum-shots but
+//     not in document
+//   - !word: allowed word, in which the terms don't count, e.g. essex for sex or cocktail for cock
+type WordListTagger struct {
+	Tag     string
+	words   []string // Matched anywhere
+	exact   []string // Matched as a hyphen-separated part
+	allowed []string
+}
+
+// NewProfanityTagger creates a profanity tagger from a word list file
+// If path is empty, the embedded default word list is used
+func NewProfanityTagger(path string) (*WordListTagger, error) {
+	data := defaultProfanityWords
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read word list: %w", err)
+		}
+		data = string(content)
+	}
+
+	t := &WordListTagger{Tag: ProfanityTag}
+	for _, word := range parseWordList(data) {
+		switch {
+		case strings.HasPrefix(word, "="):
+			t.exact = append(t.exact, word[1:])
+		case strings.HasPrefix(word, "!"):
+			t.allowed = append(t.allowed, word[1:])
+		default:
+			t.words = append(t.words, word)
+		}
+	}
+	if len(t.words) == 0 && len(t.exact) == 0 {
+		return nil, fmt.Errorf("word list is empty")
+	}
+
+	return t, nil
+}
+
+// Match reports whether the label contains any of the listed words outside the allowed words
+func (t *WordListTagger) Match(label string) bool {
+	label = strings.ToLower(label)
+	if len(t.exact) > 0 {
+		for _, part := range strings.Split(label, "-") {
+			for _, word := range t.exact {
+				if part == word {
+					return true
+				}
+			}
+		}
+	}
+	for _, word := range t.words {
+		for i := 0; ; {
+			at := strings.Index(label[i:], word)
+			if at < 0 {
+				break
+			}
+			at += i
+			if !t.isAllowed(label, at, at+len(word)) {
+				return true
+			}
+			i = at + 1
+		}
+	}
+	return false
+}
+
+// isAllowed reports whether label[start:end] lies within an allowed word of the label
+func (t *WordListTagger) isAllowed(label string, start, end int) bool {
+	for _, word := range t.allowed {
+		// The allowed word must begin at most len(word)-(end-start) bytes before start to cover the match
+		from := max(0, end-len(word))
+		for i := from; i <= start && i+len(word) <= len(label); i++ {
+			if label[i:i+len(word)] == word {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseWordList parses a word list (one lowercased word per line, # comments)
+func parseWordList(data string) []string {
+	var words []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") || line == "=" || line == "!" {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words
+}
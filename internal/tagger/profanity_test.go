@@ -0,0 +1,57 @@
+package tagger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfanityTagger_Default(t *testing.T) {
+	tagger, err := NewProfanityTagger("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ordinary words that contain a short term
+	for _, label := range []string{"document", "analytics", "essex", "cocktail", "canal", "peacock", "accumulate", "cucumber", "scunthorpe", "dickens", "sextant"} {
+		if tagger.Match(label) {
+			t.Errorf("Match(%q) = true, want false", label)
+		}
+	}
+	for _, label := range []string{"porn", "freeporn", "hotsex", "sex-essex", "cum", "cum-shots", "bigcocks", "shithead", "Fuck"} {
+		if !tagger.Match(label) {
+			t.Errorf("Match(%q) = false, want true", label)
+		}
+	}
+}
+
+func TestProfanityTagger_List(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# custom\nbad\n!badminton\n=ass\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tagger, err := NewProfanityTagger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for label, want := range map[string]bool{
+		"bad":          true,
+		"badminton":    false,
+		"badbadminton": true,
+		"ass":          true,
+		"kick-ass":     true,
+		"class":        false,
+		"passion":      false,
+	} {
+		if got := tagger.Match(label); got != want {
+			t.Errorf("Match(%q) = %t, want %t", label, got, want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("!allowed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewProfanityTagger(path); err == nil {
+		t.Error("a list of allowed words only was accepted")
+	}
+}
@@ -0,0 +1,31 @@
+package tagger
+
+import "strings"
+
+// MaxShapeLength is the longest label that gets a shape tag
+// Longer shapes (e.g. LLLLLLLL) are not used in the investor taxonomy
+const MaxShapeLength = 5
+
+// GenerateShapeTag generates a letter/number shape tag for short labels
+// Letters map to "L" and digits to "N", e.g. "abc" -> "LLL", "a1b" -> "LNL", "007" -> "NNN"
+// Returns an empty string for labels longer than MaxShapeLength or containing other characters
+func GenerateShapeTag(label string) string {
+	if len(label) == 0 || len(label) > MaxShapeLength {
+		return ""
+	}
+
+	var shape strings.Builder
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			shape.WriteByte('L')
+		case c >= '0' && c <= '9':
+			shape.WriteByte('N')
+		default:
+			// Hyphens and IDN labels have no shape
+			return ""
+		}
+	}
+
+	return shape.String()
+}
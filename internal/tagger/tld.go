@@ -0,0 +1,36 @@
+package tagger
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// TLDWordTag is the tag applied to labels that are themselves delegated TLD strings
+const TLDWordTag = "tld-word"
+
+// tldList is an embedded copy of the IANA root zone TLD list
+// Refresh it from https://data.iana.org/TLD/tlds-alpha-by-domain.txt
+//
+//go:embed tlds-alpha-by-domain.txt
+var tldList string
+
+// tldSet holds the lowercased TLDs from tldList for fast lookup
+var tldSet = parseTLDList(tldList)
+
+// parseTLDList parses the IANA list format (one uppercase TLD per line, # comments)
+func parseTLDList(data string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = true
+	}
+	return set
+}
+
+// IsTLD reports whether the label is an existing TLD string (e.g. "app", "shop", "xyz")
+func IsTLD(label string) bool {
+	return tldSet[strings.ToLower(label)]
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Event names sent in the payload
+const (
+	EventImportCompleted   = "import.completed"
+	EventGenerateCompleted = "generate.completed"
+	EventZoneMonitored     = "zone.monitored"
+	EventDropsImported     = "drops.imported"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the body when a secret is configured
+const SignatureHeader = "X-Premium-List-Signature"
+
+// MaxAttempts is the number of delivery attempts per webhook URL
+const MaxAttempts = 3
+
+// Event is the JSON payload posted to every webhook
+type Event struct {
+	Event       string    `json:"event"`
+	Timestamp   time.Time `json:"timestamp"`
+	Database    string    `json:"database,omitempty"`
+	OutputPath  string    `json:"output_path,omitempty"`
+	ErrorReport string    `json:"error_report,omitempty"` // Import error report, if labels were rejected
+	SHA256      string    `json:"sha256,omitempty"`
+	Stats       any       `json:"stats,omitempty"`
+}
+
+// ImportStats is the stats payload of an import.completed event
+type ImportStats struct {
+	Files           int   `json:"files"`
+	FilesSkipped    int   `json:"files_skipped"`
+	LabelsProcessed int   `json:"labels_processed"`
+	NewLabels       int   `json:"new_labels"`
+	ExistingLabels  int   `json:"existing_labels"`
+	LabelsSkipped   int   `json:"labels_skipped"`
+	Errors          int   `json:"errors"`
+	DurationMS      int64 `json:"duration_ms"`
+}
+
+// GenerateStats is the stats payload of a generate.completed event
+type GenerateStats struct {
+	Format     string `json:"format"`
+	TLD        string `json:"tld,omitempty"`
+	SizeBytes  int64  `json:"size_bytes"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ZoneMonitorStats is the stats payload of a zone.monitored event
+type ZoneMonitorStats struct {
+	TLD             string `json:"tld"`
+	Baseline        string `json:"baseline"` // previous zone file, or "database"
+	PremiumLabels   int    `json:"premium_labels"`
+	NewlyRegistered int    `json:"newly_registered"`
+	DurationMS      int64  `json:"duration_ms"`
+}
+
+// DropStats is the stats payload of a drops.imported event
+type DropStats struct {
+	TLD              string `json:"tld"`
+	Date             string `json:"date"`
+	DroppedNames     int    `json:"dropped_names"`
+	KnownLabels      int    `json:"known_labels"` // Dropped names that are labels in the database
+	PremiumAvailable int    `json:"premium_available"`
+	DurationMS       int64  `json:"duration_ms"`
+}
+
+// Notifier posts events to a set of webhook URLs
+// A nil Notifier is valid and sends nothing
+type Notifier struct {
+	urls       []string
+	secret     string
+	client     *http.Client
+	retryDelay time.Duration // Wait before the second attempt, growing linearly with every further one
+}
+
+// NewNotifier creates a notifier for the given URLs, or nil if there are none
+// If secret is set, each request is signed with HMAC-SHA256
+func NewNotifier(urls []string, secret string) *Notifier {
+	if len(urls) == 0 {
+		return nil
+	}
+	return &Notifier{
+		urls:       urls,
+		secret:     secret,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		retryDelay: time.Second,
+	}
+}
+
+// Notify posts the event to every URL, retrying failed deliveries
+// All URLs are attempted; the first delivery error is returned
+func (n *Notifier) Notify(event Event) error {
+	if n == nil {
+		return nil
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var firstErr error
+	for _, url := range n.urls {
+		if err := n.deliver(url, body); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// deliver posts the body to a single URL with retries
+func (n *Notifier) deliver(url string, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		if err = n.post(url, body); err == nil {
+			return nil
+		}
+		if attempt < MaxAttempts {
+			time.Sleep(time.Duration(attempt) * n.retryDelay)
+		}
+	}
+	return fmt.Errorf("webhook %s failed after %d attempts: %w", url, MaxAttempts, err)
+}
+
+func (n *Notifier) post(url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(body, n.secret))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body using secret
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FileChecksum returns the hex SHA-256 and size of a file
+func FileChecksum(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
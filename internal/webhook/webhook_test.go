@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a webhook endpoint that fails the first failures requests and records the rest
+type recorder struct {
+	failures int
+
+	mu       sync.Mutex
+	attempts int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.attempts++
+	if rec.attempts <= rec.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rec.bodies = append(rec.bodies, body)
+	rec.headers = append(rec.headers, r.Header.Clone())
+}
+
+func TestNotify(t *testing.T) {
+	rec := &recorder{}
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	n := NewNotifier([]string{ts.URL}, "s3cret")
+	err := n.Notify(Event{
+		Event:      EventGenerateCompleted,
+		Database:   "shop.db",
+		OutputPath: "premium-shop.csv",
+		SHA256:     "abc123",
+		Stats:      GenerateStats{Format: "default", TLD: "shop", SizeBytes: 42},
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if rec.attempts != 1 || len(rec.bodies) != 1 {
+		t.Fatalf("%d attempts, %d deliveries; want 1", rec.attempts, len(rec.bodies))
+	}
+
+	body, header := rec.bodies[0], rec.headers[0]
+	if ct := header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if sig := header.Get(SignatureHeader); sig != "sha256="+Sign(body, "s3cret") {
+		t.Errorf("%s = %q doesn't match the body", SignatureHeader, sig)
+	}
+	var got struct {
+		Event
+		Stats GenerateStats `json:"stats"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("payload %s: %v", body, err)
+	}
+	if got.Event.Event != EventGenerateCompleted || got.OutputPath != "premium-shop.csv" || got.SHA256 != "abc123" || got.Stats.SizeBytes != 42 {
+		t.Errorf("payload = %s", body)
+	}
+	if time.Since(got.Timestamp) > time.Minute {
+		t.Errorf("timestamp = %v, want it set to now", got.Timestamp)
+	}
+
+	// Without a secret, requests aren't signed
+	if err := NewNotifier([]string{ts.URL}, "").Notify(Event{Event: EventImportCompleted}); err != nil {
+		t.Fatal(err)
+	}
+	if sig := rec.headers[1].Get(SignatureHeader); sig != "" {
+		t.Errorf("unsigned request has %s = %q", SignatureHeader, sig)
+	}
+
+	var nilNotifier *Notifier
+	if err := nilNotifier.Notify(Event{Event: EventImportCompleted}); err != nil {
+		t.Errorf("nil Notifier: %v", err)
+	}
+}
+
+func TestNotify_Failures(t *testing.T) {
+	// A transient failure is retried
+	flaky := &recorder{failures: MaxAttempts - 1}
+	flakyServer := httptest.NewServer(flaky)
+	defer flakyServer.Close()
+	// A permanent failure is reported, but doesn't keep the event from the other URLs
+	down := &recorder{failures: MaxAttempts}
+	downServer := httptest.NewServer(down)
+	defer downServer.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	n := NewNotifier([]string{downServer.URL, closed.URL, flakyServer.URL}, "")
+	n.retryDelay = time.Millisecond
+	err := n.Notify(Event{Event: EventImportCompleted, Stats: ImportStats{NewLabels: 3}})
+	if err == nil || !strings.Contains(err.Error(), downServer.URL) || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Notify: err = %v, want the failure of %s", err, downServer.URL)
+	}
+	if down.attempts != MaxAttempts || len(down.bodies) != 0 {
+		t.Errorf("failing URL: %d attempts, want %d", down.attempts, MaxAttempts)
+	}
+	if flaky.attempts != MaxAttempts || len(flaky.bodies) != 1 {
+		t.Errorf("flaky URL: %d attempts, %d deliveries; want delivered on attempt %d", flaky.attempts, len(flaky.bodies), MaxAttempts)
+	}
+
+	// An unreachable URL fails too
+	n = NewNotifier([]string{closed.URL}, "")
+	n.retryDelay = time.Millisecond
+	if err := n.Notify(Event{Event: EventImportCompleted}); err == nil {
+		t.Error("Notify to a closed server succeeded")
+	}
+}
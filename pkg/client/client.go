@@ -0,0 +1,524 @@
+// Package client is a Go client for the REST API served by `premium-list-maker serve`
+// The API is described in api/openapi.yaml
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Label is a label with its tags
+type Label struct {
+	ID     int64    `json:"id"`
+	Label  string   `json:"label"`
+	Length int      `json:"length"`
+	Tags   []string `json:"tags"`
+}
+
+// LabelList is a page of label search results
+type LabelList struct {
+	Labels []Label `json:"labels"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// LabelFilter holds the label search parameters; zero values are omitted
+type LabelFilter struct {
+	Tags      []string // All tags must match
+	MinLength int
+	MaxLength int
+	Prefix    string
+	Contains  string
+	Where     string // Filter expression, e.g. brand AND NOT registered AND len <= 5
+	Limit     int
+	Offset    int
+}
+
+// Tag is a tag with the number of labels carrying it
+type Tag struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	LabelCount int    `json:"label_count"`
+}
+
+// Tier is a price tier in a tiers configuration
+type Tier struct {
+	Tier     int      `json:"tier"`
+	Tags     []string `json:"tags"`
+	Currency string   `json:"currency"`
+	PriceReg *float64 `json:"price_reg,omitempty"`
+	PriceRen *float64 `json:"price_ren,omitempty"`
+	PriceRes *float64 `json:"price_res,omitempty"`
+}
+
+// TierValidation is the result of validating a tiers configuration
+type TierValidation struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// EvaluateRequest holds the labels to evaluate tiers against, with the exclusions generate would apply
+type EvaluateRequest struct {
+	Tiers       []Tier   `json:"tiers"`
+	Labels      []string `json:"labels"`
+	ExcludeTags []string `json:"exclude_tags,omitempty"`
+	Where       string   `json:"where,omitempty"`
+}
+
+// Entry is a premium list row with its final prices
+type Entry struct {
+	Label    string
+	Tier     int
+	PriceReg *float64
+	PriceRen *float64
+	PriceRes *float64
+	Currency string
+}
+
+// Evaluation is the decision trail of a label: how generate would list and price it
+type Evaluation struct {
+	Label      string   `json:"label"`
+	Tags       []string `json:"tags"`
+	ExcludedBy []string `json:"excluded_by"`
+	FilteredBy string   `json:"filtered_by"`
+	Tier       int      `json:"tier"`
+	Listed     bool     `json:"listed"`
+	Entry      *Entry   `json:"entry"`
+	Steps      []string `json:"steps"`
+}
+
+// EvaluateResult holds the evaluations of the labels found and the labels not in the database
+type EvaluateResult struct {
+	Results  []Evaluation `json:"results"`
+	NotFound []string     `json:"not_found"`
+}
+
+// GenerateRequest holds the premium list generation parameters
+type GenerateRequest struct {
+	Tiers       []Tier   `json:"tiers"`
+	Format      string   `json:"format,omitempty"`
+	TLD         string   `json:"tld,omitempty"`
+	ExcludeTags []string `json:"exclude_tags,omitempty"`
+	Where       string   `json:"where,omitempty"`
+}
+
+// FileImportResult holds the stats of one imported file
+type FileImportResult struct {
+	File           string        `json:"file"`
+	Tag            string        `json:"tag"`
+	Imported       int           `json:"imported"`
+	NewLabels      int           `json:"new_labels"`
+	ExistingLabels int           `json:"existing_labels"`
+	Skipped        int           `json:"skipped"`
+	HeaderSkipped  bool          `json:"header_skipped"`
+	ErrorCount     int           `json:"error_count"`
+	Errors         []ImportError `json:"errors"`
+	DurationMS     int64         `json:"duration_ms"`
+	Failed         string        `json:"failed"`
+}
+
+// ImportError is a row or batch the server couldn't import
+type ImportError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Label   string `json:"label"`
+	Kind    string `json:"kind"` // invalid_label, parse or batch
+	Message string `json:"message"`
+}
+
+// ImportResult holds the stats of an import request
+type ImportResult struct {
+	Files           []FileImportResult `json:"files"`
+	LabelsProcessed int                `json:"labels_processed"`
+	NewLabels       int                `json:"new_labels"`
+	ExistingLabels  int                `json:"existing_labels"`
+	LabelsSkipped   int                `json:"labels_skipped"`
+	DurationMS      int64              `json:"duration_ms"`
+}
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job is a background import or generation
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Status     string          `json:"status"`
+	Params     json.RawMessage `json:"params"`
+	Result     json.RawMessage `json:"result"`
+	Error      string          `json:"error"`
+	Artifact   string          `json:"artifact"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+}
+
+// Done reports whether the job has reached a final status
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// Error is returned for non-2xx responses
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client talks to a premium-list-maker server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates a client for the server at baseURL (e.g. http://localhost:8080)
+// If httpClient is nil, http.DefaultClient is used
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// ListLabels searches labels
+func (c *Client) ListLabels(ctx context.Context, filter LabelFilter) (*LabelList, error) {
+	query := url.Values{}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	setInt(query, "min_length", filter.MinLength)
+	setInt(query, "max_length", filter.MaxLength)
+	setInt(query, "limit", filter.Limit)
+	setInt(query, "offset", filter.Offset)
+	if filter.Prefix != "" {
+		query.Set("prefix", filter.Prefix)
+	}
+	if filter.Contains != "" {
+		query.Set("contains", filter.Contains)
+	}
+	if filter.Where != "" {
+		query.Set("q", filter.Where)
+	}
+
+	path := "/api/labels"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var list LabelList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetLabel returns a label with its tags
+func (c *Client) GetLabel(ctx context.Context, label string) (*Label, error) {
+	var l Label
+	if err := c.do(ctx, http.MethodGet, "/api/labels/"+url.PathEscape(label), nil, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// CreateLabel creates a label with optional tags
+func (c *Client) CreateLabel(ctx context.Context, label string, tags ...string) (*Label, error) {
+	body := map[string]interface{}{"label": label, "tags": tags}
+	var l Label
+	if err := c.do(ctx, http.MethodPost, "/api/labels", body, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// DeleteLabel deletes a label
+func (c *Client) DeleteLabel(ctx context.Context, label string) error {
+	return c.do(ctx, http.MethodDelete, "/api/labels/"+url.PathEscape(label), nil, nil)
+}
+
+// AddLabelTags adds tags to an existing label
+func (c *Client) AddLabelTags(ctx context.Context, label string, tags ...string) (*Label, error) {
+	body := map[string]interface{}{"tags": tags}
+	var l Label
+	if err := c.do(ctx, http.MethodPost, "/api/labels/"+url.PathEscape(label)+"/tags", body, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// RemoveLabelTag removes a tag from a label
+func (c *Client) RemoveLabelTag(ctx context.Context, label, tag string) error {
+	return c.do(ctx, http.MethodDelete, "/api/labels/"+url.PathEscape(label)+"/tags/"+url.PathEscape(tag), nil, nil)
+}
+
+// ListTags lists all tags with their label counts
+func (c *Client) ListTags(ctx context.Context) ([]Tag, error) {
+	var tags []Tag
+	if err := c.do(ctx, http.MethodGet, "/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CreateTag creates a tag if it doesn't exist
+func (c *Client) CreateTag(ctx context.Context, name string) (*Tag, error) {
+	var t Tag
+	if err := c.do(ctx, http.MethodPost, "/api/tags", map[string]string{"name": name}, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RenameTag renames a tag, merging it into newName if that tag exists
+func (c *Client) RenameTag(ctx context.Context, name, newName string) error {
+	return c.do(ctx, http.MethodPut, "/api/tags/"+url.PathEscape(name), map[string]string{"name": newName}, nil)
+}
+
+// DeleteTag deletes a tag and its label associations
+func (c *Client) DeleteTag(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/tags/"+url.PathEscape(name), nil, nil)
+}
+
+// ImportCSV uploads a CSV file; name (without .csv) is added as a tag to every label
+func (c *Client) ImportCSV(ctx context.Context, name string, r io.Reader) (*ImportResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/import?name="+url.QueryEscape(name), r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/csv")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// ImportURIs makes the server fetch and import CSV files from http(s) URIs
+func (c *Client) ImportURIs(ctx context.Context, uris []string) (*ImportResult, error) {
+	var result ImportResult
+	if err := c.do(ctx, http.MethodPost, "/api/import", map[string][]string{"uris": uris}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ValidateTiers validates a tiers configuration against the server's tags
+func (c *Client) ValidateTiers(ctx context.Context, tiers []Tier) (*TierValidation, error) {
+	var v TierValidation
+	if err := c.do(ctx, http.MethodPost, "/api/tiers/validate", tiers, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// EvaluateTiers evaluates tiers against up to 1000 labels in the database
+func (c *Client) EvaluateTiers(ctx context.Context, req EvaluateRequest) (*EvaluateResult, error) {
+	var result EvaluateResult
+	if err := c.do(ctx, http.MethodPost, "/api/tiers/evaluate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Generate generates a premium list and writes the CSV to w
+func (c *Client) Generate(ctx context.Context, req GenerateRequest, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodPost, "/api/generate", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read premium list: %w", err)
+	}
+	return nil
+}
+
+// SubmitGenerateJob starts a generation in the background
+func (c *Client) SubmitGenerateJob(ctx context.Context, req GenerateRequest) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/api/jobs/generate", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SubmitImportURIsJob starts a background import of CSV files fetched from http(s) URIs
+func (c *Client) SubmitImportURIsJob(ctx context.Context, uris []string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/api/jobs/import", map[string][]string{"uris": uris}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob returns the status and result of a job
+func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs returns the most recent jobs, newest first
+func (c *Client) ListJobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	if err := c.do(ctx, http.MethodGet, "/api/jobs", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a queued job, or stops a running one, which is canceled once WaitJob returns it
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodDelete, "/api/jobs/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitJob polls a job every interval until it is done or ctx ends
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Done() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DownloadJobArtifact writes the artifact of a finished job (e.g. the generated premium list) to w
+func (c *Client) DownloadJobArtifact(ctx context.Context, id string, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id)+"/artifact", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return nil
+}
+
+// do sends a request and decodes the JSON response into out (if not nil)
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends a request with an optional JSON body
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.doRequest(req)
+}
+
+// doRequest sends a prepared request, turning non-2xx responses into *Error
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(resp.Body)
+	var errBody struct {
+		Error  string   `json:"error"`
+		Errors []string `json:"errors"`
+	}
+	switch {
+	case json.Unmarshal(data, &errBody) == nil && errBody.Error != "":
+		apiErr.Message = errBody.Error
+	case len(errBody.Errors) > 0:
+		// Generate returns the tier validation result for invalid tiers
+		apiErr.Message = strings.Join(errBody.Errors, "; ")
+	default:
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return nil, apiErr
+}
+
+// setInt sets a query parameter if the value is not zero
+func setInt(query url.Values, name string, value int) {
+	if value != 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
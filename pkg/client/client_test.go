@@ -0,0 +1,171 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/server"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	srv, err := server.New(database, server.Options{JobDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return New(ts.URL, ts.Client())
+}
+
+func TestClientLabelsAndTags(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := c.CreateLabel(ctx, "Example", "words")
+	if err != nil {
+		t.Fatalf("CreateLabel: %v", err)
+	}
+	if l.Label != "example" || len(l.Tags) != 1 || l.Tags[0] != "words" {
+		t.Errorf("CreateLabel = %+v", l)
+	}
+
+	if _, err := c.AddLabelTags(ctx, "example", "geo"); err != nil {
+		t.Fatalf("AddLabelTags: %v", err)
+	}
+	list, err := c.ListLabels(ctx, LabelFilter{Tags: []string{"words", "geo"}})
+	if err != nil {
+		t.Fatalf("ListLabels: %v", err)
+	}
+	if list.Total != 1 || list.Labels[0].Label != "example" {
+		t.Errorf("ListLabels = %+v", list)
+	}
+
+	if err := c.RenameTag(ctx, "geo", "places"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	tags, err := c.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Errorf("ListTags = %+v", tags)
+	}
+
+	if err := c.DeleteLabel(ctx, "example"); err != nil {
+		t.Fatalf("DeleteLabel: %v", err)
+	}
+	if _, err := c.GetLabel(ctx, "example"); !IsNotFound(err) {
+		t.Errorf("GetLabel after delete: got %v, want not found", err)
+	}
+}
+
+func TestClientGenerate(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := c.CreateLabel(ctx, "abc", "premium"); err != nil {
+		t.Fatalf("CreateLabel: %v", err)
+	}
+
+	price := 100.0
+	tiers := []Tier{{Tier: 1, Tags: []string{"premium"}, Currency: "USD", PriceReg: &price}}
+
+	v, err := c.ValidateTiers(ctx, tiers)
+	if err != nil {
+		t.Fatalf("ValidateTiers: %v", err)
+	}
+	if !v.Valid {
+		t.Errorf("ValidateTiers = %+v, want valid", v)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Generate(ctx, GenerateRequest{Tiers: tiers}, &buf); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !strings.Contains(buf.String(), "abc") {
+		t.Errorf("Generate output missing label:\n%s", buf.String())
+	}
+
+	err = c.Generate(ctx, GenerateRequest{Tiers: []Tier{{Tier: 1}}}, &buf)
+	if err == nil {
+		t.Error("Generate with invalid tiers: expected error")
+	}
+}
+
+func TestClientImportCSV(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	result, err := c.ImportCSV(ctx, "short words.csv", strings.NewReader("label\nfoo\nbar\n-bad\n"))
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	if len(result.Files) != 1 || result.NewLabels != 2 || result.Files[0].ErrorCount != 1 {
+		t.Errorf("ImportCSV = %+v", result)
+	}
+	if errs := result.Files[0].Errors; len(errs) != 1 || errs[0].Kind != "invalid_label" || errs[0].Line != 4 || errs[0].Label != "-bad" {
+		t.Errorf("ImportCSV errors = %+v", errs)
+	}
+
+	l, err := c.GetLabel(ctx, "foo")
+	if err != nil {
+		t.Fatalf("GetLabel: %v", err)
+	}
+	found := false
+	for _, tag := range l.Tags {
+		found = found || tag == "short words"
+	}
+	if !found {
+		t.Errorf("label tags = %v, want filename tag", l.Tags)
+	}
+}
+
+func TestClientGenerateJob(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := c.CreateLabel(ctx, "abc", "premium"); err != nil {
+		t.Fatalf("CreateLabel: %v", err)
+	}
+
+	price := 100.0
+	job, err := c.SubmitGenerateJob(ctx, GenerateRequest{Tiers: []Tier{{Tier: 1, Tags: []string{"premium"}, Currency: "USD", PriceReg: &price}}})
+	if err != nil {
+		t.Fatalf("SubmitGenerateJob: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	job, err = c.WaitJob(ctx, job.ID, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if job.Status != JobSucceeded {
+		t.Fatalf("job status = %s (%s), want succeeded", job.Status, job.Error)
+	}
+
+	var buf bytes.Buffer
+	if err := c.DownloadJobArtifact(ctx, job.ID, &buf); err != nil {
+		t.Fatalf("DownloadJobArtifact: %v", err)
+	}
+	if !strings.Contains(buf.String(), "abc") {
+		t.Errorf("artifact missing label:\n%s", buf.String())
+	}
+
+	if _, err := c.CancelJob(ctx, job.ID); err == nil {
+		t.Error("CancelJob on a finished job: expected error")
+	}
+}
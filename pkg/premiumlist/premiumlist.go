@@ -0,0 +1,242 @@
+// Package premiumlist is the public Go API of premium-list-maker, for services that embed premium list
+// generation instead of running the CLI.
+//
+// A Store is a label database: labels are imported from CSV files or added directly, tagged, and matched
+// against pricing tiers to produce a premium list:
+//
+//	store, err := premiumlist.Open("labels.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	if _, err := store.ImportCSV("dictionary.csv", premiumlist.ImportOptions{AutoTag: true, Tag: "dictionary"}); err != nil {
+//		return err
+//	}
+//	tiers, err := premiumlist.LoadTiers("tiers.json")
+//	if err != nil {
+//		return err
+//	}
+//	entries, _, err := store.Match(tiers, []string{"registered"})
+//
+// Fields and methods of this package are only added, never changed or removed
+package premiumlist
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"premium-list-maker/internal/db"
+	"premium-list-maker/internal/generator"
+	"premium-list-maker/internal/importer"
+	"premium-list-maker/internal/models"
+	"premium-list-maker/internal/progress"
+	"premium-list-maker/internal/tagexpr"
+)
+
+// Tier is a pricing tier: labels carrying any of its tags get its prices, the highest matching tier wins
+type Tier = models.Tier
+
+// Entry is a label of a premium list with its tier and prices
+type Entry = generator.PremiumListEntry
+
+// Label is a label in the store with its tags
+type Label = models.Label
+
+// LabelFilter restricts the labels returned by Store.Labels
+type LabelFilter = db.LabelFilter
+
+// Filter is a parsed filter expression for LabelFilter.Where and GenerateOptions.Where, see ParseFilter
+type Filter = tagexpr.Expr
+
+// ImportStats summarizes a CSV import
+type ImportStats = importer.ImportStats
+
+// ImportError records a row or batch an import couldn't store, see ErrInvalidLabel, ErrParse and ErrBatch
+type ImportError = importer.ImportError
+
+// Kinds of import errors, for errors.Is on an ImportError
+var (
+	ErrInvalidLabel = importer.ErrInvalidLabel
+	ErrParse        = importer.ErrParse
+	ErrBatch        = importer.ErrBatch
+)
+
+// TierValidation holds the problems found in a tiers configuration
+type TierValidation = generator.TierValidation
+
+// Output formats of Store.Generate
+const (
+	FormatDefault = "default"  // Label,Tier,price_reg,price_ren,price_res,currency
+	FormatCNicNew = "cnic-new" // One row per label and price type; needs a TLD
+	FormatXLSX    = "xlsx"     // Workbook with a summary sheet and one sheet per tier
+)
+
+// Store is a label database
+// A Store is safe for use by one goroutine at a time
+type Store struct {
+	db *db.DB
+}
+
+// Open opens the SQLite label database at path, creating it if it doesn't exist
+func Open(path string) (*Store, error) {
+	database, err := db.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// ImportOptions controls a CSV import
+type ImportOptions struct {
+	AutoTag        bool                // Add length tags (len:N) and content tags (e.g. 3 letter shapes)
+	Tag            string              // Tag added to every imported label, if not empty
+	RankThresholds []int               // Add rank:topN tags by position in the file, e.g. 1000, 10000
+	Progress       func(linesRead int) // Called after every batch, may be nil
+	BatchSize      int                 // Labels inserted per batch (default 10000)
+	Validation     string              // ValidationStrict (default) or ValidationLDH
+	Context        context.Context     // Stops the import when done, keeping the labels committed so far
+}
+
+// Validation profiles of ImportOptions
+const (
+	ValidationStrict = importer.ValidationStrict // Valid LDH labels and IDNs
+	ValidationLDH    = importer.ValidationLDH    // Letters, digits and inner hyphens, without the IDN rules
+)
+
+// progress adapts the Progress function to the importer's progress updates
+func (o ImportOptions) progress() progress.Func {
+	if o.Progress == nil {
+		return nil
+	}
+	return func(u progress.Update) { o.Progress(u.Rows) }
+}
+
+// importOptions converts the options to those of the importer
+func (o ImportOptions) importOptions() importer.ImportOption {
+	return importer.WithOptions(importer.ImportOptions{
+		AutoTag:        o.AutoTag,
+		Tag:            o.Tag,
+		RankThresholds: o.RankThresholds,
+		BatchSize:      o.BatchSize,
+		Validation:     o.Validation,
+		Progress:       o.progress(),
+		Context:        o.Context,
+	})
+}
+
+// ImportCSV imports the labels in the first column of a CSV file
+func (s *Store) ImportCSV(path string, opts ImportOptions) (*ImportStats, error) {
+	return importer.ImportCSV(s.db, path, opts.importOptions())
+}
+
+// ImportCSVFrom imports the labels in the first column of CSV read from r, e.g. an upload
+func (s *Store) ImportCSVFrom(r io.Reader, opts ImportOptions) (*ImportStats, error) {
+	return importer.ImportCSVReader(s.db, r, opts.importOptions())
+}
+
+// AddLabels adds labels with the given tags, creating missing labels and tags
+// Labels are normalized (lowercased, U-labels converted to A-labels) and must be valid LDH labels
+func (s *Store) AddLabels(labels []string, tags ...string) error {
+	normalized := make([]string, len(labels))
+	for i, label := range labels {
+		normalized[i] = importer.NormalizeLabel(label)
+		if err := importer.ValidateLabel(normalized[i]); err != nil {
+			return fmt.Errorf("invalid label %q: %w", label, err)
+		}
+	}
+	if len(tags) == 0 {
+		for _, label := range normalized {
+			if _, err := s.db.InsertLabel(label, len(label)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, tag := range tags {
+		if _, err := s.db.TagLabels(normalized, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Labels returns the labels matching the filter, ordered by label
+func (s *Store) Labels(filter LabelFilter) ([]Label, error) {
+	return s.db.ListLabels(filter)
+}
+
+// Match assigns every label to its best tier, leaving out labels carrying any of excludeTags
+// It returns the premium list entries and the number of excluded labels
+func (s *Store) Match(tiers []Tier, excludeTags []string) ([]Entry, int, error) {
+	return generator.MatchLabels(s.db, tiers, excludeTags)
+}
+
+// GenerateOptions controls the premium list written by Store.Generate
+type GenerateOptions struct {
+	Format      string                          // FormatDefault (if empty), FormatCNicNew or FormatXLSX
+	TLD         string                          // Required for FormatCNicNew
+	ExcludeTags []string                        // Leave out labels carrying any of these tags
+	Where       Filter                          // Only list labels matching this filter, nil for all
+	Progress    func(phase string, entries int) // Called when matching, writing and done, may be nil
+	Context     context.Context                 // Stops the generation when done
+}
+
+// options converts the options to those of the generator
+func (o GenerateOptions) options() generator.Options {
+	opts := generator.Options{Format: o.Format, TLD: o.TLD, ExcludeTags: o.ExcludeTags, Where: o.Where, Context: o.Context}
+	if opts.Format == "" {
+		opts.Format = FormatDefault
+	}
+	if o.Progress != nil {
+		opts.Progress = func(u progress.Update) { o.Progress(u.Phase, u.Total) }
+	}
+	return opts
+}
+
+// Generate writes the premium list of the tiers to outputPath
+// It returns the number of entries written
+func (s *Store) Generate(tiers []Tier, outputPath string, opts GenerateOptions) (int, error) {
+	result, err := generator.GenerateFromTiers(s.db, tiers, outputPath, opts.options())
+	if err != nil {
+		return 0, err
+	}
+	return result.Entries, nil
+}
+
+// GenerateTo writes the premium list of the tiers to w, e.g. an HTTP response
+// It returns the number of entries written
+func (s *Store) GenerateTo(tiers []Tier, w io.Writer, opts GenerateOptions) (int, error) {
+	result, err := generator.GenerateTo(s.db, tiers, w, opts.options())
+	if err != nil {
+		return 0, err
+	}
+	return result.Entries, nil
+}
+
+// ParseFilter parses a filter expression combining tags and lengths with AND, OR and NOT,
+// e.g. `brand AND NOT registered AND len <= 5`; tags may use * and ? wildcards
+func ParseFilter(expr string) (Filter, error) {
+	return tagexpr.Parse(expr)
+}
+
+// LoadTiers loads tiers from a JSON file
+func LoadTiers(path string) ([]Tier, error) {
+	return generator.LoadTiers(path)
+}
+
+// ParseTiers parses a tiers JSON document
+func ParseTiers(data []byte) ([]Tier, error) {
+	return generator.ParseTiers(data)
+}
+
+// ValidateTiers checks tiers for structural problems such as duplicate tier numbers or missing prices
+func ValidateTiers(tiers []Tier) *TierValidation {
+	return generator.ValidateTiers(tiers, nil)
+}
@@ -0,0 +1,66 @@
+package premiumlist_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"premium-list-maker/pkg/premiumlist"
+)
+
+func Example() {
+	dir, _ := os.MkdirTemp("", "premiumlist-example")
+	defer os.RemoveAll(dir)
+
+	store, err := premiumlist.Open(filepath.Join(dir, "labels.db"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer store.Close()
+
+	store.AddLabels([]string{"shoes", "Hats"}, "fashion")
+	store.AddLabels([]string{"cars"}, "fashion", "registered")
+
+	price := 250.0
+	tiers := []premiumlist.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+	entries, excluded, err := store.Match(tiers, []string{"registered"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(len(entries), "premium names,", excluded, "excluded")
+	// Output: 2 premium names, 1 excluded
+}
+
+func ExampleStore_GenerateTo() {
+	dir, _ := os.MkdirTemp("", "premiumlist-example")
+	defer os.RemoveAll(dir)
+
+	store, err := premiumlist.Open(filepath.Join(dir, "labels.db"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer store.Close()
+
+	if _, err := store.ImportCSVFrom(strings.NewReader("label\nshoes\nhats\n"), premiumlist.ImportOptions{Tag: "fashion"}); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	price := 250.0
+	tiers := []premiumlist.Tier{{Tier: 1, Tags: []string{"fashion"}, Currency: "USD", PriceReg: &price}}
+	var list strings.Builder
+	if _, err := store.GenerateTo(tiers, &list, premiumlist.GenerateOptions{Format: premiumlist.FormatCNicNew, TLD: "shop"}); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(list.String())
+	// Unordered output:
+	// label,suffix,type,currency,amount
+	// hats,shop,Registration,USD,250.00
+	// shoes,shop,Registration,USD,250.00
+}